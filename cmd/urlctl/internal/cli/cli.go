@@ -0,0 +1,168 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"url-shortener/pkg/client"
+)
+
+// Exit codes of Run
+const (
+	ExitOK    = 0
+	ExitError = 1
+	ExitUsage = 2
+)
+
+// session is what commands work with, the client of the selected profile and the printer of the selected format
+type session struct {
+	client  *client.Client
+	printer printer
+	out     io.Writer
+	errOut  io.Writer
+}
+
+type command struct {
+	usage       string
+	description string
+	run         func(ctx context.Context, s session, args []string) error
+}
+
+// usageError is returned for invalid arguments, the usage of the command is printed with it
+type usageError struct {
+	message string
+}
+
+func newUsageError(format string, args ...interface{}) usageError {
+	return usageError{message: fmt.Sprintf(format, args...)}
+}
+
+func (e usageError) Error() string {
+	return e.message
+}
+
+var commands = map[string]command{
+	"shorten": {"shorten [flags] <long_url>", "create a link", shorten},
+	"get":     {"get <short_url>", "show a link", get},
+	"stats":   {"stats <short_url>", "show the click counts of a link", stats},
+	"list":    {"list [-tag <tag>]... [-folder <folder>] [-limit <n>]", "list links", list},
+	"search":  {"search [-tag <tag>]... [-folder <folder>] [-limit <n>] <text>", "search links", search},
+	"delete":  {"delete <short_url>...", "delete links", deleteLinks},
+	"import":  {"import [-batch-size <n>] <file.csv|file.jsonl>", "create links for the long URLs of a file", importFile},
+	"export":  {"export [-out <file>]", "write the archive of the default namespace (admin)", export},
+	"tenants": {"tenants list | create [-name <name>] [-daily-quota <n>] [-monthly-quota <n>] <id> | suspend <id> | resume <id>",
+		"manage tenants and issue their API keys (admin)", tenants},
+	"counter": {"counter [-tenant <id>]", "show the id counter of a namespace (admin)", counter},
+}
+
+// Run runs the command of args and returns the exit code
+// Global flags select the profile file, the profile and the output format before the command name
+func Run(ctx context.Context, args []string, out, errOut io.Writer) int {
+	flags := flag.NewFlagSet("urlctl", flag.ContinueOnError)
+	flags.SetOutput(errOut)
+	configPath := flags.String("config", ConfigPath(), "profile file")
+	profileName := flags.String("profile", "", "profile to use (default the profile selected in the file, or default)")
+	format := flags.String("output", formatTable, "output format: table or json")
+	flags.Usage = func() { usage(flags, errOut) }
+	if err := flags.Parse(args); err != nil {
+		return ExitUsage
+	}
+
+	if flags.NArg() == 0 {
+		usage(flags, errOut)
+		return ExitUsage
+	}
+
+	name := flags.Arg(0)
+	cmd, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(errOut, "unknown command [%s]\n", name)
+		usage(flags, errOut)
+		return ExitUsage
+	}
+
+	if *format != formatTable && *format != formatJSON {
+		fmt.Fprintf(errOut, "unsupported output format [%s]\n", *format)
+		return ExitUsage
+	}
+
+	profile, err := LoadProfile(*configPath, *profileName)
+	if err != nil {
+		fmt.Fprintln(errOut, err)
+		return ExitError
+	}
+
+	config := client.DefaultConfig(profile.BaseURL, profile.APIKey)
+	config.AdminKey = profile.AdminKey
+	c, err := client.New(config)
+	if err != nil {
+		fmt.Fprintln(errOut, err)
+		return ExitError
+	}
+
+	s := session{client: c, printer: printer{out: out, format: *format}, out: out, errOut: errOut}
+	if err := cmd.run(ctx, s, flags.Args()[1:]); err != nil {
+		var usageErr usageError
+		if errors.As(err, &usageErr) {
+			fmt.Fprintf(errOut, "%v\nusage: urlctl %s\n", err, cmd.usage)
+			return ExitUsage
+		}
+
+		if errors.Is(err, flag.ErrHelp) {
+			return ExitUsage
+		}
+
+		fmt.Fprintln(errOut, err)
+		return ExitError
+	}
+
+	return ExitOK
+}
+
+func usage(flags *flag.FlagSet, errOut io.Writer) {
+	fmt.Fprintln(errOut, "usage: urlctl [-config <file>] [-profile <name>] [-output table|json] <command> [flags] [args]")
+	fmt.Fprintln(errOut, "\ncommands:")
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(errOut, "  %-8s %s\n", name, commands[name].description)
+	}
+
+	fmt.Fprintln(errOut, "\nflags:")
+	flags.PrintDefaults()
+}
+
+// parse parses the flags of a command and checks the number of its remaining args
+func parse(flags *flag.FlagSet, s session, args []string, minArgs, maxArgs int) ([]string, error) {
+	flags.SetOutput(s.errOut)
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+
+	rest := flags.Args()
+	if len(rest) < minArgs || (maxArgs >= 0 && len(rest) > maxArgs) {
+		return nil, newUsageError("wrong number of arguments")
+	}
+
+	return rest, nil
+}
+
+// stringsFlag collects the values of a repeated flag
+type stringsFlag []string
+
+func (f *stringsFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *stringsFlag) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
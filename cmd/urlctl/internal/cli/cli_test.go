@@ -0,0 +1,217 @@
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"url-shortener/cmd/urlctl/internal/cli"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Run", func() {
+	var (
+		server  *httptest.Server
+		routes  map[string]http.HandlerFunc
+		config  string
+		out     *bytes.Buffer
+		errOut  *bytes.Buffer
+		headers http.Header
+	)
+
+	BeforeEach(func() {
+		routes = map[string]http.HandlerFunc{}
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			headers = r.Header
+			if handler, ok := routes[r.Method+" "+r.URL.Path]; ok {
+				handler(w, r)
+				return
+			}
+
+			w.WriteHeader(http.StatusNotFound)
+			io.WriteString(w, `"URL does not exist"`)
+		}))
+
+		dir := GinkgoT().TempDir()
+		config = filepath.Join(dir, "config.json")
+		Expect(os.WriteFile(config, []byte(fmt.Sprintf(`{"profiles":{"default":{"base_url":%q,"api_key":"key","admin_key":"admin"}}}`, server.URL)), 0o600)).
+			To(Succeed())
+		out, errOut = &bytes.Buffer{}, &bytes.Buffer{}
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	run := func(args ...string) int {
+		return cli.Run(context.Background(), append([]string{"-config", config}, args...), out, errOut)
+	}
+
+	respond := func(status int, body string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(status)
+			io.WriteString(w, body)
+		}
+	}
+
+	When("shortening a url", func() {
+		It("should create a link with the flags and print it", func() {
+			routes["POST /api/v1/urls"] = func(w http.ResponseWriter, r *http.Request) {
+				defer GinkgoRecover()
+				var body map[string]interface{}
+				Expect(json.NewDecoder(r.Body).Decode(&body)).To(Succeed())
+				Expect(body).To(HaveKeyWithValue("long_url", "https://example.com"))
+				Expect(body).To(HaveKeyWithValue("tags", ConsistOf("a", "b")))
+				Expect(body).To(HaveKeyWithValue("not_after", "2030-01-01T00:00:00Z"))
+				respond(http.StatusCreated, `{"short_url":"abc","long_url":"https://example.com","tags":["a","b"]}`)(w, r)
+			}
+
+			Expect(run("shorten", "-tag", "a", "-tag", "b", "-not-after", "2030-01-01T00:00:00Z", "https://example.com")).To(Equal(cli.ExitOK))
+			Expect(headers.Get("X-Tenant-Key")).To(Equal("key"))
+			Expect(out.String()).To(MatchRegexp(`short url:\s+abc`))
+			Expect(out.String()).To(MatchRegexp(`tags:\s+a, b`))
+		})
+
+		It("should reject invalid times and missing urls as usage errors", func() {
+			Expect(run("shorten", "-not-after", "tomorrow", "https://example.com")).To(Equal(cli.ExitUsage))
+			Expect(run("shorten")).To(Equal(cli.ExitUsage))
+			Expect(errOut.String()).To(ContainSubstring("usage: urlctl shorten"))
+		})
+	})
+
+	When("getting a link which does not exist", func() {
+		It("should print the error of the service", func() {
+			Expect(run("get", "abc")).To(Equal(cli.ExitError))
+			Expect(errOut.String()).To(ContainSubstring("URL does not exist"))
+		})
+	})
+
+	When("listing links as json", func() {
+		It("should print them as a json array", func() {
+			routes["GET /api/v1/urls"] = func(w http.ResponseWriter, r *http.Request) {
+				defer GinkgoRecover()
+				Expect(r.URL.Query()["tag"]).To(Equal([]string{"sale"}))
+				respond(http.StatusOK, `{"results":[{"short_url":"abc","long_url":"https://example.com"}]}`)(w, r)
+			}
+
+			Expect(run("-output", "json", "list", "-tag", "sale")).To(Equal(cli.ExitOK))
+			Expect(out.String()).To(MatchJSON(`[{"short_url":"abc","long_url":"https://example.com"}]`))
+		})
+	})
+
+	When("searching links", func() {
+		It("should print them as a table", func() {
+			routes["GET /api/v1/urls/search"] = func(w http.ResponseWriter, r *http.Request) {
+				defer GinkgoRecover()
+				Expect(r.URL.Query().Get("q")).To(Equal("spring sale"))
+				respond(http.StatusOK, `{"results":[{"short_url":"abc","long_url":"https://example.com","title":"Spring"}]}`)(w, r)
+			}
+
+			Expect(run("search", "spring", "sale")).To(Equal(cli.ExitOK))
+			Expect(out.String()).To(MatchRegexp(`SHORT URL\s+DOMAIN\s+LONG URL`))
+			Expect(out.String()).To(MatchRegexp(`abc\s+https://example.com\s+Spring`))
+		})
+	})
+
+	When("deleting links", func() {
+		It("should delete each of them", func() {
+			routes["DELETE /api/v1/urls/abc"] = respond(http.StatusNoContent, "")
+			routes["DELETE /api/v1/urls/def"] = respond(http.StatusNoContent, "")
+
+			Expect(run("delete", "abc", "def")).To(Equal(cli.ExitOK))
+			Expect(out.String()).To(MatchRegexp(`def\s+deleted`))
+		})
+	})
+
+	When("importing a file", func() {
+		It("should create its long urls in batches and fail if some failed", func() {
+			var batches [][]string
+			routes["POST /api/v1/urls/bulk"] = func(w http.ResponseWriter, r *http.Request) {
+				defer GinkgoRecover()
+				var body struct {
+					LongURLs []string `json:"long_urls"`
+				}
+				Expect(json.NewDecoder(r.Body).Decode(&body)).To(Succeed())
+				batches = append(batches, body.LongURLs)
+				if len(batches) == 1 {
+					respond(http.StatusOK, `{"results":[{"long_url":"https://first.com","short_url":"a","reused":false},
+						{"long_url":"https://second.com","short_url":"b","reused":true}]}`)(w, r)
+					return
+				}
+
+				respond(http.StatusOK, `{"results":[{"long_url":"https://third.com","reused":false,"error":"Quota exceeded"}]}`)(w, r)
+			}
+
+			path := filepath.Join(GinkgoT().TempDir(), "urls.csv")
+			Expect(os.WriteFile(path, []byte("https://first.com\nhttps://second.com\nhttps://third.com\n"), 0o600)).To(Succeed())
+
+			Expect(run("import", "-batch-size", "2", path)).To(Equal(cli.ExitError))
+			Expect(batches).To(Equal([][]string{{"https://first.com", "https://second.com"}, {"https://third.com"}}))
+			Expect(out.String()).To(MatchRegexp(`https://second.com\s+b\s+reused`))
+			Expect(out.String()).To(MatchRegexp(`https://third.com\s+Quota exceeded`))
+			Expect(errOut.String()).To(ContainSubstring("failed to import 1 of 3 long urls"))
+		})
+	})
+
+	When("managing tenants", func() {
+		It("should create a tenant and print its api key", func() {
+			routes["POST /api/v1/admin/tenants"] = func(w http.ResponseWriter, r *http.Request) {
+				defer GinkgoRecover()
+				Expect(r.Header.Get("X-API-Key")).To(Equal("admin"))
+				var body map[string]interface{}
+				Expect(json.NewDecoder(r.Body).Decode(&body)).To(Succeed())
+				Expect(body).To(Equal(map[string]interface{}{"id": "acme", "name": "Acme", "daily_quota": float64(100)}))
+				respond(http.StatusCreated, `{"id":"acme","name":"Acme","daily_quota":100,"api_key":"secret"}`)(w, r)
+			}
+
+			Expect(run("tenants", "create", "-name", "Acme", "-daily-quota", "100", "acme")).To(Equal(cli.ExitOK))
+			Expect(out.String()).To(MatchRegexp(`api key:\s+secret`))
+		})
+
+		It("should suspend a tenant", func() {
+			routes["POST /api/v1/admin/tenants/acme/suspend"] = respond(http.StatusNoContent, "")
+			Expect(run("tenants", "suspend", "acme")).To(Equal(cli.ExitOK))
+		})
+
+		It("should reject unknown tenants commands", func() {
+			Expect(run("tenants", "rename", "acme")).To(Equal(cli.ExitUsage))
+		})
+	})
+
+	When("inspecting the counter", func() {
+		It("should print the state of the namespace", func() {
+			routes["GET /api/v1/admin/counter"] = func(w http.ResponseWriter, r *http.Request) {
+				defer GinkgoRecover()
+				Expect(r.URL.Query().Get("tenant")).To(Equal("acme"))
+				respond(http.StatusOK, `{"tenant":"acme","count":61,"next_short_url":"10"}`)(w, r)
+			}
+
+			Expect(run("-output", "json", "counter", "-tenant", "acme")).To(Equal(cli.ExitOK))
+			Expect(out.String()).To(MatchJSON(`{"tenant":"acme","count":61,"next_short_url":"10"}`))
+		})
+	})
+
+	When("exporting the archive", func() {
+		It("should write it to the file", func() {
+			archive := `{"type":"counter","count":1}` + "\n"
+			routes["GET /api/v1/admin/export"] = respond(http.StatusOK, archive)
+			path := filepath.Join(GinkgoT().TempDir(), "archive.jsonl")
+
+			Expect(run("export", "-out", path)).To(Equal(cli.ExitOK))
+			Expect(os.ReadFile(path)).To(Equal([]byte(archive)))
+		})
+	})
+
+	It("should reject unknown commands and output formats", func() {
+		Expect(run("rename")).To(Equal(cli.ExitUsage))
+		Expect(run("-output", "yaml", "list")).To(Equal(cli.ExitUsage))
+	})
+})
@@ -0,0 +1,369 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+	"url-shortener/pkg/client"
+	"url-shortener/pkg/urlfile"
+)
+
+// defaultBatchSize is the number of long URLs sent per bulk request, the default bulk limit of the service
+const defaultBatchSize = 1000
+
+func shorten(ctx context.Context, s session, args []string) error {
+	flags := flag.NewFlagSet("shorten", flag.ContinueOnError)
+	var (
+		request client.CreateRequest
+		tags    stringsFlag
+	)
+	flags.StringVar(&request.Domain, "domain", "", "branded domain of the link (default the domain of the profile)")
+	flags.StringVar(&request.Title, "title", "", "title of the link")
+	flags.StringVar(&request.Description, "description", "", "description of the link")
+	flags.Var(&tags, "tag", "tag of the link, can be repeated")
+	flags.StringVar(&request.Folder, "folder", "", "folder of the link, e.g. campaigns/spring")
+	flags.Int64Var(&request.MaxClicks, "max-clicks", 0, "number of redirects after which the link stops working")
+	flags.StringVar(&request.Password, "password", "", "password required before redirecting")
+	notBefore := flags.String("not-before", "", "RFC 3339 time the link starts redirecting")
+	notAfter := flags.String("not-after", "", "RFC 3339 time the link stops redirecting")
+	rest, err := parse(flags, s, args, 1, 1)
+	if err != nil {
+		return err
+	}
+
+	request.LongURL = rest[0]
+	request.Tags = tags
+	if request.NotBefore, err = parseTime("not-before", *notBefore); err != nil {
+		return err
+	}
+
+	if request.NotAfter, err = parseTime("not-after", *notAfter); err != nil {
+		return err
+	}
+
+	link, err := s.client.Create(ctx, request)
+	if err != nil {
+		return err
+	}
+
+	return printLink(s, link)
+}
+
+func get(ctx context.Context, s session, args []string) error {
+	rest, err := parse(flag.NewFlagSet("get", flag.ContinueOnError), s, args, 1, 1)
+	if err != nil {
+		return err
+	}
+
+	link, err := s.client.Get(ctx, rest[0])
+	if err != nil {
+		return err
+	}
+
+	return printLink(s, link)
+}
+
+func stats(ctx context.Context, s session, args []string) error {
+	rest, err := parse(flag.NewFlagSet("stats", flag.ContinueOnError), s, args, 1, 1)
+	if err != nil {
+		return err
+	}
+
+	stats, err := s.client.Stats(ctx, rest[0])
+	if err != nil {
+		return err
+	}
+
+	fields := [][2]string{
+		{"short url", stats.ShortURL},
+		{"domain", stats.Domain},
+		{"clicks", fmt.Sprint(stats.Clicks)},
+		{"max clicks", formatInt(stats.MaxClicks)},
+	}
+
+	for _, variant := range sortedKeys(stats.VariantClicks) {
+		fields = append(fields, [2]string{"variant " + variant, fmt.Sprint(stats.VariantClicks[variant])})
+	}
+
+	return s.printer.fields(stats, fields...)
+}
+
+func list(ctx context.Context, s session, args []string) error {
+	flags, options := listFlags("list")
+	if _, err := parse(flags, s, args, 0, 0); err != nil {
+		return err
+	}
+
+	links, err := s.client.List(ctx, *options)
+	if err != nil {
+		return err
+	}
+
+	return printLinks(s, links)
+}
+
+func search(ctx context.Context, s session, args []string) error {
+	flags, options := listFlags("search")
+	rest, err := parse(flags, s, args, 1, -1)
+	if err != nil {
+		return err
+	}
+
+	links, err := s.client.Search(ctx, strings.Join(rest, " "), *options)
+	if err != nil {
+		return err
+	}
+
+	return printLinks(s, links)
+}
+
+func deleteLinks(ctx context.Context, s session, args []string) error {
+	rest, err := parse(flag.NewFlagSet("delete", flag.ContinueOnError), s, args, 1, -1)
+	if err != nil {
+		return err
+	}
+
+	deleted := make([]string, 0, len(rest))
+	for _, code := range rest {
+		if err := s.client.Delete(ctx, code); err != nil {
+			return fmt.Errorf("%w, deleted %d of %d links", err, len(deleted), len(rest))
+		}
+
+		deleted = append(deleted, code)
+	}
+
+	rows := make([][]string, len(deleted))
+	for i, code := range deleted {
+		rows[i] = []string{code, "deleted"}
+	}
+
+	return s.printer.print(map[string][]string{"deleted": deleted}, []string{"SHORT URL", "STATUS"}, rows)
+}
+
+func importFile(ctx context.Context, s session, args []string) error {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	batchSize := flags.Int("batch-size", defaultBatchSize, "number of long URLs per request, at most the bulk limit of the service")
+	rest, err := parse(flags, s, args, 1, 1)
+	if err != nil {
+		return err
+	}
+
+	if *batchSize < 1 {
+		return newUsageError("batch size must be positive")
+	}
+
+	longURLs, err := urlfile.Read(rest[0])
+	if err != nil {
+		return err
+	}
+
+	var (
+		results []client.BulkResult
+		failed  int
+	)
+	for start := 0; start < len(longURLs); start += *batchSize {
+		end := start + *batchSize
+		if end > len(longURLs) {
+			end = len(longURLs)
+		}
+
+		batch, err := s.client.CreateBulk(ctx, longURLs[start:end])
+		if err != nil {
+			return fmt.Errorf("%w, imported %d of %d long urls", err, start, len(longURLs))
+		}
+
+		results = append(results, batch...)
+	}
+
+	rows := make([][]string, len(results))
+	for i, result := range results {
+		status := "created"
+		switch {
+		case result.Error != "":
+			status = result.Error
+			failed++
+		case result.Reused:
+			status = "reused"
+		}
+
+		rows[i] = []string{result.LongURL, result.ShortURL, status}
+	}
+
+	if err := s.printer.print(results, []string{"LONG URL", "SHORT URL", "STATUS"}, rows); err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("failed to import %d of %d long urls", failed, len(results))
+	}
+
+	return nil
+}
+
+func export(ctx context.Context, s session, args []string) error {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	out := flags.String("out", "", "archive file to write (default stdout)")
+	if _, err := parse(flags, s, args, 0, 0); err != nil {
+		return err
+	}
+
+	if *out == "" {
+		return s.client.Export(ctx, s.out)
+	}
+
+	file, err := os.Create(*out)
+	if err != nil {
+		return fmt.Errorf("failed to create archive: %w", err)
+	}
+
+	if err := s.client.Export(ctx, file); err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+func tenants(ctx context.Context, s session, args []string) error {
+	if len(args) == 0 {
+		return newUsageError("missing tenants command")
+	}
+
+	switch args[0] {
+	case "list":
+		if _, err := parse(flag.NewFlagSet("tenants list", flag.ContinueOnError), s, args[1:], 0, 0); err != nil {
+			return err
+		}
+
+		list, err := s.client.ListTenants(ctx)
+		if err != nil {
+			return err
+		}
+
+		rows := make([][]string, len(list))
+		for i, tenant := range list {
+			rows[i] = []string{tenant.ID, tenant.Name, fmt.Sprint(tenant.Suspended), formatInt(tenant.DailyQuota),
+				formatInt(tenant.MonthlyQuota), tenant.CreatedAt.Format(time.RFC3339)}
+		}
+
+		return s.printer.print(list, []string{"ID", "NAME", "SUSPENDED", "DAILY QUOTA", "MONTHLY QUOTA", "CREATED"}, rows)
+	case "create":
+		flags := flag.NewFlagSet("tenants create", flag.ContinueOnError)
+		var tenant client.Tenant
+		flags.StringVar(&tenant.Name, "name", "", "display name of the tenant")
+		flags.Int64Var(&tenant.DailyQuota, "daily-quota", 0, "links created per UTC day, 0 means unlimited")
+		flags.Int64Var(&tenant.MonthlyQuota, "monthly-quota", 0, "links created per UTC month, 0 means unlimited")
+		rest, err := parse(flags, s, args[1:], 1, 1)
+		if err != nil {
+			return err
+		}
+
+		tenant.ID = rest[0]
+		created, key, err := s.client.CreateTenant(ctx, tenant)
+		if err != nil {
+			return err
+		}
+
+		response := struct {
+			client.Tenant
+			APIKey string `json:"api_key"`
+		}{created, key}
+		return s.printer.fields(response,
+			[2]string{"id", created.ID},
+			[2]string{"name", created.Name},
+			[2]string{"daily quota", formatInt(created.DailyQuota)},
+			[2]string{"monthly quota", formatInt(created.MonthlyQuota)},
+			[2]string{"api key", key},
+		)
+	case "suspend", "resume":
+		rest, err := parse(flag.NewFlagSet("tenants "+args[0], flag.ContinueOnError), s, args[1:], 1, 1)
+		if err != nil {
+			return err
+		}
+
+		suspended := args[0] == "suspend"
+		if err := s.client.SuspendTenant(ctx, rest[0], suspended); err != nil {
+			return err
+		}
+
+		return s.printer.fields(map[string]interface{}{"id": rest[0], "suspended": suspended},
+			[2]string{"id", rest[0]},
+			[2]string{"suspended", fmt.Sprint(suspended)},
+		)
+	default:
+		return newUsageError("unknown tenants command [%s]", args[0])
+	}
+}
+
+func counter(ctx context.Context, s session, args []string) error {
+	flags := flag.NewFlagSet("counter", flag.ContinueOnError)
+	tenant := flags.String("tenant", "", "tenant of the namespace (default the default namespace)")
+	if _, err := parse(flags, s, args, 0, 0); err != nil {
+		return err
+	}
+
+	state, err := s.client.CounterState(ctx, *tenant)
+	if err != nil {
+		return err
+	}
+
+	return s.printer.fields(state,
+		[2]string{"tenant", state.Tenant},
+		[2]string{"count", fmt.Sprint(state.Count)},
+		[2]string{"next short url", state.NextShortURL},
+	)
+}
+
+func listFlags(name string) (*flag.FlagSet, *client.ListOptions) {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	options := &client.ListOptions{}
+	flags.Var((*stringsFlag)(&options.Tags), "tag", "tag the links must have, can be repeated")
+	flags.StringVar(&options.Folder, "folder", "", "folder the links must be within")
+	flags.IntVar(&options.Limit, "limit", 0, "maximum number of links (default the service default)")
+	return flags, options
+}
+
+func printLink(s session, link client.Link) error {
+	return s.printer.fields(link,
+		[2]string{"short url", link.ShortURL},
+		[2]string{"domain", link.Domain},
+		[2]string{"long url", link.LongURL},
+		[2]string{"title", link.Title},
+		[2]string{"description", link.Description},
+		[2]string{"tags", strings.Join(link.Tags, ", ")},
+		[2]string{"folder", link.Folder},
+		[2]string{"max clicks", formatInt(link.MaxClicks)},
+		[2]string{"not before", formatTime(link.NotBefore)},
+		[2]string{"not after", formatTime(link.NotAfter)},
+		[2]string{"protected", formatBool(link.Protected)},
+	)
+}
+
+func printLinks(s session, links []client.Link) error {
+	rows := make([][]string, len(links))
+	for i, link := range links {
+		rows[i] = []string{link.ShortURL, link.Domain, link.LongURL, link.Title, strings.Join(link.Tags, ","), link.Folder}
+	}
+
+	if links == nil {
+		links = []client.Link{}
+	}
+
+	return s.printer.print(links, []string{"SHORT URL", "DOMAIN", "LONG URL", "TITLE", "TAGS", "FOLDER"}, rows)
+}
+
+func parseTime(name, value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, newUsageError("invalid %s [%s], expected an RFC 3339 time", name, value)
+	}
+
+	return &t, nil
+}
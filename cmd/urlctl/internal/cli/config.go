@@ -0,0 +1,83 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+const (
+	defaultProfile = "default"
+	defaultBaseURL = "http://localhost:8080"
+)
+
+// Profile holds the address and the keys used to call one deployment of the service
+type Profile struct {
+	BaseURL string `json:"base_url"`
+	// APIKey is the tenant key, the default namespace is used if empty
+	APIKey string `json:"api_key,omitempty"`
+	// AdminKey is required by the tenants, counter and export commands and by delete in the default namespace
+	AdminKey string `json:"admin_key,omitempty"`
+}
+
+// Config is the profile file, Profile names the profile used when none is selected
+type Config struct {
+	Profile  string             `json:"profile,omitempty"`
+	Profiles map[string]Profile `json:"profiles"`
+}
+
+// ConfigPath returns the path of the profile file, URLCTL_CONFIG or urlctl/config.json in the user config directory
+func ConfigPath() string {
+	if path := os.Getenv("URLCTL_CONFIG"); path != "" {
+		return path
+	}
+
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "urlctl.json"
+	}
+
+	return filepath.Join(dir, "urlctl", "config.json")
+}
+
+// LoadProfile returns the profile of the name from the file at path, an empty name selects the default profile of the file
+// Without a file, the default profile calls a local service
+func LoadProfile(path, name string) (Profile, error) {
+	var config Config
+	data, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+	case err != nil:
+		return Profile{}, fmt.Errorf("failed to read config: %w", err)
+	default:
+		if err := json.Unmarshal(data, &config); err != nil {
+			return Profile{}, fmt.Errorf("failed to parse config [%s]: %w", path, err)
+		}
+	}
+
+	if name == "" {
+		name = config.Profile
+	}
+
+	if name == "" {
+		name = defaultProfile
+	}
+
+	profile, ok := config.Profiles[name]
+	if !ok {
+		if name != defaultProfile {
+			return Profile{}, fmt.Errorf("profile [%s] does not exist in [%s]", name, path)
+		}
+
+		profile = Profile{BaseURL: defaultBaseURL}
+	}
+
+	if profile.BaseURL == "" {
+		return Profile{}, fmt.Errorf("profile [%s] has no base_url", name)
+	}
+
+	return profile, nil
+}
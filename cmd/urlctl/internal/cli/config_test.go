@@ -0,0 +1,67 @@
+package cli_test
+
+import (
+	"os"
+	"path/filepath"
+	"url-shortener/cmd/urlctl/internal/cli"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("LoadProfile", func() {
+	var path string
+
+	BeforeEach(func() {
+		path = filepath.Join(GinkgoT().TempDir(), "config.json")
+	})
+
+	When("there is no profile file", func() {
+		It("should call a local service with the default profile", func() {
+			profile, err := cli.LoadProfile(path, "")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(profile.BaseURL).To(Equal("http://localhost:8080"))
+
+			_, err = cli.LoadProfile(path, "prod")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	When("there is a profile file", func() {
+		BeforeEach(func() {
+			Expect(os.WriteFile(path, []byte(`{
+				"profile": "prod",
+				"profiles": {
+					"prod": {"base_url": "https://sho.rt", "api_key": "key", "admin_key": "admin"},
+					"staging": {"base_url": "https://staging.sho.rt"},
+					"broken": {}
+				}
+			}`), 0o600)).To(Succeed())
+		})
+
+		It("should use the selected profile of the file by default", func() {
+			profile, err := cli.LoadProfile(path, "")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(profile).To(Equal(cli.Profile{BaseURL: "https://sho.rt", APIKey: "key", AdminKey: "admin"}))
+		})
+
+		It("should use the profile of the name", func() {
+			profile, err := cli.LoadProfile(path, "staging")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(profile.BaseURL).To(Equal("https://staging.sho.rt"))
+		})
+
+		It("should reject profiles without base url", func() {
+			_, err := cli.LoadProfile(path, "broken")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	When("the profile file is invalid", func() {
+		It("should return an error", func() {
+			Expect(os.WriteFile(path, []byte(`profiles:`), 0o600)).To(Succeed())
+			_, err := cli.LoadProfile(path, "")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
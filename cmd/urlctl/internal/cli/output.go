@@ -0,0 +1,89 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+)
+
+// printer writes results as aligned tables for humans or as JSON for scripts
+type printer struct {
+	out    io.Writer
+	format string
+}
+
+// print writes value as JSON, or as a table of the header and the rows
+func (p printer) print(value interface{}, header []string, rows [][]string) error {
+	if p.format == formatJSON {
+		encoder := json.NewEncoder(p.out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(value)
+	}
+
+	writer := tabwriter.NewWriter(p.out, 0, 0, 2, ' ', 0)
+	if header != nil {
+		fmt.Fprintln(writer, strings.Join(header, "\t"))
+	}
+
+	for _, row := range rows {
+		fmt.Fprintln(writer, strings.Join(row, "\t"))
+	}
+
+	return writer.Flush()
+}
+
+// fields writes value as JSON, or as a table of one name and value per row
+func (p printer) fields(value interface{}, fields ...[2]string) error {
+	rows := make([][]string, 0, len(fields))
+	for _, field := range fields {
+		if field[1] != "" {
+			rows = append(rows, []string{field[0] + ":", field[1]})
+		}
+	}
+
+	return p.print(value, nil, rows)
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+
+	return t.Format(time.RFC3339)
+}
+
+func formatInt(n int64) string {
+	if n == 0 {
+		return ""
+	}
+
+	return fmt.Sprint(n)
+}
+
+// formatBool returns yes for true and nothing for false, so false fields are left out
+func formatBool(b bool) string {
+	if !b {
+		return ""
+	}
+
+	return "yes"
+}
+
+func sortedKeys(m map[string]int64) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
@@ -2,13 +2,96 @@ package env
 
 import (
 	"fmt"
+	"net"
+	"time"
+	"url-shortener/pkg/redirect"
 
 	"github.com/kelseyhightower/envconfig"
 )
 
+const (
+	// MigrationDualWrite keeps the default Firestore project as primary store and mirrors writes to the migration project
+	MigrationDualWrite = "dual-write"
+	// MigrationCutover makes the migration project the primary store and mirrors writes back to the default project
+	MigrationCutover = "cutover"
+
+	// SearchIndexFirestore keeps the search index in Firestore, shared by all instances
+	SearchIndexFirestore = "firestore"
+	// SearchIndexMemory keeps the search index in process, it is built from the stored URLs on first use
+	SearchIndexMemory = "memory"
+)
+
 type AppConfig struct {
-	Host string `envconfig:"HOST" default:"localhost"`
-	Port int    `envconfig:"PORT" default:"8080"`
+	Host         string `envconfig:"HOST" default:"localhost"`
+	Port         int    `envconfig:"PORT" default:"8080"`
+	RedirectType int    `envconfig:"REDIRECT_TYPE" default:"302"`
+	BulkLimit    int    `envconfig:"BULK_LIMIT" default:"1000"`
+	PublicURL    string `envconfig:"PUBLIC_URL"`
+	QRCacheSize  int    `envconfig:"QR_CACHE_SIZE" default:"1000"`
+	// CookieSecret signs password access cookies, a random one is used if empty
+	CookieSecret        string        `envconfig:"COOKIE_SECRET"`
+	PasswordCookieTTL   time.Duration `envconfig:"PASSWORD_COOKIE_TTL" default:"1h"`
+	PasswordMaxAttempts int           `envconfig:"PASSWORD_MAX_ATTEMPTS" default:"5"`
+	PasswordLockout     time.Duration `envconfig:"PASSWORD_LOCKOUT" default:"15m"`
+	// GeoIPFile is a CSV file of networks and country codes used by country routing rules
+	GeoIPFile string `envconfig:"GEOIP_FILE"`
+	// TrustedProxies are IPs or CIDRs of proxies whose X-Forwarded-For header sets the client IP, no proxy is trusted if empty
+	TrustedProxies []string `envconfig:"TRUSTED_PROXIES"`
+	// AdminAPIKey protects the admin API, which is disabled if empty
+	AdminAPIKey string `envconfig:"ADMIN_API_KEY"`
+	// DomainCacheTTL is how long registered domains are cached before they are reloaded
+	DomainCacheTTL time.Duration `envconfig:"DOMAIN_CACHE_TTL" default:"1m"`
+	// TenantCacheTTL is how long tenants are cached before they are reloaded, so suspensions apply to all instances
+	TenantCacheTTL time.Duration `envconfig:"TENANT_CACHE_TTL" default:"1m"`
+	// SearchIndex is where links are indexed for search, either firestore or memory
+	SearchIndex string `envconfig:"SEARCH_INDEX" default:"firestore"`
+	// UnfurlEnabled fetches the title, description and image of destination pages to preview links in chat apps
+	UnfurlEnabled   bool          `envconfig:"UNFURL_ENABLED" default:"true"`
+	UnfurlTimeout   time.Duration `envconfig:"UNFURL_TIMEOUT" default:"5s"`
+	UnfurlWorkers   int           `envconfig:"UNFURL_WORKERS" default:"4"`
+	UnfurlQueueSize int           `envconfig:"UNFURL_QUEUE_SIZE" default:"1000"`
+	// HealthCheckEnabled runs the monitor checking destinations, it should run on a single instance only
+	HealthCheckEnabled      bool          `envconfig:"HEALTHCHECK_ENABLED" default:"false"`
+	HealthCheckInterval     time.Duration `envconfig:"HEALTHCHECK_INTERVAL" default:"24h"`
+	HealthCheckTimeout      time.Duration `envconfig:"HEALTHCHECK_TIMEOUT" default:"10s"`
+	HealthCheckHostInterval time.Duration `envconfig:"HEALTHCHECK_HOST_INTERVAL" default:"1s"`
+	HealthCheckJitter       time.Duration `envconfig:"HEALTHCHECK_JITTER" default:"500ms"`
+	HealthCheckWorkers      int           `envconfig:"HEALTHCHECK_WORKERS" default:"8"`
+	HealthCheckHistory      int           `envconfig:"HEALTHCHECK_HISTORY" default:"10"`
+	// HealthCheckBrokenAfter is how long a destination must fail before its link is reported as broken
+	HealthCheckBrokenAfter time.Duration `envconfig:"HEALTHCHECK_BROKEN_AFTER" default:"72h"`
+	// WebhookDispatcherEnabled sends the queued webhook deliveries and publishes expired links,
+	// it should run on a single instance only as expired links would be published by every instance
+	WebhookDispatcherEnabled bool          `envconfig:"WEBHOOK_DISPATCHER_ENABLED" default:"false"`
+	WebhookPollInterval      time.Duration `envconfig:"WEBHOOK_POLL_INTERVAL" default:"5s"`
+	WebhookExpiredInterval   time.Duration `envconfig:"WEBHOOK_EXPIRED_INTERVAL" default:"1m"`
+	WebhookTimeout           time.Duration `envconfig:"WEBHOOK_TIMEOUT" default:"10s"`
+	WebhookWorkers           int           `envconfig:"WEBHOOK_WORKERS" default:"4"`
+	WebhookBatchSize         int           `envconfig:"WEBHOOK_BATCH_SIZE" default:"50"`
+	// WebhookMaxAttempts failed attempts give a delivery up, retries wait from WebhookBackoffBase doubling up to WebhookBackoffMax
+	WebhookMaxAttempts int           `envconfig:"WEBHOOK_MAX_ATTEMPTS" default:"8"`
+	WebhookBackoffBase time.Duration `envconfig:"WEBHOOK_BACKOFF_BASE" default:"30s"`
+	WebhookBackoffMax  time.Duration `envconfig:"WEBHOOK_BACKOFF_MAX" default:"6h"`
+	// WebhookClickThresholds are the counted clicks of a link at which a click event is sent
+	WebhookClickThresholds []int64 `envconfig:"WEBHOOK_CLICK_THRESHOLDS" default:"100,1000,10000"`
+	// ChangesPollInterval is how often streams of changes check for new ones
+	ChangesPollInterval time.Duration `envconfig:"CHANGES_POLL_INTERVAL" default:"1s"`
+	// GRPCPort serves the gRPC API, it is disabled if 0. GRPCTimeout is the deadline of calls sent without an earlier one
+	GRPCPort    int           `envconfig:"GRPC_PORT" default:"9090"`
+	GRPCTimeout time.Duration `envconfig:"GRPC_TIMEOUT" default:"10s"`
+	// OpenAPIValidateRequests rejects requests which do not match the OpenAPI spec,
+	// OpenAPIValidateResponses logs JSON responses which do not match it
+	OpenAPIValidateRequests  bool `envconfig:"OPENAPI_VALIDATE_REQUESTS" default:"true"`
+	OpenAPIValidateResponses bool `envconfig:"OPENAPI_VALIDATE_RESPONSES" default:"false"`
+	// ShortHosts are hosts serving the default domain besides the host of PublicURL, destinations on them
+	// and on branded domains are followed up to LoopMaxDepth short URLs to reject redirect loops
+	ShortHosts   []string `envconfig:"SHORT_HOSTS"`
+	LoopMaxDepth int      `envconfig:"LOOP_MAX_DEPTH" default:"3"`
+	// OutboundAllowedNetworks are CIDRs of internal networks requests to destinations may reach nevertheless
+	OutboundAllowedNetworks []string `envconfig:"OUTBOUND_ALLOWED_NETWORKS"`
+	// MigrationMode is empty unless the service is being migrated to the store of MigrationProject
+	MigrationMode    string `envconfig:"MIGRATION_MODE"`
+	MigrationProject string `envconfig:"MIGRATION_FIRESTORE_PROJECT"`
 }
 
 // LoadAppConfig binds environment variables to application config
@@ -18,5 +101,59 @@ func LoadAppConfig() (AppConfig, error) {
 		return AppConfig{}, fmt.Errorf("failed to load app config: %v", err)
 	}
 
+	if !redirect.IsValid(config.RedirectType) {
+		return AppConfig{}, fmt.Errorf("unsupported redirect type [%d]", config.RedirectType)
+	}
+
+	if config.SearchIndex != SearchIndexFirestore && config.SearchIndex != SearchIndexMemory {
+		return AppConfig{}, fmt.Errorf("unsupported search index [%s]", config.SearchIndex)
+	}
+
+	if config.WebhookWorkers < 1 || config.WebhookBatchSize < 1 || config.WebhookMaxAttempts < 1 {
+		return AppConfig{}, fmt.Errorf("webhook workers, batch size and max attempts must be positive")
+	}
+
+	if config.UnfurlWorkers < 1 {
+		return AppConfig{}, fmt.Errorf("unfurl workers must be positive")
+	}
+
+	if config.HealthCheckWorkers < 1 {
+		return AppConfig{}, fmt.Errorf("health check workers must be positive")
+	}
+
+	if config.ChangesPollInterval <= 0 {
+		return AppConfig{}, fmt.Errorf("changes poll interval must be positive")
+	}
+
+	if config.GRPCPort != 0 && config.GRPCPort == config.Port {
+		return AppConfig{}, fmt.Errorf("grpc port must differ from port [%d]", config.Port)
+	}
+
+	if config.GRPCTimeout <= 0 {
+		return AppConfig{}, fmt.Errorf("grpc timeout must be positive")
+	}
+
+	for _, proxy := range config.TrustedProxies {
+		if _, _, err := net.ParseCIDR(proxy); err != nil && net.ParseIP(proxy) == nil {
+			return AppConfig{}, fmt.Errorf("invalid trusted proxy [%s], expected IP or CIDR", proxy)
+		}
+	}
+
+	for _, cidr := range config.OutboundAllowedNetworks {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			return AppConfig{}, fmt.Errorf("invalid outbound allowed network [%s]: %v", cidr, err)
+		}
+	}
+
+	switch config.MigrationMode {
+	case "":
+	case MigrationDualWrite, MigrationCutover:
+		if config.MigrationProject == "" {
+			return AppConfig{}, fmt.Errorf("migration mode [%s] requires a migration project", config.MigrationMode)
+		}
+	default:
+		return AppConfig{}, fmt.Errorf("unsupported migration mode [%s]", config.MigrationMode)
+	}
+
 	return config, nil
 }
@@ -1,6 +1,7 @@
 package env_test
 
 import (
+	"net/http"
 	"os"
 	"strconv"
 
@@ -14,6 +15,9 @@ var _ = Describe("Env", func() {
 	const (
 		hostEnv     = "HOST"
 		portEnv     = "PORT"
+		redirectEnv = "REDIRECT_TYPE"
+		modeEnv     = "MIGRATION_MODE"
+		projectEnv  = "MIGRATION_FIRESTORE_PROJECT"
 		host        = "127.0.0.1"
 		port        = 8080
 		invalidPort = "invalid"
@@ -52,6 +56,211 @@ var _ = Describe("Env", func() {
 			Expect(err).ToNot(HaveOccurred())
 			Expect(config.Host).To(Equal(host))
 			Expect(config.Port).To(Equal(port))
+			Expect(config.RedirectType).To(Equal(http.StatusFound))
+			Expect(config.SearchIndex).To(Equal(env.SearchIndexFirestore))
+			Expect(config.WebhookClickThresholds).To(Equal([]int64{100, 1000, 10000}))
+			Expect(config.GRPCPort).To(Equal(9090))
+			Expect(config.OpenAPIValidateRequests).To(BeTrue())
+			Expect(config.OpenAPIValidateResponses).To(BeFalse())
+		})
+	})
+
+	When("webhook max attempts are not positive", func() {
+		BeforeEach(func() {
+			Expect(os.Setenv("WEBHOOK_MAX_ATTEMPTS", "0")).To(Succeed())
+		})
+
+		AfterEach(func() {
+			Expect(os.Unsetenv("WEBHOOK_MAX_ATTEMPTS")).To(Succeed())
+		})
+
+		It("should return an error", func() {
+			_, err := env.LoadAppConfig()
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	When("unfurl workers are not positive", func() {
+		BeforeEach(func() {
+			Expect(os.Setenv("UNFURL_WORKERS", "0")).To(Succeed())
+		})
+
+		AfterEach(func() {
+			Expect(os.Unsetenv("UNFURL_WORKERS")).To(Succeed())
+		})
+
+		It("should return an error", func() {
+			_, err := env.LoadAppConfig()
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	When("health check workers are not positive", func() {
+		BeforeEach(func() {
+			Expect(os.Setenv("HEALTHCHECK_WORKERS", "0")).To(Succeed())
+		})
+
+		AfterEach(func() {
+			Expect(os.Unsetenv("HEALTHCHECK_WORKERS")).To(Succeed())
+		})
+
+		It("should return an error", func() {
+			_, err := env.LoadAppConfig()
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	When("changes poll interval is not positive", func() {
+		BeforeEach(func() {
+			Expect(os.Setenv("CHANGES_POLL_INTERVAL", "0s")).To(Succeed())
+		})
+
+		AfterEach(func() {
+			Expect(os.Unsetenv("CHANGES_POLL_INTERVAL")).To(Succeed())
+		})
+
+		It("should return an error", func() {
+			_, err := env.LoadAppConfig()
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	When("grpc port is the http port", func() {
+		BeforeEach(func() {
+			Expect(os.Setenv("GRPC_PORT", "8080")).To(Succeed())
+		})
+
+		AfterEach(func() {
+			Expect(os.Unsetenv("GRPC_PORT")).To(Succeed())
+		})
+
+		It("should return an error", func() {
+			_, err := env.LoadAppConfig()
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	When("an outbound allowed network is not a CIDR", func() {
+		BeforeEach(func() {
+			Expect(os.Setenv("OUTBOUND_ALLOWED_NETWORKS", "10.0.0.0/8,internal")).To(Succeed())
+		})
+
+		AfterEach(func() {
+			Expect(os.Unsetenv("OUTBOUND_ALLOWED_NETWORKS")).To(Succeed())
+		})
+
+		It("should return an error", func() {
+			_, err := env.LoadAppConfig()
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	When("a trusted proxy is neither an IP nor a CIDR", func() {
+		BeforeEach(func() {
+			Expect(os.Setenv("TRUSTED_PROXIES", "10.0.0.1,10.1.0.0/16,proxy")).To(Succeed())
+		})
+
+		AfterEach(func() {
+			Expect(os.Unsetenv("TRUSTED_PROXIES")).To(Succeed())
+		})
+
+		It("should return an error", func() {
+			_, err := env.LoadAppConfig()
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	When("search index is not supported", func() {
+		BeforeEach(func() {
+			Expect(os.Setenv("SEARCH_INDEX", "elastic")).To(Succeed())
+		})
+
+		AfterEach(func() {
+			Expect(os.Unsetenv("SEARCH_INDEX")).To(Succeed())
+		})
+
+		It("should return an error", func() {
+			_, err := env.LoadAppConfig()
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	When("redirect type is not supported", func() {
+		BeforeEach(func() {
+			Expect(os.Setenv(redirectEnv, strconv.Itoa(http.StatusOK))).To(Succeed())
+		})
+
+		AfterEach(func() {
+			Expect(os.Unsetenv(redirectEnv)).To(Succeed())
+		})
+
+		It("should return an error", func() {
+			_, err := env.LoadAppConfig()
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	When("redirect type is set", func() {
+		BeforeEach(func() {
+			Expect(os.Setenv(redirectEnv, strconv.Itoa(http.StatusPermanentRedirect))).To(Succeed())
+		})
+
+		AfterEach(func() {
+			Expect(os.Unsetenv(redirectEnv)).To(Succeed())
+		})
+
+		It("should load it", func() {
+			config, err := env.LoadAppConfig()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(config.RedirectType).To(Equal(http.StatusPermanentRedirect))
+		})
+	})
+	When("migration mode is not supported", func() {
+		BeforeEach(func() {
+			Expect(os.Setenv(modeEnv, "invalid")).To(Succeed())
+		})
+
+		AfterEach(func() {
+			Expect(os.Unsetenv(modeEnv)).To(Succeed())
+		})
+
+		It("should return an error", func() {
+			_, err := env.LoadAppConfig()
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	When("migration mode is set without a migration project", func() {
+		BeforeEach(func() {
+			Expect(os.Setenv(modeEnv, env.MigrationDualWrite)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			Expect(os.Unsetenv(modeEnv)).To(Succeed())
+		})
+
+		It("should return an error", func() {
+			_, err := env.LoadAppConfig()
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	When("migration mode is set with a migration project", func() {
+		BeforeEach(func() {
+			Expect(os.Setenv(modeEnv, env.MigrationCutover)).To(Succeed())
+			Expect(os.Setenv(projectEnv, "new-project")).To(Succeed())
+		})
+
+		AfterEach(func() {
+			Expect(os.Unsetenv(modeEnv)).To(Succeed())
+			Expect(os.Unsetenv(projectEnv)).To(Succeed())
+		})
+
+		It("should load it", func() {
+			config, err := env.LoadAppConfig()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(config.MigrationMode).To(Equal(env.MigrationCutover))
+			Expect(config.MigrationProject).To(Equal("new-project"))
 		})
 	})
 })
@@ -0,0 +1,219 @@
+package importer
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"url-shortener/cmd/urlshortener/internal/urlshortener"
+	"url-shortener/pkg/urlfile"
+)
+
+//go:generate mockgen --source=importer.go --destination mocks/importer.go --package mocks
+
+type Creator interface {
+	CreateShortURLs(ctx context.Context, domain string, longURLs []string) []urlshortener.BulkResult
+}
+
+// Report summarizes an import run
+type Report struct {
+	Created int
+	Reused  int
+	Failed  int
+	// Skipped is the number of URLs already processed by a previous run
+	Skipped int
+}
+
+type Importer struct {
+	creator        Creator
+	domain         string
+	batchSize      int
+	checkpointPath string
+	output         io.Writer
+}
+
+// New is a constructor function, URLs are created on the domain or the default one if empty
+func New(creator Creator, domain string, batchSize int, checkpointPath string, output io.Writer) *Importer {
+	return &Importer{
+		creator:        creator,
+		domain:         domain,
+		batchSize:      batchSize,
+		checkpointPath: checkpointPath,
+		output:         output,
+	}
+}
+
+// checkpoint is the progress of an import, Offset URLs were processed and Failed are the indexes of those which failed
+type checkpoint struct {
+	Offset int
+	Failed []int
+}
+
+// Import creates short URLs for all long URLs in a CSV or JSONL file in batches
+// The progress is stored in the checkpoint file after every batch, so an interrupted import resumes from the last
+// completed batch. URLs which failed are retried first when the import is run again, the checkpoint is kept until
+// none fails. Each URL is written to the output as a CSV row with its short URL and status.
+func (i *Importer) Import(ctx context.Context, path string) (Report, error) {
+	if i.batchSize < 1 {
+		return Report{}, fmt.Errorf("batch size must be positive, got [%d]", i.batchSize)
+	}
+
+	longURLs, err := urlfile.Read(path)
+	if err != nil {
+		return Report{}, err
+	}
+
+	previous, err := i.loadCheckpoint()
+	if err != nil {
+		return Report{}, err
+	}
+
+	report := Report{Skipped: previous.Offset - len(previous.Failed)}
+	writer := csv.NewWriter(i.output)
+	if previous.Offset == 0 {
+		if err := writer.Write([]string{"long_url", "short_url", "status"}); err != nil {
+			return report, fmt.Errorf("failed to write output: %w", err)
+		}
+	}
+
+	// failed URLs of the previous runs are retried before the remaining ones
+	indexes := append([]int{}, previous.Failed...)
+	for index := previous.Offset; index < len(longURLs); index++ {
+		indexes = append(indexes, index)
+	}
+
+	var failed []int
+	for start := 0; start < len(indexes); start += i.batchSize {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+
+		end := start + i.batchSize
+		if end > len(indexes) {
+			end = len(indexes)
+		}
+
+		batch := make([]string, end-start)
+		for j, index := range indexes[start:end] {
+			batch[j] = longURLs[index]
+		}
+
+		results := i.creator.CreateShortURLs(ctx, i.domain, batch)
+		var batchFailed []int
+		for j, result := range results {
+			status := "created"
+			switch {
+			case result.Err != nil:
+				status = "failed: " + result.Err.Error()
+				batchFailed = append(batchFailed, indexes[start+j])
+			case result.Reused:
+				status = "reused"
+				report.Reused++
+			default:
+				report.Created++
+			}
+
+			if err := writer.Write([]string{result.LongURL, result.ShortURL, status}); err != nil {
+				return report, fmt.Errorf("failed to write output: %w", err)
+			}
+		}
+
+		writer.Flush()
+		if err := writer.Error(); err != nil {
+			return report, fmt.Errorf("failed to write output: %w", err)
+		}
+
+		// a batch of new URLs failing entirely points to an outage, retried URLs may keep failing on their own
+		if len(batchFailed) == len(results) && start >= len(previous.Failed) {
+			return report, fmt.Errorf("failed to import batch starting at [%d], rerun to resume", indexes[start])
+		}
+
+		failed = append(failed, batchFailed...)
+		next := checkpoint{Offset: previous.Offset, Failed: append(append([]int{}, failed...), retriesAfter(indexes, end, previous)...)}
+		if last := indexes[end-1]; last >= previous.Offset {
+			next.Offset = last + 1
+		}
+
+		if err := i.saveCheckpoint(next); err != nil {
+			return report, err
+		}
+	}
+
+	report.Failed = len(failed)
+	if len(failed) > 0 {
+		return report, nil
+	}
+
+	if err := os.Remove(i.checkpointPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return report, fmt.Errorf("failed to remove checkpoint: %w", err)
+	}
+
+	return report, nil
+}
+
+// retriesAfter returns the failed URLs of the previous runs which are not retried yet
+func retriesAfter(indexes []int, end int, previous checkpoint) []int {
+	if end >= len(previous.Failed) {
+		return nil
+	}
+
+	return indexes[end:len(previous.Failed)]
+}
+
+func (i *Importer) loadCheckpoint() (checkpoint, error) {
+	data, err := os.ReadFile(i.checkpointPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return checkpoint{}, nil
+		}
+
+		return checkpoint{}, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+
+	// the first line is the offset, the optional second one lists the failed indexes
+	lines := strings.SplitN(strings.TrimSpace(string(data)), "\n", 2)
+	var result checkpoint
+	if result.Offset, err = strconv.Atoi(strings.TrimSpace(lines[0])); err != nil {
+		return checkpoint{}, fmt.Errorf("failed to parse checkpoint: %w", err)
+	}
+
+	if len(lines) == 2 {
+		for _, value := range strings.Split(strings.TrimSpace(lines[1]), ",") {
+			index, err := strconv.Atoi(value)
+			if err != nil {
+				return checkpoint{}, fmt.Errorf("failed to parse checkpoint: %w", err)
+			}
+
+			result.Failed = append(result.Failed, index)
+		}
+	}
+
+	return result, nil
+}
+
+func (i *Importer) saveCheckpoint(progress checkpoint) error {
+	data := strconv.Itoa(progress.Offset)
+	if len(progress.Failed) > 0 {
+		failed := make([]string, len(progress.Failed))
+		for j, index := range progress.Failed {
+			failed[j] = strconv.Itoa(index)
+		}
+
+		data += "\n" + strings.Join(failed, ",")
+	}
+
+	tmpPath := i.checkpointPath + ".tmp"
+	if err := os.WriteFile(tmpPath, []byte(data), 0o644); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, i.checkpointPath); err != nil {
+		return fmt.Errorf("failed to save checkpoint: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,160 @@
+package importer_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"url-shortener/cmd/urlshortener/internal/importer"
+	"url-shortener/cmd/urlshortener/internal/importer/mocks"
+	"url-shortener/cmd/urlshortener/internal/urlshortener"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Importer", func() {
+	const (
+		firstURL  = "https://first.com"
+		secondURL = "https://second.com"
+		thirdURL  = "https://third.com"
+		batchSize = 2
+	)
+
+	var (
+		mockCtrl       *gomock.Controller
+		mockCreator    *mocks.MockCreator
+		output         *bytes.Buffer
+		dir            string
+		checkpointPath string
+		imp            *importer.Importer
+		ctx            context.Context
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockCreator = mocks.NewMockCreator(mockCtrl)
+		output = &bytes.Buffer{}
+		dir = GinkgoT().TempDir()
+		checkpointPath = filepath.Join(dir, "import.checkpoint")
+		imp = importer.New(mockCreator, "", batchSize, checkpointPath, output)
+		ctx = context.Background()
+	})
+
+	writeFile := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		Expect(os.WriteFile(path, []byte(content), 0o644)).To(Succeed())
+		return path
+	}
+
+	When("importing succeeds", func() {
+		var path string
+		BeforeEach(func() {
+			path = writeFile("urls.csv", "https://first.com\nhttps://second.com\nhttps://third.com\n")
+			mockCreator.EXPECT().CreateShortURLs(ctx, "", []string{firstURL, secondURL}).Return([]urlshortener.BulkResult{
+				{LongURL: firstURL, ShortURL: "1"},
+				{LongURL: secondURL, ShortURL: "2", Reused: true},
+			})
+			mockCreator.EXPECT().CreateShortURLs(ctx, "", []string{thirdURL}).Return([]urlshortener.BulkResult{
+				{LongURL: thirdURL, ShortURL: "3"},
+			})
+		})
+
+		It("should report created and reused urls and remove the checkpoint", func() {
+			report, err := imp.Import(ctx, path)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(report).To(Equal(importer.Report{Created: 2, Reused: 1}))
+			Expect(output.String()).To(ContainSubstring("https://second.com,2,reused"))
+			Expect(checkpointPath).ToNot(BeAnExistingFile())
+		})
+	})
+
+	When("the batch size is not positive", func() {
+		It("should return an error without creating urls", func() {
+			path := writeFile("urls.csv", "https://first.com\n")
+			_, err := importer.New(mockCreator, "", 0, checkpointPath, output).Import(ctx, path)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	When("a whole batch fails", func() {
+		var path string
+		BeforeEach(func() {
+			path = writeFile("urls.csv", "https://first.com\nhttps://second.com\nhttps://third.com\n")
+			mockCreator.EXPECT().CreateShortURLs(ctx, "", []string{firstURL, secondURL}).Return([]urlshortener.BulkResult{
+				{LongURL: firstURL, ShortURL: "1"},
+				{LongURL: secondURL, ShortURL: "2"},
+			})
+			mockCreator.EXPECT().CreateShortURLs(ctx, "", []string{thirdURL}).Return([]urlshortener.BulkResult{
+				{LongURL: thirdURL, Err: errors.New("err")},
+			})
+		})
+
+		It("should return an error and keep the checkpoint", func() {
+			_, err := imp.Import(ctx, path)
+			Expect(err).To(HaveOccurred())
+			Expect(os.ReadFile(checkpointPath)).To(Equal([]byte("2")))
+		})
+	})
+
+	When("resuming from a checkpoint", func() {
+		var path string
+		BeforeEach(func() {
+			path = writeFile("urls.jsonl", `{"long_url": "https://first.com"}`+"\n"+`{"long_url": "https://second.com"}`+"\n"+`{"long_url": "https://third.com"}`+"\n")
+			Expect(os.WriteFile(checkpointPath, []byte("2"), 0o644)).To(Succeed())
+			mockCreator.EXPECT().CreateShortURLs(ctx, "", []string{thirdURL}).Return([]urlshortener.BulkResult{
+				{LongURL: thirdURL, ShortURL: "3"},
+			})
+		})
+
+		It("should skip already processed urls", func() {
+			report, err := imp.Import(ctx, path)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(report).To(Equal(importer.Report{Created: 1, Skipped: 2}))
+		})
+	})
+
+	When("a batch partly fails", func() {
+		var path string
+		BeforeEach(func() {
+			path = writeFile("urls.csv", "https://first.com\nhttps://second.com\nhttps://third.com\n")
+			mockCreator.EXPECT().CreateShortURLs(ctx, "", []string{firstURL, secondURL}).Return([]urlshortener.BulkResult{
+				{LongURL: firstURL, ShortURL: "1"},
+				{LongURL: secondURL, Err: errors.New("err")},
+			})
+			mockCreator.EXPECT().CreateShortURLs(ctx, "", []string{thirdURL}).Return([]urlshortener.BulkResult{
+				{LongURL: thirdURL, ShortURL: "3"},
+			})
+		})
+
+		It("should keep the failed urls in the checkpoint and retry them on resume", func() {
+			report, err := imp.Import(ctx, path)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(report).To(Equal(importer.Report{Created: 2, Failed: 1}))
+			Expect(os.ReadFile(checkpointPath)).To(Equal([]byte("3\n1")))
+
+			mockCreator.EXPECT().CreateShortURLs(ctx, "", []string{secondURL}).Return([]urlshortener.BulkResult{
+				{LongURL: secondURL, ShortURL: "2"},
+			})
+			report, err = importer.New(mockCreator, "", batchSize, checkpointPath, output).Import(ctx, path)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(report).To(Equal(importer.Report{Created: 1, Skipped: 2}))
+			Expect(checkpointPath).ToNot(BeAnExistingFile())
+		})
+
+		It("should keep urls which fail again without blocking the import", func() {
+			_, err := imp.Import(ctx, path)
+			Expect(err).ToNot(HaveOccurred())
+
+			mockCreator.EXPECT().CreateShortURLs(ctx, "", []string{secondURL}).Return([]urlshortener.BulkResult{
+				{LongURL: secondURL, Err: errors.New("err")},
+			})
+			report, err := importer.New(mockCreator, "", batchSize, checkpointPath, output).Import(ctx, path)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(report).To(Equal(importer.Report{Failed: 1, Skipped: 2}))
+			Expect(os.ReadFile(checkpointPath)).To(Equal([]byte("3\n1")))
+		})
+	})
+})
@@ -0,0 +1,50 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: importer.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+	urlshortener "url-shortener/cmd/urlshortener/internal/urlshortener"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockCreator is a mock of Creator interface.
+type MockCreator struct {
+	ctrl     *gomock.Controller
+	recorder *MockCreatorMockRecorder
+}
+
+// MockCreatorMockRecorder is the mock recorder for MockCreator.
+type MockCreatorMockRecorder struct {
+	mock *MockCreator
+}
+
+// NewMockCreator creates a new mock instance.
+func NewMockCreator(ctrl *gomock.Controller) *MockCreator {
+	mock := &MockCreator{ctrl: ctrl}
+	mock.recorder = &MockCreatorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCreator) EXPECT() *MockCreatorMockRecorder {
+	return m.recorder
+}
+
+// CreateShortURLs mocks base method.
+func (m *MockCreator) CreateShortURLs(ctx context.Context, domain string, longURLs []string) []urlshortener.BulkResult {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateShortURLs", ctx, domain, longURLs)
+	ret0, _ := ret[0].([]urlshortener.BulkResult)
+	return ret0
+}
+
+// CreateShortURLs indicates an expected call of CreateShortURLs.
+func (mr *MockCreatorMockRecorder) CreateShortURLs(ctx, domain, longURLs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateShortURLs", reflect.TypeOf((*MockCreator)(nil).CreateShortURLs), ctx, domain, longURLs)
+}
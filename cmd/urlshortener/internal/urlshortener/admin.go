@@ -0,0 +1,64 @@
+package urlshortener
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type counterResponse struct {
+	Tenant string `json:"tenant,omitempty"`
+	CounterState
+}
+
+// GetCounter returns the counter state of the default namespace, or of the tenant query param
+func (p *Presenter) GetCounter(ctx *gin.Context) {
+	controller := p.controller
+	tenantID := ctx.Query("tenant")
+	if tenantID != "" {
+		if p.config.Tenants == nil {
+			ctx.JSON(http.StatusNotFound, "Tenant does not exist")
+			return
+		}
+
+		tenant, ok := p.config.Tenants.Get(ctx, tenantID)
+		if !ok {
+			ctx.JSON(http.StatusNotFound, "Tenant does not exist")
+			return
+		}
+
+		controller = p.config.Tenants.Controller(tenant)
+	}
+
+	state, err := controller.CounterState(ctx)
+	if err != nil {
+		logrus.Errorf("Failed to get counter state: %v", err)
+		ctx.JSON(http.StatusInternalServerError, "Error occured while getting counter state")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, counterResponse{Tenant: tenantID, CounterState: state})
+}
+
+// ExportArchive streams the archive of the default namespace, the counter state followed by every URL as JSONL
+// The status is sent before the export starts, so a failure in the middle truncates the archive
+func (p *Presenter) ExportArchive(ctx *gin.Context) {
+	if p.config.Archive == nil {
+		ctx.JSON(http.StatusNotFound, "Export is disabled")
+		return
+	}
+
+	ctx.Header("Content-Type", "application/x-ndjson")
+	ctx.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="urls-%s.jsonl"`, time.Now().UTC().Format("20060102-150405")))
+	ctx.Status(http.StatusOK)
+	summary, err := p.config.Archive.Export(ctx, ctx.Writer)
+	if err != nil {
+		logrus.Errorf("Failed to export archive: %v", err)
+		return
+	}
+
+	logrus.Infof("export finished: urls [%d], count [%d]", summary.URLs, summary.Count)
+}
@@ -0,0 +1,100 @@
+package urlshortener_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"url-shortener/cmd/urlshortener/internal/urlshortener"
+	"url-shortener/cmd/urlshortener/internal/urlshortener/mocks"
+	"url-shortener/pkg/backup"
+	"url-shortener/pkg/repository/firestore/tenants"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type archiveFunc func(ctx context.Context, writer io.Writer) (backup.Summary, error)
+
+func (f archiveFunc) Export(ctx context.Context, writer io.Writer) (backup.Summary, error) {
+	return f(ctx, writer)
+}
+
+var _ = Describe("Admin", func() {
+	var (
+		mockCtrl       *gomock.Controller
+		mockController *mocks.MockController
+		mockTenants    *mocks.MockTenants
+		config         urlshortener.Config
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockController = mocks.NewMockController(mockCtrl)
+		mockTenants = mocks.NewMockTenants(mockCtrl)
+		config = urlshortener.Config{Tenants: mockTenants}
+	})
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		presenter := urlshortener.NewPresenter(mockController, config)
+		handler := gin.New()
+		handler.GET("/api/v1/admin/counter", presenter.GetCounter)
+		handler.GET("/api/v1/admin/export", presenter.ExportArchive)
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+		return recorder
+	}
+
+	When("getting the counter state", func() {
+		It("should return the state of the default namespace", func() {
+			mockController.EXPECT().CounterState(gomock.Any()).Return(urlshortener.CounterState{Count: 61, NextShortURL: "10"}, nil)
+
+			recorder := serve("/api/v1/admin/counter")
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Body.String()).To(MatchJSON(`{"count":61,"next_short_url":"10"}`))
+		})
+
+		It("should return the state of a tenant", func() {
+			tenant := tenants.Tenant{ID: "acme"}
+			tenantController := mocks.NewMockController(mockCtrl)
+			mockTenants.EXPECT().Get(gomock.Any(), "acme").Return(tenant, true)
+			mockTenants.EXPECT().Controller(tenant).Return(tenantController)
+			tenantController.EXPECT().CounterState(gomock.Any()).Return(urlshortener.CounterState{Count: 1, NextShortURL: "2"}, nil)
+
+			recorder := serve("/api/v1/admin/counter?tenant=acme")
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Body.String()).To(MatchJSON(`{"tenant":"acme","count":1,"next_short_url":"2"}`))
+		})
+
+		It("should answer not found for unknown tenants", func() {
+			mockTenants.EXPECT().Get(gomock.Any(), "acme").Return(tenants.Tenant{}, false)
+			Expect(serve("/api/v1/admin/counter?tenant=acme").Code).To(Equal(http.StatusNotFound))
+		})
+
+		It("should answer internal server error if the counter cannot be read", func() {
+			mockController.EXPECT().CounterState(gomock.Any()).Return(urlshortener.CounterState{}, errors.New("unavailable"))
+			Expect(serve("/api/v1/admin/counter").Code).To(Equal(http.StatusInternalServerError))
+		})
+	})
+
+	When("exporting the archive", func() {
+		It("should stream it", func() {
+			config.Archive = archiveFunc(func(_ context.Context, writer io.Writer) (backup.Summary, error) {
+				_, err := io.WriteString(writer, `{"type":"counter","count":1}`+"\n")
+				return backup.Summary{Count: 1}, err
+			})
+
+			recorder := serve("/api/v1/admin/export")
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Header().Get("Content-Type")).To(Equal("application/x-ndjson"))
+			Expect(recorder.Body.String()).To(Equal(`{"type":"counter","count":1}` + "\n"))
+		})
+
+		It("should answer not found if export is disabled", func() {
+			Expect(serve("/api/v1/admin/export").Code).To(Equal(http.StatusNotFound))
+		})
+	})
+})
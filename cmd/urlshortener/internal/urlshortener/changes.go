@@ -0,0 +1,156 @@
+package urlshortener
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+	"url-shortener/pkg/repository/firestore/urls"
+
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultChangesLimit = 100
+	maxChangesLimit     = 500
+	// streamKeepAlive is the longest a stream stays silent, so proxies do not close idle streams
+	streamKeepAlive = 15 * time.Second
+)
+
+type change struct {
+	// Cursor resumes the feed after this change
+	Cursor   string `json:"cursor"`
+	Type     string `json:"type"`
+	ShortURL string `json:"short_url"`
+	Domain   string `json:"domain,omitempty"`
+	// URL is the URL after the change, missing if it was deleted
+	URL *urls.URL `json:"url,omitempty"`
+	At  time.Time `json:"at"`
+}
+
+type changesResponse struct {
+	Changes []change `json:"changes"`
+	// Cursor resumes the feed after the returned changes, it is the since cursor if there are none
+	Cursor string `json:"cursor"`
+}
+
+// ListChanges returns the changes of URLs of the namespace committed after the since cursor, oldest first
+// Clients resume by passing the returned cursor as since, an empty since starts from the first change
+func (p *Presenter) ListChanges(ctx *gin.Context) {
+	since, err := urls.ParseCursor(ctx.Query("since"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, "Invalid since cursor")
+		return
+	}
+
+	limit := defaultChangesLimit
+	if value := ctx.Query("limit"); value != "" {
+		limit, err = strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > maxChangesLimit {
+			ctx.JSON(http.StatusBadRequest, fmt.Sprintf("Limit must be between 1 and %d", maxChangesLimit))
+			return
+		}
+	}
+
+	changes, err := p.controllerOf(ctx).ListChanges(ctx, since, limit)
+	if err != nil {
+		logrus.Errorf("Failed to list changes: %v", err)
+		ctx.JSON(http.StatusInternalServerError, "Error occured while listing changes")
+		return
+	}
+
+	response := changesResponse{Changes: make([]change, len(changes)), Cursor: since.String()}
+	for i, record := range changes {
+		response.Changes[i] = toChange(record)
+		response.Cursor = response.Changes[i].Cursor
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// StreamChanges sends the changes of URLs of the namespace committed after the since cursor as Server-Sent Events
+// Every event carries its cursor as id, so reconnecting clients resume after the Last-Event-ID header
+func (p *Presenter) StreamChanges(ctx *gin.Context) {
+	value := ctx.Query("since")
+	if value == "" {
+		value = ctx.GetHeader("Last-Event-ID")
+	}
+
+	cursor, err := urls.ParseCursor(value)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, "Invalid since cursor")
+		return
+	}
+
+	controller := p.controllerOf(ctx)
+	ctx.Header("Content-Type", sse.ContentType)
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Header("X-Accel-Buffering", "no")
+	ctx.Status(http.StatusOK)
+	ctx.Writer.Flush()
+
+	ticker := time.NewTicker(p.config.ChangesPollInterval)
+	defer ticker.Stop()
+	lastSent := time.Now()
+	for {
+		changes, err := controller.ListChanges(ctx, cursor, maxChangesLimit)
+		if err != nil {
+			logrus.Errorf("Failed to list changes: %v", err)
+			return
+		}
+
+		for _, record := range changes {
+			event := toChange(record)
+			cursor = record.Cursor()
+			ctx.Render(-1, sse.Event{Id: event.Cursor, Event: "change", Data: event})
+		}
+
+		if len(changes) > 0 || time.Since(lastSent) >= streamKeepAlive {
+			if len(changes) == 0 {
+				ctx.Render(-1, sse.Event{Event: "ping", Data: ""})
+			}
+
+			ctx.Writer.Flush()
+			lastSent = time.Now()
+		}
+
+		if len(changes) == maxChangesLimit {
+			continue
+		}
+
+		select {
+		case <-ctx.Request.Context().Done():
+			return
+		case <-p.streams.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// StopStreams ends the streams of changes, streams opened afterwards end after their first poll,
+// so open streams do not hold up a graceful shutdown while other requests finish
+func (p *Presenter) StopStreams() {
+	p.stopStreams()
+}
+
+func toChange(record urls.Change) change {
+	domain, code := urls.SplitKey(record.Key)
+	result := change{
+		Cursor:   record.Cursor().String(),
+		Type:     record.Type,
+		ShortURL: code,
+		Domain:   domain,
+		At:       record.At,
+	}
+
+	if record.URL != nil && record.Type != urls.ChangeDeleted {
+		url := *record.URL
+		url.PasswordHash = ""
+		result.URL = &url
+	}
+
+	return result
+}
@@ -0,0 +1,110 @@
+package urlshortener_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"time"
+	"url-shortener/cmd/urlshortener/internal/urlshortener"
+	"url-shortener/cmd/urlshortener/internal/urlshortener/mocks"
+	"url-shortener/pkg/repository/firestore/urls"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Changes", func() {
+	var (
+		mockCtrl       *gomock.Controller
+		mockController *mocks.MockController
+		presenter      *urlshortener.Presenter
+		handler        *gin.Engine
+		at             = time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC)
+		created        = urls.Change{ID: "c1", Type: urls.ChangeCreated, Key: "abc@go.example.com", At: at,
+			URL: &urls.URL{LongURL: "https://example.com", PasswordHash: "hash"}}
+		deleted = urls.Change{ID: "c2", Type: urls.ChangeDeleted, Key: "abc@go.example.com", At: at.Add(time.Second)}
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockController = mocks.NewMockController(mockCtrl)
+		presenter = urlshortener.NewPresenter(mockController, urlshortener.Config{ChangesPollInterval: time.Millisecond})
+		handler = gin.New()
+		handler.GET("/api/v1/changes", presenter.ListChanges)
+		handler.GET("/api/v1/changes/stream", presenter.StreamChanges)
+	})
+
+	When("listing changes after a cursor", func() {
+		BeforeEach(func() {
+			mockController.EXPECT().ListChanges(gomock.Any(), created.Cursor(), 100).Return([]urls.Change{deleted}, nil)
+		})
+
+		It("should return them with the cursor of the last one", func() {
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/v1/changes?since="+created.Cursor().String(), nil))
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+
+			var response struct {
+				Changes []struct {
+					Type     string `json:"type"`
+					ShortURL string `json:"short_url"`
+					Domain   string `json:"domain"`
+				} `json:"changes"`
+				Cursor string `json:"cursor"`
+			}
+			Expect(json.Unmarshal(recorder.Body.Bytes(), &response)).To(Succeed())
+			Expect(response.Changes).To(HaveLen(1))
+			Expect(response.Changes[0].Type).To(Equal(urls.ChangeDeleted))
+			Expect(response.Changes[0].ShortURL).To(Equal("abc"))
+			Expect(response.Changes[0].Domain).To(Equal("go.example.com"))
+			Expect(response.Cursor).To(Equal(deleted.Cursor().String()))
+		})
+	})
+
+	When("listing changes with a malformed cursor", func() {
+		It("should reject the request", func() {
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/v1/changes?since=abc", nil))
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+		})
+	})
+
+	When("streaming changes", func() {
+		It("should send every change as an event with its cursor until the client leaves", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			gomock.InOrder(
+				mockController.EXPECT().ListChanges(gomock.Any(), urls.Cursor{}, gomock.Any()).Return([]urls.Change{created}, nil),
+				mockController.EXPECT().ListChanges(gomock.Any(), created.Cursor(), gomock.Any()).
+					DoAndReturn(func(context.Context, urls.Cursor, int) ([]urls.Change, error) {
+						cancel()
+						return nil, nil
+					}),
+			)
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/v1/changes/stream", nil).WithContext(ctx))
+
+			Expect(recorder.Header().Get("Content-Type")).To(Equal("text/event-stream"))
+			Expect(recorder.Body.String()).To(ContainSubstring("id:" + created.Cursor().String()))
+			Expect(recorder.Body.String()).To(ContainSubstring("event:change"))
+			Expect(recorder.Body.String()).To(ContainSubstring(`"long_url":"https://example.com"`))
+			Expect(recorder.Body.String()).NotTo(ContainSubstring("hash"))
+		})
+
+		It("should end the stream once streams are stopped", func() {
+			mockController.EXPECT().ListChanges(gomock.Any(), urls.Cursor{}, gomock.Any()).
+				DoAndReturn(func(context.Context, urls.Cursor, int) ([]urls.Change, error) {
+					presenter.StopStreams()
+					return nil, nil
+				})
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/v1/changes/stream", nil))
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+		})
+	})
+})
@@ -2,11 +2,21 @@ package urlshortener
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/base64"
 	"errors"
 	"fmt"
+	"time"
+	"url-shortener/pkg/repository/firestore/domains"
 	"url-shortener/pkg/repository/firestore/urls"
+	"url-shortener/pkg/repository/firestore/webhooks"
+	"url-shortener/pkg/search"
+	"url-shortener/pkg/unfurl"
+	"url-shortener/pkg/variants"
+	"url-shortener/pkg/webhook"
 
 	"cloud.google.com/go/firestore"
+	"github.com/sirupsen/logrus"
 )
 
 //go:generate mockgen --source=controller.go --destination mocks/controller.go --package mocks
@@ -14,55 +24,519 @@ import (
 type Repository interface {
 	AddURLTx(tx *firestore.Transaction, id string, url urls.URL) error
 	GetByShortURL(ctx context.Context, shortURL string) (urls.URL, error)
-	GetDocIDByLongURL(ctx context.Context, longURL string) (string, error)
+	GetStats(ctx context.Context, shortURL string) (urls.URL, error)
+	GetDocIDByLongURL(ctx context.Context, domain, longURL string) (string, error)
+	ConsumeClick(ctx context.Context, shortURL string) (urls.URL, error)
+	ListScheduled(ctx context.Context, field string, from, to time.Time, after urls.Cursor, limit int) ([]urls.Record, error)
+	ListBroken(ctx context.Context) ([]urls.Record, error)
+	UpdateVariants(ctx context.Context, shortURL string, urlVariants []variants.Variant) error
+	UpdateMetadata(ctx context.Context, shortURL string, metadata urls.Metadata) error
+	UpdatePreview(ctx context.Context, shortURL string, preview unfurl.Preview) error
+	IncrementVariantClicks(ctx context.Context, shortURL, variant string) error
+	Delete(ctx context.Context, shortURL string) error
+	ListChanges(ctx context.Context, after urls.Cursor, limit int) ([]urls.Change, error)
 	RunTransaction(ctx context.Context, txFunc func(context.Context, *firestore.Transaction) error) error
 }
 
 type Counter interface {
 	IncrementCounterTx(tx *firestore.Transaction) error
+	IncrementCounterByTx(tx *firestore.Transaction, delta int64) error
 	GetCountTx(tx *firestore.Transaction) (int64, error)
+	GetCount(ctx context.Context) (int64, error)
 }
 
 type Encoder interface {
 	EncodeToBase62(number uint64) string
 }
 
+// Index is the search index of URLs
+type Index interface {
+	Put(ctx context.Context, doc search.Document) error
+	Delete(ctx context.Context, id string) error
+	Search(ctx context.Context, query search.Query) ([]search.Document, error)
+}
+
+// Previews fetches the previews of destination pages in the background
+type Previews interface {
+	Enqueue(url string, save unfurl.Save) bool
+}
+
+// ShortDomains tells which hosts serve short URLs
+type ShortDomains interface {
+	Lookup(ctx context.Context, host string) (domains.Domain, bool)
+}
+
+// Webhooks stores the webhook subscriptions of the namespace and queues events for them in the outbox
+type Webhooks interface {
+	Publish(ctx context.Context, events ...webhook.Event) error
+	AddSubscription(ctx context.Context, subscription webhooks.Subscription) (webhooks.Subscription, error)
+	ListSubscriptions(ctx context.Context) ([]webhooks.Subscription, error)
+	DeleteSubscription(ctx context.Context, id string) error
+	ListDeliveries(ctx context.Context, subscriptionID string, limit int) ([]webhooks.Entry, error)
+}
+
+// Quota limits the number of URLs created in a namespace
+type Quota interface {
+	ConsumeTx(tx *firestore.Transaction, n int64) error
+}
+
+var errWebhooksDisabled = errors.New("webhooks are not configured")
+
+// maxBatchSize keeps a bulk transaction below the Firestore limit of 500 writes,
+// every URL is written together with its change log entry
+const maxBatchSize = 200
+
+// changesPageSize is the number of changes of URLs published together
+const changesPageSize = 500
+
+// expiredPageSize is the number of expired URLs published together
+const expiredPageSize = 500
+
+// BulkResult is the outcome of creating a single URL as part of a bulk request
+type BulkResult struct {
+	LongURL  string
+	ShortURL string
+	Reused   bool
+	Err      error
+}
+
+// CounterState is the allocation state of the ids of short URLs
+type CounterState struct {
+	Count int64 `json:"count"`
+	// NextShortURL is the short URL the next allocated id is encoded to
+	NextShortURL string `json:"next_short_url"`
+}
+
+// Schedule lists URLs activating and deactivating in a time range
+type Schedule struct {
+	Activating   []urls.Record
+	Deactivating []urls.Record
+	// Next continues both lists after the returned URLs
+	Next ScheduleCursor
+}
+
+// ScheduleCursor is a position in both lists of a schedule, the zero cursor is their start
+type ScheduleCursor struct {
+	Activating   urls.Cursor
+	Deactivating urls.Cursor
+	// ActivatingDone and DeactivatingDone mark lists without more URLs
+	ActivatingDone   bool
+	DeactivatingDone bool
+}
+
+// Done reports whether both lists have no more URLs
+func (c ScheduleCursor) Done() bool {
+	return c.ActivatingDone && c.DeactivatingDone
+}
+
 type URLController struct {
-	repository Repository
-	counter    Counter
-	encoder    Encoder
+	repository  Repository
+	counter     Counter
+	encoder     Encoder
+	searchIndex Index
+	quota       Quota
+	previews    Previews
+	// shortHosts are checked for destinations which are short URLs themselves, destinations are not checked if nil
+	shortHosts ShortDomains
+	// namespace is the tenant whose URLs the controller stores, empty for the default namespace
+	namespace string
+	maxDepth  int
+	// webhooks receives the events of the URLs, no events are sent if nil
+	webhooks        Webhooks
+	clickThresholds []int64
 }
 
 // NewController is a constructor function
-func NewController(repository Repository, counter Counter, encoder Encoder) *URLController {
+func NewController(repository Repository, counter Counter, encoder Encoder, searchIndex Index) *URLController {
+	return NewTenantController(repository, counter, encoder, searchIndex, nil)
+}
+
+// NewTenantController is a constructor function of a controller whose created URLs count towards the quota,
+// URLs are not limited if quota is nil
+func NewTenantController(repository Repository, counter Counter, encoder Encoder, searchIndex Index, quota Quota) *URLController {
 	return &URLController{
-		repository: repository,
-		counter:    counter,
-		encoder:    encoder,
+		repository:  repository,
+		counter:     counter,
+		encoder:     encoder,
+		searchIndex: searchIndex,
+		quota:       quota,
 	}
 }
 
-// CreateShortURL creates an URL object if not exists, otherwise it returns the id of the existing one
-func (c *URLController) CreateShortURL(ctx context.Context, longURL string) (string, error) {
-	id, err := c.repository.GetDocIDByLongURL(ctx, longURL)
+// WithPreviews makes the controller fetch the destination preview of every created URL
+func (c *URLController) WithPreviews(previews Previews) *URLController {
+	c.previews = previews
+	return c
+}
+
+// WithLoopProtection makes the controller check destinations on the short hosts before storing them
+// Chains of short URLs are followed up to maxDepth hops, chains which loop, are longer or contain unknown
+// short URLs are rejected. Chains through URLs without their own settings are flattened to their final destination
+// Only short URLs of the namespace of the controller are followed, short URLs of other namespaces are kept as they are
+func (c *URLController) WithLoopProtection(shortHosts ShortDomains, namespace string, maxDepth int) *URLController {
+	c.shortHosts = shortHosts
+	c.namespace = namespace
+	c.maxDepth = maxDepth
+	return c
+}
+
+// WithWebhooks makes the controller publish the lifecycle events of its URLs to the webhook subscriptions
+// A click event is published when the counted clicks of an URL reach one of clickThresholds
+func (c *URLController) WithWebhooks(webhooks Webhooks, clickThresholds []int64) *URLController {
+	c.webhooks = webhooks
+	c.clickThresholds = clickThresholds
+	return c
+}
+
+// CreateShortURL creates an URL object on the domain if not exists, otherwise it returns the id of the existing one
+// The default domain is used if domain is empty
+func (c *URLController) CreateShortURL(ctx context.Context, domain, longURL string) (string, error) {
+	longURL, err := c.flatten(ctx, "", longURL)
+	if err != nil {
+		return "", err
+	}
+
+	key, err := c.repository.GetDocIDByLongURL(ctx, domain, longURL)
 	if err != nil {
 		var notFoundErr urls.NotFoundError
 		if errors.As(err, &notFoundErr) {
-			return c.createShortURL(ctx, longURL)
+			return c.createShortURL(ctx, urls.URL{LongURL: longURL, Domain: domain})
 		}
 
 		return "", fmt.Errorf("failed to get doc id by long url: %w", err)
 	}
 
+	_, id := urls.SplitKey(key)
 	return id, nil
 }
 
+// CreateURL creates an URL object with its own settings and returns its id
+// Unlike CreateShortURL it always allocates a new id and marks the URL as exclusive,
+// as links with their own settings must not be shared
+func (c *URLController) CreateURL(ctx context.Context, url urls.URL) (string, error) {
+	url, err := c.flattenURL(ctx, "", url)
+	if err != nil {
+		return "", err
+	}
+
+	url.Exclusive = true
+	return c.createShortURL(ctx, url)
+}
+
+// CreateShortURLs creates URL objects for many long URLs at once and returns a result per long URL in the same order
+// Already shortened long URLs, including duplicates within the request, are reused
+// New ones are allocated in batches, so the counter is read and incremented once per batch
+func (c *URLController) CreateShortURLs(ctx context.Context, domain string, longURLs []string) []BulkResult {
+	results := make([]BulkResult, len(longURLs))
+	destinations := make([]string, len(longURLs))
+	firstIndex := make(map[string]int, len(longURLs))
+	var pending []int
+	for i, longURL := range longURLs {
+		results[i].LongURL = longURL
+		if _, ok := firstIndex[longURL]; ok {
+			continue
+		}
+
+		firstIndex[longURL] = i
+		destination, err := c.flatten(ctx, "", longURL)
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+
+		destinations[i] = destination
+		key, err := c.repository.GetDocIDByLongURL(ctx, domain, destination)
+		if err != nil {
+			var notFoundErr urls.NotFoundError
+			if errors.As(err, &notFoundErr) {
+				pending = append(pending, i)
+				continue
+			}
+
+			results[i].Err = fmt.Errorf("failed to get doc id by long url: %w", err)
+			continue
+		}
+
+		_, results[i].ShortURL = urls.SplitKey(key)
+		results[i].Reused = true
+	}
+
+	for start := 0; start < len(pending); start += maxBatchSize {
+		end := start + maxBatchSize
+		if end > len(pending) {
+			end = len(pending)
+		}
+
+		c.createBatch(ctx, domain, results, destinations, pending[start:end])
+	}
+
+	for i := range results {
+		first := firstIndex[results[i].LongURL]
+		if first == i {
+			continue
+		}
+
+		results[i].ShortURL = results[first].ShortURL
+		results[i].Err = results[first].Err
+		results[i].Reused = results[first].Err == nil
+	}
+
+	return results
+}
+
 // GetByShortURL return URL object by short URL address
 func (c *URLController) GetByShortURL(ctx context.Context, shortURL string) (urls.URL, error) {
 	return c.repository.GetByShortURL(ctx, shortURL)
 }
 
-func (c *URLController) createShortURL(ctx context.Context, longURL string) (string, error) {
+// GetStats returns URL object by short URL address with all of its counted clicks, it reads more than GetByShortURL
+func (c *URLController) GetStats(ctx context.Context, shortURL string) (urls.URL, error) {
+	return c.repository.GetStats(ctx, shortURL)
+}
+
+// ConsumeClick counts a redirect of an URL with limited clicks and returns the updated URL
+// It returns exhausted error once the URL has no clicks left
+func (c *URLController) ConsumeClick(ctx context.Context, shortURL string) (urls.URL, error) {
+	return c.repository.ConsumeClick(ctx, shortURL)
+}
+
+// UpdateVariants replaces the weighted destinations of an URL without changing its short URL
+func (c *URLController) UpdateVariants(ctx context.Context, shortURL string, urlVariants []variants.Variant) error {
+	url, err := c.flattenURL(ctx, shortURL, urls.URL{Variants: urlVariants})
+	if err != nil {
+		return err
+	}
+
+	return c.repository.UpdateVariants(ctx, shortURL, url.Variants)
+}
+
+// UpdateMetadata replaces the title, description, tags and folder of an URL and returns the updated URL
+func (c *URLController) UpdateMetadata(ctx context.Context, shortURL string, metadata urls.Metadata) (urls.URL, error) {
+	if err := c.repository.UpdateMetadata(ctx, shortURL, metadata); err != nil {
+		return urls.URL{}, err
+	}
+
+	url, err := c.repository.GetByShortURL(ctx, shortURL)
+	if err != nil {
+		return urls.URL{}, fmt.Errorf("failed to get updated url: %w", err)
+	}
+
+	c.index(ctx, shortURL, url)
+	return url, nil
+}
+
+// DeleteURL deletes an URL and removes it from the search index, its short URL is not reused
+func (c *URLController) DeleteURL(ctx context.Context, shortURL string) error {
+	if err := c.repository.Delete(ctx, shortURL); err != nil {
+		return err
+	}
+
+	if err := c.searchIndex.Delete(ctx, shortURL); err != nil {
+		logrus.Warnf("failed to remove url [%s] from index: %v", shortURL, err)
+	}
+
+	return nil
+}
+
+// Search returns the URLs matching the query
+func (c *URLController) Search(ctx context.Context, query search.Query) ([]search.Document, error) {
+	return c.searchIndex.Search(ctx, query)
+}
+
+// RecordVariantClick counts a redirect to the variant of an URL
+func (c *URLController) RecordVariantClick(ctx context.Context, shortURL, variant string) error {
+	return c.repository.IncrementVariantClicks(ctx, shortURL, variant)
+}
+
+// ListScheduled returns up to limit URLs which become active and up to limit URLs which become inactive within [from, to)
+// starting after the cursor
+func (c *URLController) ListScheduled(ctx context.Context, from, to time.Time, after ScheduleCursor, limit int) (Schedule, error) {
+	schedule := Schedule{Next: after}
+	if !after.ActivatingDone {
+		activating, err := c.repository.ListScheduled(ctx, urls.NotBeforeField, from, to, after.Activating, limit)
+		if err != nil {
+			return Schedule{}, fmt.Errorf("failed to list activating urls: %w", err)
+		}
+
+		schedule.Activating = activating
+		schedule.Next.Activating, schedule.Next.ActivatingDone = nextScheduled(activating, urls.NotBeforeField, limit)
+	}
+
+	if !after.DeactivatingDone {
+		deactivating, err := c.repository.ListScheduled(ctx, urls.NotAfterField, from, to, after.Deactivating, limit)
+		if err != nil {
+			return Schedule{}, fmt.Errorf("failed to list deactivating urls: %w", err)
+		}
+
+		schedule.Deactivating = deactivating
+		schedule.Next.Deactivating, schedule.Next.DeactivatingDone = nextScheduled(deactivating, urls.NotAfterField, limit)
+	}
+
+	return schedule, nil
+}
+
+// nextScheduled returns the cursor after a page of scheduled URLs and whether the list has no more URLs
+func nextScheduled(records []urls.Record, field string, limit int) (urls.Cursor, bool) {
+	if len(records) < limit {
+		return urls.Cursor{}, true
+	}
+
+	return records[len(records)-1].ScheduleCursor(field), false
+}
+
+// ListBroken returns URLs whose long URL has been failing health checks for too long
+func (c *URLController) ListBroken(ctx context.Context) ([]urls.Record, error) {
+	return c.repository.ListBroken(ctx)
+}
+
+// ListChanges returns up to limit changes of URLs committed after the cursor
+func (c *URLController) ListChanges(ctx context.Context, after urls.Cursor, limit int) ([]urls.Change, error) {
+	return c.repository.ListChanges(ctx, after, limit)
+}
+
+// CounterState returns the number of allocated ids and the next short URL
+func (c *URLController) CounterState(ctx context.Context) (CounterState, error) {
+	count, err := c.counter.GetCount(ctx)
+	if err != nil {
+		return CounterState{}, fmt.Errorf("failed to get count: %w", err)
+	}
+
+	return CounterState{Count: count, NextShortURL: c.encoder.EncodeToBase62(uint64(count + 1))}, nil
+}
+
+// PublishExpired publishes an expired event for every URL which stopped redirecting within [from, to)
+func (c *URLController) PublishExpired(ctx context.Context, from, to time.Time) error {
+	if c.webhooks == nil {
+		return nil
+	}
+
+	var after urls.Cursor
+	for {
+		expired, err := c.repository.ListScheduled(ctx, urls.NotAfterField, from, to, after, expiredPageSize)
+		if err != nil {
+			return fmt.Errorf("failed to list expired urls: %w", err)
+		}
+
+		events := make([]webhook.Event, len(expired))
+		for i, record := range expired {
+			events[i] = c.event(webhook.LinkExpired, record.ID, record.URL)
+			events[i].ID = fmt.Sprintf("%s.%s.%d", record.ID, webhook.LinkExpired, record.URL.NotAfter.UnixMicro())
+			events[i].At = *record.URL.NotAfter
+		}
+
+		if err := c.webhooks.Publish(ctx, events...); err != nil {
+			return fmt.Errorf("failed to publish expired urls: %w", err)
+		}
+
+		var done bool
+		if after, done = nextScheduled(expired, urls.NotAfterField, expiredPageSize); done {
+			return nil
+		}
+	}
+}
+
+// PublishChanges publishes the events of the changes of URLs committed after the cursor and returns the cursor
+// after the last published change. Changes are written in the transaction of the URL, so no event is lost,
+// and a change published again after a failure is not delivered twice
+func (c *URLController) PublishChanges(ctx context.Context, after urls.Cursor) (urls.Cursor, error) {
+	if c.webhooks == nil {
+		return after, nil
+	}
+
+	for {
+		changes, err := c.repository.ListChanges(ctx, after, changesPageSize)
+		if err != nil {
+			return after, fmt.Errorf("failed to list changes: %w", err)
+		}
+
+		var events []webhook.Event
+		for _, change := range changes {
+			events = append(events, c.changeEvents(change)...)
+		}
+
+		if len(events) > 0 {
+			if err := c.webhooks.Publish(ctx, events...); err != nil {
+				return after, fmt.Errorf("failed to publish changes: %w", err)
+			}
+		}
+
+		if len(changes) > 0 {
+			after = changes[len(changes)-1].Cursor()
+		}
+
+		if len(changes) < changesPageSize {
+			return after, nil
+		}
+	}
+}
+
+// LastChange returns the cursor of the last change of URLs, the zero cursor if there is none
+func (c *URLController) LastChange(ctx context.Context) (urls.Cursor, error) {
+	var last urls.Cursor
+	for {
+		changes, err := c.repository.ListChanges(ctx, last, changesPageSize)
+		if err != nil {
+			return urls.Cursor{}, fmt.Errorf("failed to list changes: %w", err)
+		}
+
+		if len(changes) > 0 {
+			last = changes[len(changes)-1].Cursor()
+		}
+
+		if len(changes) < changesPageSize {
+			return last, nil
+		}
+	}
+}
+
+// AddSubscription subscribes the URL to the event types, or to all of them if eventTypes is empty
+// The returned subscription holds the generated secret signing the deliveries
+func (c *URLController) AddSubscription(ctx context.Context, url string, eventTypes []string) (webhooks.Subscription, error) {
+	if c.webhooks == nil {
+		return webhooks.Subscription{}, errWebhooksDisabled
+	}
+
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		return webhooks.Subscription{}, fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+
+	return c.webhooks.AddSubscription(ctx, webhooks.Subscription{
+		URL:       url,
+		Secret:    base64.RawURLEncoding.EncodeToString(random),
+		Events:    eventTypes,
+		CreatedAt: time.Now().UTC(),
+	})
+}
+
+// ListSubscriptions returns the webhook subscriptions of the namespace
+func (c *URLController) ListSubscriptions(ctx context.Context) ([]webhooks.Subscription, error) {
+	if c.webhooks == nil {
+		return nil, errWebhooksDisabled
+	}
+
+	return c.webhooks.ListSubscriptions(ctx)
+}
+
+// DeleteSubscription deletes a webhook subscription, its pending deliveries are canceled
+func (c *URLController) DeleteSubscription(ctx context.Context, id string) error {
+	if c.webhooks == nil {
+		return errWebhooksDisabled
+	}
+
+	return c.webhooks.DeleteSubscription(ctx, id)
+}
+
+// ListDeliveries returns the latest deliveries of a webhook subscription with their attempts
+func (c *URLController) ListDeliveries(ctx context.Context, subscriptionID string, limit int) ([]webhooks.Entry, error) {
+	if c.webhooks == nil {
+		return nil, errWebhooksDisabled
+	}
+
+	return c.webhooks.ListDeliveries(ctx, subscriptionID, limit)
+}
+
+func (c *URLController) createShortURL(ctx context.Context, url urls.URL) (string, error) {
 	var id string
 	err := c.repository.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
 		total, err := c.counter.GetCountTx(tx)
@@ -70,17 +544,142 @@ func (c *URLController) createShortURL(ctx context.Context, longURL string) (str
 			return err
 		}
 
+		if err := c.consumeQuotaTx(tx, 1); err != nil {
+			return err
+		}
+
 		if err := c.counter.IncrementCounterTx(tx); err != nil {
 			return err
 		}
 
 		id = c.encoder.EncodeToBase62(uint64(total + 1))
-		return c.repository.AddURLTx(tx, id, urls.URL{LongURL: longURL})
+		return c.repository.AddURLTx(tx, urls.Key(url.Domain, id), url)
 	})
 
 	if err != nil {
 		return "", fmt.Errorf("failed to run transaction: %w", err)
 	}
 
+	c.index(ctx, urls.Key(url.Domain, id), url)
+	c.unfurl(urls.Key(url.Domain, id), url.LongURL)
 	return id, nil
 }
+
+func (c *URLController) createBatch(ctx context.Context, domain string, results []BulkResult, destinations []string, indexes []int) {
+	ids := make([]string, len(indexes))
+	err := c.repository.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		total, err := c.counter.GetCountTx(tx)
+		if err != nil {
+			return err
+		}
+
+		if err := c.consumeQuotaTx(tx, int64(len(indexes))); err != nil {
+			return err
+		}
+
+		if err := c.counter.IncrementCounterByTx(tx, int64(len(indexes))); err != nil {
+			return err
+		}
+
+		for i, index := range indexes {
+			ids[i] = c.encoder.EncodeToBase62(uint64(total + int64(i) + 1))
+			url := urls.URL{LongURL: destinations[index], Domain: domain}
+			if err := c.repository.AddURLTx(tx, urls.Key(domain, ids[i]), url); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	for i, index := range indexes {
+		if err != nil {
+			results[index].Err = fmt.Errorf("failed to run transaction: %w", err)
+			continue
+		}
+
+		results[index].ShortURL = ids[i]
+		url := urls.URL{LongURL: destinations[index], Domain: domain}
+		c.index(ctx, urls.Key(domain, ids[i]), url)
+		c.unfurl(urls.Key(domain, ids[i]), destinations[index])
+	}
+}
+
+// index adds the URL to the search index
+// A failure does not fail the request as the URL is already created, it is indexed again by the reindex command
+func (c *URLController) index(ctx context.Context, key string, url urls.URL) {
+	if err := c.searchIndex.Put(ctx, url.Document(key)); err != nil {
+		logrus.Warnf("failed to index url [%s]: %v", key, err)
+	}
+}
+
+// unfurl schedules fetching the preview of the destination page, the URL is not changed if it fails
+func (c *URLController) unfurl(key, longURL string) {
+	if c.previews == nil {
+		return
+	}
+
+	c.previews.Enqueue(longURL, func(ctx context.Context, preview unfurl.Preview) error {
+		return c.repository.UpdatePreview(ctx, key, preview)
+	})
+}
+
+// event returns an event of the URL with the document id key
+func (c *URLController) event(eventType, key string, url urls.URL) webhook.Event {
+	domain, code := urls.SplitKey(key)
+	return webhook.Event{
+		Type:     eventType,
+		At:       time.Now().UTC(),
+		ShortURL: code,
+		Domain:   domain,
+		LongURL:  url.LongURL,
+		Clicks:   url.Clicks,
+	}
+}
+
+// changeEvents returns the events of a change of an URL, their ids are derived from the change
+// so the events of a change published again are not delivered twice
+func (c *URLController) changeEvents(change urls.Change) []webhook.Event {
+	var url urls.URL
+	if change.URL != nil {
+		url = *change.URL
+	}
+
+	var types []string
+	switch change.Type {
+	case urls.ChangeCreated:
+		types = append(types, webhook.LinkCreated)
+	case urls.ChangeUpdated:
+		types = append(types, webhook.LinkUpdated)
+	case urls.ChangeDeleted:
+		types = append(types, webhook.LinkDeleted)
+	case urls.ChangeClicked:
+		for _, threshold := range c.clickThresholds {
+			if url.Clicks == threshold {
+				types = append(types, webhook.LinkClicks)
+			}
+		}
+
+		if url.Exhausted() && url.Clicks == url.MaxClicks {
+			types = append(types, webhook.LinkExhausted)
+		}
+	}
+
+	events := make([]webhook.Event, len(types))
+	for i, eventType := range types {
+		events[i] = c.event(eventType, change.Key, url)
+		events[i].ID = change.ID + "." + eventType
+		events[i].At = change.At
+	}
+
+	return events
+}
+
+// consumeQuotaTx counts n new URLs against the quota, it must run before the writes of the transaction
+func (c *URLController) consumeQuotaTx(tx *firestore.Transaction, n int64) error {
+	if c.quota == nil {
+		return nil
+	}
+
+	return c.quota.ConsumeTx(tx, n)
+}
@@ -3,9 +3,16 @@ package urlshortener_test
 import (
 	"context"
 	"errors"
+	"fmt"
+	"net/http"
+	"time"
 	"url-shortener/cmd/urlshortener/internal/urlshortener"
 	"url-shortener/cmd/urlshortener/internal/urlshortener/mocks"
+	"url-shortener/pkg/repository/firestore/tenants"
 	"url-shortener/pkg/repository/firestore/urls"
+	"url-shortener/pkg/search"
+	"url-shortener/pkg/unfurl"
+	"url-shortener/pkg/webhook"
 
 	"cloud.google.com/go/firestore"
 	"github.com/golang/mock/gomock"
@@ -24,6 +31,7 @@ var _ = Describe("Controller", func() {
 		mockRepository *mocks.MockRepository
 		mockCounter    *mocks.MockCounter
 		mockEncoder    *mocks.MockEncoder
+		mockIndex      *mocks.MockIndex
 		controller     *urlshortener.URLController
 		ctx            context.Context
 	)
@@ -33,63 +41,75 @@ var _ = Describe("Controller", func() {
 		mockRepository = mocks.NewMockRepository(mockCtrl)
 		mockCounter = mocks.NewMockCounter(mockCtrl)
 		mockEncoder = mocks.NewMockEncoder(mockCtrl)
-		controller = urlshortener.NewController(mockRepository, mockCounter, mockEncoder)
+		mockIndex = mocks.NewMockIndex(mockCtrl)
+		controller = urlshortener.NewController(mockRepository, mockCounter, mockEncoder, mockIndex)
 		ctx = context.Background()
 	})
 
 	When("when getting document id by long url fails", func() {
 		BeforeEach(func() {
-			mockRepository.EXPECT().GetDocIDByLongURL(ctx, longURL).Return("", errors.New("err"))
+			mockRepository.EXPECT().GetDocIDByLongURL(ctx, "", longURL).Return("", errors.New("err"))
 		})
 
 		It("should return an error", func() {
-			_, err := controller.CreateShortURL(ctx, longURL)
+			_, err := controller.CreateShortURL(ctx, "", longURL)
 			Expect(err).To(HaveOccurred())
 		})
 	})
 
 	When("getting document id by long url succeeds", func() {
 		BeforeEach(func() {
-			mockRepository.EXPECT().GetDocIDByLongURL(ctx, longURL).Return(shortURL, nil)
+			mockRepository.EXPECT().GetDocIDByLongURL(ctx, "", longURL).Return(shortURL, nil)
 		})
 
 		It("should return a short url", func() {
-			url, err := controller.CreateShortURL(ctx, longURL)
+			url, err := controller.CreateShortURL(ctx, "", longURL)
 			Expect(err).ToNot(HaveOccurred())
 			Expect(url).To(Equal(shortURL))
 		})
 	})
 
+	When("getting the counter state", func() {
+		It("should return the count and the short url of the next id", func() {
+			mockCounter.EXPECT().GetCount(ctx).Return(int64(61), nil)
+			mockEncoder.EXPECT().EncodeToBase62(uint64(62)).Return("10")
+
+			state, err := controller.CounterState(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(state).To(Equal(urlshortener.CounterState{Count: 61, NextShortURL: "10"}))
+		})
+	})
+
 	When("getting total count fails", func() {
 		BeforeEach(func() {
-			mockRepository.EXPECT().GetDocIDByLongURL(ctx, longURL).Return(shortURL, urls.NewNotFoundError())
+			mockRepository.EXPECT().GetDocIDByLongURL(ctx, "", longURL).Return(shortURL, urls.NewNotFoundError())
 			mockRepository.EXPECT().RunTransaction(ctx, gomock.Any()).DoAndReturn(triggerTransaction)
 			mockCounter.EXPECT().GetCountTx(gomock.Any()).Return(int64(0), errors.New("err"))
 		})
 
 		It("should return an error", func() {
-			_, err := controller.CreateShortURL(ctx, longURL)
+			_, err := controller.CreateShortURL(ctx, "", longURL)
 			Expect(err).To(HaveOccurred())
 		})
 	})
 
 	When("incrementing counter fails", func() {
 		BeforeEach(func() {
-			mockRepository.EXPECT().GetDocIDByLongURL(ctx, longURL).Return(shortURL, urls.NewNotFoundError())
+			mockRepository.EXPECT().GetDocIDByLongURL(ctx, "", longURL).Return(shortURL, urls.NewNotFoundError())
 			mockRepository.EXPECT().RunTransaction(ctx, gomock.Any()).DoAndReturn(triggerTransaction)
 			mockCounter.EXPECT().GetCountTx(gomock.Any()).Return(int64(0), nil)
 			mockCounter.EXPECT().IncrementCounterTx(gomock.Any()).Return(errors.New("err"))
 		})
 
 		It("should return an error", func() {
-			_, err := controller.CreateShortURL(ctx, longURL)
+			_, err := controller.CreateShortURL(ctx, "", longURL)
 			Expect(err).To(HaveOccurred())
 		})
 	})
 
 	When("adding url fails", func() {
 		BeforeEach(func() {
-			mockRepository.EXPECT().GetDocIDByLongURL(ctx, longURL).Return(shortURL, urls.NewNotFoundError())
+			mockRepository.EXPECT().GetDocIDByLongURL(ctx, "", longURL).Return(shortURL, urls.NewNotFoundError())
 			mockRepository.EXPECT().RunTransaction(ctx, gomock.Any()).DoAndReturn(triggerTransaction)
 			mockCounter.EXPECT().GetCountTx(gomock.Any()).Return(int64(0), nil)
 			mockCounter.EXPECT().IncrementCounterTx(gomock.Any()).Return(nil)
@@ -98,28 +118,119 @@ var _ = Describe("Controller", func() {
 		})
 
 		It("should return an error", func() {
-			_, err := controller.CreateShortURL(ctx, longURL)
+			_, err := controller.CreateShortURL(ctx, "", longURL)
 			Expect(err).To(HaveOccurred())
 		})
 	})
 
 	When("running transaction succeeds", func() {
 		BeforeEach(func() {
-			mockRepository.EXPECT().GetDocIDByLongURL(ctx, longURL).Return(shortURL, urls.NewNotFoundError())
+			mockRepository.EXPECT().GetDocIDByLongURL(ctx, "", longURL).Return(shortURL, urls.NewNotFoundError())
 			mockRepository.EXPECT().RunTransaction(ctx, gomock.Any()).DoAndReturn(triggerTransaction)
 			mockCounter.EXPECT().GetCountTx(gomock.Any()).Return(int64(0), nil)
 			mockCounter.EXPECT().IncrementCounterTx(gomock.Any()).Return(nil)
 			mockEncoder.EXPECT().EncodeToBase62(gomock.Any()).Return(shortURL)
 			mockRepository.EXPECT().AddURLTx(gomock.Any(), shortURL, urls.URL{LongURL: longURL}).Return(nil)
+			mockIndex.EXPECT().Put(ctx, search.Document{ID: shortURL, LongURL: longURL}).Return(nil)
 		})
 
 		It("should return short url", func() {
-			url, err := controller.CreateShortURL(ctx, longURL)
+			url, err := controller.CreateShortURL(ctx, "", longURL)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(url).To(Equal(shortURL))
+		})
+	})
+
+	When("creating a short url on a branded domain", func() {
+		BeforeEach(func() {
+			mockRepository.EXPECT().GetDocIDByLongURL(ctx, "go.example.com", longURL).Return("", urls.NewNotFoundError())
+			mockRepository.EXPECT().RunTransaction(ctx, gomock.Any()).DoAndReturn(triggerTransaction)
+			mockCounter.EXPECT().GetCountTx(gomock.Any()).Return(int64(0), nil)
+			mockCounter.EXPECT().IncrementCounterTx(gomock.Any()).Return(nil)
+			mockEncoder.EXPECT().EncodeToBase62(gomock.Any()).Return(shortURL)
+			mockRepository.EXPECT().AddURLTx(gomock.Any(), shortURL+"@go.example.com", urls.URL{LongURL: longURL, Domain: "go.example.com"}).Return(nil)
+			mockIndex.EXPECT().Put(ctx, search.Document{ID: shortURL + "@go.example.com", LongURL: longURL}).Return(nil)
+		})
+
+		It("should store it under the domain and return its code", func() {
+			url, err := controller.CreateShortURL(ctx, "go.example.com", longURL)
 			Expect(err).ToNot(HaveOccurred())
 			Expect(url).To(Equal(shortURL))
 		})
 	})
 
+	When("a short url already exists on a branded domain", func() {
+		BeforeEach(func() {
+			mockRepository.EXPECT().GetDocIDByLongURL(ctx, "go.example.com", longURL).Return(shortURL+"@go.example.com", nil)
+		})
+
+		It("should return its code", func() {
+			url, err := controller.CreateShortURL(ctx, "go.example.com", longURL)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(url).To(Equal(shortURL))
+		})
+	})
+
+	When("creating an url with its own settings", func() {
+		var url = urls.URL{LongURL: longURL, RedirectType: http.StatusMovedPermanently}
+		BeforeEach(func() {
+			mockRepository.EXPECT().RunTransaction(ctx, gomock.Any()).DoAndReturn(triggerTransaction)
+			mockCounter.EXPECT().GetCountTx(gomock.Any()).Return(int64(0), nil)
+			mockCounter.EXPECT().IncrementCounterTx(gomock.Any()).Return(nil)
+			mockEncoder.EXPECT().EncodeToBase62(gomock.Any()).Return(shortURL)
+			mockRepository.EXPECT().AddURLTx(gomock.Any(), shortURL, urls.URL{LongURL: longURL, RedirectType: http.StatusMovedPermanently, Exclusive: true}).Return(nil)
+			mockIndex.EXPECT().Put(ctx, gomock.Any()).Return(errors.New("err"))
+		})
+
+		It("should not reuse an existing url and return short url even if indexing fails", func() {
+			id, err := controller.CreateURL(ctx, url)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(id).To(Equal(shortURL))
+		})
+	})
+
+	When("creating urls in bulk", func() {
+		const (
+			newURL    = "new-url"
+			failedURL = "failed-url"
+		)
+
+		BeforeEach(func() {
+			mockRepository.EXPECT().GetDocIDByLongURL(ctx, "", longURL).Return(shortURL, nil)
+			mockRepository.EXPECT().GetDocIDByLongURL(ctx, "", newURL).Return("", urls.NewNotFoundError())
+			mockRepository.EXPECT().GetDocIDByLongURL(ctx, "", failedURL).Return("", errors.New("err"))
+			mockRepository.EXPECT().RunTransaction(ctx, gomock.Any()).DoAndReturn(triggerTransaction)
+			mockCounter.EXPECT().GetCountTx(gomock.Any()).Return(int64(41), nil)
+			mockCounter.EXPECT().IncrementCounterByTx(gomock.Any(), int64(1)).Return(nil)
+			mockEncoder.EXPECT().EncodeToBase62(uint64(42)).Return("new-short-url")
+			mockRepository.EXPECT().AddURLTx(gomock.Any(), "new-short-url", urls.URL{LongURL: newURL}).Return(nil)
+			mockIndex.EXPECT().Put(ctx, search.Document{ID: "new-short-url", LongURL: newURL}).Return(nil)
+		})
+
+		It("should reuse existing urls, allocate new ones in a batch and report failures", func() {
+			results := controller.CreateShortURLs(ctx, "", []string{longURL, newURL, failedURL, newURL})
+			Expect(results).To(HaveLen(4))
+			Expect(results[0]).To(Equal(urlshortener.BulkResult{LongURL: longURL, ShortURL: shortURL, Reused: true}))
+			Expect(results[1]).To(Equal(urlshortener.BulkResult{LongURL: newURL, ShortURL: "new-short-url"}))
+			Expect(results[2].Err).To(HaveOccurred())
+			Expect(results[3]).To(Equal(urlshortener.BulkResult{LongURL: newURL, ShortURL: "new-short-url", Reused: true}))
+		})
+	})
+
+	When("creating urls in bulk fails to run transaction", func() {
+		BeforeEach(func() {
+			mockRepository.EXPECT().GetDocIDByLongURL(ctx, "", longURL).Return("", urls.NewNotFoundError())
+			mockRepository.EXPECT().RunTransaction(ctx, gomock.Any()).DoAndReturn(triggerTransaction)
+			mockCounter.EXPECT().GetCountTx(gomock.Any()).Return(int64(0), errors.New("err"))
+		})
+
+		It("should report an error for every url of the batch", func() {
+			results := controller.CreateShortURLs(ctx, "", []string{longURL})
+			Expect(results).To(HaveLen(1))
+			Expect(results[0].Err).To(HaveOccurred())
+		})
+	})
+
 	When("getting url object by short url succeds", func() {
 		BeforeEach(func() {
 			mockRepository.EXPECT().GetByShortURL(ctx, shortURL).Return(urls.URL{LongURL: longURL}, nil)
@@ -132,6 +243,293 @@ var _ = Describe("Controller", func() {
 		})
 	})
 
+	When("getting the stats of an url", func() {
+		BeforeEach(func() {
+			mockRepository.EXPECT().GetStats(ctx, shortURL).Return(urls.URL{LongURL: longURL, VariantClicks: map[string]int64{"a": 2}}, nil)
+		})
+
+		It("should return url object with its variant clicks", func() {
+			url, err := controller.GetStats(ctx, shortURL)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(url.VariantClicks).To(Equal(map[string]int64{"a": 2}))
+		})
+	})
+
+	When("listing scheduled urls", func() {
+		var from, to time.Time
+
+		BeforeEach(func() {
+			from = time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+			to = from.Add(time.Hour)
+		})
+
+		It("should return activating and deactivating urls", func() {
+			mockRepository.EXPECT().ListScheduled(ctx, urls.NotBeforeField, from, to, urls.Cursor{}, 2).Return([]urls.Record{{ID: shortURL}}, nil)
+			mockRepository.EXPECT().ListScheduled(ctx, urls.NotAfterField, from, to, urls.Cursor{}, 2).Return(nil, nil)
+
+			schedule, err := controller.ListScheduled(ctx, from, to, urlshortener.ScheduleCursor{}, 2)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(schedule.Activating).To(Equal([]urls.Record{{ID: shortURL}}))
+			Expect(schedule.Deactivating).To(BeEmpty())
+			Expect(schedule.Next.Done()).To(BeTrue())
+		})
+
+		It("should continue full pages after their last url and skip finished lists", func() {
+			notBefore := from.Add(time.Minute)
+			after := urls.Cursor{At: from, ID: "previous"}
+			mockRepository.EXPECT().ListScheduled(ctx, urls.NotBeforeField, from, to, after, 1).
+				Return([]urls.Record{{ID: shortURL, URL: urls.URL{NotBefore: &notBefore}}}, nil)
+
+			schedule, err := controller.ListScheduled(ctx, from, to, urlshortener.ScheduleCursor{Activating: after, DeactivatingDone: true}, 1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(schedule.Next).To(Equal(urlshortener.ScheduleCursor{
+				Activating:       urls.Cursor{At: notBefore, ID: shortURL},
+				DeactivatingDone: true,
+			}))
+		})
+	})
+
+	When("recording a variant click", func() {
+		BeforeEach(func() {
+			mockRepository.EXPECT().IncrementVariantClicks(ctx, shortURL, "a").Return(nil)
+		})
+
+		It("should increment the variant clicks", func() {
+			Expect(controller.RecordVariantClick(ctx, shortURL, "a")).To(Succeed())
+		})
+	})
+
+	When("consuming a click of an url", func() {
+		BeforeEach(func() {
+			mockRepository.EXPECT().ConsumeClick(ctx, shortURL).Return(urls.URL{}, urls.NewExhaustedError())
+		})
+
+		It("should return the repository error", func() {
+			_, err := controller.ConsumeClick(ctx, shortURL)
+			Expect(err).To(BeAssignableToTypeOf(urls.ExhaustedError{}))
+		})
+	})
+
+	When("creating urls of a tenant", func() {
+		var mockQuota *mocks.MockQuota
+
+		BeforeEach(func() {
+			mockQuota = mocks.NewMockQuota(mockCtrl)
+			controller = urlshortener.NewTenantController(mockRepository, mockCounter, mockEncoder, mockIndex, mockQuota)
+			mockRepository.EXPECT().GetDocIDByLongURL(ctx, "", longURL).Return("", urls.NewNotFoundError())
+			mockRepository.EXPECT().RunTransaction(ctx, gomock.Any()).DoAndReturn(triggerTransaction)
+			mockCounter.EXPECT().GetCountTx(gomock.Any()).Return(int64(0), nil)
+		})
+
+		It("should count them towards the quota", func() {
+			mockQuota.EXPECT().ConsumeTx(gomock.Any(), int64(1)).Return(nil)
+			mockCounter.EXPECT().IncrementCounterTx(gomock.Any()).Return(nil)
+			mockEncoder.EXPECT().EncodeToBase62(gomock.Any()).Return(shortURL)
+			mockRepository.EXPECT().AddURLTx(gomock.Any(), shortURL, urls.URL{LongURL: longURL}).Return(nil)
+			mockIndex.EXPECT().Put(ctx, gomock.Any()).Return(nil)
+
+			url, err := controller.CreateShortURL(ctx, "", longURL)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(url).To(Equal(shortURL))
+		})
+
+		It("should not create them once the quota is exceeded", func() {
+			mockQuota.EXPECT().ConsumeTx(gomock.Any(), int64(1)).Return(tenants.NewQuotaExceededError("daily", 10))
+
+			_, err := controller.CreateShortURL(ctx, "", longURL)
+			var quotaErr tenants.QuotaExceededError
+			Expect(errors.As(err, &quotaErr)).To(BeTrue())
+		})
+	})
+
+	When("updating metadata of an url", func() {
+		var metadata = urls.Metadata{Title: "title", Tags: []string{"tag"}}
+
+		BeforeEach(func() {
+			mockRepository.EXPECT().UpdateMetadata(ctx, shortURL, metadata).Return(nil)
+			mockRepository.EXPECT().GetByShortURL(ctx, shortURL).Return(urls.URL{LongURL: longURL, Metadata: metadata}, nil)
+			mockIndex.EXPECT().Put(ctx, search.Document{ID: shortURL, LongURL: longURL, Title: "title", Tags: []string{"tag"}}).Return(nil)
+		})
+
+		It("should index the updated url", func() {
+			url, err := controller.UpdateMetadata(ctx, shortURL, metadata)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(url.Metadata).To(Equal(metadata))
+		})
+	})
+
+	When("updating metadata of an url that does not exist", func() {
+		BeforeEach(func() {
+			mockRepository.EXPECT().UpdateMetadata(ctx, shortURL, urls.Metadata{}).Return(urls.NewNotFoundError())
+		})
+
+		It("should return not found error", func() {
+			_, err := controller.UpdateMetadata(ctx, shortURL, urls.Metadata{})
+			Expect(err).To(BeAssignableToTypeOf(urls.NotFoundError{}))
+		})
+	})
+
+	When("creating a short url with previews", func() {
+		var (
+			mockPreviews *mocks.MockPreviews
+			save         unfurl.Save
+		)
+
+		BeforeEach(func() {
+			mockPreviews = mocks.NewMockPreviews(mockCtrl)
+			controller.WithPreviews(mockPreviews)
+			mockRepository.EXPECT().GetDocIDByLongURL(ctx, "", longURL).Return("", urls.NewNotFoundError())
+			mockRepository.EXPECT().RunTransaction(ctx, gomock.Any()).DoAndReturn(triggerTransaction)
+			mockCounter.EXPECT().GetCountTx(gomock.Any()).Return(int64(0), nil)
+			mockCounter.EXPECT().IncrementCounterTx(gomock.Any()).Return(nil)
+			mockEncoder.EXPECT().EncodeToBase62(gomock.Any()).Return(shortURL)
+			mockRepository.EXPECT().AddURLTx(gomock.Any(), shortURL, urls.URL{LongURL: longURL}).Return(nil)
+			mockIndex.EXPECT().Put(ctx, gomock.Any()).Return(nil)
+			mockPreviews.EXPECT().Enqueue(longURL, gomock.Any()).DoAndReturn(func(url string, saveFunc unfurl.Save) bool {
+				save = saveFunc
+				return true
+			})
+		})
+
+		It("should store the fetched preview on the url", func() {
+			_, err := controller.CreateShortURL(ctx, "", longURL)
+			Expect(err).ToNot(HaveOccurred())
+
+			preview := unfurl.Preview{Title: "title"}
+			mockRepository.EXPECT().UpdatePreview(ctx, shortURL, preview).Return(nil)
+			Expect(save(ctx, preview)).To(Succeed())
+		})
+	})
+
+	When("publishing changes with webhooks", func() {
+		var (
+			mockWebhooks *mocks.MockWebhooks
+			at           = time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC)
+		)
+
+		BeforeEach(func() {
+			mockWebhooks = mocks.NewMockWebhooks(mockCtrl)
+			controller.WithWebhooks(mockWebhooks, []int64{2, 10})
+		})
+
+		It("should publish an event per change identified by the change", func() {
+			changes := []urls.Change{
+				{ID: "c1", Type: urls.ChangeCreated, Key: shortURL + "@go.example.com", URL: &urls.URL{LongURL: longURL}, At: at},
+				{ID: "c2", Type: urls.ChangeDeleted, Key: shortURL, URL: &urls.URL{LongURL: longURL}, At: at.Add(time.Second)},
+			}
+			mockRepository.EXPECT().ListChanges(ctx, urls.Cursor{}, 500).Return(changes, nil)
+			mockWebhooks.EXPECT().Publish(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, events ...webhook.Event) error {
+				Expect(events).To(HaveLen(2))
+				Expect(events[0].ID).To(Equal("c1.link.created"))
+				Expect(events[0].Type).To(Equal(webhook.LinkCreated))
+				Expect(events[0].ShortURL).To(Equal(shortURL))
+				Expect(events[0].Domain).To(Equal("go.example.com"))
+				Expect(events[0].LongURL).To(Equal(longURL))
+				Expect(events[0].At).To(Equal(at))
+				Expect(events[1].Type).To(Equal(webhook.LinkDeleted))
+				Expect(events[1].LongURL).To(Equal(longURL))
+				return nil
+			})
+
+			Expect(controller.PublishChanges(ctx, urls.Cursor{})).To(Equal(changes[1].Cursor()))
+		})
+
+		It("should publish click events at the thresholds and once the url is exhausted", func() {
+			changes := []urls.Change{
+				{ID: "c1", Type: urls.ChangeClicked, Key: shortURL, URL: &urls.URL{LongURL: longURL, MaxClicks: 3, Clicks: 1}, At: at},
+				{ID: "c2", Type: urls.ChangeClicked, Key: shortURL, URL: &urls.URL{LongURL: longURL, MaxClicks: 3, Clicks: 2}, At: at},
+				{ID: "c3", Type: urls.ChangeClicked, Key: shortURL, URL: &urls.URL{LongURL: longURL, MaxClicks: 3, Clicks: 3}, At: at},
+			}
+			mockRepository.EXPECT().ListChanges(ctx, urls.Cursor{}, 500).Return(changes, nil)
+			mockWebhooks.EXPECT().Publish(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, events ...webhook.Event) error {
+				Expect(events).To(HaveLen(2))
+				Expect(events[0].Type).To(Equal(webhook.LinkClicks))
+				Expect(events[0].Clicks).To(Equal(int64(2)))
+				Expect(events[1].Type).To(Equal(webhook.LinkExhausted))
+				return nil
+			})
+
+			Expect(controller.PublishChanges(ctx, urls.Cursor{})).To(Equal(changes[2].Cursor()))
+		})
+
+		It("should return the cursor of the published pages if publishing fails", func() {
+			page := make([]urls.Change, 500)
+			for i := range page {
+				page[i] = urls.Change{ID: fmt.Sprintf("c%d", i), Type: urls.ChangeUpdated, Key: shortURL, URL: &urls.URL{LongURL: longURL}, At: at}
+			}
+
+			gomock.InOrder(
+				mockRepository.EXPECT().ListChanges(ctx, urls.Cursor{}, 500).Return(page, nil),
+				mockWebhooks.EXPECT().Publish(ctx, gomock.Any()).Return(nil),
+				mockRepository.EXPECT().ListChanges(ctx, page[499].Cursor(), 500).Return(page[:1], nil),
+				mockWebhooks.EXPECT().Publish(ctx, gomock.Any()).Return(errors.New("err")),
+			)
+
+			published, err := controller.PublishChanges(ctx, urls.Cursor{})
+			Expect(err).To(HaveOccurred())
+			Expect(published).To(Equal(page[499].Cursor()))
+		})
+	})
+
+	When("deleting an url", func() {
+		BeforeEach(func() {
+			mockRepository.EXPECT().Delete(ctx, shortURL).Return(nil)
+			mockIndex.EXPECT().Delete(ctx, shortURL).Return(nil)
+		})
+
+		It("should remove it from the index", func() {
+			Expect(controller.DeleteURL(ctx, shortURL)).To(Succeed())
+		})
+	})
+
+	When("deleting an url that does not exist", func() {
+		BeforeEach(func() {
+			mockRepository.EXPECT().Delete(ctx, shortURL).Return(urls.NewNotFoundError())
+		})
+
+		It("should return not found error", func() {
+			Expect(controller.DeleteURL(ctx, shortURL)).To(BeAssignableToTypeOf(urls.NotFoundError{}))
+		})
+	})
+
+	When("publishing expired urls", func() {
+		var (
+			mockWebhooks *mocks.MockWebhooks
+			from         = time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC)
+			to           = from.Add(time.Minute)
+			notAfter     = from.Add(time.Second)
+		)
+
+		BeforeEach(func() {
+			mockWebhooks = mocks.NewMockWebhooks(mockCtrl)
+			controller.WithWebhooks(mockWebhooks, nil)
+		})
+
+		It("should publish an expired event at the end of each url", func() {
+			mockRepository.EXPECT().ListScheduled(ctx, urls.NotAfterField, from, to, urls.Cursor{}, 500).
+				Return([]urls.Record{{ID: shortURL, URL: urls.URL{LongURL: longURL, NotAfter: &notAfter}}}, nil)
+			mockWebhooks.EXPECT().Publish(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, events ...webhook.Event) error {
+				Expect(events).To(ConsistOf(HaveField("Type", webhook.LinkExpired)))
+				Expect(events[0].At).To(Equal(notAfter))
+				return nil
+			})
+
+			Expect(controller.PublishExpired(ctx, from, to)).To(Succeed())
+		})
+
+		It("should continue after a full page", func() {
+			page := make([]urls.Record, 500)
+			for i := range page {
+				page[i] = urls.Record{ID: fmt.Sprintf("id-%d", i), URL: urls.URL{LongURL: longURL, NotAfter: &notAfter}}
+			}
+
+			mockRepository.EXPECT().ListScheduled(ctx, urls.NotAfterField, from, to, urls.Cursor{At: notAfter, ID: "id-499"}, 500).Return(nil, nil)
+			mockRepository.EXPECT().ListScheduled(ctx, urls.NotAfterField, from, to, urls.Cursor{}, 500).Return(page, nil)
+			mockWebhooks.EXPECT().Publish(ctx, gomock.Any()).Return(nil).Times(2)
+
+			Expect(controller.PublishExpired(ctx, from, to)).To(Succeed())
+		})
+	})
 })
 
 func triggerTransaction(ctx context.Context, txFunc func(context.Context, *firestore.Transaction) error) error {
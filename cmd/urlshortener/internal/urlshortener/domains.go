@@ -0,0 +1,267 @@
+package urlshortener
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"net"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+	"url-shortener/pkg/redirect"
+	"url-shortener/pkg/repository/firestore/domains"
+	"url-shortener/pkg/repository/firestore/urls"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+//go:generate mockgen --source=domains.go --destination mocks/domains.go --package mocks
+
+type DomainStore interface {
+	AddDomain(ctx context.Context, domain domains.Domain) error
+	DeleteDomain(ctx context.Context, name string) error
+	ListDomains(ctx context.Context) ([]domains.Domain, error)
+}
+
+// domainKey is the gin context key of the domain resolved for the request
+const domainKey = "domain"
+
+var domainPattern = regexp.MustCompile(`^([a-z0-9]([a-z0-9-]*[a-z0-9])?\.)+[a-z]{2,}$`)
+
+// DomainRegistry resolves request hosts to registered branded domains
+// The domains are kept in memory and reloaded from the store after ttl,
+// changes made through the registry apply immediately on this instance
+type DomainRegistry struct {
+	store    DomainStore
+	ttl      time.Duration
+	mu       sync.Mutex
+	domains  map[string]domains.Domain
+	loadedAt time.Time
+}
+
+// NewDomainRegistry is a constructor function
+func NewDomainRegistry(store DomainStore, ttl time.Duration) *DomainRegistry {
+	return &DomainRegistry{
+		store: store,
+		ttl:   ttl,
+	}
+}
+
+// Resolve returns the registered domain of the host, the port is ignored
+// If the domains cannot be reloaded, the previously loaded ones are used
+func (r *DomainRegistry) Resolve(ctx context.Context, host string) (domains.Domain, bool) {
+	name := normalizeHost(host)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.domains == nil || time.Since(r.loadedAt) > r.ttl {
+		if err := r.load(ctx); err != nil {
+			logrus.Errorf("Failed to load domains: %v", err)
+		}
+	}
+
+	domain, ok := r.domains[name]
+	return domain, ok
+}
+
+// Register adds a branded domain
+func (r *DomainRegistry) Register(ctx context.Context, domain domains.Domain) error {
+	if err := r.store.AddDomain(ctx, domain); err != nil {
+		return err
+	}
+
+	r.invalidate()
+	return nil
+}
+
+// Unregister removes a branded domain, its URLs are kept
+func (r *DomainRegistry) Unregister(ctx context.Context, name string) error {
+	if err := r.store.DeleteDomain(ctx, name); err != nil {
+		return err
+	}
+
+	r.invalidate()
+	return nil
+}
+
+// List returns all registered domains
+func (r *DomainRegistry) List(ctx context.Context) ([]domains.Domain, error) {
+	return r.store.ListDomains(ctx)
+}
+
+func (r *DomainRegistry) load(ctx context.Context) error {
+	list, err := r.store.ListDomains(ctx)
+	r.loadedAt = time.Now()
+	if err != nil {
+		if r.domains == nil {
+			r.domains = map[string]domains.Domain{}
+		}
+
+		return err
+	}
+
+	r.domains = make(map[string]domains.Domain, len(list))
+	for _, domain := range list {
+		r.domains[domain.Name] = domain
+	}
+
+	return nil
+}
+
+func (r *DomainRegistry) invalidate() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.domains = nil
+}
+
+// normalizeHost lowercases the host and strips its port and trailing dot
+func normalizeHost(host string) string {
+	if name, _, err := net.SplitHostPort(host); err == nil {
+		host = name
+	}
+
+	return strings.TrimSuffix(strings.ToLower(host), ".")
+}
+
+// domain returns the branded domain the request was sent to, zero domain for the default one
+func (p *Presenter) domain(ctx *gin.Context) domains.Domain {
+	if value, ok := ctx.Get(domainKey); ok {
+		return value.(domains.Domain)
+	}
+
+	var domain domains.Domain
+	if p.config.Domains != nil {
+		domain, _ = p.config.Domains.Resolve(ctx, ctx.Request.Host)
+	}
+
+	ctx.Set(domainKey, domain)
+	return domain
+}
+
+// registeredDomain returns the branded domain of the name, if it is registered
+func (p *Presenter) registeredDomain(ctx *gin.Context, name string) (domains.Domain, bool) {
+	if p.config.Domains == nil {
+		return domains.Domain{}, false
+	}
+
+	return p.config.Domains.Resolve(ctx, name)
+}
+
+// urlKey returns the document id of the short URL of the request on its domain
+// Short URLs which are not valid codes are rejected by ValidateShortURL before
+func (p *Presenter) urlKey(ctx *gin.Context) string {
+	return urls.Key(p.domain(ctx).Name, ctx.Param("short_url"))
+}
+
+// ValidateShortURL answers requests for short URLs which are not valid codes with not found,
+// so they cannot name a short URL of another domain
+func (p *Presenter) ValidateShortURL(ctx *gin.Context) {
+	if !urls.ValidCode(ctx.Param("short_url")) {
+		ctx.AbortWithStatusJSON(http.StatusNotFound, "URL does not exist")
+		return
+	}
+
+	ctx.Next()
+}
+
+// notFound answers requests for unknown short URLs, branded domains may send them to their fallback page
+func (p *Presenter) notFound(ctx *gin.Context) {
+	if fallback := p.domain(ctx).NotFoundURL; fallback != "" {
+		ctx.Header("Cache-Control", redirect.CacheControl(http.StatusFound))
+		ctx.Redirect(http.StatusFound, fallback)
+		return
+	}
+
+	ctx.JSON(http.StatusNotFound, "URL does not exist")
+}
+
+// ListDomains returns the registered branded domains
+func (p *Presenter) ListDomains(ctx *gin.Context) {
+	list, err := p.config.Domains.List(ctx)
+	if err != nil {
+		logrus.Errorf("Failed to list domains: %v", err)
+		ctx.JSON(http.StatusInternalServerError, "Error occured while listing domains")
+		return
+	}
+
+	if list == nil {
+		list = []domains.Domain{}
+	}
+
+	ctx.JSON(http.StatusOK, list)
+}
+
+// RegisterDomain registers a branded domain with its defaults
+func (p *Presenter) RegisterDomain(ctx *gin.Context) {
+	var domain domains.Domain
+	if err := ctx.ShouldBindJSON(&domain); err != nil {
+		ctx.JSON(http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	domain.Name = normalizeHost(domain.Name)
+	if !domainPattern.MatchString(domain.Name) {
+		ctx.JSON(http.StatusBadRequest, "Invalid domain name")
+		return
+	}
+
+	if domain.RedirectType != 0 && !redirect.IsValid(domain.RedirectType) {
+		ctx.JSON(http.StatusBadRequest, "Unsupported redirect type")
+		return
+	}
+
+	if domain.Tenant != "" && !p.tenantExists(ctx, domain.Tenant) {
+		ctx.JSON(http.StatusBadRequest, "Unknown tenant")
+		return
+	}
+
+	if err := p.config.Domains.Register(ctx, domain); err != nil {
+		var alreadyExistsErr domains.AlreadyExistsError
+		if errors.As(err, &alreadyExistsErr) {
+			ctx.JSON(http.StatusConflict, "Domain is already registered")
+			return
+		}
+
+		logrus.Errorf("Failed to register domain: %v", err)
+		ctx.JSON(http.StatusInternalServerError, "Error occured while registering domain")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, domain)
+}
+
+// UnregisterDomain removes a branded domain, its links stop resolving but are kept
+func (p *Presenter) UnregisterDomain(ctx *gin.Context) {
+	if err := p.config.Domains.Unregister(ctx, normalizeHost(ctx.Param("domain"))); err != nil {
+		var notFoundErr domains.NotFoundError
+		if errors.As(err, &notFoundErr) {
+			ctx.JSON(http.StatusNotFound, "Domain does not exist")
+			return
+		}
+
+		logrus.Errorf("Failed to unregister domain: %v", err)
+		ctx.JSON(http.StatusInternalServerError, "Error occured while unregistering domain")
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// RequireAdmin rejects requests without the admin API key in the X-API-Key header
+// The admin API is disabled if no key is configured
+func (p *Presenter) RequireAdmin(ctx *gin.Context) {
+	if p.config.AdminAPIKey == "" {
+		ctx.AbortWithStatusJSON(http.StatusForbidden, "Admin API is disabled")
+		return
+	}
+
+	if subtle.ConstantTimeCompare([]byte(ctx.GetHeader("X-API-Key")), []byte(p.config.AdminAPIKey)) != 1 {
+		ctx.AbortWithStatusJSON(http.StatusUnauthorized, "Invalid API key")
+		return
+	}
+
+	ctx.Next()
+}
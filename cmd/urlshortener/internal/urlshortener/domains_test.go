@@ -0,0 +1,256 @@
+package urlshortener_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"time"
+	"url-shortener/cmd/urlshortener/internal/urlshortener"
+	"url-shortener/cmd/urlshortener/internal/urlshortener/mocks"
+	"url-shortener/pkg/repository/firestore/domains"
+	"url-shortener/pkg/repository/firestore/urls"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Domains", func() {
+	const (
+		shortURL = "short-url"
+		brand    = "go.example.com"
+		apiKey   = "secret"
+	)
+
+	var (
+		mockCtrl       *gomock.Controller
+		mockController *mocks.MockController
+		mockStore      *mocks.MockDomainStore
+		registry       *urlshortener.DomainRegistry
+		presenter      *urlshortener.Presenter
+		domain         domains.Domain
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockController = mocks.NewMockController(mockCtrl)
+		mockStore = mocks.NewMockDomainStore(mockCtrl)
+		registry = urlshortener.NewDomainRegistry(mockStore, time.Hour)
+		presenter = urlshortener.NewPresenter(mockController, urlshortener.Config{
+			RedirectType: http.StatusFound,
+			Domains:      registry,
+			AdminAPIKey:  apiKey,
+		})
+		domain = domains.Domain{Name: brand, RedirectType: http.StatusMovedPermanently, NotFoundURL: "https://example.com/404"}
+	})
+
+	visit := func(host string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(recorder)
+		ctx.Request = httptest.NewRequest(http.MethodGet, "/"+shortURL, nil)
+		ctx.Request.Host = host
+		ctx.Params = []gin.Param{{Key: "short_url", Value: shortURL}}
+		presenter.RedirectToLongURL(ctx)
+		return recorder
+	}
+
+	When("resolving hosts", func() {
+		BeforeEach(func() {
+			mockStore.EXPECT().ListDomains(gomock.Any()).Return([]domains.Domain{domain}, nil).Times(1)
+		})
+
+		It("should load the domains once and ignore case and port", func() {
+			ctx := context.Background()
+			resolved, ok := registry.Resolve(ctx, "GO.example.com:8080")
+			Expect(ok).To(BeTrue())
+			Expect(resolved).To(Equal(domain))
+
+			_, ok = registry.Resolve(ctx, "sho.rt")
+			Expect(ok).To(BeFalse())
+		})
+	})
+
+	When("a domain is registered through the registry", func() {
+		BeforeEach(func() {
+			gomock.InOrder(
+				mockStore.EXPECT().ListDomains(gomock.Any()).Return(nil, nil),
+				mockStore.EXPECT().AddDomain(gomock.Any(), domain).Return(nil),
+				mockStore.EXPECT().ListDomains(gomock.Any()).Return([]domains.Domain{domain}, nil),
+			)
+		})
+
+		It("should resolve it immediately", func() {
+			ctx := context.Background()
+			_, ok := registry.Resolve(ctx, brand)
+			Expect(ok).To(BeFalse())
+
+			Expect(registry.Register(ctx, domain)).To(Succeed())
+			_, ok = registry.Resolve(ctx, brand)
+			Expect(ok).To(BeTrue())
+		})
+	})
+
+	When("visiting a short url on a branded domain", func() {
+		BeforeEach(func() {
+			mockStore.EXPECT().ListDomains(gomock.Any()).Return([]domains.Domain{domain}, nil)
+			mockController.EXPECT().GetByShortURL(gomock.Any(), shortURL+"@"+brand).Return(urls.URL{LongURL: "https://example.com/page"}, nil)
+		})
+
+		It("should look it up on the domain and use the domain redirect type", func() {
+			recorder := visit(brand)
+			Expect(recorder.Code).To(Equal(http.StatusMovedPermanently))
+			Expect(recorder.Header().Get("Location")).To(Equal("https://example.com/page"))
+		})
+	})
+
+	When("visiting an unknown short url on a branded domain", func() {
+		BeforeEach(func() {
+			mockStore.EXPECT().ListDomains(gomock.Any()).Return([]domains.Domain{domain}, nil)
+			mockController.EXPECT().GetByShortURL(gomock.Any(), shortURL+"@"+brand).Return(urls.URL{}, urls.NewNotFoundError())
+		})
+
+		It("should redirect to the not found page of the domain", func() {
+			recorder := visit(brand)
+			Expect(recorder.Code).To(Equal(http.StatusFound))
+			Expect(recorder.Header().Get("Location")).To(Equal(domain.NotFoundURL))
+		})
+	})
+
+	When("loading domains fails", func() {
+		BeforeEach(func() {
+			mockStore.EXPECT().ListDomains(gomock.Any()).Return(nil, errors.New("err"))
+			mockController.EXPECT().GetByShortURL(gomock.Any(), shortURL).Return(urls.URL{}, urls.NewNotFoundError())
+		})
+
+		It("should use the default domain", func() {
+			Expect(visit(brand).Code).To(Equal(http.StatusNotFound))
+		})
+	})
+
+	create := func(body string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(recorder)
+		ctx.Request = httptest.NewRequest(http.MethodPost, "/api/v1/urls", bytes.NewBufferString(body))
+		ctx.Request.Host = "sho.rt"
+		presenter.CreateURL(ctx)
+		return recorder
+	}
+
+	When("creating a short url on a registered domain", func() {
+		BeforeEach(func() {
+			mockStore.EXPECT().ListDomains(gomock.Any()).Return([]domains.Domain{domain}, nil)
+			mockController.EXPECT().CreateURL(gomock.Any(), urls.URL{LongURL: "https://example.com", Domain: brand}).Return(shortURL, nil)
+		})
+
+		It("should create it on the domain", func() {
+			Expect(create(`{"long_url": "https://example.com", "domain": "GO.example.com"}`).Code).To(Equal(http.StatusCreated))
+		})
+	})
+
+	When("creating a short url on an unknown domain", func() {
+		BeforeEach(func() {
+			mockStore.EXPECT().ListDomains(gomock.Any()).Return([]domains.Domain{domain}, nil)
+		})
+
+		It("should return http status bad request", func() {
+			Expect(create(`{"long_url": "https://example.com", "domain": "unknown.example.com"}`).Code).To(Equal(http.StatusBadRequest))
+		})
+	})
+
+	When("visiting a short url naming a code of another domain", func() {
+		It("should return http status not found without looking it up", func() {
+			engine := gin.New()
+			engine.GET("/:short_url", presenter.ValidateShortURL, presenter.RedirectToLongURL)
+			engine.GET("/api/v1/urls/:short_url", presenter.ValidateShortURL, presenter.GetURL)
+
+			for _, path := range []string{"/abc@" + brand, "/abc%40" + brand, "/api/v1/urls/abc@" + brand} {
+				recorder := httptest.NewRecorder()
+				engine.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+				Expect(recorder.Code).To(Equal(http.StatusNotFound), path)
+			}
+		})
+	})
+
+	admin := func(method, path, key, body string) *httptest.ResponseRecorder {
+		engine := gin.New()
+		group := engine.Group("/api/v1/admin", presenter.RequireAdmin)
+		group.GET("/domains", presenter.ListDomains)
+		group.POST("/domains", presenter.RegisterDomain)
+		group.DELETE("/domains/:domain", presenter.UnregisterDomain)
+
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		request.Header.Set("X-API-Key", key)
+		engine.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	When("calling the admin API without the API key", func() {
+		It("should return http status unauthorized", func() {
+			Expect(admin(http.MethodGet, "/api/v1/admin/domains", "wrong", "").Code).To(Equal(http.StatusUnauthorized))
+		})
+	})
+
+	When("calling the admin API without a configured API key", func() {
+		BeforeEach(func() {
+			presenter = urlshortener.NewPresenter(mockController, urlshortener.Config{Domains: registry})
+		})
+
+		It("should return http status forbidden", func() {
+			Expect(admin(http.MethodGet, "/api/v1/admin/domains", "", "").Code).To(Equal(http.StatusForbidden))
+		})
+	})
+
+	When("registering an invalid domain", func() {
+		It("should return http status bad request", func() {
+			Expect(admin(http.MethodPost, "/api/v1/admin/domains", apiKey, `{"name": "not a domain"}`).Code).To(Equal(http.StatusBadRequest))
+			Expect(admin(http.MethodPost, "/api/v1/admin/domains", apiKey, `{"name": "go.example.com", "redirect_type": 200}`).Code).To(Equal(http.StatusBadRequest))
+		})
+	})
+
+	When("registering a domain that already exists", func() {
+		BeforeEach(func() {
+			mockStore.EXPECT().AddDomain(gomock.Any(), domains.Domain{Name: brand}).Return(domains.NewAlreadyExistsError())
+		})
+
+		It("should return http status conflict", func() {
+			Expect(admin(http.MethodPost, "/api/v1/admin/domains", apiKey, `{"name": "Go.Example.com"}`).Code).To(Equal(http.StatusConflict))
+		})
+	})
+
+	When("registering a domain", func() {
+		BeforeEach(func() {
+			mockStore.EXPECT().AddDomain(gomock.Any(), domain).Return(nil)
+		})
+
+		It("should return http status created", func() {
+			body := `{"name": "go.example.com", "redirect_type": 301, "not_found_url": "https://example.com/404"}`
+			Expect(admin(http.MethodPost, "/api/v1/admin/domains", apiKey, body).Code).To(Equal(http.StatusCreated))
+		})
+	})
+
+	When("unregistering a domain that does not exist", func() {
+		BeforeEach(func() {
+			mockStore.EXPECT().DeleteDomain(gomock.Any(), brand).Return(domains.NewNotFoundError())
+		})
+
+		It("should return http status not found", func() {
+			Expect(admin(http.MethodDelete, "/api/v1/admin/domains/"+brand, apiKey, "").Code).To(Equal(http.StatusNotFound))
+		})
+	})
+
+	When("listing domains", func() {
+		BeforeEach(func() {
+			mockStore.EXPECT().ListDomains(gomock.Any()).Return([]domains.Domain{domain}, nil)
+		})
+
+		It("should return them", func() {
+			recorder := admin(http.MethodGet, "/api/v1/admin/domains", apiKey, "")
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Body.String()).To(ContainSubstring(brand))
+		})
+	})
+})
@@ -0,0 +1,406 @@
+package urlshortener
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"time"
+	"url-shortener/pkg/repository/firestore/domains"
+	"url-shortener/pkg/repository/firestore/tenants"
+	"url-shortener/pkg/repository/firestore/urls"
+	"url-shortener/pkg/search"
+	"url-shortener/pkg/urlshortenerpb"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	// tenantMetadata is the gRPC metadata key of the tenant API key, the counterpart of the X-Tenant-Key header
+	tenantMetadata = "x-tenant-key"
+	// adminMetadata is the gRPC metadata key of the admin API key, the counterpart of the X-API-Key header
+	adminMetadata = "x-api-key"
+)
+
+// GRPCServer serves the URLShortener gRPC service with the same controllers as the HTTP API
+type GRPCServer struct {
+	urlshortenerpb.UnimplementedURLShortenerServer
+	controller Controller
+	config     Config
+}
+
+// NewGRPCServer is a constructor function, it uses the Domains and Tenants of the config
+func NewGRPCServer(controller Controller, config Config) *GRPCServer {
+	return &GRPCServer{controller: controller, config: config}
+}
+
+// Deadline bounds unary calls to timeout, calls sent with an earlier deadline keep it
+func Deadline(timeout time.Duration) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if deadline, ok := ctx.Deadline(); !ok || time.Until(deadline) > timeout {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// Create creates a link, a link with only a long URL reuses an existing one for the same long URL
+func (s *GRPCServer) Create(ctx context.Context, request *urlshortenerpb.CreateRequest) (*urlshortenerpb.Link, error) {
+	if request.LongUrl == "" {
+		return nil, status.Error(codes.InvalidArgument, "Long URL is required")
+	}
+
+	if request.MaxClicks < 0 {
+		return nil, status.Error(codes.InvalidArgument, "Max clicks must not be negative")
+	}
+
+	notBefore, notAfter := toTime(request.NotBefore), toTime(request.NotAfter)
+	if notBefore != nil && notAfter != nil && !notAfter.After(*notBefore) {
+		return nil, status.Error(codes.InvalidArgument, "Not after must be later than not before")
+	}
+
+	metadata, err := toMetadata(metadataRequest{
+		Title:       request.Title,
+		Description: request.Description,
+		Tags:        request.Tags,
+		Folder:      request.Folder,
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "Invalid metadata: %v", err)
+	}
+
+	controller, domain, err := s.resolve(ctx, request.Domain)
+	if err != nil {
+		return nil, err
+	}
+
+	var code string
+	if request.MaxClicks == 0 && notBefore == nil && notAfter == nil && isZero(metadata) {
+		code, err = controller.CreateShortURL(ctx, domain, request.LongUrl)
+	} else {
+		code, err = controller.CreateURL(ctx, urls.URL{
+			LongURL:   request.LongUrl,
+			Domain:    domain,
+			MaxClicks: request.MaxClicks,
+			NotBefore: notBefore,
+			NotAfter:  notAfter,
+			Metadata:  metadata,
+		})
+	}
+
+	if err != nil {
+		return nil, toStatus(err, "create short url")
+	}
+
+	url, err := controller.GetByShortURL(ctx, urls.Key(domain, code))
+	if err != nil {
+		return nil, toStatus(err, "get short url")
+	}
+
+	return toLink(code, url), nil
+}
+
+// Get returns a link
+func (s *GRPCServer) Get(ctx context.Context, request *urlshortenerpb.GetRequest) (*urlshortenerpb.Link, error) {
+	controller, key, err := s.resolveURL(ctx, request.Domain, request.ShortUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	url, err := controller.GetByShortURL(ctx, key)
+	if err != nil {
+		return nil, toStatus(err, "get short url")
+	}
+
+	return toLink(request.ShortUrl, url), nil
+}
+
+// Update replaces the title, description, tags and folder of a link
+func (s *GRPCServer) Update(ctx context.Context, request *urlshortenerpb.UpdateRequest) (*urlshortenerpb.Link, error) {
+	metadata, err := toMetadata(metadataRequest{
+		Title:       request.Title,
+		Description: request.Description,
+		Tags:        request.Tags,
+		Folder:      request.Folder,
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "Invalid metadata: %v", err)
+	}
+
+	if err := s.authorize(ctx); err != nil {
+		return nil, err
+	}
+
+	controller, key, err := s.resolveURL(ctx, request.Domain, request.ShortUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	url, err := controller.UpdateMetadata(ctx, key, metadata)
+	if err != nil {
+		return nil, toStatus(err, "update metadata")
+	}
+
+	return toLink(request.ShortUrl, url), nil
+}
+
+// Delete deletes a link
+func (s *GRPCServer) Delete(ctx context.Context, request *urlshortenerpb.DeleteRequest) (*urlshortenerpb.DeleteResponse, error) {
+	if err := s.authorize(ctx); err != nil {
+		return nil, err
+	}
+
+	controller, key, err := s.resolveURL(ctx, request.Domain, request.ShortUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := controller.DeleteURL(ctx, key); err != nil {
+		return nil, toStatus(err, "delete short url")
+	}
+
+	return &urlshortenerpb.DeleteResponse{}, nil
+}
+
+// List returns the links matching the query, tags and folder ordered by code
+func (s *GRPCServer) List(ctx context.Context, request *urlshortenerpb.ListRequest) (*urlshortenerpb.ListResponse, error) {
+	query := search.Query{Text: request.Query, Limit: defaultSearchLimit}
+	if request.Limit != 0 {
+		if request.Limit < 1 || request.Limit > maxSearchLimit {
+			return nil, status.Errorf(codes.InvalidArgument, "Limit must be between 1 and %d", maxSearchLimit)
+		}
+
+		query.Limit = int(request.Limit)
+	}
+
+	var err error
+	if query.Tags, err = search.NormalizeTags(request.Tags); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "Invalid tags: %v", err)
+	}
+
+	if query.Folder, err = search.NormalizeFolder(request.Folder); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "Invalid folder: %v", err)
+	}
+
+	controller, err := s.controllerOf(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	docs, err := controller.Search(ctx, query)
+	if err != nil {
+		return nil, toStatus(err, "search urls")
+	}
+
+	response := &urlshortenerpb.ListResponse{Links: make([]*urlshortenerpb.Link, len(docs))}
+	for i, doc := range docs {
+		domain, code := urls.SplitKey(doc.ID)
+		response.Links[i] = &urlshortenerpb.Link{
+			ShortUrl:    code,
+			Domain:      domain,
+			LongUrl:     doc.LongURL,
+			Title:       doc.Title,
+			Description: doc.Description,
+			Tags:        doc.Tags,
+			Folder:      doc.Folder,
+		}
+	}
+
+	return response, nil
+}
+
+// Stats returns the click counts of a link, clicks are only counted for links with max clicks
+func (s *GRPCServer) Stats(ctx context.Context, request *urlshortenerpb.StatsRequest) (*urlshortenerpb.StatsResponse, error) {
+	controller, key, err := s.resolveURL(ctx, request.Domain, request.ShortUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	url, err := controller.GetStats(ctx, key)
+	if err != nil {
+		return nil, toStatus(err, "get short url")
+	}
+
+	return &urlshortenerpb.StatsResponse{
+		ShortUrl:      request.ShortUrl,
+		Domain:        url.Domain,
+		Clicks:        url.Clicks,
+		MaxClicks:     url.MaxClicks,
+		VariantClicks: url.VariantClicks,
+	}, nil
+}
+
+// resolveURL returns the controller of the call and the document id of the short URL on the domain
+func (s *GRPCServer) resolveURL(ctx context.Context, domain, shortURL string) (Controller, string, error) {
+	if shortURL == "" {
+		return nil, "", status.Error(codes.InvalidArgument, "Short URL is required")
+	}
+
+	if !urls.ValidCode(shortURL) {
+		return nil, "", status.Error(codes.NotFound, "URL does not exist")
+	}
+
+	controller, domain, err := s.resolve(ctx, domain)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return controller, urls.Key(domain, shortURL), nil
+}
+
+// resolve returns the controller of the call and the registered name of the domain, empty for the default domain
+// Like the HTTP API, tenants can only use their own domains and the default namespace only unowned ones
+func (s *GRPCServer) resolve(ctx context.Context, name string) (Controller, string, error) {
+	tenant, err := s.tenant(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if name != "" {
+		var (
+			domain domains.Domain
+			ok     bool
+		)
+		if s.config.Domains != nil {
+			domain, ok = s.config.Domains.Resolve(ctx, name)
+		}
+
+		if !ok || domain.Tenant != tenant.ID {
+			return nil, "", status.Error(codes.InvalidArgument, "Unknown domain")
+		}
+
+		name = domain.Name
+	} else if tenant.ID != "" {
+		return nil, "", status.Error(codes.PermissionDenied, "Domain does not belong to the tenant")
+	}
+
+	return s.controllerFor(tenant), name, nil
+}
+
+// controllerOf returns the controller of the tenant of the call, or the default one
+func (s *GRPCServer) controllerOf(ctx context.Context) (Controller, error) {
+	tenant, err := s.tenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.controllerFor(tenant), nil
+}
+
+func (s *GRPCServer) controllerFor(tenant tenants.Tenant) Controller {
+	if tenant.ID == "" {
+		return s.controller
+	}
+
+	return s.config.Tenants.Controller(tenant)
+}
+
+// tenant authenticates the tenant key of the call, calls without a key use the default namespace
+func (s *GRPCServer) tenant(ctx context.Context) (tenants.Tenant, error) {
+	if s.config.Tenants == nil {
+		return tenants.Tenant{}, nil
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	keys := md.Get(tenantMetadata)
+	if len(keys) == 0 || keys[0] == "" {
+		return tenants.Tenant{}, nil
+	}
+
+	tenant, ok := s.config.Tenants.Authenticate(ctx, keys[0])
+	if !ok {
+		return tenants.Tenant{}, status.Error(codes.Unauthenticated, "Invalid tenant key")
+	}
+
+	if tenant.Suspended {
+		return tenants.Tenant{}, status.Error(codes.PermissionDenied, "Tenant is suspended")
+	}
+
+	return tenant, nil
+}
+
+// authorize rejects calls changing links of the default namespace without the admin API key,
+// calls with a tenant key change the links of the tenant
+func (s *GRPCServer) authorize(ctx context.Context) error {
+	tenant, err := s.tenant(ctx)
+	if err != nil || tenant.ID != "" {
+		return err
+	}
+
+	if s.config.AdminAPIKey == "" {
+		return status.Error(codes.PermissionDenied, "Admin API is disabled")
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	keys := md.Get(adminMetadata)
+	if len(keys) == 0 || subtle.ConstantTimeCompare([]byte(keys[0]), []byte(s.config.AdminAPIKey)) != 1 {
+		return status.Error(codes.Unauthenticated, "Invalid API key")
+	}
+
+	return nil
+}
+
+// toStatus maps controller errors to gRPC status errors, unexpected errors are logged and reported as internal
+func toStatus(err error, action string) error {
+	var (
+		notFoundErr    urls.NotFoundError
+		quotaErr       tenants.QuotaExceededError
+		destinationErr DestinationError
+	)
+	switch {
+	case errors.As(err, &notFoundErr):
+		return status.Error(codes.NotFound, "URL does not exist")
+	case errors.As(err, &quotaErr):
+		return status.Errorf(codes.ResourceExhausted, "The %s quota of %d links is exceeded", quotaErr.Period, quotaErr.Limit)
+	case errors.As(err, &destinationErr):
+		return status.Errorf(codes.InvalidArgument, "Invalid destination: %s", destinationErr.Reason)
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, context.Canceled):
+		return status.FromContextError(err).Err()
+	}
+
+	logrus.Errorf("Failed to %s: %v", action, err)
+	return status.Error(codes.Internal, "Error occured while processing the request")
+}
+
+func toLink(code string, url urls.URL) *urlshortenerpb.Link {
+	link := &urlshortenerpb.Link{
+		ShortUrl:    code,
+		Domain:      url.Domain,
+		LongUrl:     url.LongURL,
+		Title:       url.Title,
+		Description: url.Description,
+		Tags:        url.Tags,
+		Folder:      url.Folder,
+		MaxClicks:   url.MaxClicks,
+	}
+
+	if url.NotBefore != nil {
+		link.NotBefore = timestamppb.New(*url.NotBefore)
+	}
+
+	if url.NotAfter != nil {
+		link.NotAfter = timestamppb.New(*url.NotAfter)
+	}
+
+	return link
+}
+
+func toTime(timestamp *timestamppb.Timestamp) *time.Time {
+	if timestamp == nil {
+		return nil
+	}
+
+	t := timestamp.AsTime()
+	return &t
+}
+
+func isZero(metadata urls.Metadata) bool {
+	return metadata.Title == "" && metadata.Description == "" && len(metadata.Tags) == 0 && metadata.Folder == ""
+}
@@ -0,0 +1,261 @@
+package urlshortener_test
+
+import (
+	"context"
+	"errors"
+	"time"
+	"url-shortener/cmd/urlshortener/internal/urlshortener"
+	"url-shortener/cmd/urlshortener/internal/urlshortener/mocks"
+	"url-shortener/pkg/repository/firestore/domains"
+	"url-shortener/pkg/repository/firestore/tenants"
+	"url-shortener/pkg/repository/firestore/urls"
+	"url-shortener/pkg/search"
+	"url-shortener/pkg/urlshortenerpb"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var _ = Describe("GRPCServer", func() {
+	var (
+		mockCtrl       *gomock.Controller
+		mockController *mocks.MockController
+		mockDomains    *mocks.MockDomains
+		mockTenants    *mocks.MockTenants
+		server         *urlshortener.GRPCServer
+		ctx            context.Context
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockController = mocks.NewMockController(mockCtrl)
+		mockDomains = mocks.NewMockDomains(mockCtrl)
+		mockTenants = mocks.NewMockTenants(mockCtrl)
+		server = urlshortener.NewGRPCServer(mockController, urlshortener.Config{Domains: mockDomains, Tenants: mockTenants, AdminAPIKey: "admin-secret"})
+		ctx = context.Background()
+	})
+
+	code := func(err error) codes.Code {
+		return status.Code(err)
+	}
+
+	When("creating a link with only a long URL", func() {
+		It("should reuse the short URL of the long URL", func() {
+			mockController.EXPECT().CreateShortURL(gomock.Any(), "", "https://example.com").Return("abc", nil)
+			mockController.EXPECT().GetByShortURL(gomock.Any(), "abc").Return(urls.URL{LongURL: "https://example.com"}, nil)
+
+			link, err := server.Create(ctx, &urlshortenerpb.CreateRequest{LongUrl: "https://example.com"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(link.ShortUrl).To(Equal("abc"))
+			Expect(link.LongUrl).To(Equal("https://example.com"))
+		})
+	})
+
+	When("creating a link with settings", func() {
+		It("should create an own short URL on the domain", func() {
+			notAfter := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+			mockDomains.EXPECT().Resolve(gomock.Any(), "go.example.com").Return(domains.Domain{Name: "go.example.com"}, true)
+			mockController.EXPECT().CreateURL(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, url urls.URL) (string, error) {
+				Expect(url.Domain).To(Equal("go.example.com"))
+				Expect(url.MaxClicks).To(Equal(int64(10)))
+				Expect(url.NotAfter).To(HaveValue(Equal(notAfter)))
+				Expect(url.Tags).To(Equal([]string{"spring"}))
+				return "abc", nil
+			})
+			mockController.EXPECT().GetByShortURL(gomock.Any(), urls.Key("go.example.com", "abc")).
+				Return(urls.URL{LongURL: "https://example.com", Domain: "go.example.com", MaxClicks: 10, NotAfter: &notAfter}, nil)
+
+			link, err := server.Create(ctx, &urlshortenerpb.CreateRequest{
+				LongUrl:   "https://example.com",
+				Domain:    "go.example.com",
+				MaxClicks: 10,
+				NotAfter:  timestamppb.New(notAfter),
+				Tags:      []string{"Spring"},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(link.Domain).To(Equal("go.example.com"))
+			Expect(link.NotAfter.AsTime()).To(Equal(notAfter))
+		})
+
+		It("should reject invalid arguments", func() {
+			_, err := server.Create(ctx, &urlshortenerpb.CreateRequest{})
+			Expect(code(err)).To(Equal(codes.InvalidArgument))
+
+			_, err = server.Create(ctx, &urlshortenerpb.CreateRequest{LongUrl: "https://example.com", MaxClicks: -1})
+			Expect(code(err)).To(Equal(codes.InvalidArgument))
+
+			mockDomains.EXPECT().Resolve(gomock.Any(), "unknown.example.com").Return(domains.Domain{}, false)
+			_, err = server.Create(ctx, &urlshortenerpb.CreateRequest{LongUrl: "https://example.com", Domain: "unknown.example.com"})
+			Expect(code(err)).To(Equal(codes.InvalidArgument))
+		})
+
+		It("should map exceeded quotas and invalid destinations", func() {
+			mockController.EXPECT().CreateShortURL(gomock.Any(), "", "https://example.com").Return("", tenants.NewQuotaExceededError("daily", 10))
+			_, err := server.Create(ctx, &urlshortenerpb.CreateRequest{LongUrl: "https://example.com"})
+			Expect(code(err)).To(Equal(codes.ResourceExhausted))
+
+			mockController.EXPECT().CreateShortURL(gomock.Any(), "", "https://example.com").
+				Return("", urlshortener.NewDestinationError("https://example.com", "loop"))
+			_, err = server.Create(ctx, &urlshortenerpb.CreateRequest{LongUrl: "https://example.com"})
+			Expect(code(err)).To(Equal(codes.InvalidArgument))
+		})
+	})
+
+	When("getting a link", func() {
+		It("should return not found if it does not exist", func() {
+			mockController.EXPECT().GetByShortURL(gomock.Any(), "abc").Return(urls.URL{}, urls.NewNotFoundError())
+
+			_, err := server.Get(ctx, &urlshortenerpb.GetRequest{ShortUrl: "abc"})
+			Expect(code(err)).To(Equal(codes.NotFound))
+		})
+
+		It("should return internal for unexpected errors", func() {
+			mockController.EXPECT().GetByShortURL(gomock.Any(), "abc").Return(urls.URL{}, errors.New("unavailable"))
+
+			_, err := server.Get(ctx, &urlshortenerpb.GetRequest{ShortUrl: "abc"})
+			Expect(code(err)).To(Equal(codes.Internal))
+		})
+
+		It("should require the short URL", func() {
+			_, err := server.Get(ctx, &urlshortenerpb.GetRequest{})
+			Expect(code(err)).To(Equal(codes.InvalidArgument))
+		})
+
+		It("should not find short URLs naming a code of another domain", func() {
+			_, err := server.Get(ctx, &urlshortenerpb.GetRequest{ShortUrl: "abc@go.example.com"})
+			Expect(code(err)).To(Equal(codes.NotFound))
+		})
+	})
+
+	When("updating a link", func() {
+		BeforeEach(func() {
+			ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("x-api-key", "admin-secret"))
+		})
+
+		It("should replace its metadata", func() {
+			mockController.EXPECT().UpdateMetadata(gomock.Any(), "abc", urls.Metadata{Title: "Spring", Folder: "campaigns"}).
+				Return(urls.URL{LongURL: "https://example.com", Metadata: urls.Metadata{Title: "Spring", Folder: "campaigns"}}, nil)
+
+			link, err := server.Update(ctx, &urlshortenerpb.UpdateRequest{ShortUrl: "abc", Title: "Spring", Folder: "campaigns"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(link.Title).To(Equal("Spring"))
+		})
+	})
+
+	When("deleting a link", func() {
+		It("should return not found if it does not exist", func() {
+			mockController.EXPECT().DeleteURL(gomock.Any(), "abc").Return(urls.NewNotFoundError())
+
+			ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("x-api-key", "admin-secret"))
+			_, err := server.Delete(ctx, &urlshortenerpb.DeleteRequest{ShortUrl: "abc"})
+			Expect(code(err)).To(Equal(codes.NotFound))
+		})
+
+		It("should require the admin API key in the default namespace", func() {
+			_, err := server.Delete(ctx, &urlshortenerpb.DeleteRequest{ShortUrl: "abc"})
+			Expect(code(err)).To(Equal(codes.Unauthenticated))
+
+			ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("x-api-key", "wrong"))
+			_, err = server.Update(ctx, &urlshortenerpb.UpdateRequest{ShortUrl: "abc", Title: "Spring"})
+			Expect(code(err)).To(Equal(codes.Unauthenticated))
+		})
+	})
+
+	When("listing links", func() {
+		It("should search with the normalized filters", func() {
+			mockController.EXPECT().Search(gomock.Any(), search.Query{Text: "spring", Tags: []string{"sale"}, Limit: 10}).
+				Return([]search.Document{{ID: urls.Key("go.example.com", "abc"), LongURL: "https://example.com"}}, nil)
+
+			response, err := server.List(ctx, &urlshortenerpb.ListRequest{Query: "spring", Tags: []string{"Sale"}, Limit: 10})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(response.Links).To(HaveLen(1))
+			Expect(response.Links[0].ShortUrl).To(Equal("abc"))
+			Expect(response.Links[0].Domain).To(Equal("go.example.com"))
+		})
+
+		It("should reject limits out of range", func() {
+			_, err := server.List(ctx, &urlshortenerpb.ListRequest{Limit: 1000})
+			Expect(code(err)).To(Equal(codes.InvalidArgument))
+		})
+	})
+
+	When("getting the stats of a link", func() {
+		It("should return its clicks", func() {
+			mockController.EXPECT().GetStats(gomock.Any(), "abc").
+				Return(urls.URL{Clicks: 3, MaxClicks: 10, VariantClicks: map[string]int64{"a": 2, "b": 1}}, nil)
+
+			stats, err := server.Stats(ctx, &urlshortenerpb.StatsRequest{ShortUrl: "abc"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(stats.Clicks).To(Equal(int64(3)))
+			Expect(stats.MaxClicks).To(Equal(int64(10)))
+			Expect(stats.VariantClicks).To(HaveKeyWithValue("a", int64(2)))
+		})
+	})
+
+	When("the call carries a tenant key", func() {
+		var tenantController *mocks.MockController
+
+		BeforeEach(func() {
+			tenantController = mocks.NewMockController(mockCtrl)
+			ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("x-tenant-key", "key"))
+		})
+
+		It("should use the namespace of the tenant on its domains", func() {
+			tenant := tenants.Tenant{ID: "acme"}
+			mockTenants.EXPECT().Authenticate(gomock.Any(), "key").Return(tenant, true)
+			mockTenants.EXPECT().Controller(tenant).Return(tenantController)
+			mockDomains.EXPECT().Resolve(gomock.Any(), "go.acme.com").Return(domains.Domain{Name: "go.acme.com", Tenant: "acme"}, true)
+			tenantController.EXPECT().GetByShortURL(gomock.Any(), urls.Key("go.acme.com", "abc")).Return(urls.URL{Domain: "go.acme.com"}, nil)
+
+			_, err := server.Get(ctx, &urlshortenerpb.GetRequest{ShortUrl: "abc", Domain: "go.acme.com"})
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("should reject the default domain and domains of others", func() {
+			mockTenants.EXPECT().Authenticate(gomock.Any(), "key").Return(tenants.Tenant{ID: "acme"}, true).Times(2)
+			_, err := server.Get(ctx, &urlshortenerpb.GetRequest{ShortUrl: "abc"})
+			Expect(code(err)).To(Equal(codes.PermissionDenied))
+
+			mockDomains.EXPECT().Resolve(gomock.Any(), "go.other.com").Return(domains.Domain{Name: "go.other.com", Tenant: "other"}, true)
+			_, err = server.Get(ctx, &urlshortenerpb.GetRequest{ShortUrl: "abc", Domain: "go.other.com"})
+			Expect(code(err)).To(Equal(codes.InvalidArgument))
+		})
+
+		It("should reject invalid keys and suspended tenants", func() {
+			mockTenants.EXPECT().Authenticate(gomock.Any(), "key").Return(tenants.Tenant{}, false)
+			_, err := server.List(ctx, &urlshortenerpb.ListRequest{})
+			Expect(code(err)).To(Equal(codes.Unauthenticated))
+
+			mockTenants.EXPECT().Authenticate(gomock.Any(), "key").Return(tenants.Tenant{ID: "acme", Suspended: true}, true)
+			_, err = server.List(ctx, &urlshortenerpb.ListRequest{})
+			Expect(code(err)).To(Equal(codes.PermissionDenied))
+		})
+	})
+
+	When("a call has no deadline", func() {
+		It("should get the default one, earlier deadlines are kept", func() {
+			interceptor := urlshortener.Deadline(time.Minute)
+			handler := func(ctx context.Context, _ interface{}) (interface{}, error) {
+				deadline, ok := ctx.Deadline()
+				Expect(ok).To(BeTrue())
+				return time.Until(deadline), nil
+			}
+
+			remaining, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{}, handler)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(remaining).To(BeNumerically("~", time.Minute, time.Second))
+
+			shortCtx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+			remaining, err = interceptor(shortCtx, nil, &grpc.UnaryServerInfo{}, handler)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(remaining).To(BeNumerically("<=", time.Second))
+		})
+	})
+})
@@ -0,0 +1,46 @@
+package urlshortener
+
+import (
+	"net/http"
+	"time"
+	"url-shortener/pkg/healthcheck"
+	"url-shortener/pkg/repository/firestore/urls"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type brokenURL struct {
+	ShortURL     string              `json:"short_url"`
+	Domain       string              `json:"domain,omitempty"`
+	LongURL      string              `json:"long_url"`
+	FailingSince *time.Time          `json:"failing_since,omitempty"`
+	Checks       []healthcheck.Check `json:"checks"`
+}
+
+type brokenResponse struct {
+	Results []brokenURL `json:"results"`
+}
+
+// ListBroken returns the URLs of the namespace whose long URL has been failing health checks for too long,
+// together with their recent checks
+func (p *Presenter) ListBroken(ctx *gin.Context) {
+	records, err := p.controllerOf(ctx).ListBroken(ctx)
+	if err != nil {
+		logrus.Errorf("Failed to list broken urls: %v", err)
+		ctx.JSON(http.StatusInternalServerError, "Error occured while listing broken URLs")
+		return
+	}
+
+	response := brokenResponse{Results: make([]brokenURL, len(records))}
+	for i, record := range records {
+		domain, code := urls.SplitKey(record.ID)
+		response.Results[i] = brokenURL{ShortURL: code, Domain: domain, LongURL: record.URL.LongURL}
+		if health := record.URL.Health; health != nil {
+			response.Results[i].FailingSince = health.FailingSince
+			response.Results[i].Checks = health.Checks
+		}
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
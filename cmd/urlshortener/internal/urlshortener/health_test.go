@@ -0,0 +1,86 @@
+package urlshortener_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"time"
+	"url-shortener/cmd/urlshortener/internal/urlshortener"
+	"url-shortener/cmd/urlshortener/internal/urlshortener/mocks"
+	"url-shortener/pkg/healthcheck"
+	"url-shortener/pkg/repository/firestore/urls"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Broken URLs", func() {
+	var (
+		mockCtrl       *gomock.Controller
+		mockController *mocks.MockController
+		presenter      *urlshortener.Presenter
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockController = mocks.NewMockController(mockCtrl)
+		presenter = urlshortener.NewPresenter(mockController, urlshortener.Config{})
+	})
+
+	list := func() *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(recorder)
+		ctx.Request = httptest.NewRequest(http.MethodGet, "/api/v1/urls/broken", nil)
+		presenter.ListBroken(ctx)
+		return recorder
+	}
+
+	When("listing broken urls", func() {
+		var failingSince = time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC)
+
+		BeforeEach(func() {
+			mockController.EXPECT().ListBroken(gomock.Any()).Return([]urls.Record{{
+				ID: "abc@go.example.com",
+				URL: urls.URL{LongURL: "https://gone.com", Health: &healthcheck.Health{
+					FailingSince: &failingSince,
+					Checks:       []healthcheck.Check{{At: failingSince, Status: http.StatusNotFound}},
+					Broken:       true,
+				}},
+			}}, nil)
+		})
+
+		It("should return them with their recent checks", func() {
+			recorder := list()
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+
+			var response struct {
+				Results []struct {
+					ShortURL     string              `json:"short_url"`
+					Domain       string              `json:"domain"`
+					LongURL      string              `json:"long_url"`
+					FailingSince time.Time           `json:"failing_since"`
+					Checks       []healthcheck.Check `json:"checks"`
+				} `json:"results"`
+			}
+			Expect(json.Unmarshal(recorder.Body.Bytes(), &response)).To(Succeed())
+			Expect(response.Results).To(HaveLen(1))
+			Expect(response.Results[0].ShortURL).To(Equal("abc"))
+			Expect(response.Results[0].Domain).To(Equal("go.example.com"))
+			Expect(response.Results[0].FailingSince).To(Equal(failingSince))
+			Expect(response.Results[0].Checks[0].Status).To(Equal(http.StatusNotFound))
+		})
+	})
+
+	When("listing broken urls fails", func() {
+		BeforeEach(func() {
+			mockController.EXPECT().ListBroken(gomock.Any()).Return(nil, errors.New("err"))
+		})
+
+		It("should return http status internal server error", func() {
+			Expect(list().Code).To(Equal(http.StatusInternalServerError))
+		})
+	})
+})
@@ -0,0 +1,93 @@
+package urlshortener
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+	"url-shortener/pkg/repository/firestore/urls"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type urlDetails struct {
+	urlSummary
+	RedirectType int        `json:"redirect_type,omitempty"`
+	MaxClicks    int64      `json:"max_clicks,omitempty"`
+	NotBefore    *time.Time `json:"not_before,omitempty"`
+	NotAfter     *time.Time `json:"not_after,omitempty"`
+	// Protected is set for password protected URLs, the password is never returned
+	Protected bool `json:"protected,omitempty"`
+}
+
+type statsResponse struct {
+	ShortURL      string           `json:"short_url"`
+	Domain        string           `json:"domain,omitempty"`
+	Clicks        int64            `json:"clicks"`
+	MaxClicks     int64            `json:"max_clicks,omitempty"`
+	VariantClicks map[string]int64 `json:"variant_clicks,omitempty"`
+}
+
+// GetURL returns the settings and metadata of a short URL
+func (p *Presenter) GetURL(ctx *gin.Context) {
+	url, ok := p.getURL(ctx, p.controllerOf(ctx).GetByShortURL)
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, toDetails(ctx.Param("short_url"), url))
+}
+
+// GetStats returns the click counts of a short URL, clicks are only counted for URLs with max clicks
+func (p *Presenter) GetStats(ctx *gin.Context) {
+	url, ok := p.getURL(ctx, p.controllerOf(ctx).GetStats)
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, statsResponse{
+		ShortURL:      ctx.Param("short_url"),
+		Domain:        url.Domain,
+		Clicks:        url.Clicks,
+		MaxClicks:     url.MaxClicks,
+		VariantClicks: url.VariantClicks,
+	})
+}
+
+// getURL returns the short URL of the request with get, or answers the request if it cannot be returned
+func (p *Presenter) getURL(ctx *gin.Context, get func(ctx context.Context, shortURL string) (urls.URL, error)) (urls.URL, bool) {
+	url, err := get(ctx, p.urlKey(ctx))
+	if err != nil {
+		var notFoundErr urls.NotFoundError
+		if errors.As(err, &notFoundErr) {
+			ctx.JSON(http.StatusNotFound, "URL does not exist")
+			return urls.URL{}, false
+		}
+
+		logrus.Errorf("Failed to get by short url: %v", err)
+		ctx.JSON(http.StatusInternalServerError, "Error occured while getting short URL")
+		return urls.URL{}, false
+	}
+
+	return url, true
+}
+
+func toDetails(code string, url urls.URL) urlDetails {
+	return urlDetails{
+		urlSummary: urlSummary{
+			ShortURL:    code,
+			Domain:      url.Domain,
+			LongURL:     url.LongURL,
+			Title:       url.Title,
+			Description: url.Description,
+			Tags:        url.Tags,
+			Folder:      url.Folder,
+		},
+		RedirectType: url.RedirectType,
+		MaxClicks:    url.MaxClicks,
+		NotBefore:    url.NotBefore,
+		NotAfter:     url.NotAfter,
+		Protected:    url.PasswordHash != "",
+	}
+}
@@ -0,0 +1,81 @@
+package urlshortener_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"time"
+	"url-shortener/cmd/urlshortener/internal/urlshortener"
+	"url-shortener/cmd/urlshortener/internal/urlshortener/mocks"
+	"url-shortener/pkg/repository/firestore/urls"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Links", func() {
+	var (
+		mockCtrl       *gomock.Controller
+		mockController *mocks.MockController
+		handler        *gin.Engine
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockController = mocks.NewMockController(mockCtrl)
+		presenter := urlshortener.NewPresenter(mockController, urlshortener.Config{})
+		handler = gin.New()
+		handler.GET("/api/v1/urls/search", presenter.SearchURLs)
+		handler.GET("/api/v1/urls/:short_url", presenter.GetURL)
+		handler.GET("/api/v1/urls/:short_url/stats", presenter.GetStats)
+	})
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+		return recorder
+	}
+
+	When("getting an url", func() {
+		It("should return its settings without its password", func() {
+			notAfter := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+			mockController.EXPECT().GetByShortURL(gomock.Any(), "abc").Return(urls.URL{
+				LongURL:      "https://example.com",
+				MaxClicks:    10,
+				NotAfter:     &notAfter,
+				PasswordHash: "hash",
+				Metadata:     urls.Metadata{Title: "Spring"},
+			}, nil)
+
+			recorder := serve("/api/v1/urls/abc")
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Body.String()).ToNot(ContainSubstring("hash"))
+
+			var response map[string]interface{}
+			Expect(json.Unmarshal(recorder.Body.Bytes(), &response)).To(Succeed())
+			Expect(response).To(HaveKeyWithValue("short_url", "abc"))
+			Expect(response).To(HaveKeyWithValue("title", "Spring"))
+			Expect(response).To(HaveKeyWithValue("max_clicks", BeNumerically("==", 10)))
+			Expect(response).To(HaveKeyWithValue("not_after", "2030-01-01T00:00:00Z"))
+			Expect(response).To(HaveKeyWithValue("protected", true))
+		})
+
+		It("should answer not found if it does not exist", func() {
+			mockController.EXPECT().GetByShortURL(gomock.Any(), "abc").Return(urls.URL{}, urls.NewNotFoundError())
+			Expect(serve("/api/v1/urls/abc").Code).To(Equal(http.StatusNotFound))
+		})
+	})
+
+	When("getting the stats of an url", func() {
+		It("should return its clicks", func() {
+			mockController.EXPECT().GetStats(gomock.Any(), "abc").
+				Return(urls.URL{Clicks: 3, MaxClicks: 10, VariantClicks: map[string]int64{"a": 3}}, nil)
+
+			recorder := serve("/api/v1/urls/abc/stats")
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Body.String()).To(MatchJSON(`{"short_url":"abc","clicks":3,"max_clicks":10,"variant_clicks":{"a":3}}`))
+		})
+	})
+})
@@ -0,0 +1,214 @@
+package urlshortener
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	netURL "net/url"
+	"strings"
+	"url-shortener/pkg/redirect"
+	"url-shortener/pkg/repository/firestore/domains"
+	"url-shortener/pkg/repository/firestore/urls"
+	"url-shortener/pkg/rules"
+	"url-shortener/pkg/variants"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DestinationError is returned for destinations which are short URLs of the service that loop,
+// form too long chains or do not exist
+type DestinationError struct {
+	Destination string
+	Reason      string
+}
+
+func NewDestinationError(destination, reason string) DestinationError {
+	return DestinationError{Destination: destination, Reason: reason}
+}
+
+func (e DestinationError) Error() string {
+	return fmt.Sprintf("invalid destination [%s]: %s", e.Destination, e.Reason)
+}
+
+// invalidDestination answers the request if the error is caused by a destination looping through short URLs
+func invalidDestination(ctx *gin.Context, err error) bool {
+	var destinationErr DestinationError
+	if !errors.As(err, &destinationErr) {
+		return false
+	}
+
+	ctx.JSON(http.StatusBadRequest, fmt.Sprintf("Invalid destination: %s", destinationErr.Reason))
+	return true
+}
+
+// ShortHosts knows the hosts serving short URLs, the configured hosts of the default domain and the branded domains
+type ShortHosts struct {
+	defaults map[string]bool
+	domains  Domains
+}
+
+// NewShortHosts is a constructor function, branded domains are not known if domains is nil
+func NewShortHosts(defaults []string, domains Domains) *ShortHosts {
+	hosts := &ShortHosts{defaults: make(map[string]bool, len(defaults)), domains: domains}
+	for _, host := range defaults {
+		hosts.defaults[normalizeHost(host)] = true
+	}
+
+	return hosts
+}
+
+// Lookup returns the branded domain served on the host, the zero domain for the default domain of the default namespace,
+// and false if the host does not serve short URLs
+func (h *ShortHosts) Lookup(ctx context.Context, host string) (domains.Domain, bool) {
+	if h.defaults[normalizeHost(host)] {
+		return domains.Domain{}, true
+	}
+
+	if h.domains == nil {
+		return domains.Domain{}, false
+	}
+
+	return h.domains.Resolve(ctx, host)
+}
+
+// flatten returns the destination a short URL destination finally redirects to, or the destination itself if it is
+// not a short URL or its chain cannot be flattened. It returns destination error if the chain is invalid
+// key is the document id of the URL the destination belongs to, empty for a new URL
+func (c *URLController) flatten(ctx context.Context, key, destination string) (string, error) {
+	if c.shortHosts == nil || destination == "" {
+		return destination, nil
+	}
+
+	path := map[string]bool{}
+	if key != "" {
+		path[key] = true
+	}
+
+	return c.follow(ctx, destination, path, 0)
+}
+
+// follow walks the chain of destination, path holds the URLs already on the chain to detect loops
+// Codes of other namespaces overlap with the codes of the controller, their short URLs are treated as external destinations
+func (c *URLController) follow(ctx context.Context, destination string, path map[string]bool, depth int) (string, error) {
+	parsed, err := netURL.Parse(destination)
+	if err != nil {
+		return destination, nil
+	}
+
+	domain, ok := c.shortHosts.Lookup(ctx, parsed.Host)
+	if !ok || domain.Tenant != c.namespace {
+		return destination, nil
+	}
+
+	code, suffix, _ := strings.Cut(strings.TrimPrefix(parsed.Path, "/"), "/")
+	if code == "" || "/"+suffix == qrPath {
+		return destination, nil
+	}
+
+	if !urls.ValidCode(code) {
+		return "", NewDestinationError(destination, "unknown short URL")
+	}
+
+	key := urls.Key(domain.Name, code)
+	if path[key] {
+		return "", NewDestinationError(destination, "redirect loop")
+	}
+
+	if depth >= c.maxDepth {
+		return "", NewDestinationError(destination, fmt.Sprintf("more than %d short URLs in a row", c.maxDepth))
+	}
+
+	url, err := c.repository.GetByShortURL(ctx, key)
+	if err != nil {
+		var notFoundErr urls.NotFoundError
+		if errors.As(err, &notFoundErr) {
+			return "", NewDestinationError(destination, "unknown short URL")
+		}
+
+		return "", fmt.Errorf("failed to get by short url: %w", err)
+	}
+
+	next := url.LongURL
+	if suffix != "" {
+		if !url.Prefix {
+			return "", NewDestinationError(destination, "unknown short URL")
+		}
+
+		if next, err = redirect.JoinPath(url.LongURL, "/"+suffix); err != nil {
+			return "", NewDestinationError(destination, err.Error())
+		}
+	}
+
+	path[key] = true
+	defer delete(path, key)
+
+	final, err := c.follow(ctx, next, path, depth+1)
+	if err != nil {
+		return "", err
+	}
+
+	for _, other := range otherDestinations(url) {
+		if _, err := c.follow(ctx, other, path, depth+1); err != nil {
+			return "", err
+		}
+	}
+
+	if !flat(url) {
+		return destination, nil
+	}
+
+	return final, nil
+}
+
+// flat reports whether redirects of the URL always go to its long URL, so links to it can skip it
+func flat(url urls.URL) bool {
+	return url.PasswordHash == "" && url.MaxClicks == 0 && url.NotBefore == nil && url.NotAfter == nil &&
+		len(url.Rules) == 0 && len(url.Variants) == 0 && !url.QueryPassthrough && len(url.UTM) == 0
+}
+
+// otherDestinations returns the destinations of the URL besides its long URL
+func otherDestinations(url urls.URL) []string {
+	var destinations []string
+	if url.FallbackURL != "" {
+		destinations = append(destinations, url.FallbackURL)
+	}
+
+	for _, rule := range url.Rules {
+		destinations = append(destinations, rule.Destination)
+	}
+
+	for _, variant := range url.Variants {
+		destinations = append(destinations, variant.Destination)
+	}
+
+	return destinations
+}
+
+// flattenURL checks and flattens every destination of the URL stored with key, empty for a new URL
+func (c *URLController) flattenURL(ctx context.Context, key string, url urls.URL) (urls.URL, error) {
+	url.Rules = append([]rules.Rule(nil), url.Rules...)
+	url.Variants = append([]variants.Variant(nil), url.Variants...)
+	var err error
+	if url.LongURL, err = c.flatten(ctx, key, url.LongURL); err != nil {
+		return urls.URL{}, err
+	}
+
+	if url.FallbackURL, err = c.flatten(ctx, key, url.FallbackURL); err != nil {
+		return urls.URL{}, err
+	}
+
+	for i := range url.Rules {
+		if url.Rules[i].Destination, err = c.flatten(ctx, key, url.Rules[i].Destination); err != nil {
+			return urls.URL{}, err
+		}
+	}
+
+	for i := range url.Variants {
+		if url.Variants[i].Destination, err = c.flatten(ctx, key, url.Variants[i].Destination); err != nil {
+			return urls.URL{}, err
+		}
+	}
+
+	return url, nil
+}
@@ -0,0 +1,214 @@
+package urlshortener_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"url-shortener/cmd/urlshortener/internal/urlshortener"
+	"url-shortener/cmd/urlshortener/internal/urlshortener/mocks"
+	"url-shortener/pkg/repository/firestore/domains"
+	"url-shortener/pkg/repository/firestore/urls"
+	"url-shortener/pkg/variants"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Loop protection", func() {
+	const (
+		newID    = "new"
+		external = "https://example.com/page"
+	)
+
+	var (
+		mockCtrl       *gomock.Controller
+		mockRepository *mocks.MockRepository
+		mockCounter    *mocks.MockCounter
+		mockEncoder    *mocks.MockEncoder
+		mockIndex      *mocks.MockIndex
+		controller     *urlshortener.URLController
+		ctx            context.Context
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockRepository = mocks.NewMockRepository(mockCtrl)
+		mockCounter = mocks.NewMockCounter(mockCtrl)
+		mockEncoder = mocks.NewMockEncoder(mockCtrl)
+		mockIndex = mocks.NewMockIndex(mockCtrl)
+		controller = urlshortener.NewController(mockRepository, mockCounter, mockEncoder, mockIndex).
+			WithLoopProtection(urlshortener.NewShortHosts([]string{"sho.rt"}, nil), "", 2)
+		ctx = context.Background()
+	})
+
+	stored := func(id string, url urls.URL) {
+		mockRepository.EXPECT().GetByShortURL(ctx, id).Return(url, nil).AnyTimes()
+	}
+
+	expectCreated := func(longURL string) {
+		mockRepository.EXPECT().GetDocIDByLongURL(ctx, "", longURL).Return("", urls.NewNotFoundError())
+		mockRepository.EXPECT().RunTransaction(ctx, gomock.Any()).DoAndReturn(triggerTransaction)
+		mockCounter.EXPECT().GetCountTx(gomock.Any()).Return(int64(0), nil)
+		mockCounter.EXPECT().IncrementCounterTx(gomock.Any()).Return(nil)
+		mockEncoder.EXPECT().EncodeToBase62(gomock.Any()).Return(newID)
+		mockRepository.EXPECT().AddURLTx(gomock.Any(), newID, urls.URL{LongURL: longURL}).Return(nil)
+		mockIndex.EXPECT().Put(ctx, gomock.Any()).Return(nil)
+	}
+
+	expectDestinationError := func(err error, reason string) {
+		var destinationErr urlshortener.DestinationError
+		Expect(errors.As(err, &destinationErr)).To(BeTrue())
+		Expect(destinationErr.Reason).To(ContainSubstring(reason))
+	}
+
+	When("the destination is not a short url", func() {
+		BeforeEach(func() {
+			expectCreated(external)
+		})
+
+		It("should keep it", func() {
+			Expect(controller.CreateShortURL(ctx, "", external)).To(Equal(newID))
+		})
+	})
+
+	When("the destination is a short url without own settings", func() {
+		BeforeEach(func() {
+			stored("a", urls.URL{LongURL: "https://SHO.RT/b?ignored=1"})
+			stored("b", urls.URL{LongURL: external})
+			expectCreated(external)
+		})
+
+		It("should flatten the chain to its final destination", func() {
+			Expect(controller.CreateShortURL(ctx, "", "https://sho.rt/a")).To(Equal(newID))
+		})
+	})
+
+	When("the destination is a short url with own settings", func() {
+		BeforeEach(func() {
+			stored("split", urls.URL{LongURL: external, Variants: []variants.Variant{{Name: "b", Destination: "https://other.com", Weight: 1}}})
+			expectCreated("https://sho.rt/split")
+		})
+
+		It("should keep it", func() {
+			Expect(controller.CreateShortURL(ctx, "", "https://sho.rt/split")).To(Equal(newID))
+		})
+	})
+
+	When("the destination chain loops", func() {
+		BeforeEach(func() {
+			stored("a", urls.URL{LongURL: "https://sho.rt/b"})
+			stored("b", urls.URL{LongURL: "https://sho.rt/a"})
+		})
+
+		It("should reject it", func() {
+			_, err := controller.CreateShortURL(ctx, "", "https://sho.rt/a")
+			expectDestinationError(err, "loop")
+		})
+	})
+
+	When("the destination chain loops through a variant", func() {
+		BeforeEach(func() {
+			stored("a", urls.URL{LongURL: external, Variants: []variants.Variant{{Name: "b", Destination: "https://sho.rt/a", Weight: 1}}})
+		})
+
+		It("should reject it", func() {
+			_, err := controller.CreateShortURL(ctx, "", "https://sho.rt/a")
+			expectDestinationError(err, "loop")
+		})
+	})
+
+	When("the destination chain is too long", func() {
+		BeforeEach(func() {
+			stored("a", urls.URL{LongURL: "https://sho.rt/b"})
+			stored("b", urls.URL{LongURL: "https://sho.rt/c"})
+		})
+
+		It("should reject it", func() {
+			_, err := controller.CreateShortURL(ctx, "", "https://sho.rt/a")
+			expectDestinationError(err, "more than 2")
+		})
+	})
+
+	When("the destination is a short url which does not exist", func() {
+		BeforeEach(func() {
+			mockRepository.EXPECT().GetByShortURL(ctx, newID).Return(urls.URL{}, urls.NewNotFoundError())
+		})
+
+		It("should reject it", func() {
+			_, err := controller.CreateShortURL(ctx, "", "https://sho.rt/"+newID)
+			expectDestinationError(err, "unknown")
+		})
+	})
+
+	When("the destination is a short url naming a code of another domain", func() {
+		It("should reject it without looking it up", func() {
+			_, err := controller.CreateShortURL(ctx, "", "https://sho.rt/"+newID+"@go.example.com")
+			expectDestinationError(err, "unknown")
+		})
+	})
+
+	When("the destination is a short url of another namespace", func() {
+		BeforeEach(func() {
+			mockDomains := mocks.NewMockDomains(mockCtrl)
+			mockDomains.EXPECT().Resolve(ctx, "go.acme.com").Return(domains.Domain{Name: "go.acme.com", Tenant: "acme"}, true)
+			controller = urlshortener.NewController(mockRepository, mockCounter, mockEncoder, mockIndex).
+				WithLoopProtection(urlshortener.NewShortHosts([]string{"sho.rt"}, mockDomains), "", 2)
+			expectCreated("https://go.acme.com/a")
+		})
+
+		It("should keep it without looking up its code in the own namespace", func() {
+			Expect(controller.CreateShortURL(ctx, "", "https://go.acme.com/a")).To(Equal(newID))
+		})
+	})
+
+	When("the destination is a short url on a domain of the own namespace", func() {
+		BeforeEach(func() {
+			mockDomains := mocks.NewMockDomains(mockCtrl)
+			mockDomains.EXPECT().Resolve(ctx, "go.acme.com").Return(domains.Domain{Name: "go.acme.com", Tenant: "acme"}, true)
+			mockDomains.EXPECT().Resolve(ctx, "example.com").Return(domains.Domain{}, false)
+			controller = urlshortener.NewController(mockRepository, mockCounter, mockEncoder, mockIndex).
+				WithLoopProtection(urlshortener.NewShortHosts([]string{"sho.rt"}, mockDomains), "acme", 2)
+			stored(urls.Key("go.acme.com", "a"), urls.URL{LongURL: external})
+			expectCreated(external)
+		})
+
+		It("should flatten it", func() {
+			Expect(controller.CreateShortURL(ctx, "", "https://go.acme.com/a")).To(Equal(newID))
+		})
+	})
+
+	When("the variants of an url point back to it", func() {
+		BeforeEach(func() {
+			stored("a", urls.URL{LongURL: "https://sho.rt/x"})
+		})
+
+		It("should reject them", func() {
+			err := controller.UpdateVariants(ctx, "x", []variants.Variant{{Name: "a", Destination: "https://sho.rt/a", Weight: 1}})
+			expectDestinationError(err, "loop")
+		})
+	})
+
+	When("creating a short url with a looping destination", func() {
+		var mockController *mocks.MockController
+
+		BeforeEach(func() {
+			mockController = mocks.NewMockController(mockCtrl)
+			mockController.EXPECT().CreateShortURL(gomock.Any(), "", "https://sho.rt/a").
+				Return("", urlshortener.NewDestinationError("https://sho.rt/a", "redirect loop"))
+		})
+
+		It("should return http status bad request", func() {
+			presenter := urlshortener.NewPresenter(mockController, urlshortener.Config{})
+			recorder := httptest.NewRecorder()
+			ginCtx, _ := gin.CreateTestContext(recorder)
+			ginCtx.Request = httptest.NewRequest(http.MethodPost, "/", strings.NewReader("https://sho.rt/a"))
+			presenter.CreateShortURL(ginCtx)
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+			Expect(recorder.Body.String()).To(ContainSubstring("redirect loop"))
+		})
+	})
+})
@@ -7,7 +7,14 @@ package mocks
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
+	domains "url-shortener/pkg/repository/firestore/domains"
 	urls "url-shortener/pkg/repository/firestore/urls"
+	webhooks "url-shortener/pkg/repository/firestore/webhooks"
+	search "url-shortener/pkg/search"
+	unfurl "url-shortener/pkg/unfurl"
+	variants "url-shortener/pkg/variants"
+	webhook "url-shortener/pkg/webhook"
 
 	firestore "cloud.google.com/go/firestore"
 	gomock "github.com/golang/mock/gomock"
@@ -50,6 +57,35 @@ func (mr *MockRepositoryMockRecorder) AddURLTx(tx, id, url interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddURLTx", reflect.TypeOf((*MockRepository)(nil).AddURLTx), tx, id, url)
 }
 
+// ConsumeClick mocks base method.
+func (m *MockRepository) ConsumeClick(ctx context.Context, shortURL string) (urls.URL, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConsumeClick", ctx, shortURL)
+	ret0, _ := ret[0].(urls.URL)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConsumeClick indicates an expected call of ConsumeClick.
+func (mr *MockRepositoryMockRecorder) ConsumeClick(ctx, shortURL interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumeClick", reflect.TypeOf((*MockRepository)(nil).ConsumeClick), ctx, shortURL)
+}
+
+// Delete mocks base method.
+func (m *MockRepository) Delete(ctx context.Context, shortURL string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, shortURL)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockRepositoryMockRecorder) Delete(ctx, shortURL interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockRepository)(nil).Delete), ctx, shortURL)
+}
+
 // GetByShortURL mocks base method.
 func (m *MockRepository) GetByShortURL(ctx context.Context, shortURL string) (urls.URL, error) {
 	m.ctrl.T.Helper()
@@ -66,18 +102,92 @@ func (mr *MockRepositoryMockRecorder) GetByShortURL(ctx, shortURL interface{}) *
 }
 
 // GetDocIDByLongURL mocks base method.
-func (m *MockRepository) GetDocIDByLongURL(ctx context.Context, longURL string) (string, error) {
+func (m *MockRepository) GetDocIDByLongURL(ctx context.Context, domain, longURL string) (string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetDocIDByLongURL", ctx, longURL)
+	ret := m.ctrl.Call(m, "GetDocIDByLongURL", ctx, domain, longURL)
 	ret0, _ := ret[0].(string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetDocIDByLongURL indicates an expected call of GetDocIDByLongURL.
-func (mr *MockRepositoryMockRecorder) GetDocIDByLongURL(ctx, longURL interface{}) *gomock.Call {
+func (mr *MockRepositoryMockRecorder) GetDocIDByLongURL(ctx, domain, longURL interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDocIDByLongURL", reflect.TypeOf((*MockRepository)(nil).GetDocIDByLongURL), ctx, domain, longURL)
+}
+
+// GetStats mocks base method.
+func (m *MockRepository) GetStats(ctx context.Context, shortURL string) (urls.URL, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStats", ctx, shortURL)
+	ret0, _ := ret[0].(urls.URL)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStats indicates an expected call of GetStats.
+func (mr *MockRepositoryMockRecorder) GetStats(ctx, shortURL interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStats", reflect.TypeOf((*MockRepository)(nil).GetStats), ctx, shortURL)
+}
+
+// IncrementVariantClicks mocks base method.
+func (m *MockRepository) IncrementVariantClicks(ctx context.Context, shortURL, variant string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IncrementVariantClicks", ctx, shortURL, variant)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// IncrementVariantClicks indicates an expected call of IncrementVariantClicks.
+func (mr *MockRepositoryMockRecorder) IncrementVariantClicks(ctx, shortURL, variant interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncrementVariantClicks", reflect.TypeOf((*MockRepository)(nil).IncrementVariantClicks), ctx, shortURL, variant)
+}
+
+// ListBroken mocks base method.
+func (m *MockRepository) ListBroken(ctx context.Context) ([]urls.Record, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListBroken", ctx)
+	ret0, _ := ret[0].([]urls.Record)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListBroken indicates an expected call of ListBroken.
+func (mr *MockRepositoryMockRecorder) ListBroken(ctx interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDocIDByLongURL", reflect.TypeOf((*MockRepository)(nil).GetDocIDByLongURL), ctx, longURL)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListBroken", reflect.TypeOf((*MockRepository)(nil).ListBroken), ctx)
+}
+
+// ListChanges mocks base method.
+func (m *MockRepository) ListChanges(ctx context.Context, after urls.Cursor, limit int) ([]urls.Change, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListChanges", ctx, after, limit)
+	ret0, _ := ret[0].([]urls.Change)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListChanges indicates an expected call of ListChanges.
+func (mr *MockRepositoryMockRecorder) ListChanges(ctx, after, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListChanges", reflect.TypeOf((*MockRepository)(nil).ListChanges), ctx, after, limit)
+}
+
+// ListScheduled mocks base method.
+func (m *MockRepository) ListScheduled(ctx context.Context, field string, from, to time.Time, after urls.Cursor, limit int) ([]urls.Record, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListScheduled", ctx, field, from, to, after, limit)
+	ret0, _ := ret[0].([]urls.Record)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListScheduled indicates an expected call of ListScheduled.
+func (mr *MockRepositoryMockRecorder) ListScheduled(ctx, field, from, to, after, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListScheduled", reflect.TypeOf((*MockRepository)(nil).ListScheduled), ctx, field, from, to, after, limit)
 }
 
 // RunTransaction mocks base method.
@@ -94,6 +204,48 @@ func (mr *MockRepositoryMockRecorder) RunTransaction(ctx, txFunc interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RunTransaction", reflect.TypeOf((*MockRepository)(nil).RunTransaction), ctx, txFunc)
 }
 
+// UpdateMetadata mocks base method.
+func (m *MockRepository) UpdateMetadata(ctx context.Context, shortURL string, metadata urls.Metadata) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateMetadata", ctx, shortURL, metadata)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateMetadata indicates an expected call of UpdateMetadata.
+func (mr *MockRepositoryMockRecorder) UpdateMetadata(ctx, shortURL, metadata interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateMetadata", reflect.TypeOf((*MockRepository)(nil).UpdateMetadata), ctx, shortURL, metadata)
+}
+
+// UpdatePreview mocks base method.
+func (m *MockRepository) UpdatePreview(ctx context.Context, shortURL string, preview unfurl.Preview) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePreview", ctx, shortURL, preview)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePreview indicates an expected call of UpdatePreview.
+func (mr *MockRepositoryMockRecorder) UpdatePreview(ctx, shortURL, preview interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePreview", reflect.TypeOf((*MockRepository)(nil).UpdatePreview), ctx, shortURL, preview)
+}
+
+// UpdateVariants mocks base method.
+func (m *MockRepository) UpdateVariants(ctx context.Context, shortURL string, urlVariants []variants.Variant) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateVariants", ctx, shortURL, urlVariants)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateVariants indicates an expected call of UpdateVariants.
+func (mr *MockRepositoryMockRecorder) UpdateVariants(ctx, shortURL, urlVariants interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateVariants", reflect.TypeOf((*MockRepository)(nil).UpdateVariants), ctx, shortURL, urlVariants)
+}
+
 // MockCounter is a mock of Counter interface.
 type MockCounter struct {
 	ctrl     *gomock.Controller
@@ -117,6 +269,21 @@ func (m *MockCounter) EXPECT() *MockCounterMockRecorder {
 	return m.recorder
 }
 
+// GetCount mocks base method.
+func (m *MockCounter) GetCount(ctx context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCount", ctx)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCount indicates an expected call of GetCount.
+func (mr *MockCounterMockRecorder) GetCount(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCount", reflect.TypeOf((*MockCounter)(nil).GetCount), ctx)
+}
+
 // GetCountTx mocks base method.
 func (m *MockCounter) GetCountTx(tx *firestore.Transaction) (int64, error) {
 	m.ctrl.T.Helper()
@@ -132,6 +299,20 @@ func (mr *MockCounterMockRecorder) GetCountTx(tx interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCountTx", reflect.TypeOf((*MockCounter)(nil).GetCountTx), tx)
 }
 
+// IncrementCounterByTx mocks base method.
+func (m *MockCounter) IncrementCounterByTx(tx *firestore.Transaction, delta int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IncrementCounterByTx", tx, delta)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// IncrementCounterByTx indicates an expected call of IncrementCounterByTx.
+func (mr *MockCounterMockRecorder) IncrementCounterByTx(tx, delta interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncrementCounterByTx", reflect.TypeOf((*MockCounter)(nil).IncrementCounterByTx), tx, delta)
+}
+
 // IncrementCounterTx mocks base method.
 func (m *MockCounter) IncrementCounterTx(tx *firestore.Transaction) error {
 	m.ctrl.T.Helper()
@@ -182,3 +363,282 @@ func (mr *MockEncoderMockRecorder) EncodeToBase62(number interface{}) *gomock.Ca
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EncodeToBase62", reflect.TypeOf((*MockEncoder)(nil).EncodeToBase62), number)
 }
+
+// MockIndex is a mock of Index interface.
+type MockIndex struct {
+	ctrl     *gomock.Controller
+	recorder *MockIndexMockRecorder
+}
+
+// MockIndexMockRecorder is the mock recorder for MockIndex.
+type MockIndexMockRecorder struct {
+	mock *MockIndex
+}
+
+// NewMockIndex creates a new mock instance.
+func NewMockIndex(ctrl *gomock.Controller) *MockIndex {
+	mock := &MockIndex{ctrl: ctrl}
+	mock.recorder = &MockIndexMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIndex) EXPECT() *MockIndexMockRecorder {
+	return m.recorder
+}
+
+// Delete mocks base method.
+func (m *MockIndex) Delete(ctx context.Context, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockIndexMockRecorder) Delete(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockIndex)(nil).Delete), ctx, id)
+}
+
+// Put mocks base method.
+func (m *MockIndex) Put(ctx context.Context, doc search.Document) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Put", ctx, doc)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Put indicates an expected call of Put.
+func (mr *MockIndexMockRecorder) Put(ctx, doc interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Put", reflect.TypeOf((*MockIndex)(nil).Put), ctx, doc)
+}
+
+// Search mocks base method.
+func (m *MockIndex) Search(ctx context.Context, query search.Query) ([]search.Document, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Search", ctx, query)
+	ret0, _ := ret[0].([]search.Document)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Search indicates an expected call of Search.
+func (mr *MockIndexMockRecorder) Search(ctx, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Search", reflect.TypeOf((*MockIndex)(nil).Search), ctx, query)
+}
+
+// MockPreviews is a mock of Previews interface.
+type MockPreviews struct {
+	ctrl     *gomock.Controller
+	recorder *MockPreviewsMockRecorder
+}
+
+// MockPreviewsMockRecorder is the mock recorder for MockPreviews.
+type MockPreviewsMockRecorder struct {
+	mock *MockPreviews
+}
+
+// NewMockPreviews creates a new mock instance.
+func NewMockPreviews(ctrl *gomock.Controller) *MockPreviews {
+	mock := &MockPreviews{ctrl: ctrl}
+	mock.recorder = &MockPreviewsMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPreviews) EXPECT() *MockPreviewsMockRecorder {
+	return m.recorder
+}
+
+// Enqueue mocks base method.
+func (m *MockPreviews) Enqueue(url string, save unfurl.Save) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Enqueue", url, save)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// Enqueue indicates an expected call of Enqueue.
+func (mr *MockPreviewsMockRecorder) Enqueue(url, save interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Enqueue", reflect.TypeOf((*MockPreviews)(nil).Enqueue), url, save)
+}
+
+// MockShortDomains is a mock of ShortDomains interface.
+type MockShortDomains struct {
+	ctrl     *gomock.Controller
+	recorder *MockShortDomainsMockRecorder
+}
+
+// MockShortDomainsMockRecorder is the mock recorder for MockShortDomains.
+type MockShortDomainsMockRecorder struct {
+	mock *MockShortDomains
+}
+
+// NewMockShortDomains creates a new mock instance.
+func NewMockShortDomains(ctrl *gomock.Controller) *MockShortDomains {
+	mock := &MockShortDomains{ctrl: ctrl}
+	mock.recorder = &MockShortDomainsMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockShortDomains) EXPECT() *MockShortDomainsMockRecorder {
+	return m.recorder
+}
+
+// Lookup mocks base method.
+func (m *MockShortDomains) Lookup(ctx context.Context, host string) (domains.Domain, bool) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Lookup", ctx, host)
+	ret0, _ := ret[0].(domains.Domain)
+	ret1, _ := ret[1].(bool)
+	return ret0, ret1
+}
+
+// Lookup indicates an expected call of Lookup.
+func (mr *MockShortDomainsMockRecorder) Lookup(ctx, host interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Lookup", reflect.TypeOf((*MockShortDomains)(nil).Lookup), ctx, host)
+}
+
+// MockWebhooks is a mock of Webhooks interface.
+type MockWebhooks struct {
+	ctrl     *gomock.Controller
+	recorder *MockWebhooksMockRecorder
+}
+
+// MockWebhooksMockRecorder is the mock recorder for MockWebhooks.
+type MockWebhooksMockRecorder struct {
+	mock *MockWebhooks
+}
+
+// NewMockWebhooks creates a new mock instance.
+func NewMockWebhooks(ctrl *gomock.Controller) *MockWebhooks {
+	mock := &MockWebhooks{ctrl: ctrl}
+	mock.recorder = &MockWebhooksMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWebhooks) EXPECT() *MockWebhooksMockRecorder {
+	return m.recorder
+}
+
+// AddSubscription mocks base method.
+func (m *MockWebhooks) AddSubscription(ctx context.Context, subscription webhooks.Subscription) (webhooks.Subscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddSubscription", ctx, subscription)
+	ret0, _ := ret[0].(webhooks.Subscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddSubscription indicates an expected call of AddSubscription.
+func (mr *MockWebhooksMockRecorder) AddSubscription(ctx, subscription interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddSubscription", reflect.TypeOf((*MockWebhooks)(nil).AddSubscription), ctx, subscription)
+}
+
+// DeleteSubscription mocks base method.
+func (m *MockWebhooks) DeleteSubscription(ctx context.Context, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSubscription", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteSubscription indicates an expected call of DeleteSubscription.
+func (mr *MockWebhooksMockRecorder) DeleteSubscription(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSubscription", reflect.TypeOf((*MockWebhooks)(nil).DeleteSubscription), ctx, id)
+}
+
+// ListDeliveries mocks base method.
+func (m *MockWebhooks) ListDeliveries(ctx context.Context, subscriptionID string, limit int) ([]webhooks.Entry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDeliveries", ctx, subscriptionID, limit)
+	ret0, _ := ret[0].([]webhooks.Entry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDeliveries indicates an expected call of ListDeliveries.
+func (mr *MockWebhooksMockRecorder) ListDeliveries(ctx, subscriptionID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDeliveries", reflect.TypeOf((*MockWebhooks)(nil).ListDeliveries), ctx, subscriptionID, limit)
+}
+
+// ListSubscriptions mocks base method.
+func (m *MockWebhooks) ListSubscriptions(ctx context.Context) ([]webhooks.Subscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSubscriptions", ctx)
+	ret0, _ := ret[0].([]webhooks.Subscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSubscriptions indicates an expected call of ListSubscriptions.
+func (mr *MockWebhooksMockRecorder) ListSubscriptions(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSubscriptions", reflect.TypeOf((*MockWebhooks)(nil).ListSubscriptions), ctx)
+}
+
+// Publish mocks base method.
+func (m *MockWebhooks) Publish(ctx context.Context, events ...webhook.Event) error {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx}
+	for _, a := range events {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Publish", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Publish indicates an expected call of Publish.
+func (mr *MockWebhooksMockRecorder) Publish(ctx interface{}, events ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx}, events...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Publish", reflect.TypeOf((*MockWebhooks)(nil).Publish), varargs...)
+}
+
+// MockQuota is a mock of Quota interface.
+type MockQuota struct {
+	ctrl     *gomock.Controller
+	recorder *MockQuotaMockRecorder
+}
+
+// MockQuotaMockRecorder is the mock recorder for MockQuota.
+type MockQuotaMockRecorder struct {
+	mock *MockQuota
+}
+
+// NewMockQuota creates a new mock instance.
+func NewMockQuota(ctrl *gomock.Controller) *MockQuota {
+	mock := &MockQuota{ctrl: ctrl}
+	mock.recorder = &MockQuotaMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockQuota) EXPECT() *MockQuotaMockRecorder {
+	return m.recorder
+}
+
+// ConsumeTx mocks base method.
+func (m *MockQuota) ConsumeTx(tx *firestore.Transaction, n int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConsumeTx", tx, n)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ConsumeTx indicates an expected call of ConsumeTx.
+func (mr *MockQuotaMockRecorder) ConsumeTx(tx, n interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumeTx", reflect.TypeOf((*MockQuota)(nil).ConsumeTx), tx, n)
+}
@@ -0,0 +1,79 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: domains.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+	domains "url-shortener/pkg/repository/firestore/domains"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockDomainStore is a mock of DomainStore interface.
+type MockDomainStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockDomainStoreMockRecorder
+}
+
+// MockDomainStoreMockRecorder is the mock recorder for MockDomainStore.
+type MockDomainStoreMockRecorder struct {
+	mock *MockDomainStore
+}
+
+// NewMockDomainStore creates a new mock instance.
+func NewMockDomainStore(ctrl *gomock.Controller) *MockDomainStore {
+	mock := &MockDomainStore{ctrl: ctrl}
+	mock.recorder = &MockDomainStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDomainStore) EXPECT() *MockDomainStoreMockRecorder {
+	return m.recorder
+}
+
+// AddDomain mocks base method.
+func (m *MockDomainStore) AddDomain(ctx context.Context, domain domains.Domain) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddDomain", ctx, domain)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddDomain indicates an expected call of AddDomain.
+func (mr *MockDomainStoreMockRecorder) AddDomain(ctx, domain interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddDomain", reflect.TypeOf((*MockDomainStore)(nil).AddDomain), ctx, domain)
+}
+
+// DeleteDomain mocks base method.
+func (m *MockDomainStore) DeleteDomain(ctx context.Context, name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteDomain", ctx, name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteDomain indicates an expected call of DeleteDomain.
+func (mr *MockDomainStoreMockRecorder) DeleteDomain(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteDomain", reflect.TypeOf((*MockDomainStore)(nil).DeleteDomain), ctx, name)
+}
+
+// ListDomains mocks base method.
+func (m *MockDomainStore) ListDomains(ctx context.Context) ([]domains.Domain, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDomains", ctx)
+	ret0, _ := ret[0].([]domains.Domain)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDomains indicates an expected call of ListDomains.
+func (mr *MockDomainStoreMockRecorder) ListDomains(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDomains", reflect.TypeOf((*MockDomainStore)(nil).ListDomains), ctx)
+}
@@ -6,8 +6,18 @@ package mocks
 
 import (
 	context "context"
+	io "io"
+	net "net"
 	reflect "reflect"
+	time "time"
+	urlshortener "url-shortener/cmd/urlshortener/internal/urlshortener"
+	backup "url-shortener/pkg/backup"
+	domains "url-shortener/pkg/repository/firestore/domains"
+	tenants "url-shortener/pkg/repository/firestore/tenants"
 	urls "url-shortener/pkg/repository/firestore/urls"
+	webhooks "url-shortener/pkg/repository/firestore/webhooks"
+	search "url-shortener/pkg/search"
+	variants "url-shortener/pkg/variants"
 
 	gomock "github.com/golang/mock/gomock"
 )
@@ -35,19 +45,121 @@ func (m *MockController) EXPECT() *MockControllerMockRecorder {
 	return m.recorder
 }
 
+// AddSubscription mocks base method.
+func (m *MockController) AddSubscription(ctx context.Context, url string, eventTypes []string) (webhooks.Subscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddSubscription", ctx, url, eventTypes)
+	ret0, _ := ret[0].(webhooks.Subscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddSubscription indicates an expected call of AddSubscription.
+func (mr *MockControllerMockRecorder) AddSubscription(ctx, url, eventTypes interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddSubscription", reflect.TypeOf((*MockController)(nil).AddSubscription), ctx, url, eventTypes)
+}
+
+// ConsumeClick mocks base method.
+func (m *MockController) ConsumeClick(ctx context.Context, shortURL string) (urls.URL, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConsumeClick", ctx, shortURL)
+	ret0, _ := ret[0].(urls.URL)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConsumeClick indicates an expected call of ConsumeClick.
+func (mr *MockControllerMockRecorder) ConsumeClick(ctx, shortURL interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumeClick", reflect.TypeOf((*MockController)(nil).ConsumeClick), ctx, shortURL)
+}
+
+// CounterState mocks base method.
+func (m *MockController) CounterState(ctx context.Context) (urlshortener.CounterState, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CounterState", ctx)
+	ret0, _ := ret[0].(urlshortener.CounterState)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CounterState indicates an expected call of CounterState.
+func (mr *MockControllerMockRecorder) CounterState(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CounterState", reflect.TypeOf((*MockController)(nil).CounterState), ctx)
+}
+
 // CreateShortURL mocks base method.
-func (m *MockController) CreateShortURL(ctx context.Context, longURL string) (string, error) {
+func (m *MockController) CreateShortURL(ctx context.Context, domain, longURL string) (string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateShortURL", ctx, longURL)
+	ret := m.ctrl.Call(m, "CreateShortURL", ctx, domain, longURL)
 	ret0, _ := ret[0].(string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // CreateShortURL indicates an expected call of CreateShortURL.
-func (mr *MockControllerMockRecorder) CreateShortURL(ctx, longURL interface{}) *gomock.Call {
+func (mr *MockControllerMockRecorder) CreateShortURL(ctx, domain, longURL interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateShortURL", reflect.TypeOf((*MockController)(nil).CreateShortURL), ctx, longURL)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateShortURL", reflect.TypeOf((*MockController)(nil).CreateShortURL), ctx, domain, longURL)
+}
+
+// CreateShortURLs mocks base method.
+func (m *MockController) CreateShortURLs(ctx context.Context, domain string, longURLs []string) []urlshortener.BulkResult {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateShortURLs", ctx, domain, longURLs)
+	ret0, _ := ret[0].([]urlshortener.BulkResult)
+	return ret0
+}
+
+// CreateShortURLs indicates an expected call of CreateShortURLs.
+func (mr *MockControllerMockRecorder) CreateShortURLs(ctx, domain, longURLs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateShortURLs", reflect.TypeOf((*MockController)(nil).CreateShortURLs), ctx, domain, longURLs)
+}
+
+// CreateURL mocks base method.
+func (m *MockController) CreateURL(ctx context.Context, url urls.URL) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateURL", ctx, url)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateURL indicates an expected call of CreateURL.
+func (mr *MockControllerMockRecorder) CreateURL(ctx, url interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateURL", reflect.TypeOf((*MockController)(nil).CreateURL), ctx, url)
+}
+
+// DeleteSubscription mocks base method.
+func (m *MockController) DeleteSubscription(ctx context.Context, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSubscription", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteSubscription indicates an expected call of DeleteSubscription.
+func (mr *MockControllerMockRecorder) DeleteSubscription(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSubscription", reflect.TypeOf((*MockController)(nil).DeleteSubscription), ctx, id)
+}
+
+// DeleteURL mocks base method.
+func (m *MockController) DeleteURL(ctx context.Context, shortURL string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteURL", ctx, shortURL)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteURL indicates an expected call of DeleteURL.
+func (mr *MockControllerMockRecorder) DeleteURL(ctx, shortURL interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteURL", reflect.TypeOf((*MockController)(nil).DeleteURL), ctx, shortURL)
 }
 
 // GetByShortURL mocks base method.
@@ -64,3 +176,419 @@ func (mr *MockControllerMockRecorder) GetByShortURL(ctx, shortURL interface{}) *
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByShortURL", reflect.TypeOf((*MockController)(nil).GetByShortURL), ctx, shortURL)
 }
+
+// GetStats mocks base method.
+func (m *MockController) GetStats(ctx context.Context, shortURL string) (urls.URL, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStats", ctx, shortURL)
+	ret0, _ := ret[0].(urls.URL)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStats indicates an expected call of GetStats.
+func (mr *MockControllerMockRecorder) GetStats(ctx, shortURL interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStats", reflect.TypeOf((*MockController)(nil).GetStats), ctx, shortURL)
+}
+
+// ListBroken mocks base method.
+func (m *MockController) ListBroken(ctx context.Context) ([]urls.Record, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListBroken", ctx)
+	ret0, _ := ret[0].([]urls.Record)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListBroken indicates an expected call of ListBroken.
+func (mr *MockControllerMockRecorder) ListBroken(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListBroken", reflect.TypeOf((*MockController)(nil).ListBroken), ctx)
+}
+
+// ListChanges mocks base method.
+func (m *MockController) ListChanges(ctx context.Context, after urls.Cursor, limit int) ([]urls.Change, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListChanges", ctx, after, limit)
+	ret0, _ := ret[0].([]urls.Change)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListChanges indicates an expected call of ListChanges.
+func (mr *MockControllerMockRecorder) ListChanges(ctx, after, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListChanges", reflect.TypeOf((*MockController)(nil).ListChanges), ctx, after, limit)
+}
+
+// ListDeliveries mocks base method.
+func (m *MockController) ListDeliveries(ctx context.Context, subscriptionID string, limit int) ([]webhooks.Entry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDeliveries", ctx, subscriptionID, limit)
+	ret0, _ := ret[0].([]webhooks.Entry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDeliveries indicates an expected call of ListDeliveries.
+func (mr *MockControllerMockRecorder) ListDeliveries(ctx, subscriptionID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDeliveries", reflect.TypeOf((*MockController)(nil).ListDeliveries), ctx, subscriptionID, limit)
+}
+
+// ListScheduled mocks base method.
+func (m *MockController) ListScheduled(ctx context.Context, from, to time.Time, after urlshortener.ScheduleCursor, limit int) (urlshortener.Schedule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListScheduled", ctx, from, to, after, limit)
+	ret0, _ := ret[0].(urlshortener.Schedule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListScheduled indicates an expected call of ListScheduled.
+func (mr *MockControllerMockRecorder) ListScheduled(ctx, from, to, after, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListScheduled", reflect.TypeOf((*MockController)(nil).ListScheduled), ctx, from, to, after, limit)
+}
+
+// ListSubscriptions mocks base method.
+func (m *MockController) ListSubscriptions(ctx context.Context) ([]webhooks.Subscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSubscriptions", ctx)
+	ret0, _ := ret[0].([]webhooks.Subscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSubscriptions indicates an expected call of ListSubscriptions.
+func (mr *MockControllerMockRecorder) ListSubscriptions(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSubscriptions", reflect.TypeOf((*MockController)(nil).ListSubscriptions), ctx)
+}
+
+// RecordVariantClick mocks base method.
+func (m *MockController) RecordVariantClick(ctx context.Context, shortURL, variant string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordVariantClick", ctx, shortURL, variant)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordVariantClick indicates an expected call of RecordVariantClick.
+func (mr *MockControllerMockRecorder) RecordVariantClick(ctx, shortURL, variant interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordVariantClick", reflect.TypeOf((*MockController)(nil).RecordVariantClick), ctx, shortURL, variant)
+}
+
+// Search mocks base method.
+func (m *MockController) Search(ctx context.Context, query search.Query) ([]search.Document, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Search", ctx, query)
+	ret0, _ := ret[0].([]search.Document)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Search indicates an expected call of Search.
+func (mr *MockControllerMockRecorder) Search(ctx, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Search", reflect.TypeOf((*MockController)(nil).Search), ctx, query)
+}
+
+// UpdateMetadata mocks base method.
+func (m *MockController) UpdateMetadata(ctx context.Context, shortURL string, metadata urls.Metadata) (urls.URL, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateMetadata", ctx, shortURL, metadata)
+	ret0, _ := ret[0].(urls.URL)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateMetadata indicates an expected call of UpdateMetadata.
+func (mr *MockControllerMockRecorder) UpdateMetadata(ctx, shortURL, metadata interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateMetadata", reflect.TypeOf((*MockController)(nil).UpdateMetadata), ctx, shortURL, metadata)
+}
+
+// UpdateVariants mocks base method.
+func (m *MockController) UpdateVariants(ctx context.Context, shortURL string, urlVariants []variants.Variant) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateVariants", ctx, shortURL, urlVariants)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateVariants indicates an expected call of UpdateVariants.
+func (mr *MockControllerMockRecorder) UpdateVariants(ctx, shortURL, urlVariants interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateVariants", reflect.TypeOf((*MockController)(nil).UpdateVariants), ctx, shortURL, urlVariants)
+}
+
+// MockArchive is a mock of Archive interface.
+type MockArchive struct {
+	ctrl     *gomock.Controller
+	recorder *MockArchiveMockRecorder
+}
+
+// MockArchiveMockRecorder is the mock recorder for MockArchive.
+type MockArchiveMockRecorder struct {
+	mock *MockArchive
+}
+
+// NewMockArchive creates a new mock instance.
+func NewMockArchive(ctrl *gomock.Controller) *MockArchive {
+	mock := &MockArchive{ctrl: ctrl}
+	mock.recorder = &MockArchiveMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockArchive) EXPECT() *MockArchiveMockRecorder {
+	return m.recorder
+}
+
+// Export mocks base method.
+func (m *MockArchive) Export(ctx context.Context, writer io.Writer) (backup.Summary, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Export", ctx, writer)
+	ret0, _ := ret[0].(backup.Summary)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Export indicates an expected call of Export.
+func (mr *MockArchiveMockRecorder) Export(ctx, writer interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Export", reflect.TypeOf((*MockArchive)(nil).Export), ctx, writer)
+}
+
+// MockLocator is a mock of Locator interface.
+type MockLocator struct {
+	ctrl     *gomock.Controller
+	recorder *MockLocatorMockRecorder
+}
+
+// MockLocatorMockRecorder is the mock recorder for MockLocator.
+type MockLocatorMockRecorder struct {
+	mock *MockLocator
+}
+
+// NewMockLocator creates a new mock instance.
+func NewMockLocator(ctrl *gomock.Controller) *MockLocator {
+	mock := &MockLocator{ctrl: ctrl}
+	mock.recorder = &MockLocatorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLocator) EXPECT() *MockLocatorMockRecorder {
+	return m.recorder
+}
+
+// Country mocks base method.
+func (m *MockLocator) Country(ip net.IP) string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Country", ip)
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// Country indicates an expected call of Country.
+func (mr *MockLocatorMockRecorder) Country(ip interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Country", reflect.TypeOf((*MockLocator)(nil).Country), ip)
+}
+
+// MockDomains is a mock of Domains interface.
+type MockDomains struct {
+	ctrl     *gomock.Controller
+	recorder *MockDomainsMockRecorder
+}
+
+// MockDomainsMockRecorder is the mock recorder for MockDomains.
+type MockDomainsMockRecorder struct {
+	mock *MockDomains
+}
+
+// NewMockDomains creates a new mock instance.
+func NewMockDomains(ctrl *gomock.Controller) *MockDomains {
+	mock := &MockDomains{ctrl: ctrl}
+	mock.recorder = &MockDomainsMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDomains) EXPECT() *MockDomainsMockRecorder {
+	return m.recorder
+}
+
+// List mocks base method.
+func (m *MockDomains) List(ctx context.Context) ([]domains.Domain, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx)
+	ret0, _ := ret[0].([]domains.Domain)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockDomainsMockRecorder) List(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockDomains)(nil).List), ctx)
+}
+
+// Register mocks base method.
+func (m *MockDomains) Register(ctx context.Context, domain domains.Domain) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Register", ctx, domain)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Register indicates an expected call of Register.
+func (mr *MockDomainsMockRecorder) Register(ctx, domain interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Register", reflect.TypeOf((*MockDomains)(nil).Register), ctx, domain)
+}
+
+// Resolve mocks base method.
+func (m *MockDomains) Resolve(ctx context.Context, host string) (domains.Domain, bool) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Resolve", ctx, host)
+	ret0, _ := ret[0].(domains.Domain)
+	ret1, _ := ret[1].(bool)
+	return ret0, ret1
+}
+
+// Resolve indicates an expected call of Resolve.
+func (mr *MockDomainsMockRecorder) Resolve(ctx, host interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Resolve", reflect.TypeOf((*MockDomains)(nil).Resolve), ctx, host)
+}
+
+// Unregister mocks base method.
+func (m *MockDomains) Unregister(ctx context.Context, name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Unregister", ctx, name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Unregister indicates an expected call of Unregister.
+func (mr *MockDomainsMockRecorder) Unregister(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unregister", reflect.TypeOf((*MockDomains)(nil).Unregister), ctx, name)
+}
+
+// MockTenants is a mock of Tenants interface.
+type MockTenants struct {
+	ctrl     *gomock.Controller
+	recorder *MockTenantsMockRecorder
+}
+
+// MockTenantsMockRecorder is the mock recorder for MockTenants.
+type MockTenantsMockRecorder struct {
+	mock *MockTenants
+}
+
+// NewMockTenants creates a new mock instance.
+func NewMockTenants(ctrl *gomock.Controller) *MockTenants {
+	mock := &MockTenants{ctrl: ctrl}
+	mock.recorder = &MockTenantsMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTenants) EXPECT() *MockTenantsMockRecorder {
+	return m.recorder
+}
+
+// Authenticate mocks base method.
+func (m *MockTenants) Authenticate(ctx context.Context, key string) (tenants.Tenant, bool) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Authenticate", ctx, key)
+	ret0, _ := ret[0].(tenants.Tenant)
+	ret1, _ := ret[1].(bool)
+	return ret0, ret1
+}
+
+// Authenticate indicates an expected call of Authenticate.
+func (mr *MockTenantsMockRecorder) Authenticate(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authenticate", reflect.TypeOf((*MockTenants)(nil).Authenticate), ctx, key)
+}
+
+// Controller mocks base method.
+func (m *MockTenants) Controller(tenant tenants.Tenant) urlshortener.Controller {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Controller", tenant)
+	ret0, _ := ret[0].(urlshortener.Controller)
+	return ret0
+}
+
+// Controller indicates an expected call of Controller.
+func (mr *MockTenantsMockRecorder) Controller(tenant interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Controller", reflect.TypeOf((*MockTenants)(nil).Controller), tenant)
+}
+
+// Create mocks base method.
+func (m *MockTenants) Create(ctx context.Context, tenant tenants.Tenant) (tenants.Tenant, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, tenant)
+	ret0, _ := ret[0].(tenants.Tenant)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockTenantsMockRecorder) Create(ctx, tenant interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockTenants)(nil).Create), ctx, tenant)
+}
+
+// Get mocks base method.
+func (m *MockTenants) Get(ctx context.Context, id string) (tenants.Tenant, bool) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, id)
+	ret0, _ := ret[0].(tenants.Tenant)
+	ret1, _ := ret[1].(bool)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockTenantsMockRecorder) Get(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockTenants)(nil).Get), ctx, id)
+}
+
+// List mocks base method.
+func (m *MockTenants) List(ctx context.Context) ([]tenants.Tenant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx)
+	ret0, _ := ret[0].([]tenants.Tenant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockTenantsMockRecorder) List(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockTenants)(nil).List), ctx)
+}
+
+// Suspend mocks base method.
+func (m *MockTenants) Suspend(ctx context.Context, id string, suspended bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Suspend", ctx, id, suspended)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Suspend indicates an expected call of Suspend.
+func (mr *MockTenantsMockRecorder) Suspend(ctx, id, suspended interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Suspend", reflect.TypeOf((*MockTenants)(nil).Suspend), ctx, id, suspended)
+}
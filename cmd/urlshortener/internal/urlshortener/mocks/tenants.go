@@ -0,0 +1,131 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: tenants.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+	urlshortener "url-shortener/cmd/urlshortener/internal/urlshortener"
+	tenants "url-shortener/pkg/repository/firestore/tenants"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockTenantStore is a mock of TenantStore interface.
+type MockTenantStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockTenantStoreMockRecorder
+}
+
+// MockTenantStoreMockRecorder is the mock recorder for MockTenantStore.
+type MockTenantStoreMockRecorder struct {
+	mock *MockTenantStore
+}
+
+// NewMockTenantStore creates a new mock instance.
+func NewMockTenantStore(ctrl *gomock.Controller) *MockTenantStore {
+	mock := &MockTenantStore{ctrl: ctrl}
+	mock.recorder = &MockTenantStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTenantStore) EXPECT() *MockTenantStoreMockRecorder {
+	return m.recorder
+}
+
+// AddTenant mocks base method.
+func (m *MockTenantStore) AddTenant(ctx context.Context, tenant tenants.Tenant) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddTenant", ctx, tenant)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddTenant indicates an expected call of AddTenant.
+func (mr *MockTenantStoreMockRecorder) AddTenant(ctx, tenant interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddTenant", reflect.TypeOf((*MockTenantStore)(nil).AddTenant), ctx, tenant)
+}
+
+// ListTenants mocks base method.
+func (m *MockTenantStore) ListTenants(ctx context.Context) ([]tenants.Tenant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTenants", ctx)
+	ret0, _ := ret[0].([]tenants.Tenant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTenants indicates an expected call of ListTenants.
+func (mr *MockTenantStoreMockRecorder) ListTenants(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTenants", reflect.TypeOf((*MockTenantStore)(nil).ListTenants), ctx)
+}
+
+// SetSuspended mocks base method.
+func (m *MockTenantStore) SetSuspended(ctx context.Context, id string, suspended bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetSuspended", ctx, id, suspended)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetSuspended indicates an expected call of SetSuspended.
+func (mr *MockTenantStoreMockRecorder) SetSuspended(ctx, id, suspended interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSuspended", reflect.TypeOf((*MockTenantStore)(nil).SetSuspended), ctx, id, suspended)
+}
+
+// MockNamespaces is a mock of Namespaces interface.
+type MockNamespaces struct {
+	ctrl     *gomock.Controller
+	recorder *MockNamespacesMockRecorder
+}
+
+// MockNamespacesMockRecorder is the mock recorder for MockNamespaces.
+type MockNamespacesMockRecorder struct {
+	mock *MockNamespaces
+}
+
+// NewMockNamespaces creates a new mock instance.
+func NewMockNamespaces(ctrl *gomock.Controller) *MockNamespaces {
+	mock := &MockNamespaces{ctrl: ctrl}
+	mock.recorder = &MockNamespacesMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockNamespaces) EXPECT() *MockNamespacesMockRecorder {
+	return m.recorder
+}
+
+// Controller mocks base method.
+func (m *MockNamespaces) Controller(tenant tenants.Tenant) urlshortener.Controller {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Controller", tenant)
+	ret0, _ := ret[0].(urlshortener.Controller)
+	return ret0
+}
+
+// Controller indicates an expected call of Controller.
+func (mr *MockNamespacesMockRecorder) Controller(tenant interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Controller", reflect.TypeOf((*MockNamespaces)(nil).Controller), tenant)
+}
+
+// Init mocks base method.
+func (m *MockNamespaces) Init(ctx context.Context, tenant tenants.Tenant) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Init", ctx, tenant)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Init indicates an expected call of Init.
+func (mr *MockNamespacesMockRecorder) Init(ctx, tenant interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Init", reflect.TypeOf((*MockNamespaces)(nil).Init), ctx, tenant)
+}
@@ -0,0 +1,168 @@
+package urlshortener
+
+import (
+	"bytes"
+	_ "embed"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+	"url-shortener/pkg/openapi"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// maxValidatedResponse is the largest response body checked against the spec, larger ones are not checked
+const maxValidatedResponse = 1 << 20
+
+//go:embed openapi.json
+var specDocument []byte
+
+var apiSpec = mustParseSpec(specDocument)
+
+// swaggerUI is the pinned Swagger UI release the docs page loads, bump it deliberately
+const swaggerUI = "https://unpkg.com/swagger-ui-dist@5.17.14"
+
+var docsPage = []byte(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>URL Shortener API</title>
+<link rel="stylesheet" href="` + swaggerUI + `/swagger-ui.css" crossorigin="anonymous">
+</head>
+<body>
+<div id="swagger-ui"></div>
+<script src="` + swaggerUI + `/swagger-ui-bundle.js" crossorigin="anonymous"></script>
+<script>
+SwaggerUIBundle({url: "/openapi.json", dom_id: "#swagger-ui"});
+</script>
+</body>
+</html>
+`)
+
+func mustParseSpec(document []byte) *openapi.Spec {
+	spec, err := openapi.Parse(document)
+	if err != nil {
+		panic(fmt.Sprintf("invalid embedded openapi spec: %v", err))
+	}
+
+	return spec
+}
+
+// Spec returns the OpenAPI spec of the HTTP API
+func Spec() *openapi.Spec {
+	return apiSpec
+}
+
+// SpecPath converts a gin route to the path template of the spec, e.g. /:short_url/*suffix to /{short_url}/{suffix}
+func SpecPath(route string) string {
+	segments := strings.Split(route, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+
+	return strings.Join(segments, "/")
+}
+
+// ServeSpec returns the OpenAPI spec of the HTTP API
+func (p *Presenter) ServeSpec(ctx *gin.Context) {
+	ctx.Data(http.StatusOK, "application/json; charset=utf-8", specDocument)
+}
+
+// ServeDocs returns a page browsing the OpenAPI spec
+func (p *Presenter) ServeDocs(ctx *gin.Context) {
+	ctx.Data(http.StatusOK, "text/html; charset=utf-8", docsPage)
+}
+
+// ValidateSpec rejects requests which do not match the OpenAPI spec and logs JSON responses which do not match it,
+// as enabled by the config. Routes missing from the spec are not checked.
+func (p *Presenter) ValidateSpec(ctx *gin.Context) {
+	if !p.config.ValidateRequests && !p.config.ValidateResponses {
+		ctx.Next()
+		return
+	}
+
+	path := SpecPath(ctx.FullPath())
+	operation, ok := apiSpec.Operation(ctx.Request.Method, path)
+	if !ok {
+		ctx.Next()
+		return
+	}
+
+	if p.config.ValidateRequests {
+		params := make(map[string]string, len(ctx.Params))
+		for _, param := range ctx.Params {
+			params[param.Key] = param.Value
+		}
+
+		if err := apiSpec.ValidateRequest(operation, ctx.Request, params); err != nil {
+			var validationErr openapi.ValidationError
+			if errors.As(err, &validationErr) {
+				ctx.AbortWithStatusJSON(http.StatusBadRequest, fmt.Sprintf("Invalid request: %v", validationErr))
+				return
+			}
+
+			logrus.Errorf("Failed to validate request: %v", err)
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, "Error occured while validating request")
+			return
+		}
+	}
+
+	if !p.config.ValidateResponses {
+		ctx.Next()
+		return
+	}
+
+	writer := &capturingWriter{ResponseWriter: ctx.Writer}
+	ctx.Writer = writer
+	ctx.Next()
+
+	if !writer.capturing() || writer.truncated {
+		return
+	}
+
+	if err := apiSpec.ValidateResponse(operation, writer.Status(), writer.Header().Get("Content-Type"), writer.body.Bytes()); err != nil {
+		logrus.Warnf("Response of %s %s does not match the openapi spec: %v", ctx.Request.Method, path, err)
+	}
+}
+
+// capturingWriter keeps a copy of JSON response bodies, other responses like streams are written as they are
+type capturingWriter struct {
+	gin.ResponseWriter
+	body      bytes.Buffer
+	truncated bool
+}
+
+func (w *capturingWriter) Write(data []byte) (int, error) {
+	w.capture(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *capturingWriter) WriteString(data string) (int, error) {
+	w.capture([]byte(data))
+	return w.ResponseWriter.WriteString(data)
+}
+
+func (w *capturingWriter) capture(data []byte) {
+	if !w.capturing() || w.truncated {
+		return
+	}
+
+	if w.body.Len()+len(data) > maxValidatedResponse {
+		w.truncated = true
+		w.body.Reset()
+		return
+	}
+
+	w.body.Write(data)
+}
+
+func (w *capturingWriter) capturing() bool {
+	mediaType, _, err := mime.ParseMediaType(w.Header().Get("Content-Type"))
+	return err == nil && mediaType == "application/json"
+}
@@ -0,0 +1,154 @@
+package urlshortener_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+	"url-shortener/cmd/urlshortener/internal/urlshortener"
+	"url-shortener/cmd/urlshortener/internal/urlshortener/mocks"
+	"url-shortener/pkg/openapi"
+	"url-shortener/pkg/repository/firestore/urls"
+	"url-shortener/pkg/search"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("OpenAPI", func() {
+	var (
+		mockCtrl       *gomock.Controller
+		mockController *mocks.MockController
+		config         urlshortener.Config
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockController = mocks.NewMockController(mockCtrl)
+		config = urlshortener.Config{ValidateRequests: true, ValidateResponses: true}
+	})
+
+	serve := func(method, path, contentType, body string) *httptest.ResponseRecorder {
+		handler := gin.New()
+		urlshortener.NewPresenter(mockController, config).RegisterRoutes(handler)
+		request := httptest.NewRequest(method, path, strings.NewReader(body))
+		if contentType != "" {
+			request.Header.Set("Content-Type", contentType)
+		}
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	It("should document every registered route and no other", func() {
+		handler := gin.New()
+		urlshortener.NewPresenter(mockController, config).RegisterRoutes(handler)
+
+		var registered []openapi.Route
+		for _, route := range handler.Routes() {
+			registered = append(registered, openapi.Route{Method: route.Method, Path: urlshortener.SpecPath(route.Path)})
+		}
+		Expect(registered).To(ConsistOf(urlshortener.Spec().Routes()))
+	})
+
+	It("should convert gin routes to path templates", func() {
+		Expect(urlshortener.SpecPath("/:short_url/*suffix")).To(Equal("/{short_url}/{suffix}"))
+		Expect(urlshortener.SpecPath("/api/v1/urls")).To(Equal("/api/v1/urls"))
+	})
+
+	It("should serve the spec and the docs page", func() {
+		recorder := serve(http.MethodGet, "/openapi.json", "", "")
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(recorder.Body.String()).To(ContainSubstring(`"openapi": "3.0.3"`))
+
+		recorder = serve(http.MethodGet, "/api/v1/docs", "", "")
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(recorder.Header().Get("Content-Type")).To(HavePrefix("text/html"))
+		Expect(recorder.Body.String()).To(ContainSubstring("/openapi.json"))
+	})
+
+	When("validating requests", func() {
+		It("should reject bodies which do not match the spec", func() {
+			recorder := serve(http.MethodPost, "/api/v1/urls", "application/json", `{"redirect_type":302}`)
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+			Expect(recorder.Body.String()).To(MatchJSON(`"Invalid request: body.long_url is required"`))
+
+			recorder = serve(http.MethodPost, "/api/v1/urls", "application/json", `{"long_url":"https://example.com","redirect_type":303}`)
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+			Expect(recorder.Body.String()).To(ContainSubstring("body.redirect_type must be one of"))
+		})
+
+		It("should reject parameters which do not match the spec", func() {
+			recorder := serve(http.MethodGet, "/api/v1/urls/search?q=spring&limit=500", "", "")
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+			Expect(recorder.Body.String()).To(MatchJSON(`"Invalid request: query.limit must be at most 200"`))
+
+			recorder = serve(http.MethodGet, "/api/v1/urls/search", "", "")
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+			Expect(recorder.Body.String()).To(MatchJSON(`"Invalid request: query.q is required"`))
+		})
+
+		It("should pass valid requests to the handlers with their body", func() {
+			mockController.EXPECT().CreateShortURL(gomock.Any(), "", "https://example.com").Return("abc", nil)
+
+			recorder := serve(http.MethodPost, "/", "text/plain", "https://example.com")
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Body.String()).To(MatchJSON(`"abc"`))
+		})
+
+		It("should not check requests if disabled", func() {
+			config.ValidateRequests = false
+
+			recorder := serve(http.MethodGet, "/api/v1/urls/search?q=spring&limit=500", "", "")
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+			Expect(recorder.Body.String()).To(MatchJSON(`"Limit must be between 1 and 200"`))
+		})
+	})
+
+	When("responding", func() {
+		validate := func(method, route string, recorder *httptest.ResponseRecorder) error {
+			operation, ok := urlshortener.Spec().Operation(method, route)
+			Expect(ok).To(BeTrue())
+			return urlshortener.Spec().ValidateResponse(operation, recorder.Code, recorder.Header().Get("Content-Type"), recorder.Body.Bytes())
+		}
+
+		It("should match the documented urls", func() {
+			notAfter := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+			url := urls.URL{
+				LongURL:      "https://example.com",
+				RedirectType: http.StatusMovedPermanently,
+				MaxClicks:    10,
+				Clicks:       3,
+				NotAfter:     &notAfter,
+				PasswordHash: "hash",
+				Metadata:     urls.Metadata{Title: "Spring", Tags: []string{"launch"}},
+			}
+			mockController.EXPECT().GetByShortURL(gomock.Any(), "abc").Return(url, nil)
+			mockController.EXPECT().GetStats(gomock.Any(), "abc").Return(url, nil)
+
+			recorder := serve(http.MethodGet, "/api/v1/urls/abc", "", "")
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(validate(http.MethodGet, "/api/v1/urls/{short_url}", recorder)).To(Succeed())
+
+			recorder = serve(http.MethodGet, "/api/v1/urls/abc/stats", "", "")
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(validate(http.MethodGet, "/api/v1/urls/{short_url}/stats", recorder)).To(Succeed())
+		})
+
+		It("should match the documented search results and errors", func() {
+			mockController.EXPECT().Search(gomock.Any(), gomock.Any()).
+				Return([]search.Document{{ID: "abc", LongURL: "https://example.com", Folder: "campaigns"}}, nil)
+
+			recorder := serve(http.MethodGet, "/api/v1/urls?folder=campaigns", "", "")
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(validate(http.MethodGet, "/api/v1/urls", recorder)).To(Succeed())
+
+			mockController.EXPECT().GetByShortURL(gomock.Any(), "abc").Return(urls.URL{}, urls.NewNotFoundError())
+			recorder = serve(http.MethodGet, "/api/v1/urls/abc", "", "")
+			Expect(recorder.Code).To(Equal(http.StatusNotFound))
+			Expect(validate(http.MethodGet, "/api/v1/urls/{short_url}", recorder)).To(Succeed())
+		})
+	})
+})
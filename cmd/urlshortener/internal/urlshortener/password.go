@@ -0,0 +1,95 @@
+package urlshortener
+
+import (
+	"errors"
+	"html/template"
+	"net/http"
+	"time"
+	"url-shortener/pkg/password"
+	"url-shortener/pkg/repository/firestore/urls"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+const accessCookie = "short_url_access"
+
+var passwordForm = template.Must(template.New("password").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Password required</title></head>
+<body>
+<form method="post">
+<p>This link is protected, enter its password to continue.</p>
+{{if .}}<p><strong>{{.}}</strong></p>{{end}}
+<input type="password" name="password" autofocus required>
+<button type="submit">Continue</button>
+</form>
+</body>
+</html>
+`))
+
+// UnlockURL checks the password submitted from the password form and redirects to the long URL if it matches
+// A signed cookie is set, so the password is not asked again until it expires
+func (p *Presenter) UnlockURL(ctx *gin.Context) {
+	shortURL := p.urlKey(ctx)
+	now := time.Now()
+	if p.lockout.Locked(shortURL, now) {
+		p.passwordForm(ctx, http.StatusTooManyRequests, "Too many failed attempts, try again later")
+		return
+	}
+
+	url, err := p.controllerOf(ctx).GetByShortURL(ctx, shortURL)
+	if err != nil {
+		var notFoundErr urls.NotFoundError
+		if errors.As(err, &notFoundErr) {
+			p.notFound(ctx)
+			return
+		}
+
+		logrus.Errorf("Failed to get by short url: %v", err)
+		ctx.JSON(http.StatusInternalServerError, "Error occured while getting short URL")
+		return
+	}
+
+	if suffix(ctx) != "" && !url.Prefix {
+		p.notFound(ctx)
+		return
+	}
+
+	if !p.available(ctx, url) {
+		return
+	}
+
+	if url.PasswordHash == "" {
+		p.redirect(ctx, shortURL, url)
+		return
+	}
+
+	if !password.Verify(url.PasswordHash, ctx.PostForm("password")) {
+		p.lockout.Fail(shortURL, now)
+		p.passwordForm(ctx, http.StatusUnauthorized, "Wrong password")
+		return
+	}
+
+	p.lockout.Reset(shortURL)
+	secure := ctx.Request.TLS != nil || ctx.GetHeader("X-Forwarded-Proto") == "https"
+	ctx.SetSameSite(http.SameSiteLaxMode)
+	ctx.SetCookie(accessCookie, p.signer.Token(shortURL, now.Add(p.config.PasswordCookieTTL)),
+		int(p.config.PasswordCookieTTL.Seconds()), "/"+ctx.Param("short_url"), "", secure, true)
+	p.redirect(ctx, shortURL, url)
+}
+
+// unlocked reports whether the request carries a valid cookie for the short URL
+func (p *Presenter) unlocked(ctx *gin.Context, shortURL string) bool {
+	token, err := ctx.Cookie(accessCookie)
+	return err == nil && p.signer.Valid(shortURL, token, time.Now())
+}
+
+func (p *Presenter) passwordForm(ctx *gin.Context, status int, message string) {
+	ctx.Header("Cache-Control", "no-store")
+	ctx.Status(status)
+	ctx.Header("Content-Type", "text/html; charset=utf-8")
+	if err := passwordForm.Execute(ctx.Writer, message); err != nil {
+		logrus.Errorf("Failed to render password form: %v", err)
+	}
+}
@@ -0,0 +1,154 @@
+package urlshortener_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"time"
+	"url-shortener/cmd/urlshortener/internal/urlshortener"
+	"url-shortener/cmd/urlshortener/internal/urlshortener/mocks"
+	"url-shortener/pkg/password"
+	"url-shortener/pkg/repository/firestore/urls"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Password protected URLs", func() {
+	const (
+		shortURL = "short-url"
+		longURL  = "https://long.com"
+		secret   = "secret"
+	)
+
+	var (
+		mockCtrl       *gomock.Controller
+		mockController *mocks.MockController
+		presenter      *urlshortener.Presenter
+		protected      urls.URL
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockController = mocks.NewMockController(mockCtrl)
+		presenter = urlshortener.NewPresenter(mockController, urlshortener.Config{
+			RedirectType:        http.StatusFound,
+			CookieSecret:        []byte("cookie-secret"),
+			PasswordCookieTTL:   time.Hour,
+			PasswordMaxAttempts: 2,
+			PasswordLockout:     time.Minute,
+		})
+
+		hash, err := password.Hash(secret)
+		Expect(err).ToNot(HaveOccurred())
+		protected = urls.URL{LongURL: longURL, PasswordHash: hash}
+	})
+
+	visit := func(cookies ...*http.Cookie) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(recorder)
+		ctx.Request = httptest.NewRequest(http.MethodGet, "/"+shortURL, nil)
+		for _, cookie := range cookies {
+			ctx.Request.AddCookie(cookie)
+		}
+
+		ctx.Params = []gin.Param{{Key: "short_url", Value: shortURL}}
+		presenter.RedirectToLongURL(ctx)
+		ctx.Writer.WriteHeaderNow()
+		return recorder
+	}
+
+	unlock := func(pass string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(recorder)
+		form := url.Values{"password": {pass}}
+		ctx.Request = httptest.NewRequest(http.MethodPost, "/"+shortURL, strings.NewReader(form.Encode()))
+		ctx.Request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		ctx.Params = []gin.Param{{Key: "short_url", Value: shortURL}}
+		presenter.UnlockURL(ctx)
+		// gin writes the header after the handler returns, which is needed for a redirect without body
+		ctx.Writer.WriteHeaderNow()
+		return recorder
+	}
+
+	When("visiting a protected url without access cookie", func() {
+		BeforeEach(func() {
+			mockController.EXPECT().GetByShortURL(gomock.Any(), shortURL).Return(protected, nil)
+		})
+
+		It("should serve the password form", func() {
+			recorder := visit()
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Body.String()).To(ContainSubstring(`type="password"`))
+			Expect(recorder.Header().Get("Cache-Control")).To(Equal("no-store"))
+		})
+	})
+
+	When("submitting a wrong password", func() {
+		BeforeEach(func() {
+			mockController.EXPECT().GetByShortURL(gomock.Any(), shortURL).Return(protected, nil).Times(2)
+		})
+
+		It("should return http status unauthorized and lock after too many attempts", func() {
+			Expect(unlock("wrong").Code).To(Equal(http.StatusUnauthorized))
+			Expect(unlock("wrong").Code).To(Equal(http.StatusUnauthorized))
+			Expect(unlock(secret).Code).To(Equal(http.StatusTooManyRequests))
+		})
+	})
+
+	When("submitting the right password", func() {
+		BeforeEach(func() {
+			mockController.EXPECT().GetByShortURL(gomock.Any(), shortURL).Return(protected, nil).Times(2)
+		})
+
+		It("should redirect and set an access cookie accepted by later visits", func() {
+			recorder := unlock(secret)
+			Expect(recorder.Code).To(Equal(http.StatusFound))
+			Expect(recorder.Header().Get("Location")).To(Equal(longURL))
+
+			cookies := recorder.Result().Cookies()
+			Expect(cookies).To(HaveLen(1))
+			Expect(cookies[0].HttpOnly).To(BeTrue())
+			Expect(cookies[0].Path).To(Equal("/" + shortURL))
+
+			Expect(visit(cookies[0]).Code).To(Equal(http.StatusFound))
+		})
+	})
+
+	When("submitting the right password of a permanently redirected url", func() {
+		BeforeEach(func() {
+			protected.RedirectType = http.StatusPermanentRedirect
+			mockController.EXPECT().GetByShortURL(gomock.Any(), shortURL).Return(protected, nil)
+		})
+
+		It("should redirect temporarily without caching", func() {
+			recorder := unlock(secret)
+			Expect(recorder.Code).To(Equal(http.StatusTemporaryRedirect))
+			Expect(recorder.Header().Get("Cache-Control")).To(Equal("private, no-cache, no-store, must-revalidate"))
+		})
+	})
+
+	When("creating a protected url", func() {
+		BeforeEach(func() {
+			mockController.EXPECT().CreateURL(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, url urls.URL) (string, error) {
+				Expect(url.LongURL).To(Equal(longURL))
+				Expect(password.Verify(url.PasswordHash, secret)).To(BeTrue())
+				return shortURL, nil
+			})
+		})
+
+		It("should store the password hash", func() {
+			recorder := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(recorder)
+			body := `{"long_url": "https://long.com", "password": "secret"}`
+			ctx.Request = httptest.NewRequest(http.MethodPost, "/api/v1/urls", bytes.NewBufferString(body))
+			presenter.CreateURL(ctx)
+			Expect(recorder.Code).To(Equal(http.StatusCreated))
+		})
+	})
+})
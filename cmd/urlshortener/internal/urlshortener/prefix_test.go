@@ -0,0 +1,93 @@
+package urlshortener_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"url-shortener/cmd/urlshortener/internal/urlshortener"
+	"url-shortener/cmd/urlshortener/internal/urlshortener/mocks"
+	"url-shortener/pkg/repository/firestore/urls"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Prefix URLs", func() {
+	const shortURL = "docs"
+
+	var (
+		mockCtrl       *gomock.Controller
+		mockController *mocks.MockController
+		presenter      *urlshortener.Presenter
+		url            urls.URL
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockController = mocks.NewMockController(mockCtrl)
+		presenter = urlshortener.NewPresenter(mockController, urlshortener.Config{RedirectType: http.StatusFound, QRCacheSize: 1})
+		url = urls.URL{LongURL: "https://example.com/docs?v=1", Prefix: true}
+	})
+
+	visit := func(suffix string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(recorder)
+		ctx.Request = httptest.NewRequest(http.MethodGet, "/"+shortURL, nil)
+		ctx.Params = []gin.Param{{Key: "short_url", Value: shortURL}, {Key: "suffix", Value: suffix}}
+		presenter.RedirectSubpath(ctx)
+		return recorder
+	}
+
+	When("visiting a path below a prefix url", func() {
+		BeforeEach(func() {
+			mockController.EXPECT().GetByShortURL(gomock.Any(), shortURL).Return(url, nil)
+		})
+
+		It("should forward the path to the destination", func() {
+			Expect(visit("/guides/setup").Header().Get("Location")).To(Equal("https://example.com/docs/guides/setup?v=1"))
+		})
+	})
+
+	When("visiting a path climbing above a prefix url", func() {
+		BeforeEach(func() {
+			mockController.EXPECT().GetByShortURL(gomock.Any(), shortURL).Return(url, nil)
+		})
+
+		It("should keep the path below the destination", func() {
+			Expect(visit("/../../admin").Header().Get("Location")).To(Equal("https://example.com/docs/admin?v=1"))
+		})
+	})
+
+	When("visiting an invalid path below a prefix url", func() {
+		BeforeEach(func() {
+			mockController.EXPECT().GetByShortURL(gomock.Any(), shortURL).Return(url, nil)
+		})
+
+		It("should return http status bad request", func() {
+			Expect(visit("/\\evil.com").Code).To(Equal(http.StatusBadRequest))
+		})
+	})
+
+	When("visiting a path below an url which is not a prefix url", func() {
+		BeforeEach(func() {
+			mockController.EXPECT().GetByShortURL(gomock.Any(), shortURL).Return(urls.URL{LongURL: "https://example.com"}, nil)
+		})
+
+		It("should return http status not found", func() {
+			Expect(visit("/guides").Code).To(Equal(http.StatusNotFound))
+		})
+	})
+
+	When("visiting the qr path of a prefix url", func() {
+		BeforeEach(func() {
+			mockController.EXPECT().GetByShortURL(gomock.Any(), shortURL).Return(url, nil)
+		})
+
+		It("should return its QR code", func() {
+			recorder := visit("/qr")
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Header().Get("Content-Type")).To(Equal("image/png"))
+		})
+	})
+})
@@ -3,8 +3,23 @@ package urlshortener
 import (
 	"context"
 	"errors"
+	"fmt"
+	"io"
+	"net"
 	"net/http"
+	netURL "net/url"
+	"time"
+	"url-shortener/pkg/backup"
+	"url-shortener/pkg/password"
+	"url-shortener/pkg/qrcode"
+	"url-shortener/pkg/redirect"
+	"url-shortener/pkg/repository/firestore/domains"
+	"url-shortener/pkg/repository/firestore/tenants"
 	"url-shortener/pkg/repository/firestore/urls"
+	"url-shortener/pkg/repository/firestore/webhooks"
+	"url-shortener/pkg/rules"
+	"url-shortener/pkg/search"
+	"url-shortener/pkg/variants"
 
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
@@ -13,22 +28,164 @@ import (
 //go:generate mockgen --source=presenter.go --destination mocks/presenter.go --package mocks
 
 type Controller interface {
-	CreateShortURL(ctx context.Context, longURL string) (string, error)
+	CreateShortURL(ctx context.Context, domain, longURL string) (string, error)
+	CreateURL(ctx context.Context, url urls.URL) (string, error)
+	CreateShortURLs(ctx context.Context, domain string, longURLs []string) []BulkResult
 	GetByShortURL(ctx context.Context, shortURL string) (urls.URL, error)
+	GetStats(ctx context.Context, shortURL string) (urls.URL, error)
+	ConsumeClick(ctx context.Context, shortURL string) (urls.URL, error)
+	ListScheduled(ctx context.Context, from, to time.Time, after ScheduleCursor, limit int) (Schedule, error)
+	ListBroken(ctx context.Context) ([]urls.Record, error)
+	UpdateVariants(ctx context.Context, shortURL string, urlVariants []variants.Variant) error
+	RecordVariantClick(ctx context.Context, shortURL, variant string) error
+	UpdateMetadata(ctx context.Context, shortURL string, metadata urls.Metadata) (urls.URL, error)
+	Search(ctx context.Context, query search.Query) ([]search.Document, error)
+	DeleteURL(ctx context.Context, shortURL string) error
+	AddSubscription(ctx context.Context, url string, eventTypes []string) (webhooks.Subscription, error)
+	ListSubscriptions(ctx context.Context) ([]webhooks.Subscription, error)
+	DeleteSubscription(ctx context.Context, id string) error
+	ListDeliveries(ctx context.Context, subscriptionID string, limit int) ([]webhooks.Entry, error)
+	ListChanges(ctx context.Context, after urls.Cursor, limit int) ([]urls.Change, error)
+	CounterState(ctx context.Context) (CounterState, error)
+}
+
+// Archive exports the URLs of the default namespace, see backup.Backup
+type Archive interface {
+	Export(ctx context.Context, writer io.Writer) (backup.Summary, error)
+}
+
+// Locator returns the country code of an IP, or empty string if it is unknown
+type Locator interface {
+	Country(ip net.IP) string
+}
+
+// Domains resolves and manages branded domains
+type Domains interface {
+	Resolve(ctx context.Context, host string) (domains.Domain, bool)
+	Register(ctx context.Context, domain domains.Domain) error
+	Unregister(ctx context.Context, name string) error
+	List(ctx context.Context) ([]domains.Domain, error)
+}
+
+// Tenants authenticates and manages tenants and provides their namespaces
+type Tenants interface {
+	Get(ctx context.Context, id string) (tenants.Tenant, bool)
+	Authenticate(ctx context.Context, key string) (tenants.Tenant, bool)
+	Create(ctx context.Context, tenant tenants.Tenant) (tenants.Tenant, string, error)
+	Suspend(ctx context.Context, id string, suspended bool) error
+	List(ctx context.Context) ([]tenants.Tenant, error)
+	Controller(tenant tenants.Tenant) Controller
+}
+
+// Config holds the presenter settings
+type Config struct {
+	// RedirectType is used for links which do not override it
+	RedirectType int
+	// BulkLimit is the maximum number of URLs accepted by a single bulk request
+	BulkLimit int
+	// PublicURL is the base of full short URLs, the request host is used if empty
+	PublicURL string
+	// QRCacheSize is the number of rendered QR codes kept in memory
+	QRCacheSize int
+	// CookieSecret signs the cookies proving that the password of a protected link was entered
+	CookieSecret []byte
+	// PasswordCookieTTL is how long a protected link can be visited again without entering its password
+	PasswordCookieTTL time.Duration
+	// PasswordMaxAttempts failed attempts lock a protected link for PasswordLockout
+	PasswordMaxAttempts int
+	PasswordLockout     time.Duration
+	// GeoIP resolves visitor countries for routing rules, country conditions never match if nil
+	GeoIP Locator
+	// Domains resolves request hosts to branded domains, all requests use the default domain if nil
+	Domains Domains
+	// Tenants resolves the tenants of requests, all requests use the default namespace if nil
+	Tenants Tenants
+	// ChangesPollInterval is how often streams of changes check for new ones
+	ChangesPollInterval time.Duration
+	// Archive exports the default namespace for the admin API, export is disabled if nil
+	Archive Archive
+	// AdminAPIKey is required in the X-API-Key header of admin requests, the admin API is disabled if empty
+	AdminAPIKey string
+	// ValidateRequests rejects requests which do not match the OpenAPI spec, ValidateResponses logs responses which do not
+	ValidateRequests  bool
+	ValidateResponses bool
+}
+
+// utmParams are the query parameters accepted as UTM defaults
+var utmParams = map[string]bool{
+	"utm_source":   true,
+	"utm_medium":   true,
+	"utm_campaign": true,
+	"utm_term":     true,
+	"utm_content":  true,
+	"utm_id":       true,
 }
 
 type Presenter struct {
 	controller Controller
+	config     Config
+	qrCache    *qrcode.Cache
+	signer     *password.Signer
+	lockout    *password.Lockout
+	// streams is done once the streams of changes are stopped
+	streams     context.Context
+	stopStreams context.CancelFunc
+}
+
+type createURLRequest struct {
+	LongURL      string `json:"long_url" binding:"required"`
+	RedirectType int    `json:"redirect_type"`
+	Password     string `json:"password"`
+	MaxClicks    int64  `json:"max_clicks"`
+	// NotBefore and NotAfter limit when the URL redirects, FallbackURL is used before NotBefore
+	NotBefore   *time.Time `json:"not_before"`
+	NotAfter    *time.Time `json:"not_after"`
+	FallbackURL string     `json:"fallback_url"`
+	// Rules route visitors to other destinations, LongURL is used if none matches
+	Rules []rules.Rule `json:"rules"`
+	// Variants split visitors between weighted destinations instead of LongURL
+	Variants []variants.Variant `json:"variants"`
+	// QueryPassthrough adds the query parameters of the visit to the destination
+	QueryPassthrough bool `json:"query_passthrough"`
+	// UTM holds default utm_* parameters added to the destination
+	UTM map[string]string `json:"utm"`
+	// Prefix forwards the path after the short URL to the destination
+	Prefix bool `json:"prefix"`
+	// Domain is the branded domain of the URL, the domain of the request is used if empty
+	Domain string `json:"domain"`
+	metadataRequest
+}
+
+type bulkCreateRequest struct {
+	LongURLs []string `json:"long_urls" binding:"required"`
+}
+
+type bulkCreateResult struct {
+	LongURL  string `json:"long_url"`
+	ShortURL string `json:"short_url,omitempty"`
+	Reused   bool   `json:"reused"`
+	Error    string `json:"error,omitempty"`
+}
+
+type bulkCreateResponse struct {
+	Results []bulkCreateResult `json:"results"`
 }
 
 // NewPresenter is a constructor function
-func NewPresenter(controller Controller) *Presenter {
+func NewPresenter(controller Controller, config Config) *Presenter {
+	streams, stopStreams := context.WithCancel(context.Background())
 	return &Presenter{
-		controller: controller,
+		controller:  controller,
+		config:      config,
+		qrCache:     qrcode.NewCache(config.QRCacheSize),
+		signer:      password.NewSigner(config.CookieSecret),
+		lockout:     password.NewLockout(config.PasswordMaxAttempts, config.PasswordLockout),
+		streams:     streams,
+		stopStreams: stopStreams,
 	}
 }
 
-// CreateShortURL creates a short URL object and returns its ID
+// CreateShortURL creates a short URL object on the domain of the request and returns its ID
 func (p *Presenter) CreateShortURL(ctx *gin.Context) {
 	urlAddress, err := ctx.GetRawData()
 	if err != nil {
@@ -37,8 +194,16 @@ func (p *Presenter) CreateShortURL(ctx *gin.Context) {
 		return
 	}
 
-	shortID, err := p.controller.CreateShortURL(ctx, string(urlAddress))
+	if p.foreignDomain(ctx, p.domain(ctx)) {
+		return
+	}
+
+	shortID, err := p.controllerOf(ctx).CreateShortURL(ctx, p.domain(ctx).Name, string(urlAddress))
 	if err != nil {
+		if quotaExceeded(ctx, err) || invalidDestination(ctx, err) {
+			return
+		}
+
 		logrus.Errorf("Failed to create short url: %v", err)
 		ctx.JSON(http.StatusInternalServerError, "Error occured while creating short URL")
 		return
@@ -47,14 +212,172 @@ func (p *Presenter) CreateShortURL(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, shortID)
 }
 
-// RedirectToLongURL accepts a short URL as path param and redirects to the long URL if it exists
+// CreateURL creates a short URL object with per link settings and returns it with its ID
+func (p *Presenter) CreateURL(ctx *gin.Context) {
+	var request createURLRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if request.RedirectType != 0 && !redirect.IsValid(request.RedirectType) {
+		ctx.JSON(http.StatusBadRequest, "Unsupported redirect type")
+		return
+	}
+
+	if request.MaxClicks < 0 {
+		ctx.JSON(http.StatusBadRequest, "Max clicks must not be negative")
+		return
+	}
+
+	if request.NotBefore != nil && request.NotAfter != nil && !request.NotAfter.After(*request.NotBefore) {
+		ctx.JSON(http.StatusBadRequest, "Not after must be later than not before")
+		return
+	}
+
+	if request.FallbackURL != "" && request.NotBefore == nil {
+		ctx.JSON(http.StatusBadRequest, "Fallback URL requires not before")
+		return
+	}
+
+	for i, rule := range request.Rules {
+		if err := rule.Validate(); err != nil {
+			ctx.JSON(http.StatusBadRequest, fmt.Sprintf("Invalid rule %d: %v", i, err))
+			return
+		}
+	}
+
+	if len(request.Variants) > 0 {
+		if err := variants.Validate(request.Variants); err != nil {
+			ctx.JSON(http.StatusBadRequest, fmt.Sprintf("Invalid variants: %v", err))
+			return
+		}
+	}
+
+	for key, value := range request.UTM {
+		if !utmParams[key] || value == "" {
+			ctx.JSON(http.StatusBadRequest, fmt.Sprintf("Invalid UTM parameter [%s]", key))
+			return
+		}
+	}
+
+	metadata, err := toMetadata(request.metadataRequest)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, fmt.Sprintf("Invalid metadata: %v", err))
+		return
+	}
+
+	domain := p.domain(ctx)
+	if request.Domain != "" {
+		registered, ok := p.registeredDomain(ctx, request.Domain)
+		if !ok || registered.Tenant != p.namespace(ctx) {
+			ctx.JSON(http.StatusBadRequest, "Unknown domain")
+			return
+		}
+
+		domain = registered
+	}
+
+	if p.foreignDomain(ctx, domain) {
+		return
+	}
+
+	url := urls.URL{
+		LongURL:          request.LongURL,
+		Domain:           domain.Name,
+		RedirectType:     request.RedirectType,
+		MaxClicks:        request.MaxClicks,
+		NotBefore:        request.NotBefore,
+		NotAfter:         request.NotAfter,
+		FallbackURL:      request.FallbackURL,
+		Rules:            request.Rules,
+		Variants:         request.Variants,
+		QueryPassthrough: request.QueryPassthrough,
+		UTM:              request.UTM,
+		Prefix:           request.Prefix,
+		Metadata:         metadata,
+	}
+
+	if request.Password != "" {
+		hash, err := password.Hash(request.Password)
+		if err != nil {
+			logrus.Errorf("Failed to hash password: %v", err)
+			ctx.JSON(http.StatusInternalServerError, "Error occured while creating short URL")
+			return
+		}
+
+		url.PasswordHash = hash
+	}
+
+	shortID, err := p.controllerOf(ctx).CreateURL(ctx, url)
+	if err != nil {
+		if quotaExceeded(ctx, err) || invalidDestination(ctx, err) {
+			return
+		}
+
+		logrus.Errorf("Failed to create short url: %v", err)
+		ctx.JSON(http.StatusInternalServerError, "Error occured while creating short URL")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, toDetails(shortID, url))
+}
+
+// CreateShortURLs creates short URL objects for a list of long URLs and returns a result per item
+// Failing items do not fail the whole request, their error is reported in the result
+func (p *Presenter) CreateShortURLs(ctx *gin.Context) {
+	var request bulkCreateRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if len(request.LongURLs) > p.config.BulkLimit {
+		ctx.JSON(http.StatusRequestEntityTooLarge, fmt.Sprintf("At most %d URLs are accepted per request", p.config.BulkLimit))
+		return
+	}
+
+	if p.foreignDomain(ctx, p.domain(ctx)) {
+		return
+	}
+
+	results := p.controllerOf(ctx).CreateShortURLs(ctx, p.domain(ctx).Name, request.LongURLs)
+	response := bulkCreateResponse{Results: make([]bulkCreateResult, len(results))}
+	for i, result := range results {
+		response.Results[i] = bulkCreateResult{
+			LongURL:  result.LongURL,
+			ShortURL: result.ShortURL,
+			Reused:   result.Reused,
+		}
+
+		var (
+			quotaErr       tenants.QuotaExceededError
+			destinationErr DestinationError
+		)
+		if errors.As(result.Err, &quotaErr) {
+			response.Results[i].Error = "Quota exceeded"
+		} else if errors.As(result.Err, &destinationErr) {
+			response.Results[i].Error = fmt.Sprintf("Invalid destination: %s", destinationErr.Reason)
+		} else if result.Err != nil {
+			logrus.Errorf("Failed to create short url: %v", result.Err)
+			response.Results[i].Error = "Error occured while creating short URL"
+		}
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// RedirectToLongURL accepts a short URL as path param and redirects to the long URL if it exists on the domain of the request
+// Password protected URLs are answered with a password form unless the request carries a valid access cookie
+// URLs which have reached their maximum number of clicks or expired are answered with 410 Gone
+// Link preview bots are answered with the preview of the destination if it has been fetched
 func (p *Presenter) RedirectToLongURL(ctx *gin.Context) {
-	shortURL := ctx.Param("short_url")
-	url, err := p.controller.GetByShortURL(ctx, shortURL)
+	shortURL := p.urlKey(ctx)
+	url, err := p.controllerOf(ctx).GetByShortURL(ctx, shortURL)
 	if err != nil {
 		var notFoundErr urls.NotFoundError
 		if errors.As(err, &notFoundErr) {
-			ctx.JSON(http.StatusNotFound, "URL does not exist")
+			p.notFound(ctx)
 			return
 		}
 
@@ -63,5 +386,158 @@ func (p *Presenter) RedirectToLongURL(ctx *gin.Context) {
 		return
 	}
 
-	ctx.Redirect(http.StatusFound, url.LongURL)
+	if suffix(ctx) != "" && !url.Prefix {
+		p.notFound(ctx)
+		return
+	}
+
+	if !p.available(ctx, url) {
+		return
+	}
+
+	if url.PasswordHash != "" && !p.unlocked(ctx, shortURL) {
+		p.passwordForm(ctx, http.StatusOK, "")
+		return
+	}
+
+	if p.servePreview(ctx, url) {
+		return
+	}
+
+	p.redirect(ctx, shortURL, url)
+}
+
+// available reports whether the URL can be visited now, otherwise it answers the request
+// URLs which are not active yet redirect to their fallback URL if set
+func (p *Presenter) available(ctx *gin.Context, url urls.URL) bool {
+	now := time.Now()
+	switch {
+	case url.Exhausted() || url.Expired(now):
+		ctx.JSON(http.StatusGone, "URL is no longer available")
+	case url.NotStarted(now) && url.FallbackURL != "":
+		ctx.Header("Cache-Control", redirect.CacheControl(http.StatusFound))
+		ctx.Redirect(http.StatusFound, url.FallbackURL)
+	case url.NotStarted(now):
+		ctx.JSON(http.StatusNotFound, "URL is not active yet")
+	default:
+		return true
+	}
+
+	return false
+}
+
+// RedirectSubpath serves paths below a short URL, the QR code at /qr and forwarded paths of prefix URLs otherwise
+func (p *Presenter) RedirectSubpath(ctx *gin.Context) {
+	if ctx.Param("suffix") == qrPath {
+		p.QRCode(ctx)
+		return
+	}
+
+	p.RedirectToLongURL(ctx)
+}
+
+// redirect sends the client to the long URL using the link redirect type, or the default one of its domain if not set
+// The click is counted first for URLs with limited clicks. The destination is picked by the first matching
+// routing rule, otherwise by the variant assigned to the visitor, otherwise it is the long URL.
+// The path after the short URL of prefix URLs, the visit query if passed through and the UTM defaults
+// are added to the destination.
+// Redirects of limited, expiring, routed and split URLs are never permanent, so clicks keep reaching the server
+func (p *Presenter) redirect(ctx *gin.Context, shortURL string, url urls.URL) {
+	redirectType := p.config.RedirectType
+	if domainType := p.domain(ctx).RedirectType; domainType != 0 {
+		redirectType = domainType
+	}
+
+	if url.RedirectType != 0 {
+		redirectType = url.RedirectType
+	}
+
+	if url.MaxClicks > 0 {
+		if _, err := p.controllerOf(ctx).ConsumeClick(ctx, shortURL); err != nil {
+			var exhaustedErr urls.ExhaustedError
+			if errors.As(err, &exhaustedErr) {
+				ctx.JSON(http.StatusGone, "URL is no longer available")
+				return
+			}
+
+			logrus.Errorf("Failed to consume click: %v", err)
+			ctx.JSON(http.StatusInternalServerError, "Error occured while getting short URL")
+			return
+		}
+	}
+
+	destination, matched := url.LongURL, false
+	if len(url.Rules) > 0 {
+		destination, matched = rules.Match(url.Rules, p.visitor(ctx))
+	}
+
+	if !matched {
+		destination = url.LongURL
+		if variant, ok := p.assignVariant(ctx, shortURL, url.Variants); ok {
+			destination = variant.Destination
+		}
+	}
+
+	if url.Prefix {
+		joined, err := redirect.JoinPath(destination, suffix(ctx))
+		if err != nil {
+			if errors.Is(err, redirect.ErrInvalidSuffix) {
+				ctx.JSON(http.StatusBadRequest, "Invalid path")
+				return
+			}
+
+			logrus.Errorf("Failed to join path of [%s]: %v", shortURL, err)
+			ctx.JSON(http.StatusInternalServerError, "Error occured while getting short URL")
+			return
+		}
+
+		destination = joined
+	}
+
+	var incoming netURL.Values
+	if url.QueryPassthrough {
+		incoming = ctx.Request.URL.Query()
+	}
+
+	destination, err := redirect.MergeQuery(destination, incoming, url.UTM)
+	if err != nil {
+		logrus.Errorf("Failed to merge query of [%s]: %v", shortURL, err)
+		ctx.JSON(http.StatusInternalServerError, "Error occured while getting short URL")
+		return
+	}
+
+	if url.PasswordHash != "" || url.MaxClicks > 0 || url.NotAfter != nil || len(url.Rules) > 0 || len(url.Variants) > 0 {
+		redirectType = redirect.Temporary(redirectType)
+	}
+
+	if previewable(url) {
+		ctx.Header("Vary", "User-Agent")
+	}
+
+	ctx.Header("Cache-Control", redirect.CacheControl(redirectType))
+	ctx.Redirect(redirectType, destination)
+}
+
+// suffix returns the path after the short URL, empty if there is none
+func suffix(ctx *gin.Context) string {
+	if value := ctx.Param("suffix"); value != "/" {
+		return value
+	}
+
+	return ""
+}
+
+// visitor collects the request attributes routing rules are matched against
+func (p *Presenter) visitor(ctx *gin.Context) rules.Visitor {
+	visitor := rules.Visitor{
+		Platform: rules.Platform(ctx.GetHeader("User-Agent")),
+		Language: rules.Language(ctx.GetHeader("Accept-Language")),
+		Time:     time.Now(),
+	}
+
+	if p.config.GeoIP != nil {
+		visitor.Country = p.config.GeoIP.Country(net.ParseIP(ctx.ClientIP()))
+	}
+
+	return visitor
 }
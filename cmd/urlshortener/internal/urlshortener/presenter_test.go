@@ -35,14 +35,14 @@ var _ = Describe("Presenter", func() {
 		mockContext, _ = gin.CreateTestContext(recorder)
 		mockCtrl = gomock.NewController(GinkgoT())
 		mockController = mocks.NewMockController(mockCtrl)
-		presenter = urlshortener.NewPresenter(mockController)
+		presenter = urlshortener.NewPresenter(mockController, urlshortener.Config{RedirectType: http.StatusFound, BulkLimit: 2})
 	})
 
 	When("it fails to create short url", func() {
 		BeforeEach(func() {
 			mockContext.Request, err = http.NewRequest(http.MethodPost, gomock.Any().String(), bytes.NewBufferString(longURL))
 			Expect(err).ToNot(HaveOccurred())
-			mockController.EXPECT().CreateShortURL(gomock.Any(), longURL).Return("", errors.New("err"))
+			mockController.EXPECT().CreateShortURL(gomock.Any(), "", longURL).Return("", errors.New("err"))
 		})
 
 		It("should return http status internal server error", func() {
@@ -55,7 +55,7 @@ var _ = Describe("Presenter", func() {
 		BeforeEach(func() {
 			mockContext.Request, err = http.NewRequest(http.MethodPost, gomock.Any().String(), bytes.NewBufferString(longURL))
 			Expect(err).ToNot(HaveOccurred())
-			mockController.EXPECT().CreateShortURL(gomock.Any(), longURL).Return(shortURL, nil)
+			mockController.EXPECT().CreateShortURL(gomock.Any(), "", longURL).Return(shortURL, nil)
 		})
 
 		It("should return http status ok and short url", func() {
@@ -105,6 +105,196 @@ var _ = Describe("Presenter", func() {
 			presenter.RedirectToLongURL(mockContext)
 			Expect(mockContext.Writer.Status()).To(Equal(http.StatusFound))
 			Expect(recorder.Body.String()).To(ContainSubstring(longURL))
+			Expect(recorder.Header().Get("Cache-Control")).To(ContainSubstring("no-store"))
+		})
+	})
+
+	When("short url is found and overrides the redirect type", func() {
+		BeforeEach(func() {
+			mockContext.Request, err = http.NewRequest(http.MethodGet, gomock.Any().String(), nil)
+			Expect(err).ToNot(HaveOccurred())
+			mockContext.Params = []gin.Param{{Key: "short_url", Value: shortURL}}
+			mockController.EXPECT().GetByShortURL(gomock.Any(), shortURL).Return(urls.URL{LongURL: longURL, RedirectType: http.StatusMovedPermanently}, nil)
+		})
+
+		It("should redirect with the link redirect type and allow caching", func() {
+			presenter.RedirectToLongURL(mockContext)
+			Expect(mockContext.Writer.Status()).To(Equal(http.StatusMovedPermanently))
+			Expect(recorder.Header().Get("Location")).To(Equal(longURL))
+			Expect(recorder.Header().Get("Cache-Control")).To(ContainSubstring("max-age"))
+		})
+	})
+
+	When("short url has clicks left", func() {
+		BeforeEach(func() {
+			mockContext.Request, err = http.NewRequest(http.MethodGet, gomock.Any().String(), nil)
+			Expect(err).ToNot(HaveOccurred())
+			mockContext.Params = []gin.Param{{Key: "short_url", Value: shortURL}}
+			url := urls.URL{LongURL: longURL, RedirectType: http.StatusMovedPermanently, MaxClicks: 1}
+			mockController.EXPECT().GetByShortURL(gomock.Any(), shortURL).Return(url, nil)
+			url.Clicks = 1
+			mockController.EXPECT().ConsumeClick(gomock.Any(), shortURL).Return(url, nil)
+		})
+
+		It("should count the click and redirect temporarily", func() {
+			presenter.RedirectToLongURL(mockContext)
+			Expect(mockContext.Writer.Status()).To(Equal(http.StatusFound))
+			Expect(recorder.Header().Get("Location")).To(Equal(longURL))
+			Expect(recorder.Header().Get("Cache-Control")).To(ContainSubstring("no-store"))
+		})
+	})
+
+	When("short url has no clicks left", func() {
+		BeforeEach(func() {
+			mockContext.Request, err = http.NewRequest(http.MethodGet, gomock.Any().String(), nil)
+			Expect(err).ToNot(HaveOccurred())
+			mockContext.Params = []gin.Param{{Key: "short_url", Value: shortURL}}
+			mockController.EXPECT().GetByShortURL(gomock.Any(), shortURL).Return(urls.URL{LongURL: longURL, MaxClicks: 1, Clicks: 1}, nil)
+		})
+
+		It("should return http status gone", func() {
+			presenter.RedirectToLongURL(mockContext)
+			Expect(mockContext.Writer.Status()).To(Equal(http.StatusGone))
+		})
+	})
+
+	When("the last click of short url is consumed concurrently", func() {
+		BeforeEach(func() {
+			mockContext.Request, err = http.NewRequest(http.MethodGet, gomock.Any().String(), nil)
+			Expect(err).ToNot(HaveOccurred())
+			mockContext.Params = []gin.Param{{Key: "short_url", Value: shortURL}}
+			mockController.EXPECT().GetByShortURL(gomock.Any(), shortURL).Return(urls.URL{LongURL: longURL, MaxClicks: 1}, nil)
+			mockController.EXPECT().ConsumeClick(gomock.Any(), shortURL).Return(urls.URL{}, urls.NewExhaustedError())
+		})
+
+		It("should return http status gone", func() {
+			presenter.RedirectToLongURL(mockContext)
+			Expect(mockContext.Writer.Status()).To(Equal(http.StatusGone))
+		})
+	})
+
+	When("creating an url with negative max clicks", func() {
+		BeforeEach(func() {
+			body := `{"long_url": "long-url", "max_clicks": -1}`
+			mockContext.Request, err = http.NewRequest(http.MethodPost, gomock.Any().String(), bytes.NewBufferString(body))
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("should return http status bad request", func() {
+			presenter.CreateURL(mockContext)
+			Expect(mockContext.Writer.Status()).To(Equal(http.StatusBadRequest))
+		})
+	})
+
+	When("creating an url which expires before it starts", func() {
+		BeforeEach(func() {
+			body := `{"long_url": "long-url", "not_before": "2030-01-02T00:00:00Z", "not_after": "2030-01-01T00:00:00Z"}`
+			mockContext.Request, err = http.NewRequest(http.MethodPost, gomock.Any().String(), bytes.NewBufferString(body))
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("should return http status bad request", func() {
+			presenter.CreateURL(mockContext)
+			Expect(mockContext.Writer.Status()).To(Equal(http.StatusBadRequest))
+		})
+	})
+
+	When("creating an url with invalid body", func() {
+		BeforeEach(func() {
+			mockContext.Request, err = http.NewRequest(http.MethodPost, gomock.Any().String(), bytes.NewBufferString("invalid"))
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("should return http status bad request", func() {
+			presenter.CreateURL(mockContext)
+			Expect(mockContext.Writer.Status()).To(Equal(http.StatusBadRequest))
+		})
+	})
+
+	When("creating an url with unsupported redirect type", func() {
+		BeforeEach(func() {
+			body := `{"long_url": "long-url", "redirect_type": 200}`
+			mockContext.Request, err = http.NewRequest(http.MethodPost, gomock.Any().String(), bytes.NewBufferString(body))
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("should return http status bad request", func() {
+			presenter.CreateURL(mockContext)
+			Expect(mockContext.Writer.Status()).To(Equal(http.StatusBadRequest))
+		})
+	})
+
+	When("it fails to create an url", func() {
+		BeforeEach(func() {
+			body := `{"long_url": "long-url", "redirect_type": 308}`
+			mockContext.Request, err = http.NewRequest(http.MethodPost, gomock.Any().String(), bytes.NewBufferString(body))
+			Expect(err).ToNot(HaveOccurred())
+			mockController.EXPECT().CreateURL(gomock.Any(), urls.URL{LongURL: longURL, RedirectType: http.StatusPermanentRedirect}).Return("", errors.New("err"))
+		})
+
+		It("should return http status internal server error", func() {
+			presenter.CreateURL(mockContext)
+			Expect(mockContext.Writer.Status()).To(Equal(http.StatusInternalServerError))
+		})
+	})
+
+	When("it succeeds to create an url", func() {
+		BeforeEach(func() {
+			body := `{"long_url": "long-url", "redirect_type": 308}`
+			mockContext.Request, err = http.NewRequest(http.MethodPost, gomock.Any().String(), bytes.NewBufferString(body))
+			Expect(err).ToNot(HaveOccurred())
+			mockController.EXPECT().CreateURL(gomock.Any(), urls.URL{LongURL: longURL, RedirectType: http.StatusPermanentRedirect}).Return(shortURL, nil)
+		})
+
+		It("should return http status created and short url", func() {
+			presenter.CreateURL(mockContext)
+			Expect(mockContext.Writer.Status()).To(Equal(http.StatusCreated))
+			Expect(recorder.Body.String()).To(ContainSubstring(shortURL))
+		})
+	})
+	When("creating urls in bulk with invalid body", func() {
+		BeforeEach(func() {
+			mockContext.Request, err = http.NewRequest(http.MethodPost, gomock.Any().String(), bytes.NewBufferString("invalid"))
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("should return http status bad request", func() {
+			presenter.CreateShortURLs(mockContext)
+			Expect(mockContext.Writer.Status()).To(Equal(http.StatusBadRequest))
+		})
+	})
+
+	When("creating more urls in bulk than allowed", func() {
+		BeforeEach(func() {
+			body := `{"long_urls": ["a", "b", "c"]}`
+			mockContext.Request, err = http.NewRequest(http.MethodPost, gomock.Any().String(), bytes.NewBufferString(body))
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("should return http status request entity too large", func() {
+			presenter.CreateShortURLs(mockContext)
+			Expect(mockContext.Writer.Status()).To(Equal(http.StatusRequestEntityTooLarge))
+		})
+	})
+
+	When("creating urls in bulk", func() {
+		BeforeEach(func() {
+			body := `{"long_urls": ["long-url", "other-url"]}`
+			mockContext.Request, err = http.NewRequest(http.MethodPost, gomock.Any().String(), bytes.NewBufferString(body))
+			Expect(err).ToNot(HaveOccurred())
+			mockController.EXPECT().CreateShortURLs(gomock.Any(), "", []string{longURL, "other-url"}).Return([]urlshortener.BulkResult{
+				{LongURL: longURL, ShortURL: shortURL, Reused: true},
+				{LongURL: "other-url", Err: errors.New("err")},
+			})
+		})
+
+		It("should return http status ok and a result per url", func() {
+			presenter.CreateShortURLs(mockContext)
+			Expect(mockContext.Writer.Status()).To(Equal(http.StatusOK))
+			Expect(recorder.Body.String()).To(MatchJSON(`{"results": [
+				{"long_url": "long-url", "short_url": "short-url", "reused": true},
+				{"long_url": "other-url", "reused": false, "error": "Error occured while creating short URL"}
+			]}`))
 		})
 	})
 })
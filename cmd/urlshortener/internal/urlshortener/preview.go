@@ -0,0 +1,76 @@
+package urlshortener
+
+import (
+	"html/template"
+	"net/http"
+	"url-shortener/pkg/repository/firestore/urls"
+	"url-shortener/pkg/unfurl"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+var previewPage = template.Must(template.New("preview").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<meta property="og:type" content="website">
+<meta property="og:url" content="{{.URL}}">
+<meta property="og:title" content="{{.Title}}">
+{{if .Description}}<meta property="og:description" content="{{.Description}}">
+<meta name="description" content="{{.Description}}">
+{{end}}{{if .Image}}<meta property="og:image" content="{{.Image}}">
+<meta name="twitter:card" content="summary_large_image">
+<meta name="twitter:image" content="{{.Image}}">
+{{else}}<meta name="twitter:card" content="summary">
+{{end}}<meta name="twitter:title" content="{{.Title}}">
+</head>
+<body><a href="{{.Destination}}">{{.Title}}</a></body>
+</html>
+`))
+
+type previewData struct {
+	URL         string
+	Destination string
+	unfurl.Preview
+}
+
+// servePreview answers link preview bots with the OpenGraph tags of the destination instead of redirecting them
+// Titles and descriptions set on the URL win over the fetched ones. Protected and click limited URLs are
+// never previewed, so bots neither reveal them nor use up their clicks
+// Caches keep previews and redirects of previewable URLs per user agent, so visitors are never sent a preview
+func (p *Presenter) servePreview(ctx *gin.Context, url urls.URL) bool {
+	if !previewable(url) || !unfurl.IsCrawler(ctx.GetHeader("User-Agent")) {
+		return false
+	}
+
+	data := previewData{
+		URL:         p.publicURL(ctx, ctx.Param("short_url")),
+		Destination: url.LongURL,
+		Preview:     *url.Preview,
+	}
+
+	if url.Title != "" {
+		data.Preview.Title = url.Title
+	}
+
+	if url.Description != "" {
+		data.Preview.Description = url.Description
+	}
+
+	ctx.Header("Cache-Control", "public, max-age=3600")
+	ctx.Header("Vary", "User-Agent")
+	ctx.Header("Content-Type", "text/html; charset=utf-8")
+	ctx.Status(http.StatusOK)
+	if err := previewPage.Execute(ctx.Writer, data); err != nil {
+		logrus.Errorf("Failed to render preview: %v", err)
+	}
+
+	return true
+}
+
+// previewable reports whether bots are answered with the preview of the URL instead of a redirect
+func previewable(url urls.URL) bool {
+	return url.Preview != nil && url.PasswordHash == "" && url.MaxClicks == 0
+}
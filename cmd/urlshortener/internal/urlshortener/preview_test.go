@@ -0,0 +1,119 @@
+package urlshortener_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"url-shortener/cmd/urlshortener/internal/urlshortener"
+	"url-shortener/cmd/urlshortener/internal/urlshortener/mocks"
+	"url-shortener/pkg/repository/firestore/urls"
+	"url-shortener/pkg/unfurl"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Previews", func() {
+	const (
+		shortURL = "abc"
+		slackbot = "Slackbot-LinkExpanding 1.0 (+https://api.slack.com/robots)"
+	)
+
+	var (
+		mockCtrl       *gomock.Controller
+		mockController *mocks.MockController
+		presenter      *urlshortener.Presenter
+		url            urls.URL
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockController = mocks.NewMockController(mockCtrl)
+		presenter = urlshortener.NewPresenter(mockController, urlshortener.Config{
+			RedirectType: http.StatusFound,
+			QRCacheSize:  1,
+			PublicURL:    "https://sho.rt",
+		})
+		url = urls.URL{
+			LongURL: "https://example.com/sale",
+			Preview: &unfurl.Preview{Title: "Spring sale", Description: "Everything <must> go", Image: "https://example.com/sale.png"},
+		}
+	})
+
+	visit := func(userAgent string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(recorder)
+		ctx.Request = httptest.NewRequest(http.MethodGet, "/"+shortURL, nil)
+		ctx.Request.Header.Set("User-Agent", userAgent)
+		ctx.Params = []gin.Param{{Key: "short_url", Value: shortURL}}
+		presenter.RedirectToLongURL(ctx)
+		return recorder
+	}
+
+	When("a link preview bot visits an url with a preview", func() {
+		BeforeEach(func() {
+			mockController.EXPECT().GetByShortURL(gomock.Any(), shortURL).Return(url, nil)
+		})
+
+		It("should answer with the OpenGraph tags", func() {
+			recorder := visit(slackbot)
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Body.String()).To(ContainSubstring(`<meta property="og:title" content="Spring sale">`))
+			Expect(recorder.Body.String()).To(ContainSubstring(`<meta property="og:description" content="Everything &lt;must&gt; go">`))
+			Expect(recorder.Body.String()).To(ContainSubstring(`<meta property="og:image" content="https://example.com/sale.png">`))
+			Expect(recorder.Body.String()).To(ContainSubstring(`<meta property="og:url" content="https://sho.rt/abc">`))
+		})
+
+		It("should be cached per user agent", func() {
+			Expect(visit(slackbot).Header().Get("Vary")).To(Equal("User-Agent"))
+		})
+	})
+
+	When("a link preview bot visits an url with its own title", func() {
+		BeforeEach(func() {
+			url.Title = "Our sale"
+			mockController.EXPECT().GetByShortURL(gomock.Any(), shortURL).Return(url, nil)
+		})
+
+		It("should prefer it", func() {
+			Expect(visit(slackbot).Body.String()).To(ContainSubstring(`<meta property="og:title" content="Our sale">`))
+		})
+	})
+
+	When("a browser visits an url with a preview", func() {
+		BeforeEach(func() {
+			mockController.EXPECT().GetByShortURL(gomock.Any(), shortURL).Return(url, nil)
+		})
+
+		It("should redirect", func() {
+			recorder := visit("Mozilla/5.0")
+			Expect(recorder.Code).To(Equal(http.StatusFound))
+			Expect(recorder.Header().Get("Location")).To(Equal(url.LongURL))
+			Expect(recorder.Header().Get("Vary")).To(Equal("User-Agent"))
+		})
+	})
+
+	When("a link preview bot visits an url without a preview", func() {
+		BeforeEach(func() {
+			url.Preview = nil
+			mockController.EXPECT().GetByShortURL(gomock.Any(), shortURL).Return(url, nil)
+		})
+
+		It("should redirect", func() {
+			Expect(visit(slackbot).Code).To(Equal(http.StatusFound))
+		})
+	})
+
+	When("a link preview bot visits a click limited url", func() {
+		BeforeEach(func() {
+			url.MaxClicks = 1
+			mockController.EXPECT().GetByShortURL(gomock.Any(), shortURL).Return(url, nil)
+			mockController.EXPECT().ConsumeClick(gomock.Any(), shortURL).Return(url, nil)
+		})
+
+		It("should not preview it", func() {
+			Expect(visit(slackbot).Code).To(Equal(http.StatusFound))
+		})
+	})
+})
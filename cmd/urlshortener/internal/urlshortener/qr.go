@@ -0,0 +1,162 @@
+package urlshortener
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"url-shortener/pkg/qrcode"
+	"url-shortener/pkg/repository/firestore/urls"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// qrPath is the path below a short URL serving its QR code, it is never forwarded by prefix URLs
+	qrPath          = "/qr"
+	qrFormatPNG     = "png"
+	qrFormatSVG     = "svg"
+	qrDefaultSize   = 256
+	qrMinSize       = 64
+	qrMaxSize       = 2048
+	qrDefaultMargin = 4
+	qrMaxMargin     = 16
+)
+
+type qrRequest struct {
+	format string
+	level  qrcode.Level
+	opts   qrcode.Options
+}
+
+// QRCode returns a PNG or SVG QR code of the full short URL, URLs which cannot be visited now have no QR code
+// Images are cached per full short URL and rendering options, so the request host never changes the image of another host
+func (p *Presenter) QRCode(ctx *gin.Context) {
+	shortURL := p.urlKey(ctx)
+	request, err := parseQRRequest(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	contentType := "image/png"
+	if request.format == qrFormatSVG {
+		contentType = "image/svg+xml"
+	}
+
+	url, err := p.controllerOf(ctx).GetByShortURL(ctx, shortURL)
+	if err != nil {
+		var notFoundErr urls.NotFoundError
+		if errors.As(err, &notFoundErr) {
+			ctx.JSON(http.StatusNotFound, "URL does not exist")
+			return
+		}
+
+		logrus.Errorf("Failed to get by short url: %v", err)
+		ctx.JSON(http.StatusInternalServerError, "Error occured while getting short URL")
+		return
+	}
+
+	now := time.Now()
+	switch {
+	case url.Exhausted() || url.Expired(now):
+		ctx.JSON(http.StatusGone, "URL is no longer available")
+		return
+	case url.NotStarted(now):
+		ctx.JSON(http.StatusNotFound, "URL is not active yet")
+		return
+	}
+
+	target := p.publicURL(ctx, ctx.Param("short_url"))
+	key := fmt.Sprintf("%s|%s|%d|%d|%d|%v|%v", target, request.format, request.level,
+		request.opts.Size, request.opts.Margin, request.opts.Foreground, request.opts.Background)
+	if image, ok := p.qrCache.Get(key); ok {
+		p.writeQRCode(ctx, contentType, image)
+		return
+	}
+
+	code, err := qrcode.Encode(target, request.level)
+	if err != nil {
+		logrus.Errorf("Failed to encode qr code: %v", err)
+		ctx.JSON(http.StatusInternalServerError, "Error occured while generating QR code")
+		return
+	}
+
+	image := code.SVG(request.opts)
+	if request.format == qrFormatPNG {
+		if image, err = code.PNG(request.opts); err != nil {
+			logrus.Errorf("Failed to render qr code: %v", err)
+			ctx.JSON(http.StatusInternalServerError, "Error occured while generating QR code")
+			return
+		}
+	}
+
+	p.qrCache.Add(key, image)
+	p.writeQRCode(ctx, contentType, image)
+}
+
+func (p *Presenter) writeQRCode(ctx *gin.Context, contentType string, image []byte) {
+	ctx.Header("Cache-Control", "public, max-age=86400")
+	ctx.Data(http.StatusOK, contentType, image)
+}
+
+// publicURL returns the full short URL, based on the branded domain, the configured public URL or the request host
+func (p *Presenter) publicURL(ctx *gin.Context, shortURL string) string {
+	scheme := "http"
+	if ctx.Request.TLS != nil || ctx.GetHeader("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+
+	if domain := p.domain(ctx).Name; domain != "" {
+		return fmt.Sprintf("%s://%s/%s", scheme, domain, shortURL)
+	}
+
+	if p.config.PublicURL != "" {
+		return strings.TrimSuffix(p.config.PublicURL, "/") + "/" + shortURL
+	}
+
+	return fmt.Sprintf("%s://%s/%s", scheme, ctx.Request.Host, shortURL)
+}
+
+func parseQRRequest(ctx *gin.Context) (qrRequest, error) {
+	request := qrRequest{
+		format: strings.ToLower(ctx.DefaultQuery("format", qrFormatPNG)),
+		opts:   qrcode.Options{Size: qrDefaultSize, Margin: qrDefaultMargin},
+	}
+
+	if request.format != qrFormatPNG && request.format != qrFormatSVG {
+		return qrRequest{}, fmt.Errorf("unsupported format [%s], expected png or svg", request.format)
+	}
+
+	var err error
+	if request.level, err = qrcode.ParseLevel(ctx.DefaultQuery("level", "M")); err != nil {
+		return qrRequest{}, err
+	}
+
+	if size := ctx.Query("size"); size != "" {
+		request.opts.Size, err = strconv.Atoi(size)
+		if err != nil || request.opts.Size < qrMinSize || request.opts.Size > qrMaxSize {
+			return qrRequest{}, fmt.Errorf("size must be between %d and %d", qrMinSize, qrMaxSize)
+		}
+	}
+
+	if margin := ctx.Query("margin"); margin != "" {
+		request.opts.Margin, err = strconv.Atoi(margin)
+		if err != nil || request.opts.Margin < 0 || request.opts.Margin > qrMaxMargin {
+			return qrRequest{}, fmt.Errorf("margin must be between 0 and %d", qrMaxMargin)
+		}
+	}
+
+	if request.opts.Foreground, err = qrcode.ParseColor(ctx.DefaultQuery("fg", "000000")); err != nil {
+		return qrRequest{}, err
+	}
+
+	if request.opts.Background, err = qrcode.ParseColor(ctx.DefaultQuery("bg", "ffffff")); err != nil {
+		return qrRequest{}, err
+	}
+
+	return request, nil
+}
@@ -0,0 +1,158 @@
+package urlshortener_test
+
+import (
+	"bytes"
+	"errors"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"time"
+	"url-shortener/cmd/urlshortener/internal/urlshortener"
+	"url-shortener/cmd/urlshortener/internal/urlshortener/mocks"
+	"url-shortener/pkg/repository/firestore/urls"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("QRCode", func() {
+	const shortURL = "short-url"
+
+	var (
+		mockCtrl       *gomock.Controller
+		mockController *mocks.MockController
+		presenter      *urlshortener.Presenter
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockController = mocks.NewMockController(mockCtrl)
+		presenter = urlshortener.NewPresenter(mockController, urlshortener.Config{
+			RedirectType: http.StatusFound,
+			PublicURL:    "https://sho.rt",
+			QRCacheSize:  10,
+		})
+	})
+
+	requestHost := func(host, query string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(recorder)
+		ctx.Request = httptest.NewRequest(http.MethodGet, "/"+shortURL+"/qr"+query, nil)
+		ctx.Request.Host = host
+		ctx.Params = []gin.Param{{Key: "short_url", Value: shortURL}}
+		presenter.QRCode(ctx)
+		return recorder
+	}
+
+	request := func(query string) *httptest.ResponseRecorder {
+		return requestHost("example.com", query)
+	}
+
+	When("options are invalid", func() {
+		It("should return http status bad request", func() {
+			Expect(request("?format=gif").Code).To(Equal(http.StatusBadRequest))
+			Expect(request("?size=10").Code).To(Equal(http.StatusBadRequest))
+			Expect(request("?margin=-1").Code).To(Equal(http.StatusBadRequest))
+			Expect(request("?level=X").Code).To(Equal(http.StatusBadRequest))
+			Expect(request("?fg=red").Code).To(Equal(http.StatusBadRequest))
+		})
+	})
+
+	When("short url does not exist", func() {
+		BeforeEach(func() {
+			mockController.EXPECT().GetByShortURL(gomock.Any(), shortURL).Return(urls.URL{}, urls.NewNotFoundError())
+		})
+
+		It("should return http status not found", func() {
+			Expect(request("").Code).To(Equal(http.StatusNotFound))
+		})
+	})
+
+	When("getting short url fails", func() {
+		BeforeEach(func() {
+			mockController.EXPECT().GetByShortURL(gomock.Any(), shortURL).Return(urls.URL{}, errors.New("err"))
+		})
+
+		It("should return http status internal server error", func() {
+			Expect(request("").Code).To(Equal(http.StatusInternalServerError))
+		})
+	})
+
+	When("short url exists", func() {
+		BeforeEach(func() {
+			mockController.EXPECT().GetByShortURL(gomock.Any(), shortURL).Return(urls.URL{LongURL: "long-url"}, nil).AnyTimes()
+		})
+
+		It("should return a png and serve repeated requests from cache", func() {
+			recorder := request("?size=128")
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Header().Get("Content-Type")).To(Equal("image/png"))
+			img, err := png.Decode(bytes.NewReader(recorder.Body.Bytes()))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(img.Bounds().Dx()).To(BeNumerically("<=", 128))
+
+			cached := request("?size=128")
+			Expect(cached.Code).To(Equal(http.StatusOK))
+			Expect(cached.Body.Bytes()).To(Equal(recorder.Body.Bytes()))
+		})
+
+		It("should return an svg with the requested colors", func() {
+			recorder := request("?format=svg&fg=%23ff0000&bg=00ff00&margin=0")
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Header().Get("Content-Type")).To(Equal("image/svg+xml"))
+			Expect(recorder.Body.String()).To(ContainSubstring(`fill="#ff0000"`))
+			Expect(recorder.Body.String()).To(ContainSubstring(`fill="#00ff00"`))
+		})
+	})
+
+	When("short url is cached and no longer available", func() {
+		BeforeEach(func() {
+			gomock.InOrder(
+				mockController.EXPECT().GetByShortURL(gomock.Any(), shortURL).Return(urls.URL{LongURL: "long-url"}, nil),
+				mockController.EXPECT().GetByShortURL(gomock.Any(), shortURL).Return(urls.URL{LongURL: "long-url", MaxClicks: 1, Clicks: 1}, nil),
+				mockController.EXPECT().GetByShortURL(gomock.Any(), shortURL).Return(urls.URL{}, urls.NewNotFoundError()),
+			)
+		})
+
+		It("should not serve the cached image", func() {
+			Expect(request("").Code).To(Equal(http.StatusOK))
+			Expect(request("").Code).To(Equal(http.StatusGone))
+			Expect(request("").Code).To(Equal(http.StatusNotFound))
+		})
+	})
+
+	When("short url is scheduled or expired", func() {
+		It("should return no qr code", func() {
+			future := time.Now().Add(time.Hour)
+			past := time.Now().Add(-time.Hour)
+			gomock.InOrder(
+				mockController.EXPECT().GetByShortURL(gomock.Any(), shortURL).Return(urls.URL{LongURL: "long-url", NotBefore: &future}, nil),
+				mockController.EXPECT().GetByShortURL(gomock.Any(), shortURL).Return(urls.URL{LongURL: "long-url", NotAfter: &past}, nil),
+			)
+
+			Expect(request("").Code).To(Equal(http.StatusNotFound))
+			Expect(request("").Code).To(Equal(http.StatusGone))
+		})
+	})
+
+	When("no public url is configured", func() {
+		BeforeEach(func() {
+			presenter = urlshortener.NewPresenter(mockController, urlshortener.Config{
+				RedirectType: http.StatusFound,
+				QRCacheSize:  10,
+			})
+			mockController.EXPECT().GetByShortURL(gomock.Any(), shortURL).Return(urls.URL{LongURL: "long-url"}, nil).Times(2)
+		})
+
+		It("should not serve the image of one host to another", func() {
+			forged := requestHost("evil.example", "?format=svg")
+			Expect(forged.Code).To(Equal(http.StatusOK))
+
+			recorder := requestHost("sho.rt", "?format=svg")
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Body.String()).ToNot(Equal(forged.Body.String()))
+		})
+	})
+})
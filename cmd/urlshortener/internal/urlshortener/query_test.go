@@ -0,0 +1,90 @@
+package urlshortener_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"url-shortener/cmd/urlshortener/internal/urlshortener"
+	"url-shortener/cmd/urlshortener/internal/urlshortener/mocks"
+	"url-shortener/pkg/repository/firestore/urls"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Query parameters", func() {
+	const shortURL = "short-url"
+
+	var (
+		mockCtrl       *gomock.Controller
+		mockController *mocks.MockController
+		presenter      *urlshortener.Presenter
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockController = mocks.NewMockController(mockCtrl)
+		presenter = urlshortener.NewPresenter(mockController, urlshortener.Config{RedirectType: http.StatusFound})
+	})
+
+	visit := func(url urls.URL, query string) string {
+		mockController.EXPECT().GetByShortURL(gomock.Any(), shortURL).Return(url, nil)
+		recorder := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(recorder)
+		ctx.Request = httptest.NewRequest(http.MethodGet, "/"+shortURL+query, nil)
+		ctx.Params = []gin.Param{{Key: "short_url", Value: shortURL}}
+		presenter.RedirectToLongURL(ctx)
+		return recorder.Header().Get("Location")
+	}
+
+	When("the url passes the query through", func() {
+		It("should add the visit query and the UTM defaults to the destination", func() {
+			url := urls.URL{
+				LongURL:          "https://example.com/?id=1",
+				QueryPassthrough: true,
+				UTM:              map[string]string{"utm_source": "link", "utm_medium": "email"},
+			}
+			Expect(visit(url, "?ref=twitter&id=2&utm_source=twitter")).To(Equal("https://example.com/?id=1&ref=twitter&utm_medium=email&utm_source=twitter"))
+		})
+	})
+
+	When("the url does not pass the query through", func() {
+		It("should only add the UTM defaults", func() {
+			url := urls.URL{LongURL: "https://example.com/", UTM: map[string]string{"utm_campaign": "spring"}}
+			Expect(visit(url, "?ref=twitter")).To(Equal("https://example.com/?utm_campaign=spring"))
+			Expect(visit(urls.URL{LongURL: "https://example.com/"}, "?ref=twitter")).To(Equal("https://example.com/"))
+		})
+	})
+
+	When("creating an url with an unknown UTM parameter", func() {
+		It("should return http status bad request", func() {
+			recorder := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(recorder)
+			body := `{"long_url": "https://example.com", "utm": {"source": "newsletter"}}`
+			ctx.Request = httptest.NewRequest(http.MethodPost, "/api/v1/urls", bytes.NewBufferString(body))
+			presenter.CreateURL(ctx)
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+		})
+	})
+
+	When("creating an url with query passthrough and UTM defaults", func() {
+		BeforeEach(func() {
+			mockController.EXPECT().CreateURL(gomock.Any(), urls.URL{
+				LongURL:          "https://example.com",
+				QueryPassthrough: true,
+				UTM:              map[string]string{"utm_source": "newsletter"},
+			}).Return(shortURL, nil)
+		})
+
+		It("should store them on the url", func() {
+			recorder := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(recorder)
+			body := `{"long_url": "https://example.com", "query_passthrough": true, "utm": {"utm_source": "newsletter"}}`
+			ctx.Request = httptest.NewRequest(http.MethodPost, "/api/v1/urls", bytes.NewBufferString(body))
+			presenter.CreateURL(ctx)
+			Expect(recorder.Code).To(Equal(http.StatusCreated))
+		})
+	})
+})
@@ -0,0 +1,52 @@
+package urlshortener
+
+import "github.com/gin-gonic/gin"
+
+// RegisterRoutes registers the handlers of the HTTP API, every route is documented in openapi.json
+func (p *Presenter) RegisterRoutes(handler *gin.Engine) {
+	handler.Use(p.ValidateSpec)
+	handler.GET("/openapi.json", p.ServeSpec)
+	handler.GET("/api/v1/docs", p.ServeDocs)
+
+	public := handler.Group("", p.ResolveTenant, p.ValidateShortURL)
+	public.POST("/", p.RequireTenantKey, p.CreateShortURL)
+	public.GET("/:short_url", p.RedirectToLongURL)
+	public.POST("/:short_url", p.UnlockURL)
+	public.GET("/:short_url/*suffix", p.RedirectSubpath)
+	public.POST("/:short_url/*suffix", p.UnlockURL)
+
+	api := public.Group("/api/v1/urls", p.RequireTenantKey)
+	api.POST("", p.CreateURL)
+	api.POST("/bulk", p.CreateShortURLs)
+	api.GET("", p.ListURLs)
+	api.GET("/search", p.SearchURLs)
+	api.GET("/scheduled", p.ListScheduled)
+	api.GET("/broken", p.ListBroken)
+	api.GET("/:short_url", p.GetURL)
+	api.GET("/:short_url/stats", p.GetStats)
+	api.PUT("/:short_url/metadata", p.RequireNamespaceKey, p.UpdateMetadata)
+	api.GET("/:short_url/variants", p.GetVariants)
+	api.PUT("/:short_url/variants", p.RequireNamespaceKey, p.UpdateVariants)
+	api.DELETE("/:short_url", p.RequireNamespaceKey, p.DeleteURL)
+
+	hooks := public.Group("/api/v1/webhooks", p.RequireNamespaceKey)
+	hooks.POST("", p.CreateSubscription)
+	hooks.GET("", p.ListSubscriptions)
+	hooks.DELETE("/:id", p.DeleteSubscription)
+	hooks.GET("/:id/deliveries", p.ListDeliveries)
+
+	changes := public.Group("/api/v1/changes", p.RequireNamespaceKey)
+	changes.GET("", p.ListChanges)
+	changes.GET("/stream", p.StreamChanges)
+
+	admin := handler.Group("/api/v1/admin", p.RequireAdmin)
+	admin.GET("/domains", p.ListDomains)
+	admin.POST("/domains", p.RegisterDomain)
+	admin.DELETE("/domains/:domain", p.UnregisterDomain)
+	admin.GET("/tenants", p.ListTenants)
+	admin.POST("/tenants", p.CreateTenant)
+	admin.POST("/tenants/:tenant/suspend", p.SuspendTenant)
+	admin.POST("/tenants/:tenant/resume", p.ResumeTenant)
+	admin.GET("/counter", p.GetCounter)
+	admin.GET("/export", p.ExportArchive)
+}
@@ -0,0 +1,96 @@
+package urlshortener_test
+
+import (
+	"bytes"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"url-shortener/cmd/urlshortener/internal/urlshortener"
+	"url-shortener/cmd/urlshortener/internal/urlshortener/mocks"
+	"url-shortener/pkg/repository/firestore/urls"
+	"url-shortener/pkg/rules"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type fakeLocator map[string]string
+
+func (f fakeLocator) Country(ip net.IP) string {
+	return f[ip.String()]
+}
+
+var _ = Describe("Routing rules", func() {
+	const (
+		shortURL = "short-url"
+		website  = "https://example.com"
+		appStore = "https://apps.apple.com/app"
+		german   = "https://example.de"
+	)
+
+	var (
+		mockCtrl       *gomock.Controller
+		mockController *mocks.MockController
+		presenter      *urlshortener.Presenter
+		url            urls.URL
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockController = mocks.NewMockController(mockCtrl)
+		presenter = urlshortener.NewPresenter(mockController, urlshortener.Config{
+			RedirectType: http.StatusMovedPermanently,
+			GeoIP:        fakeLocator{"81.2.69.1": "DE"},
+		})
+		url = urls.URL{LongURL: website, Rules: []rules.Rule{
+			{Platforms: []string{rules.PlatformIOS}, Destination: appStore},
+			{Countries: []string{"DE"}, Destination: german},
+		}}
+	})
+
+	visit := func(userAgent, remoteAddr string) *httptest.ResponseRecorder {
+		mockController.EXPECT().GetByShortURL(gomock.Any(), shortURL).Return(url, nil)
+		recorder := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(recorder)
+		ctx.Request = httptest.NewRequest(http.MethodGet, "/"+shortURL, nil)
+		ctx.Request.Header.Set("User-Agent", userAgent)
+		ctx.Request.RemoteAddr = remoteAddr
+		ctx.Params = []gin.Param{{Key: "short_url", Value: shortURL}}
+		presenter.RedirectToLongURL(ctx)
+		return recorder
+	}
+
+	When("the visitor matches a rule", func() {
+		It("should redirect temporarily to the rule destination", func() {
+			recorder := visit("Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X)", "81.2.69.1:1234")
+			Expect(recorder.Code).To(Equal(http.StatusFound))
+			Expect(recorder.Header().Get("Location")).To(Equal(appStore))
+			Expect(recorder.Header().Get("Cache-Control")).To(ContainSubstring("no-store"))
+		})
+	})
+
+	When("the visitor country matches a rule", func() {
+		It("should redirect to the rule destination", func() {
+			Expect(visit("Mozilla/5.0 (X11; Linux x86_64)", "81.2.69.1:1234").Header().Get("Location")).To(Equal(german))
+		})
+	})
+
+	When("the visitor matches no rule", func() {
+		It("should redirect to the long url", func() {
+			Expect(visit("Mozilla/5.0 (X11; Linux x86_64)", "1.0.0.1:1234").Header().Get("Location")).To(Equal(website))
+		})
+	})
+
+	When("creating an url with an invalid rule", func() {
+		It("should return http status bad request", func() {
+			recorder := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(recorder)
+			body := `{"long_url": "https://example.com", "rules": [{"platforms": ["ios"]}]}`
+			ctx.Request = httptest.NewRequest(http.MethodPost, "/api/v1/urls", bytes.NewBufferString(body))
+			presenter.CreateURL(ctx)
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+		})
+	})
+})
@@ -0,0 +1,168 @@
+package urlshortener
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"url-shortener/pkg/repository/firestore/urls"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// defaultScheduleRange is used when the listing request does not set its end
+	defaultScheduleRange = 7 * 24 * time.Hour
+	defaultScheduleLimit = 100
+	maxScheduleLimit     = 500
+	// scheduleCursorSeparator separates the positions of both lists, it is neither part of domains nor of codes
+	scheduleCursorSeparator = ","
+	// scheduleListDone marks a list without more URLs in a cursor
+	scheduleListDone = "-"
+)
+
+type scheduledURL struct {
+	ShortURL    string     `json:"short_url"`
+	Domain      string     `json:"domain,omitempty"`
+	LongURL     string     `json:"long_url"`
+	NotBefore   *time.Time `json:"not_before,omitempty"`
+	NotAfter    *time.Time `json:"not_after,omitempty"`
+	FallbackURL string     `json:"fallback_url,omitempty"`
+}
+
+type scheduleResponse struct {
+	Activating   []scheduledURL `json:"activating"`
+	Deactivating []scheduledURL `json:"deactivating"`
+	// Cursor continues both lists after the returned URLs, it is empty if there are no more
+	Cursor string `json:"cursor"`
+}
+
+// ListScheduled returns up to limit URLs activating and up to limit URLs deactivating within [from, to)
+// Both query params are RFC 3339 timestamps, from defaults to now and to defaults to a week after from
+// Clients page by passing the returned cursor with the same range until it is empty
+func (p *Presenter) ListScheduled(ctx *gin.Context) {
+	from, err := parseTime(ctx.Query("from"), time.Now())
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, "Invalid from, expected RFC 3339 timestamp")
+		return
+	}
+
+	to, err := parseTime(ctx.Query("to"), from.Add(defaultScheduleRange))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, "Invalid to, expected RFC 3339 timestamp")
+		return
+	}
+
+	if !to.After(from) {
+		ctx.JSON(http.StatusBadRequest, "To must be later than from")
+		return
+	}
+
+	after, err := parseScheduleCursor(ctx.Query("cursor"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, "Invalid cursor")
+		return
+	}
+
+	limit := defaultScheduleLimit
+	if value := ctx.Query("limit"); value != "" {
+		limit, err = strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > maxScheduleLimit {
+			ctx.JSON(http.StatusBadRequest, fmt.Sprintf("Limit must be between 1 and %d", maxScheduleLimit))
+			return
+		}
+	}
+
+	schedule, err := p.controllerOf(ctx).ListScheduled(ctx, from, to, after, limit)
+	if err != nil {
+		logrus.Errorf("Failed to list scheduled urls: %v", err)
+		ctx.JSON(http.StatusInternalServerError, "Error occured while listing scheduled URLs")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, scheduleResponse{
+		Activating:   toScheduledURLs(schedule.Activating),
+		Deactivating: toScheduledURLs(schedule.Deactivating),
+		Cursor:       formatScheduleCursor(schedule.Next),
+	})
+}
+
+// formatScheduleCursor encodes the positions of both lists, the cursor of a finished schedule is an empty string
+func formatScheduleCursor(cursor ScheduleCursor) string {
+	if cursor.Done() {
+		return ""
+	}
+
+	return formatListCursor(cursor.Activating, cursor.ActivatingDone) + scheduleCursorSeparator +
+		formatListCursor(cursor.Deactivating, cursor.DeactivatingDone)
+}
+
+func formatListCursor(cursor urls.Cursor, done bool) string {
+	if done {
+		return scheduleListDone
+	}
+
+	return cursor.String()
+}
+
+// parseScheduleCursor decodes a cursor encoded by formatScheduleCursor, an empty string starts both lists
+func parseScheduleCursor(value string) (ScheduleCursor, error) {
+	if value == "" {
+		return ScheduleCursor{}, nil
+	}
+
+	activating, deactivating, ok := strings.Cut(value, scheduleCursorSeparator)
+	if !ok {
+		return ScheduleCursor{}, fmt.Errorf("malformed schedule cursor [%s]", value)
+	}
+
+	var (
+		cursor ScheduleCursor
+		err    error
+	)
+	if cursor.Activating, cursor.ActivatingDone, err = parseListCursor(activating); err != nil {
+		return ScheduleCursor{}, err
+	}
+
+	if cursor.Deactivating, cursor.DeactivatingDone, err = parseListCursor(deactivating); err != nil {
+		return ScheduleCursor{}, err
+	}
+
+	return cursor, nil
+}
+
+func parseListCursor(value string) (urls.Cursor, bool, error) {
+	if value == scheduleListDone {
+		return urls.Cursor{}, true, nil
+	}
+
+	cursor, err := urls.ParseCursor(value)
+	return cursor, false, err
+}
+
+func parseTime(value string, fallback time.Time) (time.Time, error) {
+	if value == "" {
+		return fallback, nil
+	}
+
+	return time.Parse(time.RFC3339, value)
+}
+
+func toScheduledURLs(records []urls.Record) []scheduledURL {
+	scheduled := make([]scheduledURL, len(records))
+	for i, record := range records {
+		domain, code := urls.SplitKey(record.ID)
+		scheduled[i] = scheduledURL{
+			ShortURL:    code,
+			Domain:      domain,
+			LongURL:     record.URL.LongURL,
+			NotBefore:   record.URL.NotBefore,
+			NotAfter:    record.URL.NotAfter,
+			FallbackURL: record.URL.FallbackURL,
+		}
+	}
+
+	return scheduled
+}
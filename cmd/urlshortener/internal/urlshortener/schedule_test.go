@@ -0,0 +1,164 @@
+package urlshortener_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"time"
+	"url-shortener/cmd/urlshortener/internal/urlshortener"
+	"url-shortener/cmd/urlshortener/internal/urlshortener/mocks"
+	"url-shortener/pkg/repository/firestore/urls"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Scheduled URLs", func() {
+	const (
+		shortURL    = "short-url"
+		longURL     = "https://long.com"
+		fallbackURL = "https://soon.com"
+	)
+
+	var (
+		mockCtrl       *gomock.Controller
+		mockController *mocks.MockController
+		presenter      *urlshortener.Presenter
+		past           time.Time
+		future         time.Time
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockController = mocks.NewMockController(mockCtrl)
+		presenter = urlshortener.NewPresenter(mockController, urlshortener.Config{RedirectType: http.StatusMovedPermanently})
+		past = time.Now().Add(-time.Hour)
+		future = time.Now().Add(time.Hour)
+	})
+
+	visit := func(url urls.URL) *httptest.ResponseRecorder {
+		mockController.EXPECT().GetByShortURL(gomock.Any(), shortURL).Return(url, nil)
+		recorder := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(recorder)
+		ctx.Request = httptest.NewRequest(http.MethodGet, "/"+shortURL, nil)
+		ctx.Params = []gin.Param{{Key: "short_url", Value: shortURL}}
+		presenter.RedirectToLongURL(ctx)
+		return recorder
+	}
+
+	list := func(query string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(recorder)
+		ctx.Request = httptest.NewRequest(http.MethodGet, "/api/v1/urls/scheduled"+query, nil)
+		presenter.ListScheduled(ctx)
+		return recorder
+	}
+
+	When("url is not active yet and has a fallback url", func() {
+		It("should redirect temporarily to the fallback url", func() {
+			recorder := visit(urls.URL{LongURL: longURL, NotBefore: &future, FallbackURL: fallbackURL})
+			Expect(recorder.Code).To(Equal(http.StatusFound))
+			Expect(recorder.Header().Get("Location")).To(Equal(fallbackURL))
+			Expect(recorder.Header().Get("Cache-Control")).To(ContainSubstring("no-store"))
+		})
+	})
+
+	When("url is not active yet", func() {
+		It("should return http status not found", func() {
+			Expect(visit(urls.URL{LongURL: longURL, NotBefore: &future}).Code).To(Equal(http.StatusNotFound))
+		})
+	})
+
+	When("url has expired", func() {
+		It("should return http status gone", func() {
+			Expect(visit(urls.URL{LongURL: longURL, NotAfter: &past}).Code).To(Equal(http.StatusGone))
+		})
+	})
+
+	When("url is active and expires later", func() {
+		It("should redirect temporarily to the long url", func() {
+			recorder := visit(urls.URL{LongURL: longURL, NotBefore: &past, NotAfter: &future})
+			Expect(recorder.Code).To(Equal(http.StatusFound))
+			Expect(recorder.Header().Get("Location")).To(Equal(longURL))
+		})
+	})
+
+	When("listing with an invalid range", func() {
+		It("should return http status bad request", func() {
+			Expect(list("?from=yesterday").Code).To(Equal(http.StatusBadRequest))
+			Expect(list("?from=2030-01-02T00:00:00Z&to=2030-01-01T00:00:00Z").Code).To(Equal(http.StatusBadRequest))
+			Expect(list("?limit=0").Code).To(Equal(http.StatusBadRequest))
+			Expect(list("?limit=501").Code).To(Equal(http.StatusBadRequest))
+			Expect(list("?cursor=abc").Code).To(Equal(http.StatusBadRequest))
+		})
+	})
+
+	When("listing fails", func() {
+		BeforeEach(func() {
+			mockController.EXPECT().ListScheduled(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(urlshortener.Schedule{}, errors.New("err"))
+		})
+
+		It("should return http status internal server error", func() {
+			Expect(list("").Code).To(Equal(http.StatusInternalServerError))
+		})
+	})
+
+	When("listing succeeds", func() {
+		var from, to time.Time
+
+		BeforeEach(func() {
+			from = time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+			to = from.Add(24 * time.Hour)
+		})
+
+		expectPage := func(after urlshortener.ScheduleCursor, limit int, schedule urlshortener.Schedule) {
+			mockController.EXPECT().ListScheduled(gomock.Any(), from, to, after, limit).Return(schedule, nil)
+		}
+
+		It("should return the activating and deactivating urls", func() {
+			expectPage(urlshortener.ScheduleCursor{}, 100, urlshortener.Schedule{
+				Activating: []urls.Record{{ID: shortURL, URL: urls.URL{LongURL: longURL, NotBefore: &from}}},
+				Next:       urlshortener.ScheduleCursor{ActivatingDone: true, DeactivatingDone: true},
+			})
+
+			recorder := list("?from=2030-01-01T00:00:00Z&to=2030-01-02T00:00:00Z")
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+
+			var response struct {
+				Activating   []map[string]interface{} `json:"activating"`
+				Deactivating []map[string]interface{} `json:"deactivating"`
+			}
+			Expect(json.Unmarshal(recorder.Body.Bytes(), &response)).To(Succeed())
+			Expect(response.Activating).To(HaveLen(1))
+			Expect(response.Activating[0]["short_url"]).To(Equal(shortURL))
+			Expect(response.Activating[0]["not_before"]).To(Equal("2030-01-01T00:00:00Z"))
+			Expect(response.Deactivating).To(BeEmpty())
+		})
+
+		It("should page with the returned cursor until it is empty", func() {
+			next := urlshortener.ScheduleCursor{Activating: urls.Cursor{At: from, ID: shortURL}, DeactivatingDone: true}
+			expectPage(urlshortener.ScheduleCursor{}, 1, urlshortener.Schedule{
+				Activating: []urls.Record{{ID: shortURL, URL: urls.URL{LongURL: longURL, NotBefore: &from}}},
+				Next:       next,
+			})
+			expectPage(next, 1, urlshortener.Schedule{Next: urlshortener.ScheduleCursor{ActivatingDone: true, DeactivatingDone: true}})
+
+			var response struct {
+				Cursor string `json:"cursor"`
+			}
+			recorder := list("?from=2030-01-01T00:00:00Z&to=2030-01-02T00:00:00Z&limit=1")
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(json.Unmarshal(recorder.Body.Bytes(), &response)).To(Succeed())
+			Expect(response.Cursor).ToNot(BeEmpty())
+
+			recorder = list("?from=2030-01-01T00:00:00Z&to=2030-01-02T00:00:00Z&limit=1&cursor=" + url.QueryEscape(response.Cursor))
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(json.Unmarshal(recorder.Body.Bytes(), &response)).To(Succeed())
+			Expect(response.Cursor).To(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,159 @@
+package urlshortener
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"unicode/utf8"
+	"url-shortener/pkg/repository/firestore/urls"
+	"url-shortener/pkg/search"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultSearchLimit = 50
+	maxSearchLimit     = 200
+	maxTitleLength     = 200
+	maxDescription     = 1000
+)
+
+type metadataRequest struct {
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	Tags        []string `json:"tags"`
+	Folder      string   `json:"folder"`
+}
+
+type urlSummary struct {
+	ShortURL    string   `json:"short_url"`
+	Domain      string   `json:"domain,omitempty"`
+	LongURL     string   `json:"long_url"`
+	Title       string   `json:"title,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	Folder      string   `json:"folder,omitempty"`
+}
+
+type searchResponse struct {
+	Results []urlSummary `json:"results"`
+}
+
+// ListURLs returns the URLs filtered by the tag and folder query params, ordered by short URL
+// Repeated tag params must all match, a folder matches its subfolders too
+func (p *Presenter) ListURLs(ctx *gin.Context) {
+	p.search(ctx, "")
+}
+
+// SearchURLs returns the URLs whose long URL, title, description or tags contain words starting with all words of q
+// It accepts the filters of ListURLs too
+func (p *Presenter) SearchURLs(ctx *gin.Context) {
+	text := ctx.Query("q")
+	if len(search.Words(text)) == 0 {
+		ctx.JSON(http.StatusBadRequest, "Missing search query q")
+		return
+	}
+
+	p.search(ctx, text)
+}
+
+func (p *Presenter) search(ctx *gin.Context, text string) {
+	query := search.Query{Text: text, Limit: defaultSearchLimit}
+	if limit := ctx.Query("limit"); limit != "" {
+		var err error
+		query.Limit, err = strconv.Atoi(limit)
+		if err != nil || query.Limit < 1 || query.Limit > maxSearchLimit {
+			ctx.JSON(http.StatusBadRequest, fmt.Sprintf("Limit must be between 1 and %d", maxSearchLimit))
+			return
+		}
+	}
+
+	var err error
+	if query.Tags, err = search.NormalizeTags(ctx.QueryArray("tag")); err != nil {
+		ctx.JSON(http.StatusBadRequest, fmt.Sprintf("Invalid tags: %v", err))
+		return
+	}
+
+	if query.Folder, err = search.NormalizeFolder(ctx.Query("folder")); err != nil {
+		ctx.JSON(http.StatusBadRequest, fmt.Sprintf("Invalid folder: %v", err))
+		return
+	}
+
+	docs, err := p.controllerOf(ctx).Search(ctx, query)
+	if err != nil {
+		logrus.Errorf("Failed to search urls: %v", err)
+		ctx.JSON(http.StatusInternalServerError, "Error occured while searching URLs")
+		return
+	}
+
+	response := searchResponse{Results: make([]urlSummary, len(docs))}
+	for i, doc := range docs {
+		domain, code := urls.SplitKey(doc.ID)
+		response.Results[i] = urlSummary{
+			ShortURL:    code,
+			Domain:      domain,
+			LongURL:     doc.LongURL,
+			Title:       doc.Title,
+			Description: doc.Description,
+			Tags:        doc.Tags,
+			Folder:      doc.Folder,
+		}
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// UpdateMetadata replaces the title, description, tags and folder of a short URL
+func (p *Presenter) UpdateMetadata(ctx *gin.Context) {
+	var request metadataRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	metadata, err := toMetadata(request)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, fmt.Sprintf("Invalid metadata: %v", err))
+		return
+	}
+
+	url, err := p.controllerOf(ctx).UpdateMetadata(ctx, p.urlKey(ctx), metadata)
+	if err != nil {
+		var notFoundErr urls.NotFoundError
+		if errors.As(err, &notFoundErr) {
+			ctx.JSON(http.StatusNotFound, "URL does not exist")
+			return
+		}
+
+		logrus.Errorf("Failed to update metadata: %v", err)
+		ctx.JSON(http.StatusInternalServerError, "Error occured while updating metadata")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, toDetails(ctx.Param("short_url"), url))
+}
+
+// toMetadata validates the metadata of a request and normalizes its tags and folder
+func toMetadata(request metadataRequest) (urls.Metadata, error) {
+	if utf8.RuneCountInString(request.Title) > maxTitleLength {
+		return urls.Metadata{}, fmt.Errorf("title must not be longer than %d characters", maxTitleLength)
+	}
+
+	if utf8.RuneCountInString(request.Description) > maxDescription {
+		return urls.Metadata{}, fmt.Errorf("description must not be longer than %d characters", maxDescription)
+	}
+
+	tags, err := search.NormalizeTags(request.Tags)
+	if err != nil {
+		return urls.Metadata{}, fmt.Errorf("invalid tags: %w", err)
+	}
+
+	folder, err := search.NormalizeFolder(request.Folder)
+	if err != nil {
+		return urls.Metadata{}, fmt.Errorf("invalid folder: %w", err)
+	}
+
+	return urls.Metadata{Title: request.Title, Description: request.Description, Tags: tags, Folder: folder}, nil
+}
@@ -0,0 +1,142 @@
+package urlshortener_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"url-shortener/cmd/urlshortener/internal/urlshortener"
+	"url-shortener/cmd/urlshortener/internal/urlshortener/mocks"
+	"url-shortener/pkg/repository/firestore/urls"
+	"url-shortener/pkg/search"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Search", func() {
+	const shortURL = "short-url"
+
+	var (
+		mockCtrl       *gomock.Controller
+		mockController *mocks.MockController
+		engine         *gin.Engine
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockController = mocks.NewMockController(mockCtrl)
+		presenter := urlshortener.NewPresenter(mockController, urlshortener.Config{})
+
+		engine = gin.New()
+		engine.POST("/api/v1/urls", presenter.CreateURL)
+		engine.GET("/api/v1/urls", presenter.ListURLs)
+		engine.GET("/api/v1/urls/search", presenter.SearchURLs)
+		engine.PUT("/api/v1/urls/:short_url/metadata", presenter.UpdateMetadata)
+	})
+
+	serve := func(method, path, body string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		engine.ServeHTTP(recorder, httptest.NewRequest(method, path, bytes.NewBufferString(body)))
+		return recorder
+	}
+
+	When("listing urls by tag and folder", func() {
+		BeforeEach(func() {
+			query := search.Query{Tags: []string{"marketing", "q1"}, Folder: "campaigns/spring", Limit: 50}
+			mockController.EXPECT().Search(gomock.Any(), query).Return([]search.Document{
+				{ID: shortURL + "@go.example.com", LongURL: "https://example.com", Title: "Launch", Tags: []string{"marketing", "q1"}, Folder: "campaigns/spring"},
+			}, nil)
+		})
+
+		It("should return the matching urls", func() {
+			recorder := serve(http.MethodGet, "/api/v1/urls?tag=Marketing&tag=q1&folder=campaigns/spring/", "")
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+
+			var response struct {
+				Results []map[string]interface{} `json:"results"`
+			}
+			Expect(json.Unmarshal(recorder.Body.Bytes(), &response)).To(Succeed())
+			Expect(response.Results).To(HaveLen(1))
+			Expect(response.Results[0]).To(HaveKeyWithValue("short_url", shortURL))
+			Expect(response.Results[0]).To(HaveKeyWithValue("domain", "go.example.com"))
+			Expect(response.Results[0]).To(HaveKeyWithValue("title", "Launch"))
+		})
+	})
+
+	When("searching urls", func() {
+		BeforeEach(func() {
+			mockController.EXPECT().Search(gomock.Any(), search.Query{Text: "spring launch", Limit: 10}).Return(nil, nil)
+		})
+
+		It("should return an empty result list", func() {
+			recorder := serve(http.MethodGet, "/api/v1/urls/search?q=spring+launch&limit=10", "")
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Body.String()).To(Equal(`{"results":[]}`))
+		})
+	})
+
+	When("searching urls fails", func() {
+		BeforeEach(func() {
+			mockController.EXPECT().Search(gomock.Any(), gomock.Any()).Return(nil, errors.New("err"))
+		})
+
+		It("should return http status internal server error", func() {
+			Expect(serve(http.MethodGet, "/api/v1/urls/search?q=spring", "").Code).To(Equal(http.StatusInternalServerError))
+		})
+	})
+
+	When("searching urls with an invalid request", func() {
+		It("should return http status bad request", func() {
+			Expect(serve(http.MethodGet, "/api/v1/urls/search", "").Code).To(Equal(http.StatusBadRequest))
+			Expect(serve(http.MethodGet, "/api/v1/urls/search?q=spring&limit=1000", "").Code).To(Equal(http.StatusBadRequest))
+			Expect(serve(http.MethodGet, "/api/v1/urls?tag=two+words", "").Code).To(Equal(http.StatusBadRequest))
+		})
+	})
+
+	When("creating an url with metadata", func() {
+		BeforeEach(func() {
+			mockController.EXPECT().CreateURL(gomock.Any(), urls.URL{
+				LongURL:  "https://example.com",
+				Metadata: urls.Metadata{Title: "Launch", Tags: []string{"marketing"}, Folder: "campaigns"},
+			}).Return(shortURL, nil)
+		})
+
+		It("should store the normalized metadata", func() {
+			body := `{"long_url": "https://example.com", "title": "Launch", "tags": ["Marketing"], "folder": "Campaigns"}`
+			Expect(serve(http.MethodPost, "/api/v1/urls", body).Code).To(Equal(http.StatusCreated))
+		})
+	})
+
+	When("updating metadata of an url", func() {
+		BeforeEach(func() {
+			metadata := urls.Metadata{Title: "Launch", Tags: []string{"marketing"}}
+			mockController.EXPECT().UpdateMetadata(gomock.Any(), shortURL, metadata).Return(urls.URL{LongURL: "https://example.com", Metadata: metadata}, nil)
+		})
+
+		It("should return the updated url", func() {
+			recorder := serve(http.MethodPut, "/api/v1/urls/"+shortURL+"/metadata", `{"title": "Launch", "tags": ["marketing"]}`)
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Body.String()).To(ContainSubstring(`"tags":["marketing"]`))
+		})
+	})
+
+	When("updating metadata of an url that does not exist", func() {
+		BeforeEach(func() {
+			mockController.EXPECT().UpdateMetadata(gomock.Any(), shortURL, gomock.Any()).Return(urls.URL{}, urls.NewNotFoundError())
+		})
+
+		It("should return http status not found", func() {
+			Expect(serve(http.MethodPut, "/api/v1/urls/"+shortURL+"/metadata", `{}`).Code).To(Equal(http.StatusNotFound))
+		})
+	})
+
+	When("updating metadata with an invalid folder", func() {
+		It("should return http status bad request", func() {
+			Expect(serve(http.MethodPut, "/api/v1/urls/"+shortURL+"/metadata", `{"folder": "a//b"}`).Code).To(Equal(http.StatusBadRequest))
+		})
+	})
+})
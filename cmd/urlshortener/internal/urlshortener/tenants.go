@@ -0,0 +1,376 @@
+package urlshortener
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sync"
+	"time"
+	"url-shortener/pkg/repository/firestore/domains"
+	"url-shortener/pkg/repository/firestore/tenants"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+//go:generate mockgen --source=tenants.go --destination mocks/tenants.go --package mocks
+
+type TenantStore interface {
+	AddTenant(ctx context.Context, tenant tenants.Tenant) error
+	SetSuspended(ctx context.Context, id string, suspended bool) error
+	ListTenants(ctx context.Context) ([]tenants.Tenant, error)
+}
+
+// Namespaces provides the isolated storage of tenants
+type Namespaces interface {
+	// Init prepares the namespace of a new tenant, it must keep the data of a namespace which is already initialized
+	Init(ctx context.Context, tenant tenants.Tenant) error
+	// Controller returns a controller working on the namespace of the tenant
+	Controller(tenant tenants.Tenant) Controller
+}
+
+const (
+	// tenantKey is the gin context key of the tenant resolved for the request
+	tenantKey = "tenant"
+	// tenantAuthKey is the gin context key set if the tenant was authenticated by its API key
+	tenantAuthKey = "tenant_authenticated"
+	tenantHeader  = "X-Tenant-Key"
+)
+
+var tenantPattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+// TenantRegistry authenticates and manages tenants
+// The tenants are kept in memory and reloaded from the store after ttl,
+// changes made through the registry apply immediately on this instance
+type TenantRegistry struct {
+	store      TenantStore
+	namespaces Namespaces
+	ttl        time.Duration
+	mu         sync.Mutex
+	tenants    map[string]tenants.Tenant
+	loadedAt   time.Time
+}
+
+// NewTenantRegistry is a constructor function
+func NewTenantRegistry(store TenantStore, namespaces Namespaces, ttl time.Duration) *TenantRegistry {
+	return &TenantRegistry{
+		store:      store,
+		namespaces: namespaces,
+		ttl:        ttl,
+	}
+}
+
+// Get returns the tenant by id
+// If the tenants cannot be reloaded, the previously loaded ones are used
+func (r *TenantRegistry) Get(ctx context.Context, id string) (tenants.Tenant, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.refresh(ctx)
+	tenant, ok := r.tenants[id]
+	return tenant, ok
+}
+
+// Authenticate returns the tenant of the API key
+func (r *TenantRegistry) Authenticate(ctx context.Context, key string) (tenants.Tenant, bool) {
+	hash := []byte(tenants.HashKey(key))
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.refresh(ctx)
+	for _, tenant := range r.tenants {
+		if subtle.ConstantTimeCompare(hash, []byte(tenant.KeyHash)) == 1 {
+			return tenant, true
+		}
+	}
+
+	return tenants.Tenant{}, false
+}
+
+// Create adds a tenant with an empty namespace and returns it with its API key
+// Only the hash of the key is stored, so it cannot be retrieved again.
+// The namespace is initialized before the tenant is added, so a failed creation can be retried
+func (r *TenantRegistry) Create(ctx context.Context, tenant tenants.Tenant) (tenants.Tenant, string, error) {
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		return tenants.Tenant{}, "", fmt.Errorf("failed to generate api key: %w", err)
+	}
+
+	key := base64.RawURLEncoding.EncodeToString(random)
+	tenant.KeyHash = tenants.HashKey(key)
+	tenant.CreatedAt = time.Now().UTC()
+	if err := r.namespaces.Init(ctx, tenant); err != nil {
+		return tenants.Tenant{}, "", fmt.Errorf("failed to initialize namespace: %w", err)
+	}
+
+	if err := r.store.AddTenant(ctx, tenant); err != nil {
+		return tenants.Tenant{}, "", err
+	}
+
+	r.invalidate()
+	return tenant, key, nil
+}
+
+// Suspend suspends or resumes a tenant, a suspended tenant can neither create nor serve links
+func (r *TenantRegistry) Suspend(ctx context.Context, id string, suspended bool) error {
+	if err := r.store.SetSuspended(ctx, id, suspended); err != nil {
+		return err
+	}
+
+	r.invalidate()
+	return nil
+}
+
+// List returns all tenants
+func (r *TenantRegistry) List(ctx context.Context) ([]tenants.Tenant, error) {
+	return r.store.ListTenants(ctx)
+}
+
+// Controller returns the controller of the tenant namespace
+func (r *TenantRegistry) Controller(tenant tenants.Tenant) Controller {
+	return r.namespaces.Controller(tenant)
+}
+
+// refresh reloads the tenants once they expired, the caller must hold the lock
+func (r *TenantRegistry) refresh(ctx context.Context) {
+	if r.tenants != nil && time.Since(r.loadedAt) <= r.ttl {
+		return
+	}
+
+	list, err := r.store.ListTenants(ctx)
+	r.loadedAt = time.Now()
+	if err != nil {
+		logrus.Errorf("Failed to load tenants: %v", err)
+		if r.tenants == nil {
+			r.tenants = map[string]tenants.Tenant{}
+		}
+
+		return
+	}
+
+	r.tenants = make(map[string]tenants.Tenant, len(list))
+	for _, tenant := range list {
+		r.tenants[tenant.ID] = tenant
+	}
+}
+
+func (r *TenantRegistry) invalidate() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.tenants = nil
+}
+
+type createTenantRequest struct {
+	ID           string `json:"id" binding:"required"`
+	Name         string `json:"name"`
+	DailyQuota   int64  `json:"daily_quota"`
+	MonthlyQuota int64  `json:"monthly_quota"`
+}
+
+type createTenantResponse struct {
+	tenants.Tenant
+	// APIKey is only returned when the tenant is created
+	APIKey string `json:"api_key"`
+}
+
+// ResolveTenant resolves the tenant of the request from the X-Tenant-Key header, or else from the owner of its domain
+// A key is accepted on the domains of its tenant and on domains without owner, so tenants can call the API on the
+// hosts of the service. Requests with an invalid key, for a domain of another tenant or of a suspended tenant
+// are rejected. Requests of the default namespace pass through
+func (p *Presenter) ResolveTenant(ctx *gin.Context) {
+	if p.config.Tenants == nil {
+		ctx.Next()
+		return
+	}
+
+	owner := p.domain(ctx).Tenant
+	var tenant tenants.Tenant
+	if key := ctx.GetHeader(tenantHeader); key != "" {
+		var ok bool
+		if tenant, ok = p.config.Tenants.Authenticate(ctx, key); !ok {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, "Invalid tenant key")
+			return
+		}
+
+		if owner != "" && tenant.ID != owner {
+			ctx.AbortWithStatusJSON(http.StatusForbidden, "Domain does not belong to the tenant")
+			return
+		}
+
+		ctx.Set(tenantAuthKey, true)
+	} else if owner != "" {
+		var ok bool
+		if tenant, ok = p.config.Tenants.Get(ctx, owner); !ok {
+			ctx.AbortWithStatusJSON(http.StatusNotFound, "Tenant does not exist")
+			return
+		}
+	} else {
+		ctx.Next()
+		return
+	}
+
+	if tenant.Suspended {
+		ctx.AbortWithStatusJSON(http.StatusForbidden, "Tenant is suspended")
+		return
+	}
+
+	ctx.Set(tenantKey, tenant)
+	ctx.Next()
+}
+
+// RequireTenantKey rejects API requests to a tenant domain which were not authenticated by the tenant key
+func (p *Presenter) RequireTenantKey(ctx *gin.Context) {
+	if _, ok := ctx.Get(tenantKey); ok && !ctx.GetBool(tenantAuthKey) {
+		ctx.AbortWithStatusJSON(http.StatusUnauthorized, "Tenant key is required")
+		return
+	}
+
+	ctx.Next()
+}
+
+// RequireNamespaceKey rejects requests which change links, webhooks or read the change feed of a namespace
+// without its key, the tenant key in a tenant namespace and the admin API key in the default namespace
+func (p *Presenter) RequireNamespaceKey(ctx *gin.Context) {
+	if _, ok := ctx.Get(tenantKey); ok {
+		p.RequireTenantKey(ctx)
+		return
+	}
+
+	p.RequireAdmin(ctx)
+}
+
+// controllerOf returns the controller of the tenant of the request, or the default one
+func (p *Presenter) controllerOf(ctx *gin.Context) Controller {
+	if value, ok := ctx.Get(tenantKey); ok {
+		return p.config.Tenants.Controller(value.(tenants.Tenant))
+	}
+
+	return p.controller
+}
+
+// namespace returns the id of the tenant of the request, empty for the default namespace
+func (p *Presenter) namespace(ctx *gin.Context) string {
+	if value, ok := ctx.Get(tenantKey); ok {
+		return value.(tenants.Tenant).ID
+	}
+
+	return ""
+}
+
+// foreignDomain answers the request if it would create links of a tenant on a domain the tenant does not own,
+// visits to such links are served from the namespace of the owner, so they could never be followed
+func (p *Presenter) foreignDomain(ctx *gin.Context, domain domains.Domain) bool {
+	if domain.Tenant == p.namespace(ctx) {
+		return false
+	}
+
+	ctx.JSON(http.StatusBadRequest, "Links of a tenant need one of its domains")
+	return true
+}
+
+// tenantExists reports whether the tenant exists, tenants never exist if they are not configured
+func (p *Presenter) tenantExists(ctx *gin.Context, id string) bool {
+	if p.config.Tenants == nil {
+		return false
+	}
+
+	_, ok := p.config.Tenants.Get(ctx, id)
+	return ok
+}
+
+// quotaExceeded answers the request if the error is caused by an exceeded quota
+func quotaExceeded(ctx *gin.Context, err error) bool {
+	var quotaErr tenants.QuotaExceededError
+	if !errors.As(err, &quotaErr) {
+		return false
+	}
+
+	ctx.JSON(http.StatusTooManyRequests, fmt.Sprintf("The %s quota of %d links is exceeded", quotaErr.Period, quotaErr.Limit))
+	return true
+}
+
+// ListTenants returns all tenants
+func (p *Presenter) ListTenants(ctx *gin.Context) {
+	list, err := p.config.Tenants.List(ctx)
+	if err != nil {
+		logrus.Errorf("Failed to list tenants: %v", err)
+		ctx.JSON(http.StatusInternalServerError, "Error occured while listing tenants")
+		return
+	}
+
+	if list == nil {
+		list = []tenants.Tenant{}
+	}
+
+	ctx.JSON(http.StatusOK, list)
+}
+
+// CreateTenant creates a tenant with its quotas and returns its API key
+func (p *Presenter) CreateTenant(ctx *gin.Context) {
+	var request createTenantRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if !tenantPattern.MatchString(request.ID) {
+		ctx.JSON(http.StatusBadRequest, "Invalid tenant id")
+		return
+	}
+
+	if request.DailyQuota < 0 || request.MonthlyQuota < 0 {
+		ctx.JSON(http.StatusBadRequest, "Quotas must not be negative")
+		return
+	}
+
+	tenant, key, err := p.config.Tenants.Create(ctx, tenants.Tenant{
+		ID:           request.ID,
+		Name:         request.Name,
+		DailyQuota:   request.DailyQuota,
+		MonthlyQuota: request.MonthlyQuota,
+	})
+	if err != nil {
+		var alreadyExistsErr tenants.AlreadyExistsError
+		if errors.As(err, &alreadyExistsErr) {
+			ctx.JSON(http.StatusConflict, "Tenant already exists")
+			return
+		}
+
+		logrus.Errorf("Failed to create tenant: %v", err)
+		ctx.JSON(http.StatusInternalServerError, "Error occured while creating tenant")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, createTenantResponse{Tenant: tenant, APIKey: key})
+}
+
+// SuspendTenant suspends a tenant, its links stop redirecting until it is resumed
+func (p *Presenter) SuspendTenant(ctx *gin.Context) {
+	p.suspendTenant(ctx, true)
+}
+
+// ResumeTenant resumes a suspended tenant
+func (p *Presenter) ResumeTenant(ctx *gin.Context) {
+	p.suspendTenant(ctx, false)
+}
+
+func (p *Presenter) suspendTenant(ctx *gin.Context, suspended bool) {
+	if err := p.config.Tenants.Suspend(ctx, ctx.Param("tenant"), suspended); err != nil {
+		var notFoundErr tenants.NotFoundError
+		if errors.As(err, &notFoundErr) {
+			ctx.JSON(http.StatusNotFound, "Tenant does not exist")
+			return
+		}
+
+		logrus.Errorf("Failed to update tenant: %v", err)
+		ctx.JSON(http.StatusInternalServerError, "Error occured while updating tenant")
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
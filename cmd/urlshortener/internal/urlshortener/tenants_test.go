@@ -0,0 +1,358 @@
+package urlshortener_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"time"
+	"url-shortener/cmd/urlshortener/internal/urlshortener"
+	"url-shortener/cmd/urlshortener/internal/urlshortener/mocks"
+	"url-shortener/pkg/repository/firestore/domains"
+	"url-shortener/pkg/repository/firestore/tenants"
+	"url-shortener/pkg/repository/firestore/urls"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Tenants", func() {
+	const (
+		shortURL  = "short-url"
+		brand     = "go.acme.com"
+		tenantKey = "tenant-secret"
+		adminKey  = "admin-secret"
+	)
+
+	var (
+		mockCtrl             *gomock.Controller
+		mockController       *mocks.MockController
+		mockTenantController *mocks.MockController
+		mockDomainStore      *mocks.MockDomainStore
+		mockTenantStore      *mocks.MockTenantStore
+		mockNamespaces       *mocks.MockNamespaces
+		registry             *urlshortener.TenantRegistry
+		engine               *gin.Engine
+		tenant               tenants.Tenant
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockController = mocks.NewMockController(mockCtrl)
+		mockTenantController = mocks.NewMockController(mockCtrl)
+		mockDomainStore = mocks.NewMockDomainStore(mockCtrl)
+		mockTenantStore = mocks.NewMockTenantStore(mockCtrl)
+		mockNamespaces = mocks.NewMockNamespaces(mockCtrl)
+		registry = urlshortener.NewTenantRegistry(mockTenantStore, mockNamespaces, time.Hour)
+		presenter := urlshortener.NewPresenter(mockController, urlshortener.Config{
+			RedirectType: http.StatusFound,
+			Domains:      urlshortener.NewDomainRegistry(mockDomainStore, time.Hour),
+			Tenants:      registry,
+			AdminAPIKey:  adminKey,
+		})
+		tenant = tenants.Tenant{ID: "acme", KeyHash: tenants.HashKey(tenantKey)}
+
+		mockDomainStore.EXPECT().ListDomains(gomock.Any()).Return([]domains.Domain{{Name: brand, Tenant: "acme"}, {Name: "go.other.com", Tenant: "other"}}, nil).AnyTimes()
+		mockNamespaces.EXPECT().Controller(gomock.Any()).Return(mockTenantController).AnyTimes()
+
+		engine = gin.New()
+		public := engine.Group("", presenter.ResolveTenant)
+		public.GET("/:short_url", presenter.RedirectToLongURL)
+		public.POST("/", presenter.RequireTenantKey, presenter.CreateShortURL)
+		public.POST("/api/v1/urls", presenter.RequireTenantKey, presenter.CreateURL)
+		public.DELETE("/api/v1/urls/:short_url", presenter.RequireNamespaceKey, presenter.DeleteURL)
+
+		admin := engine.Group("/api/v1/admin", presenter.RequireAdmin)
+		admin.GET("/tenants", presenter.ListTenants)
+		admin.POST("/tenants", presenter.CreateTenant)
+		admin.POST("/tenants/:tenant/suspend", presenter.SuspendTenant)
+		admin.POST("/domains", presenter.RegisterDomain)
+	})
+
+	serve := func(method, host, path, key, body string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		request.Host = host
+		if key != "" {
+			request.Header.Set("X-Tenant-Key", key)
+		}
+
+		engine.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	serveAdmin := func(method, path, body string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		request.Header.Set("X-API-Key", adminKey)
+		engine.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	When("authenticating tenants", func() {
+		BeforeEach(func() {
+			mockTenantStore.EXPECT().ListTenants(gomock.Any()).Return([]tenants.Tenant{tenant}, nil).Times(1)
+		})
+
+		It("should find the tenant of the key and load the tenants once", func() {
+			ctx := context.Background()
+			authenticated, ok := registry.Authenticate(ctx, tenantKey)
+			Expect(ok).To(BeTrue())
+			Expect(authenticated).To(Equal(tenant))
+
+			_, ok = registry.Authenticate(ctx, "wrong")
+			Expect(ok).To(BeFalse())
+		})
+	})
+
+	When("visiting a short url on a tenant domain", func() {
+		BeforeEach(func() {
+			mockTenantStore.EXPECT().ListTenants(gomock.Any()).Return([]tenants.Tenant{tenant}, nil)
+			mockTenantController.EXPECT().GetByShortURL(gomock.Any(), shortURL+"@"+brand).Return(urls.URL{LongURL: "https://acme.com"}, nil)
+		})
+
+		It("should look it up in the tenant namespace", func() {
+			recorder := serve(http.MethodGet, brand, "/"+shortURL, "", "")
+			Expect(recorder.Code).To(Equal(http.StatusFound))
+			Expect(recorder.Header().Get("Location")).To(Equal("https://acme.com"))
+		})
+	})
+
+	When("visiting a short url of a suspended tenant", func() {
+		BeforeEach(func() {
+			tenant.Suspended = true
+			mockTenantStore.EXPECT().ListTenants(gomock.Any()).Return([]tenants.Tenant{tenant}, nil)
+		})
+
+		It("should return http status forbidden", func() {
+			Expect(serve(http.MethodGet, brand, "/"+shortURL, "", "").Code).To(Equal(http.StatusForbidden))
+		})
+	})
+
+	When("creating a short url on a tenant domain without the tenant key", func() {
+		BeforeEach(func() {
+			mockTenantStore.EXPECT().ListTenants(gomock.Any()).Return([]tenants.Tenant{tenant}, nil)
+		})
+
+		It("should return http status unauthorized", func() {
+			Expect(serve(http.MethodPost, brand, "/", "", "https://acme.com").Code).To(Equal(http.StatusUnauthorized))
+		})
+	})
+
+	When("creating a short url with an invalid tenant key", func() {
+		BeforeEach(func() {
+			mockTenantStore.EXPECT().ListTenants(gomock.Any()).Return([]tenants.Tenant{tenant}, nil)
+		})
+
+		It("should return http status unauthorized", func() {
+			Expect(serve(http.MethodPost, brand, "/", "wrong", "https://acme.com").Code).To(Equal(http.StatusUnauthorized))
+		})
+	})
+
+	When("creating a short url with the tenant key on a domain of the default namespace", func() {
+		BeforeEach(func() {
+			mockTenantStore.EXPECT().ListTenants(gomock.Any()).Return([]tenants.Tenant{tenant}, nil)
+		})
+
+		It("should return http status bad request as the link could not be visited", func() {
+			Expect(serve(http.MethodPost, "sho.rt", "/", tenantKey, "https://acme.com").Code).To(Equal(http.StatusBadRequest))
+		})
+	})
+
+	When("creating a short url with the tenant key on a domain of another tenant", func() {
+		BeforeEach(func() {
+			mockTenantStore.EXPECT().ListTenants(gomock.Any()).Return([]tenants.Tenant{tenant}, nil)
+		})
+
+		It("should return http status forbidden", func() {
+			Expect(serve(http.MethodPost, "go.other.com", "/", tenantKey, "https://acme.com").Code).To(Equal(http.StatusForbidden))
+		})
+	})
+
+	When("creating a url with the tenant key on a domain of the default namespace", func() {
+		BeforeEach(func() {
+			mockTenantStore.EXPECT().ListTenants(gomock.Any()).Return([]tenants.Tenant{tenant}, nil)
+			mockTenantController.EXPECT().CreateURL(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, url urls.URL) (string, error) {
+				Expect(url.Domain).To(Equal(brand))
+				return shortURL, nil
+			})
+		})
+
+		It("should create it on the requested domain of the tenant", func() {
+			recorder := serve(http.MethodPost, "sho.rt", "/api/v1/urls", tenantKey, `{"long_url": "https://acme.com", "domain": "go.acme.com"}`)
+			Expect(recorder.Code).To(Equal(http.StatusCreated))
+		})
+	})
+
+	When("creating a short url with the tenant key", func() {
+		BeforeEach(func() {
+			mockTenantStore.EXPECT().ListTenants(gomock.Any()).Return([]tenants.Tenant{tenant}, nil)
+			mockTenantController.EXPECT().CreateShortURL(gomock.Any(), brand, "https://acme.com").Return(shortURL, nil)
+		})
+
+		It("should create it in the tenant namespace", func() {
+			Expect(serve(http.MethodPost, brand, "/", tenantKey, "https://acme.com").Code).To(Equal(http.StatusOK))
+		})
+	})
+
+	When("the tenant exceeded its quota", func() {
+		BeforeEach(func() {
+			mockTenantStore.EXPECT().ListTenants(gomock.Any()).Return([]tenants.Tenant{tenant}, nil)
+			mockTenantController.EXPECT().CreateURL(gomock.Any(), gomock.Any()).Return("", tenants.NewQuotaExceededError("daily", 10))
+		})
+
+		It("should return http status too many requests", func() {
+			recorder := serve(http.MethodPost, brand, "/api/v1/urls", tenantKey, `{"long_url": "https://acme.com"}`)
+			Expect(recorder.Code).To(Equal(http.StatusTooManyRequests))
+		})
+	})
+
+	When("creating a short url of the default namespace", func() {
+		BeforeEach(func() {
+			mockController.EXPECT().CreateShortURL(gomock.Any(), "", "https://example.com").Return(shortURL, nil)
+		})
+
+		It("should not require a tenant key", func() {
+			Expect(serve(http.MethodPost, "sho.rt", "/", "", "https://example.com").Code).To(Equal(http.StatusOK))
+		})
+	})
+
+	When("deleting a short url of the default namespace without the admin key", func() {
+		It("should return http status unauthorized", func() {
+			Expect(serve(http.MethodDelete, "sho.rt", "/api/v1/urls/"+shortURL, "", "").Code).To(Equal(http.StatusUnauthorized))
+		})
+	})
+
+	When("deleting a short url of the default namespace with the admin key", func() {
+		BeforeEach(func() {
+			mockController.EXPECT().DeleteURL(gomock.Any(), shortURL).Return(nil)
+		})
+
+		It("should delete it", func() {
+			Expect(serveAdmin(http.MethodDelete, "/api/v1/urls/"+shortURL, "").Code).To(Equal(http.StatusNoContent))
+		})
+	})
+
+	When("deleting a short url on a tenant domain with the tenant key", func() {
+		BeforeEach(func() {
+			mockTenantStore.EXPECT().ListTenants(gomock.Any()).Return([]tenants.Tenant{tenant}, nil)
+			mockTenantController.EXPECT().DeleteURL(gomock.Any(), shortURL+"@"+brand).Return(nil)
+		})
+
+		It("should delete it from the tenant namespace", func() {
+			Expect(serve(http.MethodDelete, brand, "/api/v1/urls/"+shortURL, tenantKey, "").Code).To(Equal(http.StatusNoContent))
+		})
+	})
+
+	When("creating a tenant", func() {
+		BeforeEach(func() {
+			gomock.InOrder(
+				mockNamespaces.EXPECT().Init(gomock.Any(), gomock.Any()).Return(nil),
+				mockTenantStore.EXPECT().AddTenant(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, created tenants.Tenant) error {
+					Expect(created.ID).To(Equal("acme"))
+					Expect(created.DailyQuota).To(Equal(int64(100)))
+					Expect(created.KeyHash).ToNot(BeEmpty())
+					return nil
+				}),
+			)
+		})
+
+		It("should return its api key once", func() {
+			recorder := serveAdmin(http.MethodPost, "/api/v1/admin/tenants", `{"id": "acme", "name": "Acme", "daily_quota": 100}`)
+			Expect(recorder.Code).To(Equal(http.StatusCreated))
+
+			var response struct {
+				ID     string `json:"id"`
+				APIKey string `json:"api_key"`
+			}
+			Expect(json.Unmarshal(recorder.Body.Bytes(), &response)).To(Succeed())
+			Expect(response.ID).To(Equal("acme"))
+			Expect(response.APIKey).ToNot(BeEmpty())
+			Expect(recorder.Body.String()).ToNot(ContainSubstring("key_hash"))
+		})
+	})
+
+	When("creating a tenant with an invalid id", func() {
+		It("should return http status bad request", func() {
+			Expect(serveAdmin(http.MethodPost, "/api/v1/admin/tenants", `{"id": "Acme Corp"}`).Code).To(Equal(http.StatusBadRequest))
+		})
+	})
+
+	When("creating a tenant that already exists", func() {
+		BeforeEach(func() {
+			mockNamespaces.EXPECT().Init(gomock.Any(), gomock.Any()).Return(nil)
+			mockTenantStore.EXPECT().AddTenant(gomock.Any(), gomock.Any()).Return(tenants.NewAlreadyExistsError())
+		})
+
+		It("should return http status conflict", func() {
+			Expect(serveAdmin(http.MethodPost, "/api/v1/admin/tenants", `{"id": "acme"}`).Code).To(Equal(http.StatusConflict))
+		})
+	})
+
+	When("initializing the namespace of a new tenant fails", func() {
+		BeforeEach(func() {
+			mockNamespaces.EXPECT().Init(gomock.Any(), gomock.Any()).Return(errors.New("unavailable"))
+		})
+
+		It("should return http status internal server error without adding the tenant", func() {
+			Expect(serveAdmin(http.MethodPost, "/api/v1/admin/tenants", `{"id": "acme"}`).Code).To(Equal(http.StatusInternalServerError))
+		})
+	})
+
+	When("suspending a tenant that does not exist", func() {
+		BeforeEach(func() {
+			mockTenantStore.EXPECT().SetSuspended(gomock.Any(), "unknown", true).Return(tenants.NewNotFoundError())
+		})
+
+		It("should return http status not found", func() {
+			Expect(serveAdmin(http.MethodPost, "/api/v1/admin/tenants/unknown/suspend", "").Code).To(Equal(http.StatusNotFound))
+		})
+	})
+
+	When("suspending a tenant", func() {
+		BeforeEach(func() {
+			gomock.InOrder(
+				mockTenantStore.EXPECT().ListTenants(gomock.Any()).Return([]tenants.Tenant{tenant}, nil),
+				mockTenantStore.EXPECT().SetSuspended(gomock.Any(), "acme", true).Return(nil),
+				mockTenantStore.EXPECT().ListTenants(gomock.Any()).Return([]tenants.Tenant{{ID: "acme", Suspended: true}}, nil),
+			)
+		})
+
+		It("should apply immediately", func() {
+			ctx := context.Background()
+			_, ok := registry.Get(ctx, "acme")
+			Expect(ok).To(BeTrue())
+
+			Expect(serveAdmin(http.MethodPost, "/api/v1/admin/tenants/acme/suspend", "").Code).To(Equal(http.StatusNoContent))
+			suspended, _ := registry.Get(ctx, "acme")
+			Expect(suspended.Suspended).To(BeTrue())
+		})
+	})
+
+	When("registering a domain of an unknown tenant", func() {
+		BeforeEach(func() {
+			mockTenantStore.EXPECT().ListTenants(gomock.Any()).Return([]tenants.Tenant{tenant}, nil)
+		})
+
+		It("should return http status bad request", func() {
+			recorder := serveAdmin(http.MethodPost, "/api/v1/admin/domains", `{"name": "go.other.com", "tenant": "other"}`)
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+		})
+	})
+
+	When("listing tenants", func() {
+		BeforeEach(func() {
+			mockTenantStore.EXPECT().ListTenants(gomock.Any()).Return(nil, nil)
+		})
+
+		It("should return an empty list", func() {
+			recorder := serveAdmin(http.MethodGet, "/api/v1/admin/tenants", "")
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Body.String()).To(Equal("[]"))
+		})
+	})
+})
@@ -0,0 +1,114 @@
+package urlshortener
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"url-shortener/pkg/repository/firestore/urls"
+	"url-shortener/pkg/variants"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	variantCookie = "short_url_variant"
+	// variantCookieMaxAge keeps a visitor on the same variant for 30 days
+	variantCookieMaxAge = 30 * 24 * 60 * 60
+)
+
+type variantsRequest struct {
+	Variants []variants.Variant `json:"variants" binding:"required"`
+}
+
+type variantStats struct {
+	variants.Variant
+	Clicks int64 `json:"clicks"`
+}
+
+type variantsResponse struct {
+	Variants []variantStats `json:"variants"`
+}
+
+// GetVariants returns the variants of a short URL with their click counts
+func (p *Presenter) GetVariants(ctx *gin.Context) {
+	url, err := p.controllerOf(ctx).GetStats(ctx, p.urlKey(ctx))
+	if err != nil {
+		var notFoundErr urls.NotFoundError
+		if errors.As(err, &notFoundErr) {
+			ctx.JSON(http.StatusNotFound, "URL does not exist")
+			return
+		}
+
+		logrus.Errorf("Failed to get by short url: %v", err)
+		ctx.JSON(http.StatusInternalServerError, "Error occured while getting short URL")
+		return
+	}
+
+	response := variantsResponse{Variants: make([]variantStats, len(url.Variants))}
+	for i, variant := range url.Variants {
+		response.Variants[i] = variantStats{Variant: variant, Clicks: url.VariantClicks[variant.Name]}
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// UpdateVariants replaces the variants of a short URL, e.g. to change their weights
+// Visitors keep their assigned variant as long as its weight is positive
+func (p *Presenter) UpdateVariants(ctx *gin.Context) {
+	var request variantsRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := variants.Validate(request.Variants); err != nil {
+		ctx.JSON(http.StatusBadRequest, fmt.Sprintf("Invalid variants: %v", err))
+		return
+	}
+
+	if err := p.controllerOf(ctx).UpdateVariants(ctx, p.urlKey(ctx), request.Variants); err != nil {
+		if invalidDestination(ctx, err) {
+			return
+		}
+
+		var notFoundErr urls.NotFoundError
+		if errors.As(err, &notFoundErr) {
+			ctx.JSON(http.StatusNotFound, "URL does not exist")
+			return
+		}
+
+		logrus.Errorf("Failed to update variants: %v", err)
+		ctx.JSON(http.StatusInternalServerError, "Error occured while updating variants")
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// assignVariant returns the variant the visitor is assigned to and counts the click
+// The assignment is kept in a cookie, a new one is picked if the cookie variant can no longer be assigned
+func (p *Presenter) assignVariant(ctx *gin.Context, shortURL string, urlVariants []variants.Variant) (variants.Variant, bool) {
+	if len(urlVariants) == 0 {
+		return variants.Variant{}, false
+	}
+
+	name, _ := ctx.Cookie(variantCookie)
+	variant, ok := variants.Find(urlVariants, name)
+	if !ok {
+		variant, ok = variants.Pick(urlVariants, nil)
+		if !ok {
+			return variants.Variant{}, false
+		}
+
+		secure := ctx.Request.TLS != nil || ctx.GetHeader("X-Forwarded-Proto") == "https"
+		ctx.SetSameSite(http.SameSiteLaxMode)
+		ctx.SetCookie(variantCookie, variant.Name, variantCookieMaxAge, "/"+ctx.Param("short_url"), "", secure, true)
+	}
+
+	if err := p.controllerOf(ctx).RecordVariantClick(ctx, shortURL, variant.Name); err != nil {
+		logrus.Errorf("Failed to record variant click: %v", err)
+	}
+
+	return variant, true
+}
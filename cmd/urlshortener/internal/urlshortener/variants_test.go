@@ -0,0 +1,161 @@
+package urlshortener_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"url-shortener/cmd/urlshortener/internal/urlshortener"
+	"url-shortener/cmd/urlshortener/internal/urlshortener/mocks"
+	"url-shortener/pkg/repository/firestore/urls"
+	"url-shortener/pkg/variants"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Variants", func() {
+	const (
+		shortURL = "short-url"
+		pageA    = "https://example.com/a"
+		pageB    = "https://example.com/b"
+	)
+
+	var (
+		mockCtrl       *gomock.Controller
+		mockController *mocks.MockController
+		presenter      *urlshortener.Presenter
+		url            urls.URL
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockController = mocks.NewMockController(mockCtrl)
+		presenter = urlshortener.NewPresenter(mockController, urlshortener.Config{RedirectType: http.StatusFound})
+		url = urls.URL{
+			LongURL: pageA,
+			Variants: []variants.Variant{
+				{Name: "a", Destination: pageA, Weight: 0},
+				{Name: "b", Destination: pageB, Weight: 1},
+			},
+			VariantClicks: map[string]int64{"a": 7},
+		}
+	})
+
+	visit := func(cookie string) *httptest.ResponseRecorder {
+		mockController.EXPECT().GetByShortURL(gomock.Any(), shortURL).Return(url, nil)
+		recorder := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(recorder)
+		ctx.Request = httptest.NewRequest(http.MethodGet, "/"+shortURL, nil)
+		if cookie != "" {
+			ctx.Request.AddCookie(&http.Cookie{Name: "short_url_variant", Value: cookie})
+		}
+		ctx.Params = []gin.Param{{Key: "short_url", Value: shortURL}}
+		presenter.RedirectToLongURL(ctx)
+		return recorder
+	}
+
+	When("a new visitor opens a split url", func() {
+		BeforeEach(func() {
+			mockController.EXPECT().RecordVariantClick(gomock.Any(), shortURL, "b").Return(nil)
+		})
+
+		It("should assign a weighted variant, remember it and redirect to it", func() {
+			recorder := visit("")
+			Expect(recorder.Code).To(Equal(http.StatusFound))
+			Expect(recorder.Header().Get("Location")).To(Equal(pageB))
+			Expect(recorder.Header().Get("Set-Cookie")).To(ContainSubstring("short_url_variant=b"))
+			Expect(recorder.Header().Get("Set-Cookie")).To(ContainSubstring("Path=/" + shortURL))
+		})
+	})
+
+	When("a returning visitor opens a split url", func() {
+		BeforeEach(func() {
+			url.Variants[0].Weight = 1
+			mockController.EXPECT().RecordVariantClick(gomock.Any(), shortURL, "a").Return(errors.New("err"))
+		})
+
+		It("should keep the assigned variant even if counting fails", func() {
+			recorder := visit("a")
+			Expect(recorder.Header().Get("Location")).To(Equal(pageA))
+			Expect(recorder.Header().Get("Set-Cookie")).To(BeEmpty())
+		})
+	})
+
+	When("the assigned variant has no weight anymore", func() {
+		BeforeEach(func() {
+			mockController.EXPECT().RecordVariantClick(gomock.Any(), shortURL, "b").Return(nil)
+		})
+
+		It("should assign another variant", func() {
+			Expect(visit("a").Header().Get("Location")).To(Equal(pageB))
+		})
+	})
+
+	When("getting variants", func() {
+		BeforeEach(func() {
+			mockController.EXPECT().GetStats(gomock.Any(), shortURL).Return(url, nil)
+		})
+
+		It("should return them with their clicks", func() {
+			recorder := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(recorder)
+			ctx.Request = httptest.NewRequest(http.MethodGet, "/api/v1/urls/"+shortURL+"/variants", nil)
+			ctx.Params = []gin.Param{{Key: "short_url", Value: shortURL}}
+			presenter.GetVariants(ctx)
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+
+			var response struct {
+				Variants []struct {
+					Name   string `json:"name"`
+					Weight int    `json:"weight"`
+					Clicks int64  `json:"clicks"`
+				} `json:"variants"`
+			}
+			Expect(json.Unmarshal(recorder.Body.Bytes(), &response)).To(Succeed())
+			Expect(response.Variants).To(HaveLen(2))
+			Expect(response.Variants[0].Name).To(Equal("a"))
+			Expect(response.Variants[0].Clicks).To(Equal(int64(7)))
+			Expect(response.Variants[1].Weight).To(Equal(1))
+		})
+	})
+
+	update := func(body string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(recorder)
+		ctx.Request = httptest.NewRequest(http.MethodPut, "/api/v1/urls/"+shortURL+"/variants", bytes.NewBufferString(body))
+		ctx.Params = []gin.Param{{Key: "short_url", Value: shortURL}}
+		presenter.UpdateVariants(ctx)
+		ctx.Writer.WriteHeaderNow()
+		return recorder
+	}
+
+	When("updating variants with invalid weights", func() {
+		It("should return http status bad request", func() {
+			Expect(update(`{"variants": [{"name": "a", "destination": "https://example.com/a", "weight": 0}]}`).Code).To(Equal(http.StatusBadRequest))
+		})
+	})
+
+	When("updating variants of an url that does not exist", func() {
+		BeforeEach(func() {
+			mockController.EXPECT().UpdateVariants(gomock.Any(), shortURL, gomock.Any()).Return(urls.NewNotFoundError())
+		})
+
+		It("should return http status not found", func() {
+			Expect(update(`{"variants": [{"name": "a", "destination": "https://example.com/a", "weight": 1}]}`).Code).To(Equal(http.StatusNotFound))
+		})
+	})
+
+	When("updating variants", func() {
+		BeforeEach(func() {
+			mockController.EXPECT().UpdateVariants(gomock.Any(), shortURL, []variants.Variant{{Name: "a", Destination: pageA, Weight: 2}}).Return(nil)
+		})
+
+		It("should return http status no content", func() {
+			Expect(update(`{"variants": [{"name": "a", "destination": "https://example.com/a", "weight": 2}]}`).Code).To(Equal(http.StatusNoContent))
+		})
+	})
+})
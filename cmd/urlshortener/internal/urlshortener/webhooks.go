@@ -0,0 +1,145 @@
+package urlshortener
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	netURL "net/url"
+	"strconv"
+	"url-shortener/pkg/repository/firestore/urls"
+	"url-shortener/pkg/repository/firestore/webhooks"
+	"url-shortener/pkg/webhook"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultDeliveriesLimit = 50
+	maxDeliveriesLimit     = 100
+)
+
+type subscriptionRequest struct {
+	URL string `json:"url" binding:"required"`
+	// Events are the event types sent to the URL, all types are sent if empty
+	Events []string `json:"events"`
+}
+
+type createSubscriptionResponse struct {
+	webhooks.Subscription
+	// Secret is only returned when the subscription is created
+	Secret string `json:"secret"`
+}
+
+// DeleteURL deletes a short URL, it is not reused for other URLs
+func (p *Presenter) DeleteURL(ctx *gin.Context) {
+	if err := p.controllerOf(ctx).DeleteURL(ctx, p.urlKey(ctx)); err != nil {
+		var notFoundErr urls.NotFoundError
+		if errors.As(err, &notFoundErr) {
+			ctx.JSON(http.StatusNotFound, "URL does not exist")
+			return
+		}
+
+		logrus.Errorf("Failed to delete url: %v", err)
+		ctx.JSON(http.StatusInternalServerError, "Error occured while deleting short URL")
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// CreateSubscription subscribes an URL to the events of the links of the namespace and returns the secret
+// signing the deliveries
+func (p *Presenter) CreateSubscription(ctx *gin.Context) {
+	var request subscriptionRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if url, err := netURL.Parse(request.URL); err != nil || (url.Scheme != "http" && url.Scheme != "https") || url.Host == "" {
+		ctx.JSON(http.StatusBadRequest, "Webhook URL must be an absolute http or https URL")
+		return
+	}
+
+	for _, eventType := range request.Events {
+		if !webhook.IsType(eventType) {
+			ctx.JSON(http.StatusBadRequest, fmt.Sprintf("Unknown event type [%s]", eventType))
+			return
+		}
+	}
+
+	subscription, err := p.controllerOf(ctx).AddSubscription(ctx, request.URL, request.Events)
+	if err != nil {
+		logrus.Errorf("Failed to add subscription: %v", err)
+		ctx.JSON(http.StatusInternalServerError, "Error occured while adding webhook")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, createSubscriptionResponse{Subscription: subscription, Secret: subscription.Secret})
+}
+
+// ListSubscriptions returns the webhook subscriptions of the namespace without their secrets
+func (p *Presenter) ListSubscriptions(ctx *gin.Context) {
+	subscriptions, err := p.controllerOf(ctx).ListSubscriptions(ctx)
+	if err != nil {
+		logrus.Errorf("Failed to list subscriptions: %v", err)
+		ctx.JSON(http.StatusInternalServerError, "Error occured while listing webhooks")
+		return
+	}
+
+	if subscriptions == nil {
+		subscriptions = []webhooks.Subscription{}
+	}
+
+	ctx.JSON(http.StatusOK, subscriptions)
+}
+
+// DeleteSubscription deletes a webhook subscription, its pending deliveries are not sent
+func (p *Presenter) DeleteSubscription(ctx *gin.Context) {
+	if err := p.controllerOf(ctx).DeleteSubscription(ctx, ctx.Param("id")); err != nil {
+		var notFoundErr webhooks.NotFoundError
+		if errors.As(err, &notFoundErr) {
+			ctx.JSON(http.StatusNotFound, "Webhook does not exist")
+			return
+		}
+
+		logrus.Errorf("Failed to delete subscription: %v", err)
+		ctx.JSON(http.StatusInternalServerError, "Error occured while deleting webhook")
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// ListDeliveries returns the latest deliveries of a webhook subscription with their status and attempts, newest first
+func (p *Presenter) ListDeliveries(ctx *gin.Context) {
+	limit := defaultDeliveriesLimit
+	if value := ctx.Query("limit"); value != "" {
+		var err error
+		limit, err = strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > maxDeliveriesLimit {
+			ctx.JSON(http.StatusBadRequest, fmt.Sprintf("Limit must be between 1 and %d", maxDeliveriesLimit))
+			return
+		}
+	}
+
+	entries, err := p.controllerOf(ctx).ListDeliveries(ctx, ctx.Param("id"), limit)
+	if err != nil {
+		var notFoundErr webhooks.NotFoundError
+		if errors.As(err, &notFoundErr) {
+			ctx.JSON(http.StatusNotFound, "Webhook does not exist")
+			return
+		}
+
+		logrus.Errorf("Failed to list deliveries: %v", err)
+		ctx.JSON(http.StatusInternalServerError, "Error occured while listing webhook deliveries")
+		return
+	}
+
+	if entries == nil {
+		entries = []webhooks.Entry{}
+	}
+
+	ctx.JSON(http.StatusOK, entries)
+}
@@ -0,0 +1,119 @@
+package urlshortener_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"url-shortener/cmd/urlshortener/internal/urlshortener"
+	"url-shortener/cmd/urlshortener/internal/urlshortener/mocks"
+	"url-shortener/pkg/repository/firestore/urls"
+	"url-shortener/pkg/repository/firestore/webhooks"
+	"url-shortener/pkg/webhook"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Webhooks", func() {
+	var (
+		mockCtrl       *gomock.Controller
+		mockController *mocks.MockController
+		handler        *gin.Engine
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockController = mocks.NewMockController(mockCtrl)
+		presenter := urlshortener.NewPresenter(mockController, urlshortener.Config{})
+		handler = gin.New()
+		handler.DELETE("/api/v1/urls/:short_url", presenter.DeleteURL)
+		handler.POST("/api/v1/webhooks", presenter.CreateSubscription)
+		handler.GET("/api/v1/webhooks", presenter.ListSubscriptions)
+		handler.DELETE("/api/v1/webhooks/:id", presenter.DeleteSubscription)
+		handler.GET("/api/v1/webhooks/:id/deliveries", presenter.ListDeliveries)
+	})
+
+	serve := func(method, path, body string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return recorder
+	}
+
+	When("deleting an url", func() {
+		It("should answer no content, or not found if it does not exist", func() {
+			mockController.EXPECT().DeleteURL(gomock.Any(), "abc").Return(nil)
+			Expect(serve(http.MethodDelete, "/api/v1/urls/abc", "").Code).To(Equal(http.StatusNoContent))
+
+			mockController.EXPECT().DeleteURL(gomock.Any(), "abc").Return(urls.NewNotFoundError())
+			Expect(serve(http.MethodDelete, "/api/v1/urls/abc", "").Code).To(Equal(http.StatusNotFound))
+		})
+	})
+
+	When("creating a subscription", func() {
+		It("should return it with its secret", func() {
+			mockController.EXPECT().AddSubscription(gomock.Any(), "https://hooks.example.com", []string{webhook.LinkCreated}).
+				Return(webhooks.Subscription{ID: "s1", URL: "https://hooks.example.com", Secret: "secret"}, nil)
+
+			recorder := serve(http.MethodPost, "/api/v1/webhooks", `{"url":"https://hooks.example.com","events":["link.created"]}`)
+			Expect(recorder.Code).To(Equal(http.StatusCreated))
+
+			var response map[string]interface{}
+			Expect(json.Unmarshal(recorder.Body.Bytes(), &response)).To(Succeed())
+			Expect(response).To(HaveKeyWithValue("id", "s1"))
+			Expect(response).To(HaveKeyWithValue("secret", "secret"))
+		})
+
+		It("should reject invalid urls and unknown event types", func() {
+			Expect(serve(http.MethodPost, "/api/v1/webhooks", `{"url":"ftp://hooks.example.com"}`).Code).To(Equal(http.StatusBadRequest))
+			Expect(serve(http.MethodPost, "/api/v1/webhooks", `{"url":"/hooks"}`).Code).To(Equal(http.StatusBadRequest))
+			Expect(serve(http.MethodPost, "/api/v1/webhooks", `{"url":"https://hooks.example.com","events":["link.renamed"]}`).Code).
+				To(Equal(http.StatusBadRequest))
+		})
+	})
+
+	When("listing subscriptions", func() {
+		It("should not return their secrets", func() {
+			mockController.EXPECT().ListSubscriptions(gomock.Any()).Return([]webhooks.Subscription{{ID: "s1", Secret: "secret"}}, nil)
+
+			recorder := serve(http.MethodGet, "/api/v1/webhooks", "")
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Body.String()).To(ContainSubstring(`"id":"s1"`))
+			Expect(recorder.Body.String()).NotTo(ContainSubstring("secret"))
+		})
+	})
+
+	When("listing deliveries", func() {
+		It("should return them with their attempts", func() {
+			mockController.EXPECT().ListDeliveries(gomock.Any(), "s1", 10).Return([]webhooks.Entry{{
+				ID:       "d1",
+				Status:   webhooks.StatusFailed,
+				Attempts: 1,
+				History:  []webhook.Attempt{{Status: http.StatusBadGateway}},
+			}}, nil)
+
+			recorder := serve(http.MethodGet, "/api/v1/webhooks/s1/deliveries?limit=10", "")
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+
+			var response []webhooks.Entry
+			Expect(json.Unmarshal(recorder.Body.Bytes(), &response)).To(Succeed())
+			Expect(response).To(HaveLen(1))
+			Expect(response[0].History[0].Status).To(Equal(http.StatusBadGateway))
+		})
+
+		It("should answer not found for subscriptions of other namespaces and reject invalid limits", func() {
+			mockController.EXPECT().ListDeliveries(gomock.Any(), "other", 50).Return(nil, webhooks.NewNotFoundError())
+			Expect(serve(http.MethodGet, "/api/v1/webhooks/other/deliveries", "").Code).To(Equal(http.StatusNotFound))
+			Expect(serve(http.MethodGet, "/api/v1/webhooks/s1/deliveries?limit=500", "").Code).To(Equal(http.StatusBadRequest))
+		})
+	})
+
+	When("deleting a subscription that does not exist", func() {
+		It("should answer not found", func() {
+			mockController.EXPECT().DeleteSubscription(gomock.Any(), "s1").Return(webhooks.NewNotFoundError())
+			Expect(serve(http.MethodDelete, "/api/v1/webhooks/s1", "").Code).To(Equal(http.StatusNotFound))
+		})
+	})
+})
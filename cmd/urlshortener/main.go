@@ -2,58 +2,190 @@ package main
 
 import (
 	"context"
+	"crypto/rand"
+	"flag"
 	"fmt"
+	"net"
 	"net/http"
+	"net/url"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
+	_ "time/tzdata"
 	"url-shortener/cmd/urlshortener/env"
+	"url-shortener/cmd/urlshortener/internal/importer"
 	"url-shortener/cmd/urlshortener/internal/urlshortener"
+	"url-shortener/pkg/backup"
 	"url-shortener/pkg/encoder"
+	"url-shortener/pkg/geoip"
+	"url-shortener/pkg/healthcheck"
+	"url-shortener/pkg/httpclient"
+	"url-shortener/pkg/migration"
 	"url-shortener/pkg/repository/firestore/counter"
+	"url-shortener/pkg/repository/firestore/domains"
+	"url-shortener/pkg/repository/firestore/index"
+	"url-shortener/pkg/repository/firestore/tenants"
 	"url-shortener/pkg/repository/firestore/urls"
+	"url-shortener/pkg/repository/firestore/webhooks"
+	"url-shortener/pkg/search"
+	"url-shortener/pkg/unfurl"
+	"url-shortener/pkg/urlshortenerpb"
+	"url-shortener/pkg/webhook"
 
 	"cloud.google.com/go/firestore"
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
 )
 
-const shardsNumber = 100
+const (
+	shardsNumber = 100
+	// previewMaxBytes is the largest part of a destination page read to find its preview
+	previewMaxBytes = 1 << 20
+	// maxRedirects is the longest redirect chain followed by requests to destinations
+	maxRedirects = 5
+)
 
 func main() {
+	command := "serve"
+	if len(os.Args) > 1 {
+		command = os.Args[1]
+	}
+
+	switch command {
+	case "serve":
+		serve()
+	case "import":
+		importURLs(os.Args[2:])
+	case "export":
+		exportArchive(os.Args[2:])
+	case "restore":
+		restoreArchive(os.Args[2:])
+	case "migrate":
+		migrate(os.Args[2:])
+	case "reindex":
+		reindex(os.Args[2:])
+	default:
+		logrus.Fatalf("unknown command [%s], expected one of: serve, import, export, restore, migrate, reindex", command)
+	}
+}
+
+func serve() {
 	logrus.Info("loading application config...")
 	config, err := env.LoadAppConfig()
 	if err != nil {
-		logrus.Fatalf("failed to create firestore client: ", err)
+		logrus.Fatal("failed to load app config: ", err)
 	}
 
-	logrus.Info("establishing firestore connection...")
-	ctx := context.Background()
-	firestoreClient, err := firestore.NewClient(ctx, firestore.DetectProjectID)
-	if err != nil {
-		logrus.Fatalf("failed to create firestore client: ", err)
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	defer cancelFunc()
+	deps := setup(ctx)
+	defer deps.firestoreClient.Close()
+	if config.SearchIndex == env.SearchIndexMemory {
+		logrus.Info("using in process search index...")
+		deps.memoryIndexes = newMemoryIndexes()
+		deps.controller = urlshortener.NewController(deps.urlsRepository, deps.counterRepository, encoder.New(), deps.searchIndex(""))
+		if _, err := deps.memoryIndexes.get("", deps.urlsRepository).build(ctx); err != nil {
+			logrus.Errorf("failed to build search index, it is built again on the next search: %v", err)
+		}
 	}
 
-	urlsRepository := urls.NewRepository(firestoreClient)
-	counterRepository := counter.NewRepository(firestoreClient, shardsNumber)
-	controller := urlshortener.NewController(urlsRepository, counterRepository, encoder.New())
-	presenter := urlshortener.NewPresenter(controller)
+	if config.MigrationMode != "" {
+		logrus.Infof("running in migration mode [%s]...", config.MigrationMode)
+		requireMigratable(ctx, deps)
+		target := setupProject(ctx, config.MigrationProject)
+		defer target.firestoreClient.Close()
+		deps = dualWrite(ctx, config.MigrationMode, deps, target)
+	}
+
+	domainRegistry := urlshortener.NewDomainRegistry(deps.domainsRepository, config.DomainCacheTTL)
+	deps.shortHosts = urlshortener.NewShortHosts(shortHosts(config), domainRegistry)
+	deps.maxDepth = config.LoopMaxDepth
+	deps.controller.WithLoopProtection(deps.shortHosts, "", deps.maxDepth)
+	deps.clickThresholds = config.WebhookClickThresholds
+	deps.controller.WithWebhooks(deps.webhooksRepository, deps.clickThresholds)
+
+	if config.UnfurlEnabled {
+		client := outboundClient(config, config.UnfurlTimeout, 0)
+		queue := unfurl.NewQueue(unfurl.NewFetcher(client, previewMaxBytes), config.UnfurlQueueSize)
+		queue.Run(ctx, config.UnfurlWorkers)
+		deps.previews = queue
+		deps.controller.WithPreviews(queue)
+	}
+
+	if config.HealthCheckEnabled {
+		logrus.Info("starting health monitor...")
+		prober := healthcheck.NewProber(outboundClient(config, config.HealthCheckTimeout, 0))
+		monitor := healthcheck.NewMonitor(prober, healthcheck.Config{
+			Interval:     config.HealthCheckInterval,
+			HostInterval: config.HealthCheckHostInterval,
+			Jitter:       config.HealthCheckJitter,
+			Workers:      config.HealthCheckWorkers,
+			Policy:       healthcheck.Policy{History: config.HealthCheckHistory, BrokenAfter: config.HealthCheckBrokenAfter},
+		})
+		go monitor.Run(ctx, deps.healthStores)
+	}
+
+	if config.WebhookDispatcherEnabled {
+		logrus.Info("starting webhook dispatcher...")
+		dispatcher := webhook.NewDispatcher(deps.webhooksRepository,
+			webhook.NewDeliverer(outboundClient(config, config.WebhookTimeout, 0)),
+			webhook.Config{
+				Interval: config.WebhookPollInterval,
+				// a claimed batch is hidden for as long as its workers may take to send it
+				Lease:       config.WebhookTimeout * time.Duration(config.WebhookBatchSize/config.WebhookWorkers+2),
+				BatchSize:   config.WebhookBatchSize,
+				Workers:     config.WebhookWorkers,
+				MaxAttempts: config.WebhookMaxAttempts,
+				BaseBackoff: config.WebhookBackoffBase,
+				MaxBackoff:  config.WebhookBackoffMax,
+			})
+		go dispatcher.Run(ctx)
+		go deps.everyNamespace(ctx, config.WebhookPollInterval, "publish changes", publishChanges)
+		go deps.everyNamespace(ctx, config.WebhookExpiredInterval, "publish expired urls", publishExpired)
+	}
+
+	presenterConfig := urlshortener.Config{
+		RedirectType:        config.RedirectType,
+		BulkLimit:           config.BulkLimit,
+		PublicURL:           config.PublicURL,
+		QRCacheSize:         config.QRCacheSize,
+		CookieSecret:        cookieSecret(config.CookieSecret),
+		PasswordCookieTTL:   config.PasswordCookieTTL,
+		PasswordMaxAttempts: config.PasswordMaxAttempts,
+		PasswordLockout:     config.PasswordLockout,
+		GeoIP:               geoIP(config.GeoIPFile),
+		Domains:             domainRegistry,
+		Tenants:             urlshortener.NewTenantRegistry(deps.tenantsRepository, deps.namespaces(), config.TenantCacheTTL),
+		ChangesPollInterval: config.ChangesPollInterval,
+		Archive:             backup.New(deps.urlsRepository, deps.counterRepository, encoder.New()),
+		AdminAPIKey:         config.AdminAPIKey,
+		ValidateRequests:    config.OpenAPIValidateRequests,
+		ValidateResponses:   config.OpenAPIValidateResponses,
+	}
+	presenter := urlshortener.NewPresenter(deps.controller, presenterConfig)
 
 	logrus.Info("initializing shards...")
-	if err := counterRepository.InitCounter(ctx); err != nil {
+	if err := deps.counterRepository.InitCounter(ctx); err != nil {
 		logrus.Fatal("failed to initialize counter: ", err)
 	}
 
 	handler := gin.Default()
-	handler.POST("/", presenter.CreateShortURL)
-	handler.GET("/:short_url", presenter.RedirectToLongURL)
+	if err := handler.SetTrustedProxies(config.TrustedProxies); err != nil {
+		logrus.Fatal("failed to set trusted proxies: ", err)
+	}
+
+	presenter.RegisterRoutes(handler)
 
 	logrus.Info("http server is starting...")
 	httpServer := &http.Server{
 		Addr:    fmt.Sprintf("%s:%d", config.Host, config.Port),
 		Handler: handler,
 	}
+	// streams of changes are stopped on shutdown, so they do not hold it up while other requests finish
+	httpServer.RegisterOnShutdown(presenter.StopStreams)
 
 	go func() {
 		if err := httpServer.ListenAndServe(); err != http.ErrServerClosed {
@@ -61,6 +193,23 @@ func main() {
 		}
 	}()
 
+	var grpcServer *grpc.Server
+	if config.GRPCPort != 0 {
+		logrus.Info("grpc server is starting...")
+		listener, err := net.Listen("tcp", fmt.Sprintf("%s:%d", config.Host, config.GRPCPort))
+		if err != nil {
+			logrus.Fatal("failed to listen for grpc: ", err)
+		}
+
+		grpcServer = grpc.NewServer(grpc.UnaryInterceptor(urlshortener.Deadline(config.GRPCTimeout)))
+		urlshortenerpb.RegisterURLShortenerServer(grpcServer, urlshortener.NewGRPCServer(deps.controller, presenterConfig))
+		go func() {
+			if err := grpcServer.Serve(listener); err != nil {
+				logrus.Fatal("failed to serve grpc: ", err)
+			}
+		}()
+	}
+
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan,
 		syscall.SIGINT,
@@ -70,9 +219,640 @@ func main() {
 	signal.Stop(sigChan)
 	logrus.Info("http server is stopping...")
 
-	shutdownCtx, cancelFunc := context.WithTimeout(ctx, 30*time.Second)
-	defer cancelFunc()
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancelShutdown()
+	var grpcStopped sync.WaitGroup
+	if grpcServer != nil {
+		grpcStopped.Add(1)
+		go func() {
+			defer grpcStopped.Done()
+			stopGRPC(shutdownCtx, grpcServer)
+		}()
+	}
+
 	if err := httpServer.Shutdown(shutdownCtx); err != nil {
 		logrus.Fatal("failed to shutdown server", err)
 	}
+
+	grpcStopped.Wait()
+}
+
+// stopGRPC waits for the pending calls to finish, once ctx is done the remaining ones are canceled
+func stopGRPC(ctx context.Context, server *grpc.Server) {
+	stopped := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		server.Stop()
+	}
+}
+
+func importURLs(args []string) {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	file := flags.String("file", "", "CSV or JSONL file with long URLs")
+	checkpoint := flags.String("checkpoint", "", "checkpoint file used to resume an interrupted import (default <file>.checkpoint)")
+	batchSize := flags.Int("batch-size", 400, "number of URLs created per batch")
+	domain := flags.String("domain", "", "branded domain the URLs are created on (default the default domain)")
+	flags.Parse(args)
+
+	if *file == "" {
+		logrus.Fatal("missing required flag -file")
+	}
+
+	if *batchSize < 1 {
+		logrus.Fatal("flag -batch-size must be positive")
+	}
+
+	if *checkpoint == "" {
+		*checkpoint = *file + ".checkpoint"
+	}
+
+	ctx, cancelFunc := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancelFunc()
+	deps := setup(ctx)
+	defer deps.firestoreClient.Close()
+
+	if err := deps.counterRepository.InitCounter(ctx); err != nil {
+		logrus.Fatal("failed to initialize counter: ", err)
+	}
+
+	report, err := importer.New(deps.controller, *domain, *batchSize, *checkpoint, os.Stdout).Import(ctx, *file)
+	logrus.Infof("import finished: created [%d], reused [%d], failed [%d], skipped [%d]",
+		report.Created, report.Reused, report.Failed, report.Skipped)
+	if err != nil {
+		logrus.Fatal("failed to import urls: ", err)
+	}
+
+	if report.Failed > 0 {
+		logrus.Warnf("[%d] urls failed, rerun the import to retry them", report.Failed)
+	}
+}
+
+func exportArchive(args []string) {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	out := flags.String("out", "", "archive file to write (default stdout)")
+	flags.Parse(args)
+
+	output := os.Stdout
+	if *out != "" {
+		file, err := os.Create(*out)
+		if err != nil {
+			logrus.Fatal("failed to create archive: ", err)
+		}
+		defer file.Close()
+		output = file
+	}
+
+	ctx := context.Background()
+	deps := setup(ctx)
+	defer deps.firestoreClient.Close()
+
+	uncopied, err := uncopiedData(ctx, deps.firestoreClient)
+	if err != nil {
+		logrus.Fatal("failed to check data outside the archive: ", err)
+	}
+
+	if len(uncopied) > 0 {
+		logrus.Warnf("the archive holds the links of the default namespace only, %v are not exported", uncopied)
+	}
+
+	summary, err := backup.New(deps.urlsRepository, deps.counterRepository, encoder.New()).Export(ctx, output)
+	if err != nil {
+		logrus.Fatal("failed to export archive: ", err)
+	}
+
+	logrus.Infof("export finished: urls [%d], count [%d]", summary.URLs, summary.Count)
+}
+
+func restoreArchive(args []string) {
+	flags := flag.NewFlagSet("restore", flag.ExitOnError)
+	in := flags.String("in", "", "archive file created by export")
+	flags.Parse(args)
+
+	if *in == "" {
+		logrus.Fatal("missing required flag -in")
+	}
+
+	file, err := os.Open(*in)
+	if err != nil {
+		logrus.Fatal("failed to open archive: ", err)
+	}
+	defer file.Close()
+
+	ctx := context.Background()
+	deps := setup(ctx)
+	defer deps.firestoreClient.Close()
+
+	if err := deps.counterRepository.InitCounter(ctx); err != nil {
+		logrus.Fatal("failed to initialize counter: ", err)
+	}
+
+	summary, err := backup.New(deps.urlsRepository, deps.counterRepository, encoder.New()).Restore(ctx, file)
+	if err != nil {
+		logrus.Fatal("failed to restore archive: ", err)
+	}
+
+	logrus.Infof("restore finished: urls [%d], counter advanced to [%d]", summary.URLs, summary.Count)
+}
+
+func reindex(args []string) {
+	flags := flag.NewFlagSet("reindex", flag.ExitOnError)
+	tenant := flags.String("tenant", "", "tenant whose URLs are indexed (default the default namespace)")
+	flags.Parse(args)
+
+	ctx, cancelFunc := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancelFunc()
+	deps := setup(ctx)
+	defer deps.firestoreClient.Close()
+
+	count, err := indexURLs(ctx, deps.namespaceURLs(*tenant), deps.searchIndex(*tenant))
+	logrus.Infof("reindex finished: indexed [%d] urls", count)
+	if err != nil {
+		logrus.Fatal("failed to reindex urls: ", err)
+	}
+}
+
+func migrate(args []string) {
+	if len(args) == 0 || (args[0] != "copy" && args[0] != "verify") {
+		logrus.Fatal("expected migrate step: copy or verify")
+	}
+
+	flags := flag.NewFlagSet("migrate "+args[0], flag.ExitOnError)
+	checkpoint := flags.String("checkpoint", "migration.checkpoint", "checkpoint file used to resume an interrupted copy")
+	batchSize := flags.Int("batch-size", 400, "number of URLs copied per batch")
+	sampleSize := flags.Int("sample", 1000, "number of URLs compared by verify")
+	flags.Parse(args[1:])
+
+	config, err := env.LoadAppConfig()
+	if err != nil {
+		logrus.Fatal("failed to load app config: ", err)
+	}
+
+	if config.MigrationProject == "" {
+		logrus.Fatal("missing migration project, set MIGRATION_FIRESTORE_PROJECT")
+	}
+
+	ctx, cancelFunc := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancelFunc()
+	source := setup(ctx)
+	defer source.firestoreClient.Close()
+	destination := setupProject(ctx, config.MigrationProject)
+	defer destination.firestoreClient.Close()
+	requireMigratable(ctx, source)
+
+	if args[0] == "verify" {
+		report, err := migration.NewVerifier(source.urlsRepository, destination.urlsRepository, *sampleSize).Verify(ctx)
+		if err != nil {
+			logrus.Fatal("failed to verify migration: ", err)
+		}
+
+		logrus.Infof("verify finished: source urls [%d], destination urls [%d], sampled [%d], mismatches %v",
+			report.SourceURLs, report.DestinationURLs, report.Sampled, report.Mismatches)
+		if !report.OK() {
+			logrus.Fatal("stores differ")
+		}
+
+		return
+	}
+
+	if err := destination.counterRepository.InitCounter(ctx); err != nil {
+		logrus.Fatal("failed to initialize counter: ", err)
+	}
+
+	copier := migration.NewCopier(source.urlsRepository, destination.urlsRepository,
+		source.counterRepository, destination.counterRepository, *batchSize, *checkpoint)
+	report, err := copier.Copy(ctx)
+	logrus.Infof("copy finished: copied [%d] after [%s], counter advanced to [%d]", report.Copied, report.ResumedAfter, report.Count)
+	if err != nil {
+		logrus.Fatal("failed to copy: ", err)
+	}
+}
+
+// uncopiedCollections hold branded domains, tenants with their namespaces and webhook subscriptions,
+// which migration, dual-write and export do not cover
+var uncopiedCollections = []string{"domains", "tenants", "webhooks"}
+
+// uncopiedData returns the collections of uncopiedCollections which hold documents
+func uncopiedData(ctx context.Context, client *firestore.Client) ([]string, error) {
+	var found []string
+	for _, collection := range uncopiedCollections {
+		docs, err := client.Collection(collection).Limit(1).Documents(ctx).GetAll()
+		if err != nil {
+			return nil, fmt.Errorf("failed to check collection [%s]: %w", collection, err)
+		}
+
+		if len(docs) > 0 {
+			found = append(found, collection)
+		}
+	}
+
+	return found, nil
+}
+
+// requireMigratable refuses to migrate a store holding data which the migration would silently lose
+func requireMigratable(ctx context.Context, deps dependencies) {
+	uncopied, err := uncopiedData(ctx, deps.firestoreClient)
+	if err != nil {
+		logrus.Fatal("failed to check data outside the migration: ", err)
+	}
+
+	if len(uncopied) > 0 {
+		logrus.Fatalf("migration copies the links of the default namespace only, %v are not empty", uncopied)
+	}
+}
+
+// dualWrite wires the controller to write to both stores, the primary one is chosen by the migration mode
+// The counter of the primary store is mirrored to the secondary one, so neither allocates ids the other one did.
+func dualWrite(ctx context.Context, mode string, current, target dependencies) dependencies {
+	primary, secondary := current, target
+	if mode == env.MigrationCutover {
+		primary, secondary = target, current
+	}
+
+	for _, deps := range []dependencies{primary, secondary} {
+		if err := deps.counterRepository.InitCounter(ctx); err != nil {
+			logrus.Fatal("failed to initialize counter: ", err)
+		}
+	}
+
+	if mode == env.MigrationCutover {
+		// ids allocated by the current store before the cutover must not be allocated again by the target one
+		count, err := secondary.counterRepository.GetCount(ctx)
+		if err != nil {
+			logrus.Fatal("failed to get count of current store: ", err)
+		}
+
+		if err := primary.counterRepository.AdvanceTo(ctx, count); err != nil {
+			logrus.Fatal("failed to advance counter of target store: ", err)
+		}
+	}
+
+	repository := migration.NewDualWriteRepository(primary.urlsRepository, secondary.urlsRepository).
+		WithCounters(primary.counterRepository, secondary.counterRepository)
+	primary.controller = urlshortener.NewController(repository, primary.counterRepository, encoder.New(), primary.searchIndex(""))
+	return primary
+}
+
+// shortHosts returns the hosts serving the default domain, the host of the public URL and the configured ones
+func shortHosts(config env.AppConfig) []string {
+	hosts := config.ShortHosts
+	if public, err := url.Parse(config.PublicURL); err == nil && public.Host != "" {
+		hosts = append(hosts, public.Host)
+	}
+
+	return hosts
+}
+
+// outboundClient returns a client for requests to user provided destinations, which cannot reach the internal network
+// except for the configured allowed networks
+func outboundClient(config env.AppConfig, timeout time.Duration, maxBodyBytes int64) *http.Client {
+	allowed := make([]*net.IPNet, len(config.OutboundAllowedNetworks))
+	for i, cidr := range config.OutboundAllowedNetworks {
+		_, allowed[i], _ = net.ParseCIDR(cidr)
+	}
+
+	return httpclient.New(httpclient.Config{
+		Timeout:         timeout,
+		MaxRedirects:    maxRedirects,
+		MaxBodyBytes:    maxBodyBytes,
+		AllowedNetworks: allowed,
+	})
+}
+
+// cookieSecret returns the configured secret or a random one,
+// in which case access cookies are not valid after a restart or on other instances
+func cookieSecret(secret string) []byte {
+	if secret != "" {
+		return []byte(secret)
+	}
+
+	logrus.Warn("COOKIE_SECRET is not set, using a random one")
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		logrus.Fatal("failed to generate cookie secret: ", err)
+	}
+
+	return random
+}
+
+// geoIP loads the GeoIP file if configured, otherwise country routing rules never match
+func geoIP(path string) urlshortener.Locator {
+	if path == "" {
+		return nil
+	}
+
+	db, err := geoip.Load(path)
+	if err != nil {
+		logrus.Fatal("failed to load geoip file: ", err)
+	}
+
+	logrus.Infof("loaded [%d] geoip networks", db.Len())
+	return db
+}
+
+type dependencies struct {
+	firestoreClient   *firestore.Client
+	urlsRepository    *urls.Repository
+	counterRepository *counter.Repository
+	domainsRepository *domains.Repository
+	tenantsRepository *tenants.Repository
+	// webhooksRepository holds the webhook subscriptions of the default namespace and the outbox of all namespaces
+	webhooksRepository *webhooks.Repository
+	// memoryIndexes holds the in process search indexes, the Firestore index is used if nil
+	memoryIndexes *memoryIndexes
+	// previews fetches the destination previews of created URLs, they are not fetched if nil
+	previews urlshortener.Previews
+	// shortHosts serve short URLs, destinations on them are checked for loops up to maxDepth unless nil
+	shortHosts *urlshortener.ShortHosts
+	maxDepth   int
+	// clickThresholds are the counted clicks of a link at which a webhook click event is published
+	clickThresholds []int64
+	controller      *urlshortener.URLController
+}
+
+func (d dependencies) namespaces() tenantNamespaces {
+	return tenantNamespaces{deps: d}
+}
+
+// namespaceURLs returns the URLs repository of the tenant, or of the default namespace if tenantID is empty
+func (d dependencies) namespaceURLs(tenantID string) *urls.Repository {
+	if tenantID == "" {
+		return d.urlsRepository
+	}
+
+	return urls.NewTenantRepository(d.firestoreClient, tenantID)
+}
+
+// searchIndex returns the search index of the tenant, or of the default namespace if tenantID is empty
+func (d dependencies) searchIndex(tenantID string) urlshortener.Index {
+	if d.memoryIndexes != nil {
+		return d.memoryIndexes.get(tenantID, d.namespaceURLs(tenantID))
+	}
+
+	if tenantID == "" {
+		return index.NewRepository(d.firestoreClient)
+	}
+
+	return index.NewTenantRepository(d.firestoreClient, tenantID)
+}
+
+// healthStores returns the URLs of the default namespace and of every active tenant to check
+func (d dependencies) healthStores(ctx context.Context) ([]healthcheck.Store, error) {
+	stores := []healthcheck.Store{d.urlsRepository}
+	all, err := d.tenantsRepository.ListTenants(ctx)
+	if err != nil {
+		return stores, fmt.Errorf("failed to list tenants: %w", err)
+	}
+
+	for _, tenant := range all {
+		if !tenant.Suspended {
+			stores = append(stores, d.namespaceURLs(tenant.ID))
+		}
+	}
+
+	return stores, nil
+}
+
+// everyNamespace runs fn for the default namespace and every active tenant, every interval until ctx is done
+// Failures are logged and the namespace is run again on the next interval
+func (d dependencies) everyNamespace(ctx context.Context, interval time.Duration, task string,
+	fn func(ctx context.Context, controller *urlshortener.URLController, repository *webhooks.Repository) error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := fn(ctx, d.controller, d.webhooksRepository); err != nil {
+			logrus.Errorf("failed to %s: %v", task, err)
+		}
+
+		all, err := d.tenantsRepository.ListTenants(ctx)
+		if err != nil {
+			logrus.Errorf("failed to list tenants: %v", err)
+		}
+
+		for _, tenant := range all {
+			if tenant.Suspended {
+				continue
+			}
+
+			repository := webhooks.NewTenantRepository(d.firestoreClient, tenant.ID)
+			if err := fn(ctx, d.namespaces().controller(tenant), repository); err != nil {
+				logrus.Errorf("failed to %s of tenant [%s]: %v", task, tenant.ID, err)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// publishExpired publishes the links of a namespace which expired since its cursor, the first run only sets the cursor
+// The end of the published range is stored, so links expiring during a restart are published after it
+func publishExpired(ctx context.Context, controller *urlshortener.URLController, repository *webhooks.Repository) error {
+	from, err := repository.ExpiredCursor(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	if !from.IsZero() {
+		if err := controller.PublishExpired(ctx, from, now); err != nil {
+			return err
+		}
+	}
+
+	return repository.SetExpiredCursor(ctx, now)
+}
+
+// publishChanges publishes the events of the changes of links of a namespace committed after its cursor
+// A namespace without a stored cursor starts at the end of its change log, tenants store the start when they are created
+func publishChanges(ctx context.Context, controller *urlshortener.URLController, repository *webhooks.Repository) error {
+	stored, ok, err := repository.ChangesCursor(ctx)
+	if err != nil {
+		return err
+	}
+
+	var after urls.Cursor
+	if ok {
+		if after, err = urls.ParseCursor(stored); err != nil {
+			return fmt.Errorf("failed to parse changes cursor: %w", err)
+		}
+	} else if after, err = controller.LastChange(ctx); err != nil {
+		return err
+	}
+
+	published, err := controller.PublishChanges(ctx, after)
+	if published != after || !ok {
+		if err := repository.SetChangesCursor(ctx, published.String()); err != nil {
+			return err
+		}
+	}
+
+	return err
+}
+
+// tenantNamespaces stores the URLs, counter, search index and usage of each tenant below its tenant document
+type tenantNamespaces struct {
+	deps dependencies
+}
+
+// Init creates the counter shards of the tenant, the events of its links are published from its first change
+// It keeps existing shards and cursors, so it can run again for a namespace which is already initialized
+func (n tenantNamespaces) Init(ctx context.Context, tenant tenants.Tenant) error {
+	if err := counter.NewTenantRepository(n.deps.firestoreClient, tenant.ID, shardsNumber).InitCounter(ctx); err != nil {
+		return err
+	}
+
+	return webhooks.NewTenantRepository(n.deps.firestoreClient, tenant.ID).InitChangesCursor(ctx)
+}
+
+// Controller returns a controller on the URLs and the code space of the tenant, limited by its quota
+func (n tenantNamespaces) Controller(tenant tenants.Tenant) urlshortener.Controller {
+	return n.controller(tenant)
+}
+
+func (n tenantNamespaces) controller(tenant tenants.Tenant) *urlshortener.URLController {
+	controller := urlshortener.NewTenantController(
+		n.deps.namespaceURLs(tenant.ID),
+		counter.NewTenantRepository(n.deps.firestoreClient, tenant.ID, shardsNumber),
+		encoder.New(),
+		n.deps.searchIndex(tenant.ID),
+		n.deps.tenantsRepository.Quota(tenant),
+	).WithPreviews(n.deps.previews).
+		WithWebhooks(webhooks.NewTenantRepository(n.deps.firestoreClient, tenant.ID), n.deps.clickThresholds)
+	if n.deps.shortHosts != nil {
+		controller.WithLoopProtection(n.deps.shortHosts, tenant.ID, n.deps.maxDepth)
+	}
+
+	return controller
+}
+
+// memoryIndexes keeps an in process search index per namespace
+type memoryIndexes struct {
+	mu      sync.Mutex
+	indexes map[string]*lazyIndex
+}
+
+func newMemoryIndexes() *memoryIndexes {
+	return &memoryIndexes{indexes: map[string]*lazyIndex{}}
+}
+
+func (m *memoryIndexes) get(namespace string, repository *urls.Repository) *lazyIndex {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if memoryIndex, ok := m.indexes[namespace]; ok {
+		return memoryIndex
+	}
+
+	memoryIndex := &lazyIndex{namespace: namespace, repository: repository}
+	m.indexes[namespace] = memoryIndex
+	return memoryIndex
+}
+
+// lazyIndex is the in process search index of a namespace, built from the stored URLs with the context of its first search
+// A failed build is retried by the next search. Changes before the build are skipped, the build reads them from the store.
+type lazyIndex struct {
+	mu         sync.Mutex
+	index      *search.MemoryIndex
+	namespace  string
+	repository *urls.Repository
+}
+
+// Put adds the document to the index once it is built
+func (l *lazyIndex) Put(ctx context.Context, doc search.Document) error {
+	if memoryIndex := l.built(); memoryIndex != nil {
+		return memoryIndex.Put(ctx, doc)
+	}
+
+	return nil
+}
+
+// Delete removes the document from the index once it is built
+func (l *lazyIndex) Delete(ctx context.Context, id string) error {
+	if memoryIndex := l.built(); memoryIndex != nil {
+		return memoryIndex.Delete(ctx, id)
+	}
+
+	return nil
+}
+
+// Search builds the index if it is not built yet and searches it
+func (l *lazyIndex) Search(ctx context.Context, query search.Query) ([]search.Document, error) {
+	memoryIndex, err := l.build(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return memoryIndex.Search(ctx, query)
+}
+
+// built returns the index, or nil if it is not built yet, it waits for a running build
+func (l *lazyIndex) built() *search.MemoryIndex {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.index
+}
+
+func (l *lazyIndex) build(ctx context.Context) (*search.MemoryIndex, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.index != nil {
+		return l.index, nil
+	}
+
+	memoryIndex := search.NewMemoryIndex()
+	count, err := indexURLs(ctx, l.repository, memoryIndex)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build search index of namespace [%s]: %w", l.namespace, err)
+	}
+
+	logrus.Infof("indexed [%d] urls of namespace [%s]", count, l.namespace)
+	l.index = memoryIndex
+	return memoryIndex, nil
+}
+
+// indexURLs puts all URLs of the repository into the search index
+func indexURLs(ctx context.Context, repository *urls.Repository, searchIndex urlshortener.Index) (int, error) {
+	count := 0
+	err := repository.ForEach(ctx, func(record urls.Record) error {
+		if err := searchIndex.Put(ctx, record.URL.Document(record.ID)); err != nil {
+			return err
+		}
+
+		count++
+		return nil
+	})
+
+	return count, err
+}
+
+func setup(ctx context.Context) dependencies {
+	return setupProject(ctx, firestore.DetectProjectID)
+}
+
+func setupProject(ctx context.Context, projectID string) dependencies {
+	logrus.Infof("establishing firestore connection to project [%s]...", projectID)
+	firestoreClient, err := firestore.NewClient(ctx, projectID)
+	if err != nil {
+		logrus.Fatal("failed to create firestore client: ", err)
+	}
+
+	urlsRepository := urls.NewRepository(firestoreClient)
+	counterRepository := counter.NewRepository(firestoreClient, shardsNumber)
+	return dependencies{
+		firestoreClient:    firestoreClient,
+		urlsRepository:     urlsRepository,
+		counterRepository:  counterRepository,
+		domainsRepository:  domains.NewRepository(firestoreClient),
+		tenantsRepository:  tenants.NewRepository(firestoreClient),
+		webhooksRepository: webhooks.NewRepository(firestoreClient),
+		controller:         urlshortener.NewController(urlsRepository, counterRepository, encoder.New(), index.NewRepository(firestoreClient)),
+	}
 }
@@ -0,0 +1,161 @@
+package backup
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"url-shortener/pkg/repository/firestore/urls"
+)
+
+//go:generate mockgen --source=backup.go --destination mocks/backup.go --package mocks
+
+const (
+	entryTypeCounter = "counter"
+	entryTypeURL     = "url"
+	restoreBatchSize = 400
+)
+
+type URLStore interface {
+	ForEach(ctx context.Context, fn func(record urls.Record) error) error
+	PutURLs(ctx context.Context, records []urls.Record) error
+}
+
+type Counter interface {
+	GetCount(ctx context.Context) (int64, error)
+	AdvanceTo(ctx context.Context, count int64) error
+}
+
+type Decoder interface {
+	DecodeFromBase62(encoded string) (uint64, error)
+}
+
+// Summary describes an exported or restored archive
+type Summary struct {
+	URLs  int
+	Count int64
+}
+
+// entry is a single line of the archive
+type entry struct {
+	Type  string    `json:"type"`
+	Code  string    `json:"code,omitempty"`
+	URL   *urls.URL `json:"url,omitempty"`
+	Count int64     `json:"count,omitempty"`
+}
+
+type Backup struct {
+	store   URLStore
+	counter Counter
+	decoder Decoder
+}
+
+// New is a constructor function
+func New(store URLStore, counter Counter, decoder Decoder) *Backup {
+	return &Backup{
+		store:   store,
+		counter: counter,
+		decoder: decoder,
+	}
+}
+
+// Export writes the counter state followed by every URL to a JSONL archive
+func (b *Backup) Export(ctx context.Context, writer io.Writer) (Summary, error) {
+	count, err := b.counter.GetCount(ctx)
+	if err != nil {
+		return Summary{}, fmt.Errorf("failed to get count: %w", err)
+	}
+
+	encoder := json.NewEncoder(writer)
+	if err := encoder.Encode(entry{Type: entryTypeCounter, Count: count}); err != nil {
+		return Summary{}, fmt.Errorf("failed to write counter: %w", err)
+	}
+
+	summary := Summary{Count: count}
+	err = b.store.ForEach(ctx, func(record urls.Record) error {
+		url := record.URL
+		if err := encoder.Encode(entry{Type: entryTypeURL, Code: record.ID, URL: &url}); err != nil {
+			return fmt.Errorf("failed to write url with id [%s]: %w", record.ID, err)
+		}
+
+		summary.URLs++
+		return nil
+	})
+	if err != nil {
+		return summary, fmt.Errorf("failed to export urls: %w", err)
+	}
+
+	return summary, nil
+}
+
+// Restore loads a JSONL archive created by Export
+// URLs are written in batches, afterwards the counter is advanced past both the archived count
+// and the highest imported id, so newly allocated ids never collide with restored ones
+func (b *Backup) Restore(ctx context.Context, reader io.Reader) (Summary, error) {
+	var (
+		summary Summary
+		batch   []urls.Record
+	)
+
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+
+		if err := b.store.PutURLs(ctx, batch); err != nil {
+			return fmt.Errorf("failed to put urls: %w", err)
+		}
+
+		summary.URLs += len(batch)
+		batch = batch[:0]
+		return nil
+	}
+
+	decoder := json.NewDecoder(reader)
+	for {
+		var e entry
+		if err := decoder.Decode(&e); err != nil {
+			if err == io.EOF {
+				break
+			}
+
+			return summary, fmt.Errorf("failed to read archive: %w", err)
+		}
+
+		switch e.Type {
+		case entryTypeCounter:
+			if e.Count > summary.Count {
+				summary.Count = e.Count
+			}
+		case entryTypeURL:
+			if e.Code == "" || e.URL == nil {
+				return summary, fmt.Errorf("invalid url entry with code [%s]", e.Code)
+			}
+
+			// codes which are not base62 encoded numbers were not allocated by the counter
+			_, code := urls.SplitKey(e.Code)
+			if number, err := b.decoder.DecodeFromBase62(code); err == nil && int64(number) > summary.Count {
+				summary.Count = int64(number)
+			}
+
+			batch = append(batch, urls.Record{ID: e.Code, URL: *e.URL})
+			if len(batch) == restoreBatchSize {
+				if err := flush(); err != nil {
+					return summary, err
+				}
+			}
+		default:
+			return summary, fmt.Errorf("unknown archive entry type [%s]", e.Type)
+		}
+	}
+
+	if err := flush(); err != nil {
+		return summary, err
+	}
+
+	if err := b.counter.AdvanceTo(ctx, summary.Count); err != nil {
+		return summary, fmt.Errorf("failed to advance counter: %w", err)
+	}
+
+	return summary, nil
+}
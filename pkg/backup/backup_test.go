@@ -0,0 +1,133 @@
+package backup_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"url-shortener/pkg/backup"
+	"url-shortener/pkg/backup/mocks"
+	"url-shortener/pkg/encoder"
+	"url-shortener/pkg/repository/firestore/urls"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Backup", func() {
+	const archive = `{"type":"counter","count":5}
+{"type":"url","code":"A","url":{"long_url":"https://first.com","redirect_type":301}}
+{"type":"url","code":"3","url":{"long_url":"https://second.com"}}
+`
+
+	var (
+		mockCtrl    *gomock.Controller
+		mockStore   *mocks.MockURLStore
+		mockCounter *mocks.MockCounter
+		b           *backup.Backup
+		ctx         context.Context
+		records     []urls.Record
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockStore = mocks.NewMockURLStore(mockCtrl)
+		mockCounter = mocks.NewMockCounter(mockCtrl)
+		b = backup.New(mockStore, mockCounter, encoder.New())
+		ctx = context.Background()
+		records = []urls.Record{
+			{ID: "A", URL: urls.URL{LongURL: "https://first.com", RedirectType: 301}},
+			{ID: "3", URL: urls.URL{LongURL: "https://second.com"}},
+		}
+	})
+
+	When("getting count fails during export", func() {
+		BeforeEach(func() {
+			mockCounter.EXPECT().GetCount(ctx).Return(int64(0), errors.New("err"))
+		})
+
+		It("should return an error", func() {
+			_, err := b.Export(ctx, &bytes.Buffer{})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	When("iterating over urls fails during export", func() {
+		BeforeEach(func() {
+			mockCounter.EXPECT().GetCount(ctx).Return(int64(5), nil)
+			mockStore.EXPECT().ForEach(ctx, gomock.Any()).Return(errors.New("err"))
+		})
+
+		It("should return an error", func() {
+			_, err := b.Export(ctx, &bytes.Buffer{})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	When("exporting succeeds", func() {
+		BeforeEach(func() {
+			mockCounter.EXPECT().GetCount(ctx).Return(int64(5), nil)
+			mockStore.EXPECT().ForEach(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(urls.Record) error) error {
+				for _, record := range records {
+					if err := fn(record); err != nil {
+						return err
+					}
+				}
+
+				return nil
+			})
+		})
+
+		It("should write counter and urls as json lines", func() {
+			output := &bytes.Buffer{}
+			summary, err := b.Export(ctx, output)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(summary).To(Equal(backup.Summary{URLs: 2, Count: 5}))
+			Expect(output.String()).To(Equal(archive))
+		})
+	})
+
+	When("restoring an archive with unknown entry", func() {
+		It("should return an error", func() {
+			_, err := b.Restore(ctx, strings.NewReader(`{"type":"unknown"}`))
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	When("putting urls fails during restore", func() {
+		BeforeEach(func() {
+			mockStore.EXPECT().PutURLs(ctx, records).Return(errors.New("err"))
+		})
+
+		It("should return an error", func() {
+			_, err := b.Restore(ctx, strings.NewReader(archive))
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	When("restoring succeeds", func() {
+		BeforeEach(func() {
+			mockStore.EXPECT().PutURLs(ctx, records).Return(nil)
+			mockCounter.EXPECT().AdvanceTo(ctx, int64(10)).Return(nil)
+		})
+
+		It("should advance counter past the highest imported id", func() {
+			summary, err := b.Restore(ctx, strings.NewReader(archive))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(summary).To(Equal(backup.Summary{URLs: 2, Count: 10}))
+		})
+	})
+
+	When("advancing counter fails during restore", func() {
+		BeforeEach(func() {
+			mockStore.EXPECT().PutURLs(ctx, records).Return(nil)
+			mockCounter.EXPECT().AdvanceTo(ctx, int64(10)).Return(errors.New("err"))
+		})
+
+		It("should return an error", func() {
+			_, err := b.Restore(ctx, strings.NewReader(archive))
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,154 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: backup.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+	urls "url-shortener/pkg/repository/firestore/urls"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockURLStore is a mock of URLStore interface.
+type MockURLStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockURLStoreMockRecorder
+}
+
+// MockURLStoreMockRecorder is the mock recorder for MockURLStore.
+type MockURLStoreMockRecorder struct {
+	mock *MockURLStore
+}
+
+// NewMockURLStore creates a new mock instance.
+func NewMockURLStore(ctrl *gomock.Controller) *MockURLStore {
+	mock := &MockURLStore{ctrl: ctrl}
+	mock.recorder = &MockURLStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockURLStore) EXPECT() *MockURLStoreMockRecorder {
+	return m.recorder
+}
+
+// ForEach mocks base method.
+func (m *MockURLStore) ForEach(ctx context.Context, fn func(urls.Record) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ForEach", ctx, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ForEach indicates an expected call of ForEach.
+func (mr *MockURLStoreMockRecorder) ForEach(ctx, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ForEach", reflect.TypeOf((*MockURLStore)(nil).ForEach), ctx, fn)
+}
+
+// PutURLs mocks base method.
+func (m *MockURLStore) PutURLs(ctx context.Context, records []urls.Record) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PutURLs", ctx, records)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PutURLs indicates an expected call of PutURLs.
+func (mr *MockURLStoreMockRecorder) PutURLs(ctx, records interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PutURLs", reflect.TypeOf((*MockURLStore)(nil).PutURLs), ctx, records)
+}
+
+// MockCounter is a mock of Counter interface.
+type MockCounter struct {
+	ctrl     *gomock.Controller
+	recorder *MockCounterMockRecorder
+}
+
+// MockCounterMockRecorder is the mock recorder for MockCounter.
+type MockCounterMockRecorder struct {
+	mock *MockCounter
+}
+
+// NewMockCounter creates a new mock instance.
+func NewMockCounter(ctrl *gomock.Controller) *MockCounter {
+	mock := &MockCounter{ctrl: ctrl}
+	mock.recorder = &MockCounterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCounter) EXPECT() *MockCounterMockRecorder {
+	return m.recorder
+}
+
+// AdvanceTo mocks base method.
+func (m *MockCounter) AdvanceTo(ctx context.Context, count int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AdvanceTo", ctx, count)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AdvanceTo indicates an expected call of AdvanceTo.
+func (mr *MockCounterMockRecorder) AdvanceTo(ctx, count interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdvanceTo", reflect.TypeOf((*MockCounter)(nil).AdvanceTo), ctx, count)
+}
+
+// GetCount mocks base method.
+func (m *MockCounter) GetCount(ctx context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCount", ctx)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCount indicates an expected call of GetCount.
+func (mr *MockCounterMockRecorder) GetCount(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCount", reflect.TypeOf((*MockCounter)(nil).GetCount), ctx)
+}
+
+// MockDecoder is a mock of Decoder interface.
+type MockDecoder struct {
+	ctrl     *gomock.Controller
+	recorder *MockDecoderMockRecorder
+}
+
+// MockDecoderMockRecorder is the mock recorder for MockDecoder.
+type MockDecoderMockRecorder struct {
+	mock *MockDecoder
+}
+
+// NewMockDecoder creates a new mock instance.
+func NewMockDecoder(ctrl *gomock.Controller) *MockDecoder {
+	mock := &MockDecoder{ctrl: ctrl}
+	mock.recorder = &MockDecoderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDecoder) EXPECT() *MockDecoderMockRecorder {
+	return m.recorder
+}
+
+// DecodeFromBase62 mocks base method.
+func (m *MockDecoder) DecodeFromBase62(encoded string) (uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DecodeFromBase62", encoded)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DecodeFromBase62 indicates an expected call of DecodeFromBase62.
+func (mr *MockDecoderMockRecorder) DecodeFromBase62(encoded interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DecodeFromBase62", reflect.TypeOf((*MockDecoder)(nil).DecodeFromBase62), encoded)
+}
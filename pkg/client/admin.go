@@ -0,0 +1,97 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Tenant owns an isolated namespace of links, its requests are authenticated by its API key
+type Tenant struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Suspended bool   `json:"suspended"`
+	// DailyQuota and MonthlyQuota limit the links created per UTC day and month, 0 means unlimited
+	DailyQuota   int64     `json:"daily_quota,omitempty"`
+	MonthlyQuota int64     `json:"monthly_quota,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// CounterState is the allocation state of the ids of short URLs of a namespace
+type CounterState struct {
+	// Tenant is empty for the default namespace
+	Tenant       string `json:"tenant,omitempty"`
+	Count        int64  `json:"count"`
+	NextShortURL string `json:"next_short_url"`
+}
+
+// ListTenants returns all tenants
+func (c *Client) ListTenants(ctx context.Context) ([]Tenant, error) {
+	var list []Tenant
+	if err := c.Do(ctx, http.MethodGet, "/api/v1/admin/tenants", nil, nil, &list); err != nil {
+		return nil, fmt.Errorf("failed to list tenants: %w", err)
+	}
+
+	return list, nil
+}
+
+// CreateTenant creates a tenant with the id, name and quotas of tenant and returns it with its API key, the key cannot be retrieved again
+func (c *Client) CreateTenant(ctx context.Context, tenant Tenant) (Tenant, string, error) {
+	request := struct {
+		ID           string `json:"id"`
+		Name         string `json:"name,omitempty"`
+		DailyQuota   int64  `json:"daily_quota,omitempty"`
+		MonthlyQuota int64  `json:"monthly_quota,omitempty"`
+	}{tenant.ID, tenant.Name, tenant.DailyQuota, tenant.MonthlyQuota}
+	var response struct {
+		Tenant
+		APIKey string `json:"api_key"`
+	}
+
+	if err := c.Do(ctx, http.MethodPost, "/api/v1/admin/tenants", nil, request, &response); err != nil {
+		return Tenant{}, "", fmt.Errorf("failed to create tenant: %w", err)
+	}
+
+	return response.Tenant, response.APIKey, nil
+}
+
+// SuspendTenant suspends or resumes a tenant, a suspended tenant can neither create nor serve links
+func (c *Client) SuspendTenant(ctx context.Context, id string, suspended bool) error {
+	action := "resume"
+	if suspended {
+		action = "suspend"
+	}
+
+	if err := c.Do(ctx, http.MethodPost, "/api/v1/admin/tenants/"+url.PathEscape(id)+"/"+action, nil, nil, nil); err != nil {
+		return fmt.Errorf("failed to %s tenant: %w", action, err)
+	}
+
+	return nil
+}
+
+// CounterState returns the counter state of the default namespace, or of the tenant if not empty
+func (c *Client) CounterState(ctx context.Context, tenant string) (CounterState, error) {
+	query := url.Values{}
+	if tenant != "" {
+		query.Set("tenant", tenant)
+	}
+
+	var state CounterState
+	if err := c.Do(ctx, http.MethodGet, "/api/v1/admin/counter", query, nil, &state); err != nil {
+		return CounterState{}, fmt.Errorf("failed to get counter state: %w", err)
+	}
+
+	return state, nil
+}
+
+// Export writes the archive of the default namespace, the counter state followed by every URL as JSONL
+func (c *Client) Export(ctx context.Context, writer io.Writer) error {
+	if err := c.Do(ctx, http.MethodGet, "/api/v1/admin/export", nil, nil, writer); err != nil {
+		return fmt.Errorf("failed to export archive: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,413 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// TenantHeader carries the API key of the tenant owning the links
+	TenantHeader = "X-Tenant-Key"
+	// AdminHeader carries the API key of the admin API
+	AdminHeader = "X-API-Key"
+	userAgent   = "url-shortener-client/1.0"
+	// maxErrorBody is the largest part of an error response read for its message
+	maxErrorBody = 4 << 10
+)
+
+// Shortener is the API of the URL shortener, implemented by Client and, for tests, by Fake
+type Shortener interface {
+	Create(ctx context.Context, request CreateRequest) (Link, error)
+	Get(ctx context.Context, code string) (Link, error)
+	Update(ctx context.Context, code string, metadata Metadata) (Link, error)
+	Delete(ctx context.Context, code string) error
+	Stats(ctx context.Context, code string) (Stats, error)
+}
+
+// Metadata describes a link to its owners, it can be changed without changing the short URL
+type Metadata struct {
+	Title       string   `json:"title,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	Folder      string   `json:"folder,omitempty"`
+}
+
+// CreateRequest holds the settings of a new link, only LongURL is required
+type CreateRequest struct {
+	LongURL string `json:"long_url"`
+	// Domain is the branded domain of the link, the domain of the base URL is used if empty
+	Domain       string `json:"domain,omitempty"`
+	RedirectType int    `json:"redirect_type,omitempty"`
+	Password     string `json:"password,omitempty"`
+	// MaxClicks is the number of redirects after which the link stops working, zero means unlimited
+	MaxClicks int64 `json:"max_clicks,omitempty"`
+	// NotBefore and NotAfter limit when the link redirects, FallbackURL is used before NotBefore
+	NotBefore   *time.Time `json:"not_before,omitempty"`
+	NotAfter    *time.Time `json:"not_after,omitempty"`
+	FallbackURL string     `json:"fallback_url,omitempty"`
+	Metadata
+}
+
+// Link is a short URL with its settings
+type Link struct {
+	// ShortURL is the code of the link, the path of the full short URL
+	ShortURL     string     `json:"short_url"`
+	Domain       string     `json:"domain,omitempty"`
+	LongURL      string     `json:"long_url"`
+	RedirectType int        `json:"redirect_type,omitempty"`
+	MaxClicks    int64      `json:"max_clicks,omitempty"`
+	NotBefore    *time.Time `json:"not_before,omitempty"`
+	NotAfter     *time.Time `json:"not_after,omitempty"`
+	// Protected is set for password protected links
+	Protected bool `json:"protected,omitempty"`
+	Metadata
+}
+
+// Stats holds the click counts of a link, clicks are only counted for links with max clicks
+type Stats struct {
+	ShortURL      string           `json:"short_url"`
+	Domain        string           `json:"domain,omitempty"`
+	Clicks        int64            `json:"clicks"`
+	MaxClicks     int64            `json:"max_clicks,omitempty"`
+	VariantClicks map[string]int64 `json:"variant_clicks,omitempty"`
+}
+
+// ListOptions filter listed links, a link must have all tags and be within the folder or any of its subfolders
+type ListOptions struct {
+	Tags   []string
+	Folder string
+	// Limit is the maximum number of returned links, the service default is used if 0
+	Limit int
+}
+
+// BulkResult is the outcome of creating a single link of a bulk request
+type BulkResult struct {
+	LongURL  string `json:"long_url"`
+	ShortURL string `json:"short_url,omitempty"`
+	Reused   bool   `json:"reused"`
+	Error    string `json:"error,omitempty"`
+}
+
+type Config struct {
+	// BaseURL is the address of the service, its host selects the branded domain links are looked up on
+	BaseURL string
+	// APIKey is the tenant key sent in the X-Tenant-Key header, requests use the default namespace if empty
+	APIKey string
+	// AdminKey is sent in the X-API-Key header, it is required by the admin methods and by Update and Delete
+	// in the default namespace
+	AdminKey string
+	// HTTPClient sends the requests, http.DefaultClient is used if nil
+	HTTPClient *http.Client
+	// MaxRetries is the number of retries of requests answered with 429 or 5xx, retries wait from BaseBackoff
+	// doubling up to MaxBackoff, or as long as the Retry-After header asks if it is shorter than MaxBackoff
+	MaxRetries  int
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+}
+
+// DefaultConfig returns the config of a client retrying 3 times, from 200ms up to 5s
+func DefaultConfig(baseURL, apiKey string) Config {
+	return Config{
+		BaseURL:     baseURL,
+		APIKey:      apiKey,
+		MaxRetries:  3,
+		BaseBackoff: 200 * time.Millisecond,
+		MaxBackoff:  5 * time.Second,
+	}
+}
+
+// Client calls the HTTP API of the service
+type Client struct {
+	baseURL *url.URL
+	config  Config
+	client  *http.Client
+}
+
+var _ Shortener = (*Client)(nil)
+
+// New is a constructor function
+func New(config Config) (*Client, error) {
+	baseURL, err := url.Parse(strings.TrimSuffix(config.BaseURL, "/"))
+	if err != nil || (baseURL.Scheme != "http" && baseURL.Scheme != "https") || baseURL.Host == "" {
+		return nil, fmt.Errorf("invalid base url [%s]", config.BaseURL)
+	}
+
+	client := config.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	return &Client{baseURL: baseURL, config: config, client: client}, nil
+}
+
+// Create creates a link with its own settings, it is never reused for the same long URL
+func (c *Client) Create(ctx context.Context, request CreateRequest) (Link, error) {
+	var link Link
+	if err := c.Do(ctx, http.MethodPost, "/api/v1/urls", nil, request, &link); err != nil {
+		return Link{}, fmt.Errorf("failed to create link: %w", err)
+	}
+
+	return link, nil
+}
+
+// Get returns the link of the code
+func (c *Client) Get(ctx context.Context, code string) (Link, error) {
+	var link Link
+	if err := c.Do(ctx, http.MethodGet, "/api/v1/urls/"+url.PathEscape(code), nil, nil, &link); err != nil {
+		return Link{}, fmt.Errorf("failed to get link: %w", err)
+	}
+
+	return link, nil
+}
+
+// Update replaces the metadata of a link and returns the updated link
+func (c *Client) Update(ctx context.Context, code string, metadata Metadata) (Link, error) {
+	var link Link
+	if err := c.Do(ctx, http.MethodPut, "/api/v1/urls/"+url.PathEscape(code)+"/metadata", nil, metadata, &link); err != nil {
+		return Link{}, fmt.Errorf("failed to update link: %w", err)
+	}
+
+	return link, nil
+}
+
+// Delete deletes a link
+func (c *Client) Delete(ctx context.Context, code string) error {
+	if err := c.Do(ctx, http.MethodDelete, "/api/v1/urls/"+url.PathEscape(code), nil, nil, nil); err != nil {
+		return fmt.Errorf("failed to delete link: %w", err)
+	}
+
+	return nil
+}
+
+// Stats returns the click counts of a link
+func (c *Client) Stats(ctx context.Context, code string) (Stats, error) {
+	var stats Stats
+	if err := c.Do(ctx, http.MethodGet, "/api/v1/urls/"+url.PathEscape(code)+"/stats", nil, nil, &stats); err != nil {
+		return Stats{}, fmt.Errorf("failed to get stats: %w", err)
+	}
+
+	return stats, nil
+}
+
+// List returns the links matching the options, ordered by short URL
+func (c *Client) List(ctx context.Context, options ListOptions) ([]Link, error) {
+	links, err := c.list(ctx, "/api/v1/urls", options.query())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list links: %w", err)
+	}
+
+	return links, nil
+}
+
+// Search returns the links matching the options whose long URL, title, description or tags contain words
+// starting with all words of text
+func (c *Client) Search(ctx context.Context, text string, options ListOptions) ([]Link, error) {
+	query := options.query()
+	query.Set("q", text)
+	links, err := c.list(ctx, "/api/v1/urls/search", query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search links: %w", err)
+	}
+
+	return links, nil
+}
+
+// CreateBulk creates links for many long URLs at once, already shortened long URLs are reused
+// Failing URLs do not fail the request, their error is reported in their result
+func (c *Client) CreateBulk(ctx context.Context, longURLs []string) ([]BulkResult, error) {
+	var response struct {
+		Results []BulkResult `json:"results"`
+	}
+
+	if err := c.Do(ctx, http.MethodPost, "/api/v1/urls/bulk", nil, map[string][]string{"long_urls": longURLs}, &response); err != nil {
+		return nil, fmt.Errorf("failed to create links: %w", err)
+	}
+
+	return response.Results, nil
+}
+
+func (c *Client) list(ctx context.Context, path string, query url.Values) ([]Link, error) {
+	var response struct {
+		Results []Link `json:"results"`
+	}
+
+	if err := c.Do(ctx, http.MethodGet, path, query, nil, &response); err != nil {
+		return nil, err
+	}
+
+	return response.Results, nil
+}
+
+func (o ListOptions) query() url.Values {
+	query := url.Values{}
+	for _, tag := range o.Tags {
+		query.Add("tag", tag)
+	}
+
+	if o.Folder != "" {
+		query.Set("folder", o.Folder)
+	}
+
+	if o.Limit > 0 {
+		query.Set("limit", strconv.Itoa(o.Limit))
+	}
+
+	return query
+}
+
+// Do sends a request with the JSON of body, if not nil, and decodes the JSON response into result, if not nil,
+// or copies the response into result if it is an io.Writer
+// Error statuses are returned as APIError. Requests answered with 429 or 503 are retried, other 5xx statuses and
+// network errors only for methods other than POST, since the service may have processed the request
+func (c *Client) Do(ctx context.Context, method, path string, query url.Values, body, result interface{}) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+	}
+
+	target := *c.baseURL
+	target.Path += path
+	target.RawQuery = query.Encode()
+	for attempt := 0; ; attempt++ {
+		response, err := c.send(ctx, method, target.String(), payload)
+		if err == nil && response.StatusCode < 300 {
+			defer response.Body.Close()
+			if result == nil || response.StatusCode == http.StatusNoContent {
+				return nil
+			}
+
+			if writer, ok := result.(io.Writer); ok {
+				if _, err := io.Copy(writer, response.Body); err != nil {
+					return fmt.Errorf("failed to read response: %w", err)
+				}
+
+				return nil
+			}
+
+			if err := json.NewDecoder(response.Body).Decode(result); err != nil {
+				return fmt.Errorf("failed to decode response: %w", err)
+			}
+
+			return nil
+		}
+
+		var wait time.Duration
+		if err != nil {
+			if ctx.Err() != nil || method == http.MethodPost {
+				return err
+			}
+		} else {
+			err = toAPIError(response)
+			wait = retryAfter(response.Header.Get("Retry-After"))
+			if !retryable(method, response.StatusCode) {
+				return err
+			}
+		}
+
+		if attempt >= c.config.MaxRetries {
+			return err
+		}
+
+		if backoff := c.backoff(attempt); wait <= 0 || wait > c.config.MaxBackoff {
+			wait = backoff
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+func (c *Client) send(ctx context.Context, method, target string, payload []byte) (*http.Response, error) {
+	var body io.Reader
+	if payload != nil {
+		body = bytes.NewReader(payload)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, method, target, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	if payload != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("User-Agent", userAgent)
+	if c.config.APIKey != "" {
+		request.Header.Set(TenantHeader, c.config.APIKey)
+	}
+
+	if c.config.AdminKey != "" {
+		request.Header.Set(AdminHeader, c.config.AdminKey)
+	}
+
+	return c.client.Do(request)
+}
+
+// backoff returns the wait before the retry following the attempt, doubling from BaseBackoff up to MaxBackoff with 10% jitter
+func (c *Client) backoff(attempt int) time.Duration {
+	wait := c.config.BaseBackoff
+	for i := 0; i < attempt && wait < c.config.MaxBackoff; i++ {
+		wait *= 2
+	}
+
+	if wait > c.config.MaxBackoff {
+		wait = c.config.MaxBackoff
+	}
+
+	if jitter := int64(wait / 10); jitter > 0 {
+		wait += time.Duration(rand.Int63n(jitter))
+	}
+
+	return wait
+}
+
+func retryable(method string, status int) bool {
+	if status == http.StatusTooManyRequests || status == http.StatusServiceUnavailable {
+		return true
+	}
+
+	return status >= 500 && method != http.MethodPost
+}
+
+// retryAfter returns the wait asked for by a Retry-After header in seconds, 0 if there is none
+func retryAfter(header string) time.Duration {
+	seconds, err := strconv.Atoi(header)
+	if err != nil || seconds < 0 {
+		return 0
+	}
+
+	return time.Duration(seconds) * time.Second
+}
+
+// toAPIError reads the message of an error response and closes it, the service answers errors with a JSON string
+func toAPIError(response *http.Response) APIError {
+	defer response.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(response.Body, maxErrorBody))
+	var message string
+	if err := json.Unmarshal(body, &message); err != nil {
+		message = strings.TrimSpace(string(body))
+	}
+
+	if message == "" {
+		message = http.StatusText(response.StatusCode)
+	}
+
+	return NewAPIError(response.StatusCode, message)
+}
@@ -0,0 +1,175 @@
+package client_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"time"
+	"url-shortener/pkg/client"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Client", func() {
+	var (
+		server   *httptest.Server
+		handler  http.HandlerFunc
+		requests int32
+		c        *client.Client
+	)
+
+	BeforeEach(func() {
+		requests = 0
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&requests, 1)
+			handler(w, r)
+		}))
+
+		config := client.DefaultConfig(server.URL, "key")
+		config.BaseBackoff, config.MaxBackoff = time.Millisecond, 5*time.Millisecond
+		var err error
+		c, err = client.New(config)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	respond := func(w http.ResponseWriter, status int, body string) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		io.WriteString(w, body)
+	}
+
+	When("creating a link", func() {
+		It("should post its settings with the tenant key", func() {
+			handler = func(w http.ResponseWriter, r *http.Request) {
+				defer GinkgoRecover()
+				Expect(r.Method).To(Equal(http.MethodPost))
+				Expect(r.URL.Path).To(Equal("/api/v1/urls"))
+				Expect(r.Header.Get(client.TenantHeader)).To(Equal("key"))
+
+				var body map[string]interface{}
+				Expect(json.NewDecoder(r.Body).Decode(&body)).To(Succeed())
+				Expect(body).To(HaveKeyWithValue("long_url", "https://example.com"))
+				Expect(body).To(HaveKeyWithValue("title", "Spring"))
+				Expect(body).ToNot(HaveKey("password"))
+				respond(w, http.StatusCreated, `{"short_url":"abc","long_url":"https://example.com","title":"Spring"}`)
+			}
+
+			link, err := c.Create(context.Background(), client.CreateRequest{
+				LongURL:  "https://example.com",
+				Metadata: client.Metadata{Title: "Spring"},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(link.ShortURL).To(Equal("abc"))
+			Expect(link.Title).To(Equal("Spring"))
+		})
+
+		It("should not retry server errors, the link may have been created", func() {
+			handler = func(w http.ResponseWriter, r *http.Request) {
+				respond(w, http.StatusInternalServerError, `"Error occured while creating short URL"`)
+			}
+
+			_, err := c.Create(context.Background(), client.CreateRequest{LongURL: "https://example.com"})
+			var apiErr client.APIError
+			Expect(errors.As(err, &apiErr)).To(BeTrue())
+			Expect(apiErr.StatusCode).To(Equal(http.StatusInternalServerError))
+			Expect(apiErr.Message).To(Equal("Error occured while creating short URL"))
+			Expect(atomic.LoadInt32(&requests)).To(Equal(int32(1)))
+		})
+
+		It("should retry when it is rate limited", func() {
+			handler = func(w http.ResponseWriter, r *http.Request) {
+				if atomic.LoadInt32(&requests) < 3 {
+					respond(w, http.StatusTooManyRequests, `"The daily quota of 10 links is exceeded"`)
+					return
+				}
+
+				respond(w, http.StatusCreated, `{"short_url":"abc","long_url":"https://example.com"}`)
+			}
+
+			link, err := c.Create(context.Background(), client.CreateRequest{LongURL: "https://example.com"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(link.ShortURL).To(Equal("abc"))
+			Expect(atomic.LoadInt32(&requests)).To(Equal(int32(3)))
+		})
+	})
+
+	When("getting a link", func() {
+		It("should retry server errors up to the max retries", func() {
+			handler = func(w http.ResponseWriter, r *http.Request) {
+				respond(w, http.StatusBadGateway, "")
+			}
+
+			_, err := c.Get(context.Background(), "abc")
+			Expect(err).To(HaveOccurred())
+			Expect(atomic.LoadInt32(&requests)).To(Equal(int32(4)))
+		})
+
+		It("should report links which do not exist", func() {
+			handler = func(w http.ResponseWriter, r *http.Request) {
+				respond(w, http.StatusNotFound, `"URL does not exist"`)
+			}
+
+			_, err := c.Get(context.Background(), "abc")
+			Expect(client.IsNotFound(err)).To(BeTrue())
+			Expect(atomic.LoadInt32(&requests)).To(Equal(int32(1)))
+		})
+
+		It("should stop retrying once the context is done", func() {
+			handler = func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Retry-After", "1")
+				respond(w, http.StatusServiceUnavailable, "")
+			}
+
+			config := client.DefaultConfig(server.URL, "")
+			config.MaxBackoff = time.Minute
+			c, err := client.New(config)
+			Expect(err).ToNot(HaveOccurred())
+
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+			_, err = c.Get(ctx, "abc")
+			Expect(err).To(MatchError(context.DeadlineExceeded))
+		})
+	})
+
+	When("updating, deleting and getting the stats of a link", func() {
+		It("should call their endpoints", func() {
+			handler = func(w http.ResponseWriter, r *http.Request) {
+				switch r.Method + " " + r.URL.Path {
+				case "PUT /api/v1/urls/abc/metadata":
+					respond(w, http.StatusOK, `{"short_url":"abc","folder":"campaigns"}`)
+				case "DELETE /api/v1/urls/abc":
+					w.WriteHeader(http.StatusNoContent)
+				case "GET /api/v1/urls/abc/stats":
+					respond(w, http.StatusOK, `{"short_url":"abc","clicks":3,"max_clicks":10}`)
+				default:
+					respond(w, http.StatusNotFound, `"URL does not exist"`)
+				}
+			}
+
+			link, err := c.Update(context.Background(), "abc", client.Metadata{Folder: "campaigns"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(link.Folder).To(Equal("campaigns"))
+
+			Expect(c.Delete(context.Background(), "abc")).To(Succeed())
+
+			stats, err := c.Stats(context.Background(), "abc")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(stats.Clicks).To(Equal(int64(3)))
+		})
+	})
+
+	It("should reject invalid base urls", func() {
+		_, err := client.New(client.Config{BaseURL: "localhost:8080"})
+		Expect(err).To(HaveOccurred())
+	})
+})
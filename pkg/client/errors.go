@@ -0,0 +1,27 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// APIError is returned for requests the service answered with an error status
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func NewAPIError(statusCode int, message string) APIError {
+	return APIError{StatusCode: statusCode, Message: message}
+}
+
+func (e APIError) Error() string {
+	return fmt.Sprintf("request failed with status %d: %s", e.StatusCode, e.Message)
+}
+
+// IsNotFound reports whether the error is caused by a link which does not exist
+func IsNotFound(err error) bool {
+	var apiErr APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
@@ -0,0 +1,140 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+)
+
+// Fake is an in-memory Shortener for tests of code using the client
+// It answers like the service: missing links fail with a 404 APIError and invalid requests with a 400 one
+type Fake struct {
+	mu     sync.Mutex
+	next   int64
+	links  map[string]Link
+	clicks map[string]int64
+}
+
+var _ Shortener = (*Fake)(nil)
+
+// NewFake is a constructor function
+func NewFake() *Fake {
+	return &Fake{links: map[string]Link{}, clicks: map[string]int64{}}
+}
+
+// Create stores a link with the next sequential code
+func (f *Fake) Create(ctx context.Context, request CreateRequest) (Link, error) {
+	if err := ctx.Err(); err != nil {
+		return Link{}, err
+	}
+
+	if request.LongURL == "" {
+		return Link{}, NewAPIError(http.StatusBadRequest, "Invalid request body")
+	}
+
+	if request.MaxClicks < 0 {
+		return Link{}, NewAPIError(http.StatusBadRequest, "Max clicks must not be negative")
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.next++
+	link := Link{
+		ShortURL:     strconv.FormatInt(f.next, 36),
+		Domain:       request.Domain,
+		LongURL:      request.LongURL,
+		RedirectType: request.RedirectType,
+		MaxClicks:    request.MaxClicks,
+		NotBefore:    request.NotBefore,
+		NotAfter:     request.NotAfter,
+		Protected:    request.Password != "",
+		Metadata:     request.Metadata,
+	}
+
+	f.links[link.ShortURL] = link
+	return link, nil
+}
+
+// Get returns a stored link
+func (f *Fake) Get(ctx context.Context, code string) (Link, error) {
+	if err := ctx.Err(); err != nil {
+		return Link{}, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	link, ok := f.links[code]
+	if !ok {
+		return Link{}, notFound()
+	}
+
+	return link, nil
+}
+
+// Update replaces the metadata of a stored link
+func (f *Fake) Update(ctx context.Context, code string, metadata Metadata) (Link, error) {
+	if err := ctx.Err(); err != nil {
+		return Link{}, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	link, ok := f.links[code]
+	if !ok {
+		return Link{}, notFound()
+	}
+
+	link.Metadata = metadata
+	f.links[code] = link
+	return link, nil
+}
+
+// Delete removes a stored link
+func (f *Fake) Delete(ctx context.Context, code string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.links[code]; !ok {
+		return notFound()
+	}
+
+	delete(f.links, code)
+	delete(f.clicks, code)
+	return nil
+}
+
+// Stats returns the clicks recorded with Click
+func (f *Fake) Stats(ctx context.Context, code string) (Stats, error) {
+	if err := ctx.Err(); err != nil {
+		return Stats{}, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	link, ok := f.links[code]
+	if !ok {
+		return Stats{}, notFound()
+	}
+
+	return Stats{ShortURL: code, Domain: link.Domain, Clicks: f.clicks[code], MaxClicks: link.MaxClicks}, nil
+}
+
+// Click records a visit of a stored link, it reports false if the link does not exist
+func (f *Fake) Click(code string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.links[code]; !ok {
+		return false
+	}
+
+	f.clicks[code]++
+	return true
+}
+
+func notFound() APIError {
+	return NewAPIError(http.StatusNotFound, "URL does not exist")
+}
@@ -0,0 +1,47 @@
+package client_test
+
+import (
+	"context"
+	"url-shortener/pkg/client"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Fake", func() {
+	var (
+		fake *client.Fake
+		ctx  context.Context
+	)
+
+	BeforeEach(func() {
+		fake = client.NewFake()
+		ctx = context.Background()
+	})
+
+	It("should keep created links until they are deleted", func() {
+		link, err := fake.Create(ctx, client.CreateRequest{LongURL: "https://example.com", MaxClicks: 10})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(link.ShortURL).ToNot(BeEmpty())
+
+		updated, err := fake.Update(ctx, link.ShortURL, client.Metadata{Title: "Spring"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(updated.Title).To(Equal("Spring"))
+
+		Expect(fake.Click(link.ShortURL)).To(BeTrue())
+		stats, err := fake.Stats(ctx, link.ShortURL)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(stats.Clicks).To(Equal(int64(1)))
+		Expect(stats.MaxClicks).To(Equal(int64(10)))
+
+		Expect(fake.Delete(ctx, link.ShortURL)).To(Succeed())
+		_, err = fake.Get(ctx, link.ShortURL)
+		Expect(client.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("should reject links without a long url", func() {
+		_, err := fake.Create(ctx, client.CreateRequest{})
+		Expect(err).To(HaveOccurred())
+		Expect(client.IsNotFound(err)).To(BeFalse())
+	})
+})
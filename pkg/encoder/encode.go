@@ -1,5 +1,10 @@
 package encoder
 
+import (
+	"fmt"
+	"strings"
+)
+
 type Encoder struct {
 }
 
@@ -24,3 +29,18 @@ func (e Encoder) EncodeToBase62(number uint64) string {
 
 	return encoded
 }
+
+// DecodeFromBase62 accepts a base62 string and returns the number it represents
+func (e Encoder) DecodeFromBase62(encoded string) (uint64, error) {
+	var number uint64
+	for _, r := range encoded {
+		index := strings.IndexRune(characterSet, r)
+		if index < 0 {
+			return 0, fmt.Errorf("invalid base62 character [%c]", r)
+		}
+
+		number = number*base + uint64(index)
+	}
+
+	return number, nil
+}
@@ -14,4 +14,18 @@ var _ = Describe("Encoder", func() {
 			Expect(encodedNumber).To(Equal("qW"))
 		})
 	})
+	When("decoding a base62 string", func() {
+		It("should return the number it represents", func() {
+			number, err := encoder.New().DecodeFromBase62("qW")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(number).To(Equal(uint64(3256)))
+		})
+	})
+
+	When("decoding a string with invalid characters", func() {
+		It("should return an error", func() {
+			_, err := encoder.New().DecodeFromBase62("q-W")
+			Expect(err).To(HaveOccurred())
+		})
+	})
 })
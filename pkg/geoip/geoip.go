@@ -0,0 +1,146 @@
+package geoip
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sort"
+	"strings"
+)
+
+// DB maps IP networks to ISO 3166 country codes
+// It is loaded from a local CSV file with a network in CIDR notation and a country code per line,
+// e.g. `1.0.0.0/24,AU`. A header line and lines starting with # are skipped.
+// Networks may be nested, an IP gets the country of the most specific network containing it.
+type DB struct {
+	ranges []ipRange
+}
+
+type ipRange struct {
+	first   net.IP
+	last    net.IP
+	country string
+	// parent is the index of the smallest range enclosing this one, -1 if there is none
+	parent int
+}
+
+// Load reads the database from a CSV file
+func Load(path string) (*DB, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open geoip file: %w", err)
+	}
+	defer file.Close()
+
+	return Parse(file)
+}
+
+// Parse reads the database from CSV
+func Parse(reader io.Reader) (*DB, error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.FieldsPerRecord = -1
+	csvReader.Comment = '#'
+
+	var ranges []ipRange
+	for line := 1; ; line++ {
+		record, err := csvReader.Read()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to read geoip line [%d]: %w", line, err)
+		}
+
+		if len(record) < 2 {
+			return nil, fmt.Errorf("geoip line [%d] has no country", line)
+		}
+
+		_, network, err := net.ParseCIDR(strings.TrimSpace(record[0]))
+		if err != nil {
+			if line == 1 {
+				continue
+			}
+
+			return nil, fmt.Errorf("invalid network on geoip line [%d]: %w", line, err)
+		}
+
+		ranges = append(ranges, ipRange{
+			first:   network.IP.To16(),
+			last:    lastIP(network),
+			country: strings.ToUpper(strings.TrimSpace(record[1])),
+		})
+	}
+
+	// enclosing ranges come before the ranges they contain, networks are either nested or disjoint
+	sort.SliceStable(ranges, func(i, j int) bool {
+		if order := bytes.Compare(ranges[i].first, ranges[j].first); order != 0 {
+			return order < 0
+		}
+
+		return bytes.Compare(ranges[i].last, ranges[j].last) > 0
+	})
+
+	var enclosing []int
+	for i := range ranges {
+		for len(enclosing) > 0 && bytes.Compare(ranges[enclosing[len(enclosing)-1]].last, ranges[i].first) < 0 {
+			enclosing = enclosing[:len(enclosing)-1]
+		}
+
+		ranges[i].parent = -1
+		if len(enclosing) > 0 {
+			ranges[i].parent = enclosing[len(enclosing)-1]
+		}
+
+		enclosing = append(enclosing, i)
+	}
+
+	return &DB{ranges: ranges}, nil
+}
+
+// Country returns the country code of the IP, or empty string if it is unknown
+func (d *DB) Country(ip net.IP) string {
+	ip = ip.To16()
+	if d == nil || ip == nil {
+		return ""
+	}
+
+	i := sort.Search(len(d.ranges), func(i int) bool {
+		return bytes.Compare(d.ranges[i].first, ip) > 0
+	})
+	if i == 0 {
+		return ""
+	}
+
+	// the last range starting at or before the IP is the most specific candidate, otherwise one of its parents
+	for j := i - 1; j >= 0; j = d.ranges[j].parent {
+		if bytes.Compare(ip, d.ranges[j].last) <= 0 {
+			return d.ranges[j].country
+		}
+	}
+
+	return ""
+}
+
+// Len returns the number of networks in the database
+func (d *DB) Len() int {
+	return len(d.ranges)
+}
+
+func lastIP(network *net.IPNet) net.IP {
+	ip := network.IP.To16()
+	mask := network.Mask
+	if len(mask) == net.IPv4len {
+		mask = append(net.CIDRMask(96, 128)[:12:12], mask...)
+	}
+
+	last := make(net.IP, net.IPv6len)
+	for i := range last {
+		last[i] = ip[i] | ^mask[i]
+	}
+
+	return last
+}
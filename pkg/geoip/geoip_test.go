@@ -0,0 +1,69 @@
+package geoip_test
+
+import (
+	"net"
+	"strings"
+	"url-shortener/pkg/geoip"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("GeoIP", func() {
+	const data = `network,country_code
+# comment
+1.0.0.0/24,au
+81.2.68.0/23,GB
+2a02:8100::/32,DE
+`
+
+	var db *geoip.DB
+
+	BeforeEach(func() {
+		var err error
+		db, err = geoip.Parse(strings.NewReader(data))
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	When("parsing a file", func() {
+		It("should skip the header and comments", func() {
+			Expect(db.Len()).To(Equal(3))
+		})
+	})
+
+	When("parsing a file with an invalid network", func() {
+		It("should return an error", func() {
+			_, err := geoip.Parse(strings.NewReader("1.0.0.0/24,AU\ninvalid,DE\n"))
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	When("looking up an IP within a network", func() {
+		It("should return its country", func() {
+			Expect(db.Country(net.ParseIP("1.0.0.255"))).To(Equal("AU"))
+			Expect(db.Country(net.ParseIP("81.2.69.200"))).To(Equal("GB"))
+			Expect(db.Country(net.ParseIP("2a02:8100:1::1"))).To(Equal("DE"))
+		})
+	})
+
+	When("looking up an IP within nested networks", func() {
+		It("should return the country of the most specific network", func() {
+			nested, err := geoip.Parse(strings.NewReader("10.0.0.0/8,US\n10.1.0.0/16,CA\n10.1.2.0/24,MX\n10.0.0.0/16,GB\n"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(nested.Country(net.ParseIP("10.1.2.3"))).To(Equal("MX"))
+			Expect(nested.Country(net.ParseIP("10.1.3.0"))).To(Equal("CA"))
+			Expect(nested.Country(net.ParseIP("10.0.0.1"))).To(Equal("GB"))
+			Expect(nested.Country(net.ParseIP("10.2.0.0"))).To(Equal("US"))
+			Expect(nested.Country(net.ParseIP("10.255.255.255"))).To(Equal("US"))
+		})
+	})
+
+	When("looking up an IP outside of all networks", func() {
+		It("should return empty country", func() {
+			Expect(db.Country(net.ParseIP("1.0.1.0"))).To(BeEmpty())
+			Expect(db.Country(net.ParseIP("81.2.70.0"))).To(BeEmpty())
+			Expect(db.Country(net.ParseIP("0.0.0.1"))).To(BeEmpty())
+			Expect(db.Country(nil)).To(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,58 @@
+package healthcheck
+
+import "time"
+
+// Check is the outcome of requesting a destination once
+type Check struct {
+	At time.Time `firestore:"at" json:"at"`
+	// Status is the HTTP status of the response, 0 if there was none
+	Status int `firestore:"status,omitempty" json:"status,omitempty"`
+	// Error describes why there was no response, e.g. a timeout or an unknown host
+	Error string `firestore:"error,omitempty" json:"error,omitempty"`
+}
+
+// OK reports whether the destination answered without a client or server error
+func (c Check) OK() bool {
+	return c.Error == "" && c.Status > 0 && c.Status < 400
+}
+
+// Policy decides how many checks are kept and when a failing destination is broken
+type Policy struct {
+	// History is the number of most recent checks kept
+	History int
+	// BrokenAfter is how long a destination must fail before it is flagged as broken
+	BrokenAfter time.Duration
+}
+
+// Health is the check history of a destination
+type Health struct {
+	CheckedAt time.Time `firestore:"checked_at" json:"checked_at"`
+	// Checks are the most recent checks, oldest first
+	Checks []Check `firestore:"checks" json:"checks"`
+	// FailingSince is the time of the first failed check since the last successful one
+	FailingSince *time.Time `firestore:"failing_since,omitempty" json:"failing_since,omitempty"`
+	// Broken is set once the destination has been failing for longer than the policy allows
+	Broken bool `firestore:"broken" json:"broken"`
+}
+
+// Record returns the health after adding the check
+func (h Health) Record(check Check, policy Policy) Health {
+	checks := append(append([]Check{}, h.Checks...), check)
+	if len(checks) > policy.History {
+		checks = checks[len(checks)-policy.History:]
+	}
+
+	health := Health{CheckedAt: check.At, Checks: checks}
+	if check.OK() {
+		return health
+	}
+
+	health.FailingSince = h.FailingSince
+	if health.FailingSince == nil {
+		at := check.At
+		health.FailingSince = &at
+	}
+
+	health.Broken = check.At.Sub(*health.FailingSince) >= policy.BrokenAfter
+	return health
+}
@@ -0,0 +1,67 @@
+package healthcheck_test
+
+import (
+	"time"
+	"url-shortener/pkg/healthcheck"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Health", func() {
+	var (
+		start  time.Time
+		policy healthcheck.Policy
+	)
+
+	BeforeEach(func() {
+		start = time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC)
+		policy = healthcheck.Policy{History: 3, BrokenAfter: 24 * time.Hour}
+	})
+
+	failed := func(at time.Time) healthcheck.Check {
+		return healthcheck.Check{At: at, Status: 404}
+	}
+
+	When("a destination starts failing", func() {
+		It("should remember since when but not flag it yet", func() {
+			health := healthcheck.Health{}.Record(failed(start), policy)
+			Expect(health.FailingSince).To(Equal(&start))
+			Expect(health.Broken).To(BeFalse())
+		})
+	})
+
+	When("a destination has been failing longer than allowed", func() {
+		It("should flag it as broken", func() {
+			health := healthcheck.Health{}.Record(failed(start), policy)
+			health = health.Record(failed(start.Add(12*time.Hour)), policy)
+			Expect(health.Broken).To(BeFalse())
+
+			health = health.Record(healthcheck.Check{At: start.Add(24 * time.Hour), Error: "timeout"}, policy)
+			Expect(health.Broken).To(BeTrue())
+			Expect(health.FailingSince).To(Equal(&start))
+		})
+	})
+
+	When("a failing destination recovers", func() {
+		It("should clear the failure", func() {
+			health := healthcheck.Health{}.Record(failed(start), policy)
+			health = health.Record(healthcheck.Check{At: start.Add(48 * time.Hour), Status: 200}, policy)
+			Expect(health.FailingSince).To(BeNil())
+			Expect(health.Broken).To(BeFalse())
+		})
+	})
+
+	When("there are more checks than the history keeps", func() {
+		It("should keep the most recent ones", func() {
+			health := healthcheck.Health{}
+			for i := 0; i < 5; i++ {
+				health = health.Record(healthcheck.Check{At: start.Add(time.Duration(i) * time.Hour), Status: 200}, policy)
+			}
+
+			Expect(health.Checks).To(HaveLen(3))
+			Expect(health.Checks[0].At).To(Equal(start.Add(2 * time.Hour)))
+			Expect(health.CheckedAt).To(Equal(start.Add(4 * time.Hour)))
+		})
+	})
+})
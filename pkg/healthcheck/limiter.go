@@ -0,0 +1,78 @@
+package healthcheck
+
+import (
+	"container/heap"
+	"math/rand"
+	"time"
+)
+
+// hostQueue holds the links of a host waiting to be checked and the earliest time of its next request
+type hostQueue struct {
+	links []Link
+	next  time.Time
+}
+
+// hostSchedule orders the queues of hosts by their next request, requests to the same host are spaced
+// by at least interval plus a random jitter, so checks never flood a single destination host while
+// the links of other hosts are checked in between
+type hostSchedule struct {
+	interval time.Duration
+	jitter   time.Duration
+	queues   []*hostQueue
+}
+
+func newHostSchedule(interval, jitter time.Duration, links map[string][]Link) *hostSchedule {
+	schedule := &hostSchedule{interval: interval, jitter: jitter}
+	now := time.Now()
+	for _, hostLinks := range links {
+		schedule.queues = append(schedule.queues, &hostQueue{links: hostLinks, next: now})
+	}
+
+	heap.Init(schedule)
+	return schedule
+}
+
+// Next returns the next link of the earliest host and the time it may be requested,
+// ok is false once every link has been sent
+func (s *hostSchedule) Next() (link Link, at time.Time, ok bool) {
+	if len(s.queues) == 0 {
+		return Link{}, time.Time{}, false
+	}
+
+	return s.queues[0].links[0], s.queues[0].next, true
+}
+
+// Sent removes the link returned by Next, the next request to its host is allowed interval plus jitter after sent
+func (s *hostSchedule) Sent(sent time.Time) {
+	queue := s.queues[0]
+	queue.links = queue.links[1:]
+	if len(queue.links) == 0 {
+		heap.Pop(s)
+		return
+	}
+
+	queue.next = sent.Add(s.interval + randomDuration(s.jitter))
+	heap.Fix(s, 0)
+}
+
+func (s *hostSchedule) Len() int { return len(s.queues) }
+
+func (s *hostSchedule) Less(i, j int) bool { return s.queues[i].next.Before(s.queues[j].next) }
+
+func (s *hostSchedule) Swap(i, j int) { s.queues[i], s.queues[j] = s.queues[j], s.queues[i] }
+
+func (s *hostSchedule) Push(x interface{}) { s.queues = append(s.queues, x.(*hostQueue)) }
+
+func (s *hostSchedule) Pop() interface{} {
+	last := s.queues[len(s.queues)-1]
+	s.queues = s.queues[:len(s.queues)-1]
+	return last
+}
+
+func randomDuration(max time.Duration) time.Duration {
+	if max <= 0 {
+		return 0
+	}
+
+	return time.Duration(rand.Int63n(int64(max)))
+}
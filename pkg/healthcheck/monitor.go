@@ -0,0 +1,175 @@
+package healthcheck
+
+import (
+	"context"
+	"fmt"
+	netURL "net/url"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Link is a destination to check
+type Link struct {
+	// Key is the document id of the link
+	Key       string
+	URL       string
+	CheckedAt time.Time
+}
+
+// Store lists the links of a namespace and records their checks
+type Store interface {
+	ForEachLink(ctx context.Context, fn func(link Link) error) error
+	RecordCheck(ctx context.Context, key string, check Check, policy Policy) error
+}
+
+// Config holds the monitor settings
+type Config struct {
+	// Interval is how often every link is checked
+	Interval time.Duration
+	// HostInterval is the least time between two requests to the same host
+	HostInterval time.Duration
+	// Jitter is the largest random delay added between requests to the same host and between runs
+	Jitter  time.Duration
+	Workers int
+	Policy  Policy
+}
+
+// Monitor periodically checks the destinations of links
+type Monitor struct {
+	prober *Prober
+	config Config
+}
+
+// NewMonitor is a constructor function
+func NewMonitor(prober *Prober, config Config) *Monitor {
+	return &Monitor{prober: prober, config: config}
+}
+
+// Run checks the links of the stores returned by stores every interval until ctx is done
+func (m *Monitor) Run(ctx context.Context, stores func(ctx context.Context) ([]Store, error)) {
+	for {
+		timer := time.NewTimer(randomDuration(m.config.Jitter))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		started := time.Now()
+		namespaces, err := stores(ctx)
+		if err != nil {
+			logrus.Errorf("failed to list stores to check: %v", err)
+		}
+
+		for _, store := range namespaces {
+			count, err := m.CheckDue(ctx, store)
+			if err != nil {
+				logrus.Errorf("failed to check links: %v", err)
+			}
+
+			logrus.Debugf("checked [%d] links", count)
+		}
+
+		timer = time.NewTimer(m.config.Interval - time.Since(started))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// CheckDue checks the links of the store which have not been checked within the last half interval
+// and returns the number of checked links. Due links are grouped by host and handed to the workers once
+// their host may be requested again, so workers never wait on a busy host while other hosts are due
+func (m *Monitor) CheckDue(ctx context.Context, store Store) (int, error) {
+	due := time.Now().Add(-m.config.Interval / 2)
+	hosts := make(map[string][]Link)
+	err := store.ForEachLink(ctx, func(link Link) error {
+		if link.CheckedAt.After(due) {
+			return nil
+		}
+
+		parsed, err := netURL.Parse(link.URL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+			return nil
+		}
+
+		hosts[parsed.Hostname()] = append(hosts[parsed.Hostname()], link)
+		return nil
+	})
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to list links: %w", err)
+	}
+
+	links := make(chan Link)
+	var (
+		wg    sync.WaitGroup
+		mu    sync.Mutex
+		count int
+	)
+
+	for i := 0; i < m.config.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for link := range links {
+				if m.check(ctx, store, link) {
+					mu.Lock()
+					count++
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+
+	err = m.dispatch(ctx, newHostSchedule(m.config.HostInterval, m.config.Jitter, hosts), links)
+	close(links)
+	wg.Wait()
+	if err != nil {
+		return count, fmt.Errorf("failed to dispatch links: %w", err)
+	}
+
+	return count, nil
+}
+
+// dispatch hands the links of the schedule to the workers, each link as soon as its host may be requested
+func (m *Monitor) dispatch(ctx context.Context, schedule *hostSchedule, links chan<- Link) error {
+	for {
+		link, at, ok := schedule.Next()
+		if !ok {
+			return nil
+		}
+
+		timer := time.NewTimer(time.Until(at))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case links <- link:
+		}
+
+		schedule.Sent(time.Now())
+	}
+}
+
+func (m *Monitor) check(ctx context.Context, store Store, link Link) bool {
+	check := m.prober.Probe(ctx, link.URL)
+	if err := store.RecordCheck(ctx, link.Key, check, m.config.Policy); err != nil {
+		logrus.Warnf("failed to record check of [%s]: %v", link.Key, err)
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,148 @@
+package healthcheck_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+	"url-shortener/pkg/healthcheck"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// store keeps links and their recorded checks in memory
+type store struct {
+	links  []healthcheck.Link
+	mu     sync.Mutex
+	checks map[string]healthcheck.Check
+}
+
+func (s *store) ForEachLink(ctx context.Context, fn func(link healthcheck.Link) error) error {
+	for _, link := range s.links {
+		if err := fn(link); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *store) RecordCheck(ctx context.Context, key string, check healthcheck.Check, policy healthcheck.Policy) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.checks[key] = check
+	return nil
+}
+
+var _ = Describe("Monitor", func() {
+	var (
+		ctx      context.Context
+		server   *httptest.Server
+		requests chan time.Time
+		monitor  *healthcheck.Monitor
+		links    *store
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		requests = make(chan time.Time, 10)
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests <- time.Now()
+			if r.URL.Path == "/gone" {
+				w.WriteHeader(http.StatusGone)
+			}
+		}))
+		monitor = healthcheck.NewMonitor(healthcheck.NewProber(server.Client()), healthcheck.Config{
+			Interval:     time.Hour,
+			HostInterval: 50 * time.Millisecond,
+			Workers:      2,
+			Policy:       healthcheck.Policy{History: 5, BrokenAfter: time.Hour},
+		})
+		links = &store{checks: map[string]healthcheck.Check{}}
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	When("links are due", func() {
+		BeforeEach(func() {
+			links.links = []healthcheck.Link{
+				{Key: "ok", URL: server.URL + "/ok"},
+				{Key: "gone", URL: server.URL + "/gone", CheckedAt: time.Now().Add(-time.Hour)},
+			}
+		})
+
+		It("should record their checks", func() {
+			count, err := monitor.CheckDue(ctx, links)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(count).To(Equal(2))
+			Expect(links.checks["ok"].OK()).To(BeTrue())
+			Expect(links.checks["gone"].Status).To(Equal(http.StatusGone))
+		})
+
+		It("should space requests to the same host", func() {
+			_, err := monitor.CheckDue(ctx, links)
+			Expect(err).NotTo(HaveOccurred())
+
+			first, second := <-requests, <-requests
+			Expect(second.Sub(first)).To(BeNumerically(">=", 40*time.Millisecond))
+		})
+	})
+
+	When("one host has more links due than others", func() {
+		var other *httptest.Server
+
+		BeforeEach(func() {
+			other = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests <- time.Time{}
+			}))
+			// a hostname other than the one of server, the host interval applies per hostname
+			otherURL := strings.Replace(other.URL, "127.0.0.1", "localhost", 1)
+			monitor = healthcheck.NewMonitor(healthcheck.NewProber(http.DefaultClient), healthcheck.Config{
+				Interval:     time.Hour,
+				HostInterval: 50 * time.Millisecond,
+				Workers:      1,
+				Policy:       healthcheck.Policy{History: 5, BrokenAfter: time.Hour},
+			})
+			links.links = []healthcheck.Link{
+				{Key: "first", URL: server.URL + "/first"},
+				{Key: "second", URL: server.URL + "/second"},
+				{Key: "other", URL: otherURL + "/ok"},
+			}
+		})
+
+		AfterEach(func() {
+			other.Close()
+		})
+
+		It("should check the other hosts while the busy host is spaced", func() {
+			count, err := monitor.CheckDue(ctx, links)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(count).To(Equal(3))
+
+			first, second, third := <-requests, <-requests, <-requests
+			Expect(first.IsZero() || second.IsZero()).To(BeTrue())
+			Expect(third.IsZero()).To(BeFalse())
+		})
+	})
+
+	When("links were checked recently or are not web URLs", func() {
+		BeforeEach(func() {
+			links.links = []healthcheck.Link{
+				{Key: "recent", URL: server.URL + "/ok", CheckedAt: time.Now().Add(-time.Minute)},
+				{Key: "mail", URL: "mailto:someone@example.com"},
+			}
+		})
+
+		It("should skip them", func() {
+			count, err := monitor.CheckDue(ctx, links)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(count).To(BeZero())
+			Expect(links.checks).To(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,50 @@
+package healthcheck
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+const userAgent = "url-shortener-healthcheck/1.0"
+
+// Prober requests destinations to check whether they still work
+type Prober struct {
+	client *http.Client
+}
+
+// NewProber is a constructor function, the client must not reach internal addresses, see httpclient.New
+func NewProber(client *http.Client) *Prober {
+	return &Prober{client: client}
+}
+
+// Probe requests the destination with HEAD and falls back to GET if HEAD fails with a client or server error,
+// as many servers do not implement HEAD. Redirects are followed, the body is never read
+func (p *Prober) Probe(ctx context.Context, url string) Check {
+	check := p.request(ctx, http.MethodHead, url)
+	if check.Error == "" && check.Status >= 400 {
+		return p.request(ctx, http.MethodGet, url)
+	}
+
+	return check
+}
+
+func (p *Prober) request(ctx context.Context, method, url string) Check {
+	check := Check{At: time.Now().UTC()}
+	request, err := http.NewRequestWithContext(ctx, method, url, nil)
+	if err != nil {
+		check.Error = err.Error()
+		return check
+	}
+
+	request.Header.Set("User-Agent", userAgent)
+	response, err := p.client.Do(request)
+	if err != nil {
+		check.Error = err.Error()
+		return check
+	}
+
+	response.Body.Close()
+	check.Status = response.StatusCode
+	return check
+}
@@ -0,0 +1,72 @@
+package healthcheck_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"url-shortener/pkg/healthcheck"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Prober", func() {
+	var (
+		ctx    context.Context
+		server *httptest.Server
+		prober *healthcheck.Prober
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		mux := http.NewServeMux()
+		mux.HandleFunc("/ok", func(w http.ResponseWriter, r *http.Request) {})
+		mux.HandleFunc("/get-only", func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+			}
+		})
+		mux.HandleFunc("/gone", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusGone)
+		})
+		server = httptest.NewServer(mux)
+		prober = healthcheck.NewProber(server.Client())
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	When("the destination answers HEAD", func() {
+		It("should succeed", func() {
+			Expect(prober.Probe(ctx, server.URL+"/ok").OK()).To(BeTrue())
+		})
+	})
+
+	When("the destination only answers GET", func() {
+		It("should fall back to GET", func() {
+			check := prober.Probe(ctx, server.URL+"/get-only")
+			Expect(check.Status).To(Equal(http.StatusOK))
+		})
+	})
+
+	When("the destination is gone", func() {
+		It("should fail with its status", func() {
+			check := prober.Probe(ctx, server.URL+"/gone")
+			Expect(check.OK()).To(BeFalse())
+			Expect(check.Status).To(Equal(http.StatusGone))
+		})
+	})
+
+	When("the destination does not answer", func() {
+		BeforeEach(func() {
+			server.Close()
+		})
+
+		It("should fail with the error", func() {
+			check := prober.Probe(ctx, server.URL+"/ok")
+			Expect(check.OK()).To(BeFalse())
+			Expect(check.Error).NotTo(BeEmpty())
+		})
+	})
+})
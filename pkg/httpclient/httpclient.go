@@ -0,0 +1,145 @@
+package httpclient
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+var (
+	// ErrForbiddenAddress is returned for connections to private, loopback, link local or reserved addresses
+	ErrForbiddenAddress = errors.New("forbidden address")
+	// ErrTooManyRedirects is returned once a redirect chain is longer than allowed
+	ErrTooManyRedirects = errors.New("too many redirects")
+	// ErrBodyTooLarge is returned while reading a response body larger than allowed
+	ErrBodyTooLarge = errors.New("response body too large")
+)
+
+// Config holds the limits of the client
+type Config struct {
+	// Timeout limits a whole request including redirects and reading the body
+	Timeout time.Duration
+	// MaxRedirects is the longest followed redirect chain, any redirect fails if 0
+	MaxRedirects int
+	// MaxBodyBytes is the largest response body, bodies are not limited if 0
+	MaxBodyBytes int64
+	// AllowedNetworks are exceptions to the forbidden ranges, e.g. for trusted internal services
+	AllowedNetworks []*net.IPNet
+}
+
+// New returns an HTTP client for requests to user provided URLs
+// The client only connects to public addresses, checked after DNS resolution for every dialed address,
+// so neither a destination nor any of its redirects can make the service reach its internal network.
+// Proxies configured by environment are ignored, as connections through them cannot be checked
+func New(config Config) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: config.Timeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			return checkAddress(address, config.AllowedNetworks)
+		},
+	}
+
+	var transport http.RoundTripper = &http.Transport{
+		Proxy:                 nil,
+		DialContext:           dialer.DialContext,
+		TLSHandshakeTimeout:   config.Timeout,
+		ResponseHeaderTimeout: config.Timeout,
+		MaxIdleConns:          10,
+		IdleConnTimeout:       30 * time.Second,
+	}
+
+	if config.MaxBodyBytes > 0 {
+		transport = &limitedTransport{next: transport, maxBytes: config.MaxBodyBytes}
+	}
+
+	return &http.Client{
+		Transport: transport,
+		Timeout:   config.Timeout,
+		CheckRedirect: func(request *http.Request, via []*http.Request) error {
+			if len(via) > config.MaxRedirects {
+				return fmt.Errorf("%w: stopped after %d", ErrTooManyRedirects, config.MaxRedirects)
+			}
+
+			if request.URL.Scheme != "http" && request.URL.Scheme != "https" {
+				return fmt.Errorf("unsupported redirect scheme [%s]", request.URL.Scheme)
+			}
+
+			return nil
+		},
+	}
+}
+
+func checkAddress(address string, allowed []*net.IPNet) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return fmt.Errorf("%w [%s]", ErrForbiddenAddress, host)
+	}
+
+	for _, network := range allowed {
+		if network.Contains(ip) {
+			return nil
+		}
+	}
+
+	if !IsPublic(ip) {
+		return fmt.Errorf("%w [%s]", ErrForbiddenAddress, host)
+	}
+
+	return nil
+}
+
+// limitedTransport fails responses whose body is larger than maxBytes
+// Bodies of redirects are only limited while read, as the client discards them
+type limitedTransport struct {
+	next     http.RoundTripper
+	maxBytes int64
+}
+
+func (t *limitedTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	response, err := t.next.RoundTrip(request)
+	if err != nil {
+		return nil, err
+	}
+
+	redirect := response.StatusCode >= 300 && response.StatusCode < 400 && response.Header.Get("Location") != ""
+	if response.ContentLength > t.maxBytes && !redirect {
+		response.Body.Close()
+		return nil, fmt.Errorf("%w: %d bytes", ErrBodyTooLarge, response.ContentLength)
+	}
+
+	response.Body = &limitedBody{ReadCloser: response.Body, remaining: t.maxBytes}
+	return response, nil
+}
+
+// limitedBody returns ErrBodyTooLarge instead of the bytes after the limit
+type limitedBody struct {
+	io.ReadCloser
+	remaining int64
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.remaining < 0 {
+		return 0, ErrBodyTooLarge
+	}
+
+	if int64(len(p)) > b.remaining+1 {
+		p = p[:b.remaining+1]
+	}
+
+	n, err := b.ReadCloser.Read(p)
+	b.remaining -= int64(n)
+	if b.remaining < 0 {
+		return n + int(b.remaining), ErrBodyTooLarge
+	}
+
+	return n, err
+}
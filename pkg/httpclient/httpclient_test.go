@@ -0,0 +1,164 @@
+package httpclient_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"time"
+	"url-shortener/pkg/httpclient"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("HTTP Client", func() {
+	var (
+		server   *httptest.Server
+		loopback *net.IPNet
+		config   httpclient.Config
+	)
+
+	BeforeEach(func() {
+		mux := http.NewServeMux()
+		mux.HandleFunc("/ok", func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("ok"))
+		})
+		mux.HandleFunc("/redirect/", func(w http.ResponseWriter, r *http.Request) {
+			hops, _ := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/redirect/"))
+			if hops == 0 {
+				w.Write([]byte("arrived"))
+				return
+			}
+
+			http.Redirect(w, r, fmt.Sprintf("/redirect/%d", hops-1), http.StatusFound)
+		})
+		mux.HandleFunc("/large", func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(strings.Repeat("a", 100)))
+		})
+		mux.HandleFunc("/stream", func(w http.ResponseWriter, r *http.Request) {
+			w.(http.Flusher).Flush()
+			w.Write([]byte(strings.Repeat("a", 100)))
+		})
+		mux.HandleFunc("/ftp", func(w http.ResponseWriter, r *http.Request) {
+			http.Redirect(w, r, "ftp://example.com/file", http.StatusFound)
+		})
+		mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(200 * time.Millisecond)
+		})
+		server = httptest.NewServer(mux)
+
+		_, loopback, _ = net.ParseCIDR("127.0.0.0/8")
+		config = httpclient.Config{
+			Timeout:         time.Second,
+			MaxRedirects:    3,
+			MaxBodyBytes:    10,
+			AllowedNetworks: []*net.IPNet{loopback},
+		}
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	get := func(path string) (string, error) {
+		response, err := httpclient.New(config).Get(server.URL + path)
+		if err != nil {
+			return "", err
+		}
+		defer response.Body.Close()
+
+		body, err := io.ReadAll(response.Body)
+		return string(body), err
+	}
+
+	When("the destination is a loopback address", func() {
+		BeforeEach(func() {
+			config.AllowedNetworks = nil
+		})
+
+		It("should refuse to connect", func() {
+			_, err := get("/ok")
+			Expect(errors.Is(err, httpclient.ErrForbiddenAddress)).To(BeTrue())
+		})
+	})
+
+	When("the destination is an allowed network", func() {
+		It("should connect", func() {
+			Expect(get("/ok")).To(Equal("ok"))
+		})
+	})
+
+	When("the redirect chain is within the limit", func() {
+		It("should follow it", func() {
+			Expect(get("/redirect/3")).To(Equal("arrived"))
+		})
+	})
+
+	When("the redirect chain is too long", func() {
+		It("should return an error", func() {
+			_, err := get("/redirect/4")
+			Expect(errors.Is(err, httpclient.ErrTooManyRedirects)).To(BeTrue())
+		})
+	})
+
+	When("a redirect leaves http", func() {
+		It("should return an error", func() {
+			_, err := get("/ftp")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	When("the body is larger than allowed", func() {
+		It("should return an error before reading it", func() {
+			_, err := get("/large")
+			Expect(errors.Is(err, httpclient.ErrBodyTooLarge)).To(BeTrue())
+		})
+	})
+
+	When("the body of unknown length is larger than allowed", func() {
+		It("should return an error while reading it", func() {
+			body, err := get("/stream")
+			Expect(errors.Is(err, httpclient.ErrBodyTooLarge)).To(BeTrue())
+			Expect(body).To(HaveLen(10))
+		})
+	})
+
+	When("the destination is too slow", func() {
+		BeforeEach(func() {
+			config.Timeout = 50 * time.Millisecond
+		})
+
+		It("should time out", func() {
+			_, err := get("/slow")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	When("checking addresses", func() {
+		DescribeTable("should tell public ones apart",
+			func(address string, public bool) {
+				Expect(httpclient.IsPublic(net.ParseIP(address))).To(Equal(public))
+			},
+			Entry("public IPv4", "93.184.216.34", true),
+			Entry("public IPv6", "2606:2800:220:1:248:1893:25c8:1946", true),
+			Entry("loopback", "127.0.0.1", false),
+			Entry("IPv6 loopback", "::1", false),
+			Entry("private", "10.1.2.3", false),
+			Entry("private", "172.16.0.1", false),
+			Entry("private", "192.168.1.1", false),
+			Entry("cloud metadata", "169.254.169.254", false),
+			Entry("unspecified", "0.0.0.0", false),
+			Entry("carrier grade NAT", "100.64.0.1", false),
+			Entry("IPv4 mapped loopback", "::ffff:127.0.0.1", false),
+			Entry("unique local", "fd00:ec2::254", false),
+			Entry("IPv6 link local", "fe80::1", false),
+			Entry("NAT64", "64:ff9b::a00:1", false),
+			Entry("broadcast", "255.255.255.255", false),
+		)
+	})
+})
@@ -0,0 +1,144 @@
+package migration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"url-shortener/pkg/repository/firestore/urls"
+)
+
+//go:generate mockgen --source=copier.go --destination mocks/copier.go --package mocks
+
+type Source interface {
+	ForEachAfter(ctx context.Context, afterID string, fn func(record urls.Record) error) error
+}
+
+type Destination interface {
+	CreateURLs(ctx context.Context, records []urls.Record) error
+}
+
+type Counter interface {
+	GetCount(ctx context.Context) (int64, error)
+	AdvanceTo(ctx context.Context, count int64) error
+}
+
+// CopyReport describes a copier run
+type CopyReport struct {
+	Copied int
+	// ResumedAfter is the id the run continued after, empty if it started from the beginning
+	ResumedAfter string
+	Count        int64
+}
+
+// Copier copies all URLs and the counter state from one store to another
+type Copier struct {
+	source             Source
+	destination        Destination
+	sourceCounter      Counter
+	destinationCounter Counter
+	batchSize          int
+	checkpointPath     string
+}
+
+// NewCopier is a constructor function
+func NewCopier(source Source, destination Destination, sourceCounter, destinationCounter Counter, batchSize int, checkpointPath string) *Copier {
+	return &Copier{
+		source:             source,
+		destination:        destination,
+		sourceCounter:      sourceCounter,
+		destinationCounter: destinationCounter,
+		batchSize:          batchSize,
+		checkpointPath:     checkpointPath,
+	}
+}
+
+// Copy copies URLs in id order in batches and stores the last copied id in the checkpoint file after every batch,
+// so an interrupted copy resumes after it. Finally the destination counter is advanced to the source one.
+// It is meant to run while the service is in dual-write mode, so URLs created meanwhile reach both stores.
+// URLs which already exist in the destination are not copied, as dual-write may have changed them after they were read.
+func (c *Copier) Copy(ctx context.Context) (CopyReport, error) {
+	afterID, err := c.loadCheckpoint()
+	if err != nil {
+		return CopyReport{}, err
+	}
+
+	report := CopyReport{ResumedAfter: afterID}
+	var batch []urls.Record
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+
+		if err := c.destination.CreateURLs(ctx, batch); err != nil {
+			return fmt.Errorf("failed to copy batch after id [%s]: %w", afterID, err)
+		}
+
+		afterID = batch[len(batch)-1].ID
+		if err := c.saveCheckpoint(afterID); err != nil {
+			return err
+		}
+
+		report.Copied += len(batch)
+		batch = batch[:0]
+		return nil
+	}
+
+	err = c.source.ForEachAfter(ctx, afterID, func(record urls.Record) error {
+		batch = append(batch, record)
+		if len(batch) < c.batchSize {
+			return nil
+		}
+
+		return flush()
+	})
+	if err != nil {
+		return report, fmt.Errorf("failed to copy urls: %w", err)
+	}
+
+	if err := flush(); err != nil {
+		return report, err
+	}
+
+	report.Count, err = c.sourceCounter.GetCount(ctx)
+	if err != nil {
+		return report, fmt.Errorf("failed to get source count: %w", err)
+	}
+
+	if err := c.destinationCounter.AdvanceTo(ctx, report.Count); err != nil {
+		return report, fmt.Errorf("failed to advance destination counter: %w", err)
+	}
+
+	if err := os.Remove(c.checkpointPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return report, fmt.Errorf("failed to remove checkpoint: %w", err)
+	}
+
+	return report, nil
+}
+
+func (c *Copier) loadCheckpoint() (string, error) {
+	data, err := os.ReadFile(c.checkpointPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", nil
+		}
+
+		return "", fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+
+	return strings.TrimSpace(string(data)), nil
+}
+
+func (c *Copier) saveCheckpoint(id string) error {
+	tmpPath := c.checkpointPath + ".tmp"
+	if err := os.WriteFile(tmpPath, []byte(id), 0o644); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, c.checkpointPath); err != nil {
+		return fmt.Errorf("failed to save checkpoint: %w", err)
+	}
+
+	return nil
+}
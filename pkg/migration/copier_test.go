@@ -0,0 +1,119 @@
+package migration_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"url-shortener/pkg/migration"
+	"url-shortener/pkg/migration/mocks"
+	"url-shortener/pkg/repository/firestore/urls"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Copier", func() {
+	const batchSize = 2
+
+	var (
+		mockCtrl               *gomock.Controller
+		mockSource             *mocks.MockSource
+		mockDestination        *mocks.MockDestination
+		mockSourceCounter      *mocks.MockCounter
+		mockDestinationCounter *mocks.MockCounter
+		checkpointPath         string
+		copier                 *migration.Copier
+		ctx                    context.Context
+		records                []urls.Record
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockSource = mocks.NewMockSource(mockCtrl)
+		mockDestination = mocks.NewMockDestination(mockCtrl)
+		mockSourceCounter = mocks.NewMockCounter(mockCtrl)
+		mockDestinationCounter = mocks.NewMockCounter(mockCtrl)
+		checkpointPath = filepath.Join(GinkgoT().TempDir(), "copy.checkpoint")
+		copier = migration.NewCopier(mockSource, mockDestination, mockSourceCounter, mockDestinationCounter, batchSize, checkpointPath)
+		ctx = context.Background()
+		records = []urls.Record{
+			{ID: "1", URL: urls.URL{LongURL: "https://first.com"}},
+			{ID: "2", URL: urls.URL{LongURL: "https://second.com"}},
+			{ID: "3", URL: urls.URL{LongURL: "https://third.com"}},
+		}
+	})
+
+	iterate := func(records []urls.Record) func(context.Context, string, func(urls.Record) error) error {
+		return func(ctx context.Context, afterID string, fn func(urls.Record) error) error {
+			for _, record := range records {
+				if err := fn(record); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		}
+	}
+
+	When("copying succeeds", func() {
+		BeforeEach(func() {
+			mockSource.EXPECT().ForEachAfter(ctx, "", gomock.Any()).DoAndReturn(iterate(records))
+			mockDestination.EXPECT().CreateURLs(ctx, records[:2]).Return(nil)
+			mockDestination.EXPECT().CreateURLs(ctx, records[2:]).Return(nil)
+			mockSourceCounter.EXPECT().GetCount(ctx).Return(int64(3), nil)
+			mockDestinationCounter.EXPECT().AdvanceTo(ctx, int64(3)).Return(nil)
+		})
+
+		It("should copy urls in batches, advance the counter and remove the checkpoint", func() {
+			report, err := copier.Copy(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(report).To(Equal(migration.CopyReport{Copied: 3, Count: 3}))
+			Expect(checkpointPath).ToNot(BeAnExistingFile())
+		})
+	})
+
+	When("copying a batch fails", func() {
+		BeforeEach(func() {
+			mockSource.EXPECT().ForEachAfter(ctx, "", gomock.Any()).DoAndReturn(iterate(records))
+			mockDestination.EXPECT().CreateURLs(ctx, records[:2]).Return(nil)
+			mockDestination.EXPECT().CreateURLs(ctx, records[2:]).Return(errors.New("err"))
+		})
+
+		It("should return an error and keep the last copied id", func() {
+			_, err := copier.Copy(ctx)
+			Expect(err).To(HaveOccurred())
+			Expect(os.ReadFile(checkpointPath)).To(Equal([]byte("2")))
+		})
+	})
+
+	When("resuming from a checkpoint", func() {
+		BeforeEach(func() {
+			Expect(os.WriteFile(checkpointPath, []byte("2"), 0o644)).To(Succeed())
+			mockSource.EXPECT().ForEachAfter(ctx, "2", gomock.Any()).DoAndReturn(iterate(records[2:]))
+			mockDestination.EXPECT().CreateURLs(ctx, records[2:]).Return(nil)
+			mockSourceCounter.EXPECT().GetCount(ctx).Return(int64(3), nil)
+			mockDestinationCounter.EXPECT().AdvanceTo(ctx, int64(3)).Return(nil)
+		})
+
+		It("should continue after the last copied id", func() {
+			report, err := copier.Copy(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(report).To(Equal(migration.CopyReport{Copied: 1, ResumedAfter: "2", Count: 3}))
+		})
+	})
+
+	When("advancing the destination counter fails", func() {
+		BeforeEach(func() {
+			mockSource.EXPECT().ForEachAfter(ctx, "", gomock.Any()).DoAndReturn(iterate(nil))
+			mockSourceCounter.EXPECT().GetCount(ctx).Return(int64(3), nil)
+			mockDestinationCounter.EXPECT().AdvanceTo(ctx, int64(3)).Return(errors.New("err"))
+		})
+
+		It("should return an error", func() {
+			_, err := copier.Copy(ctx)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
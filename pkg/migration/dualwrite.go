@@ -0,0 +1,311 @@
+package migration
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+	"url-shortener/pkg/repository/firestore/urls"
+	"url-shortener/pkg/unfurl"
+	"url-shortener/pkg/variants"
+
+	"cloud.google.com/go/firestore"
+	"github.com/sirupsen/logrus"
+)
+
+//go:generate mockgen --source=dualwrite.go --destination mocks/dualwrite.go --package mocks
+
+type Primary interface {
+	AddURLTx(tx *firestore.Transaction, id string, url urls.URL) error
+	GetByShortURL(ctx context.Context, shortURL string) (urls.URL, error)
+	GetStats(ctx context.Context, shortURL string) (urls.URL, error)
+	GetDocIDByLongURL(ctx context.Context, domain, longURL string) (string, error)
+	ConsumeClick(ctx context.Context, shortURL string) (urls.URL, error)
+	ListScheduled(ctx context.Context, field string, from, to time.Time, after urls.Cursor, limit int) ([]urls.Record, error)
+	ListBroken(ctx context.Context) ([]urls.Record, error)
+	UpdateVariants(ctx context.Context, shortURL string, urlVariants []variants.Variant) error
+	UpdateMetadata(ctx context.Context, shortURL string, metadata urls.Metadata) error
+	UpdatePreview(ctx context.Context, shortURL string, preview unfurl.Preview) error
+	IncrementVariantClicks(ctx context.Context, shortURL, variant string) error
+	Delete(ctx context.Context, shortURL string) error
+	ListChanges(ctx context.Context, after urls.Cursor, limit int) ([]urls.Change, error)
+	RunTransaction(ctx context.Context, txFunc func(context.Context, *firestore.Transaction) error) error
+}
+
+type Secondary interface {
+	PutURLs(ctx context.Context, records []urls.Record) error
+	SetClicks(ctx context.Context, shortURL string, clicks int64) error
+	GetByShortURL(ctx context.Context, shortURL string) (urls.URL, error)
+	GetStats(ctx context.Context, shortURL string) (urls.URL, error)
+	GetDocIDByLongURL(ctx context.Context, domain, longURL string) (string, error)
+	ConsumeClick(ctx context.Context, shortURL string) (urls.URL, error)
+	UpdateVariants(ctx context.Context, shortURL string, urlVariants []variants.Variant) error
+	UpdateMetadata(ctx context.Context, shortURL string, metadata urls.Metadata) error
+	UpdatePreview(ctx context.Context, shortURL string, preview unfurl.Preview) error
+	IncrementVariantClicks(ctx context.Context, shortURL, variant string) error
+	Delete(ctx context.Context, shortURL string) error
+}
+
+// DualWriteRepository writes URLs to both stores and reads from the primary one,
+// falling back to the secondary one for URLs which have not been copied yet
+type DualWriteRepository struct {
+	primary   Primary
+	secondary Secondary
+	mu        sync.Mutex
+	pending   map[*firestore.Transaction][]urls.Record
+	// primaryCounter is mirrored to secondaryCounter after URLs are added, it is not mirrored if nil
+	primaryCounter   Counter
+	secondaryCounter Counter
+}
+
+// NewDualWriteRepository is a constructor function
+func NewDualWriteRepository(primary Primary, secondary Secondary) *DualWriteRepository {
+	return &DualWriteRepository{
+		primary:   primary,
+		secondary: secondary,
+		pending:   make(map[*firestore.Transaction][]urls.Record),
+	}
+}
+
+// WithCounters mirrors the counter of the primary store to the secondary one after URLs are added,
+// so ids allocated meanwhile are not allocated again once the secondary store becomes the primary one
+func (r *DualWriteRepository) WithCounters(primary, secondary Counter) *DualWriteRepository {
+	r.primaryCounter = primary
+	r.secondaryCounter = secondary
+	return r
+}
+
+// AddURLTx creates URL document in the primary store and queues it for the secondary one
+// The queued URL is written once the transaction commits
+func (r *DualWriteRepository) AddURLTx(tx *firestore.Transaction, id string, url urls.URL) error {
+	if err := r.primary.AddURLTx(tx, id, url); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.pending[tx] = append(r.pending[tx], urls.Record{ID: id, URL: url})
+	return nil
+}
+
+// GetByShortURL returns a URL from the primary store, or the secondary one if it is not found
+func (r *DualWriteRepository) GetByShortURL(ctx context.Context, shortURL string) (urls.URL, error) {
+	url, err := r.primary.GetByShortURL(ctx, shortURL)
+	var notFoundErr urls.NotFoundError
+	if errors.As(err, &notFoundErr) {
+		return r.secondary.GetByShortURL(ctx, shortURL)
+	}
+
+	return url, err
+}
+
+// GetStats returns a URL with its variant clicks from the primary store, or the secondary one if it is not found
+func (r *DualWriteRepository) GetStats(ctx context.Context, shortURL string) (urls.URL, error) {
+	url, err := r.primary.GetStats(ctx, shortURL)
+	var notFoundErr urls.NotFoundError
+	if errors.As(err, &notFoundErr) {
+		return r.secondary.GetStats(ctx, shortURL)
+	}
+
+	return url, err
+}
+
+// GetDocIDByLongURL returns a URL document id by long url on the domain from the primary store,
+// or the secondary one if it is not found
+func (r *DualWriteRepository) GetDocIDByLongURL(ctx context.Context, domain, longURL string) (string, error) {
+	id, err := r.primary.GetDocIDByLongURL(ctx, domain, longURL)
+	var notFoundErr urls.NotFoundError
+	if errors.As(err, &notFoundErr) {
+		return r.secondary.GetDocIDByLongURL(ctx, domain, longURL)
+	}
+
+	return id, err
+}
+
+// ListScheduled lists scheduled URLs from the primary store
+func (r *DualWriteRepository) ListScheduled(ctx context.Context, field string, from, to time.Time, after urls.Cursor, limit int) ([]urls.Record, error) {
+	return r.primary.ListScheduled(ctx, field, from, to, after, limit)
+}
+
+// ListBroken lists URLs with broken long URLs from the primary store, where the health monitor records checks
+func (r *DualWriteRepository) ListBroken(ctx context.Context) ([]urls.Record, error) {
+	return r.primary.ListBroken(ctx)
+}
+
+// ConsumeClick counts a click in the primary store and mirrors the click count to the secondary one,
+// URLs which have not been copied yet are counted in the secondary store
+func (r *DualWriteRepository) ConsumeClick(ctx context.Context, shortURL string) (urls.URL, error) {
+	url, err := r.primary.ConsumeClick(ctx, shortURL)
+	var notFoundErr urls.NotFoundError
+	if errors.As(err, &notFoundErr) {
+		return r.secondary.ConsumeClick(ctx, shortURL)
+	}
+
+	if err != nil {
+		return urls.URL{}, err
+	}
+
+	if err := r.secondary.SetClicks(ctx, shortURL, url.Clicks); err != nil && !errors.As(err, &notFoundErr) {
+		logrus.Warnf("failed to write click count of [%s] to secondary store: %v", shortURL, err)
+	}
+
+	return url, nil
+}
+
+// UpdateVariants updates the variants in the primary store and mirrors them to the secondary one,
+// URLs which have not been copied yet are updated in the secondary store
+func (r *DualWriteRepository) UpdateVariants(ctx context.Context, shortURL string, urlVariants []variants.Variant) error {
+	err := r.primary.UpdateVariants(ctx, shortURL, urlVariants)
+	var notFoundErr urls.NotFoundError
+	if errors.As(err, &notFoundErr) {
+		return r.secondary.UpdateVariants(ctx, shortURL, urlVariants)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if err := r.secondary.UpdateVariants(ctx, shortURL, urlVariants); err != nil {
+		logrus.Warnf("failed to write variants of [%s] to secondary store: %v", shortURL, err)
+	}
+
+	return nil
+}
+
+// UpdateMetadata updates the metadata in the primary store and mirrors it to the secondary one,
+// URLs which have not been copied yet are updated in the secondary store
+func (r *DualWriteRepository) UpdateMetadata(ctx context.Context, shortURL string, metadata urls.Metadata) error {
+	err := r.primary.UpdateMetadata(ctx, shortURL, metadata)
+	var notFoundErr urls.NotFoundError
+	if errors.As(err, &notFoundErr) {
+		return r.secondary.UpdateMetadata(ctx, shortURL, metadata)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if err := r.secondary.UpdateMetadata(ctx, shortURL, metadata); err != nil {
+		logrus.Warnf("failed to write metadata of [%s] to secondary store: %v", shortURL, err)
+	}
+
+	return nil
+}
+
+// UpdatePreview stores the preview in the primary store and mirrors it to the secondary one,
+// URLs which have not been copied yet are updated in the secondary store
+func (r *DualWriteRepository) UpdatePreview(ctx context.Context, shortURL string, preview unfurl.Preview) error {
+	err := r.primary.UpdatePreview(ctx, shortURL, preview)
+	var notFoundErr urls.NotFoundError
+	if errors.As(err, &notFoundErr) {
+		return r.secondary.UpdatePreview(ctx, shortURL, preview)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if err := r.secondary.UpdatePreview(ctx, shortURL, preview); err != nil {
+		logrus.Warnf("failed to write preview of [%s] to secondary store: %v", shortURL, err)
+	}
+
+	return nil
+}
+
+// IncrementVariantClicks counts a variant click in the primary store and mirrors it to the secondary one,
+// URLs which have not been copied yet are counted in the secondary store
+func (r *DualWriteRepository) IncrementVariantClicks(ctx context.Context, shortURL, variant string) error {
+	err := r.primary.IncrementVariantClicks(ctx, shortURL, variant)
+	var notFoundErr urls.NotFoundError
+	if errors.As(err, &notFoundErr) {
+		return r.secondary.IncrementVariantClicks(ctx, shortURL, variant)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if err := r.secondary.IncrementVariantClicks(ctx, shortURL, variant); err != nil {
+		logrus.Warnf("failed to write variant click of [%s] to secondary store: %v", shortURL, err)
+	}
+
+	return nil
+}
+
+// Delete deletes the URL from both stores, URLs which have not been copied yet are deleted in the secondary store
+func (r *DualWriteRepository) Delete(ctx context.Context, shortURL string) error {
+	err := r.primary.Delete(ctx, shortURL)
+	var notFoundErr urls.NotFoundError
+	if errors.As(err, &notFoundErr) {
+		return r.secondary.Delete(ctx, shortURL)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if err := r.secondary.Delete(ctx, shortURL); err != nil && !errors.As(err, &notFoundErr) {
+		logrus.Warnf("failed to delete [%s] from secondary store: %v", shortURL, err)
+	}
+
+	return nil
+}
+
+// ListChanges returns the changes logged by the primary store
+func (r *DualWriteRepository) ListChanges(ctx context.Context, after urls.Cursor, limit int) ([]urls.Change, error) {
+	return r.primary.ListChanges(ctx, after, limit)
+}
+
+// RunTransaction runs the function in a transaction of the primary store
+// After commit the URLs added by the last attempt are written to the secondary store and the counter is mirrored.
+// A failing secondary write does not fail the transaction, the copier reconciles it.
+func (r *DualWriteRepository) RunTransaction(ctx context.Context, txFunc func(context.Context, *firestore.Transaction) error) error {
+	var attempts []*firestore.Transaction
+	defer func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		for _, tx := range attempts {
+			delete(r.pending, tx)
+		}
+	}()
+
+	err := r.primary.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		r.mu.Lock()
+		delete(r.pending, tx)
+		attempts = append(attempts, tx)
+		r.mu.Unlock()
+		return txFunc(ctx, tx)
+	})
+	if err != nil || len(attempts) == 0 {
+		return err
+	}
+
+	r.mu.Lock()
+	records := r.pending[attempts[len(attempts)-1]]
+	r.mu.Unlock()
+	if len(records) == 0 {
+		return nil
+	}
+
+	if err := r.secondary.PutURLs(ctx, records); err != nil {
+		logrus.Warnf("failed to write [%d] urls to secondary store: %v", len(records), err)
+	}
+
+	if r.primaryCounter != nil {
+		r.mirrorCount(ctx)
+	}
+
+	return nil
+}
+
+func (r *DualWriteRepository) mirrorCount(ctx context.Context) {
+	count, err := r.primaryCounter.GetCount(ctx)
+	if err != nil {
+		logrus.Warnf("failed to get count of primary store: %v", err)
+		return
+	}
+
+	if err := r.secondaryCounter.AdvanceTo(ctx, count); err != nil {
+		logrus.Warnf("failed to advance counter of secondary store to [%d]: %v", count, err)
+	}
+}
@@ -0,0 +1,258 @@
+package migration_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"url-shortener/pkg/migration"
+	"url-shortener/pkg/migration/mocks"
+	"url-shortener/pkg/repository/firestore/urls"
+	"url-shortener/pkg/unfurl"
+
+	"cloud.google.com/go/firestore"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DualWriteRepository", func() {
+	const (
+		shortURL = "short-url"
+		longURL  = "long-url"
+	)
+
+	var (
+		mockCtrl      *gomock.Controller
+		mockPrimary   *mocks.MockPrimary
+		mockSecondary *mocks.MockSecondary
+		repository    *migration.DualWriteRepository
+		ctx           context.Context
+		url           urls.URL
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockPrimary = mocks.NewMockPrimary(mockCtrl)
+		mockSecondary = mocks.NewMockSecondary(mockCtrl)
+		repository = migration.NewDualWriteRepository(mockPrimary, mockSecondary)
+		ctx = context.Background()
+		url = urls.URL{LongURL: longURL}
+	})
+
+	addURL := func(ctx context.Context, tx *firestore.Transaction) error {
+		return repository.AddURLTx(tx, shortURL, url)
+	}
+
+	When("url is not found in the primary store", func() {
+		BeforeEach(func() {
+			mockPrimary.EXPECT().GetByShortURL(ctx, shortURL).Return(urls.URL{}, urls.NewNotFoundError())
+			mockSecondary.EXPECT().GetByShortURL(ctx, shortURL).Return(url, nil)
+		})
+
+		It("should return it from the secondary store", func() {
+			Expect(repository.GetByShortURL(ctx, shortURL)).To(Equal(url))
+		})
+	})
+
+	When("long url is not found in the primary store", func() {
+		BeforeEach(func() {
+			mockPrimary.EXPECT().GetDocIDByLongURL(ctx, "", longURL).Return("", urls.NewNotFoundError())
+			mockSecondary.EXPECT().GetDocIDByLongURL(ctx, "", longURL).Return(shortURL, nil)
+		})
+
+		It("should reuse the url of the secondary store", func() {
+			Expect(repository.GetDocIDByLongURL(ctx, "", longURL)).To(Equal(shortURL))
+		})
+	})
+
+	When("getting url from the primary store fails", func() {
+		BeforeEach(func() {
+			mockPrimary.EXPECT().GetByShortURL(ctx, shortURL).Return(urls.URL{}, errors.New("err"))
+		})
+
+		It("should return an error without falling back", func() {
+			_, err := repository.GetByShortURL(ctx, shortURL)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	When("getting the stats of an url which is not in the primary store", func() {
+		BeforeEach(func() {
+			mockPrimary.EXPECT().GetStats(ctx, shortURL).Return(urls.URL{}, urls.NewNotFoundError())
+			mockSecondary.EXPECT().GetStats(ctx, shortURL).Return(url, nil)
+		})
+
+		It("should return them from the secondary store", func() {
+			Expect(repository.GetStats(ctx, shortURL)).To(Equal(url))
+		})
+	})
+
+	When("consuming a click of an url in the primary store", func() {
+		BeforeEach(func() {
+			url.MaxClicks, url.Clicks = 2, 1
+			mockPrimary.EXPECT().ConsumeClick(ctx, shortURL).Return(url, nil)
+			mockSecondary.EXPECT().SetClicks(ctx, shortURL, int64(1)).Return(errors.New("err"))
+		})
+
+		It("should mirror only the click count to the secondary store and ignore its failure", func() {
+			Expect(repository.ConsumeClick(ctx, shortURL)).To(Equal(url))
+		})
+	})
+
+	When("consuming a click of an url which is not in the primary store", func() {
+		BeforeEach(func() {
+			mockPrimary.EXPECT().ConsumeClick(ctx, shortURL).Return(urls.URL{}, urls.NewNotFoundError())
+			mockSecondary.EXPECT().ConsumeClick(ctx, shortURL).Return(urls.URL{}, urls.NewExhaustedError())
+		})
+
+		It("should consume it in the secondary store", func() {
+			_, err := repository.ConsumeClick(ctx, shortURL)
+			Expect(err).To(BeAssignableToTypeOf(urls.ExhaustedError{}))
+		})
+	})
+
+	When("incrementing variant clicks of an url which is not in the primary store", func() {
+		BeforeEach(func() {
+			mockPrimary.EXPECT().IncrementVariantClicks(ctx, shortURL, "a").Return(urls.NewNotFoundError())
+			mockSecondary.EXPECT().IncrementVariantClicks(ctx, shortURL, "a").Return(nil)
+		})
+
+		It("should count it in the secondary store", func() {
+			Expect(repository.IncrementVariantClicks(ctx, shortURL, "a")).To(Succeed())
+		})
+	})
+
+	When("mirroring updated variants fails", func() {
+		BeforeEach(func() {
+			mockPrimary.EXPECT().UpdateVariants(ctx, shortURL, nil).Return(nil)
+			mockSecondary.EXPECT().UpdateVariants(ctx, shortURL, nil).Return(errors.New("err"))
+		})
+
+		It("should not fail the update", func() {
+			Expect(repository.UpdateVariants(ctx, shortURL, nil)).To(Succeed())
+		})
+	})
+
+	When("updating metadata of an url which is not in the primary store", func() {
+		var metadata = urls.Metadata{Title: "title", Tags: []string{"tag"}}
+
+		BeforeEach(func() {
+			mockPrimary.EXPECT().UpdateMetadata(ctx, shortURL, metadata).Return(urls.NewNotFoundError())
+			mockSecondary.EXPECT().UpdateMetadata(ctx, shortURL, metadata).Return(nil)
+		})
+
+		It("should update it in the secondary store", func() {
+			Expect(repository.UpdateMetadata(ctx, shortURL, metadata)).To(Succeed())
+		})
+	})
+
+	When("updating the preview of an url which is in both stores", func() {
+		var preview = unfurl.Preview{Title: "title"}
+
+		BeforeEach(func() {
+			mockPrimary.EXPECT().UpdatePreview(ctx, shortURL, preview).Return(nil)
+			mockSecondary.EXPECT().UpdatePreview(ctx, shortURL, preview).Return(errors.New("err"))
+		})
+
+		It("should not fail on the secondary store", func() {
+			Expect(repository.UpdatePreview(ctx, shortURL, preview)).To(Succeed())
+		})
+	})
+
+	When("deleting an url which is not in the primary store", func() {
+		BeforeEach(func() {
+			mockPrimary.EXPECT().Delete(ctx, shortURL).Return(urls.NewNotFoundError())
+			mockSecondary.EXPECT().Delete(ctx, shortURL).Return(nil)
+		})
+
+		It("should delete it in the secondary store", func() {
+			Expect(repository.Delete(ctx, shortURL)).To(Succeed())
+		})
+	})
+
+	When("transaction commits", func() {
+		BeforeEach(func() {
+			mockPrimary.EXPECT().RunTransaction(ctx, gomock.Any()).DoAndReturn(triggerTransaction)
+			mockPrimary.EXPECT().AddURLTx(gomock.Any(), shortURL, url).Return(nil)
+			mockSecondary.EXPECT().PutURLs(ctx, []urls.Record{{ID: shortURL, URL: url}}).Return(nil)
+		})
+
+		It("should write the added urls to the secondary store", func() {
+			Expect(repository.RunTransaction(ctx, addURL)).To(Succeed())
+		})
+	})
+
+	When("writing to the secondary store fails", func() {
+		BeforeEach(func() {
+			mockPrimary.EXPECT().RunTransaction(ctx, gomock.Any()).DoAndReturn(triggerTransaction)
+			mockPrimary.EXPECT().AddURLTx(gomock.Any(), shortURL, url).Return(nil)
+			mockSecondary.EXPECT().PutURLs(ctx, gomock.Any()).Return(errors.New("err"))
+		})
+
+		It("should not fail the committed transaction", func() {
+			Expect(repository.RunTransaction(ctx, addURL)).To(Succeed())
+		})
+	})
+
+	When("the store is cut over after urls were created in dual-write mode", func() {
+		var primaryCounter, secondaryCounter *memoryCounter
+
+		BeforeEach(func() {
+			primaryCounter, secondaryCounter = &memoryCounter{count: 5}, &memoryCounter{}
+			repository.WithCounters(primaryCounter, secondaryCounter)
+
+			// the copier advances the secondary counter once it copied the urls
+			mockSource := mocks.NewMockSource(mockCtrl)
+			mockSource.EXPECT().ForEachAfter(ctx, "", gomock.Any()).Return(nil)
+			copier := migration.NewCopier(mockSource, mocks.NewMockDestination(mockCtrl), primaryCounter, secondaryCounter, 2,
+				filepath.Join(GinkgoT().TempDir(), "copy.checkpoint"))
+			Expect(copier.Copy(ctx)).Error().ToNot(HaveOccurred())
+
+			mockPrimary.EXPECT().RunTransaction(ctx, gomock.Any()).DoAndReturn(triggerTransaction)
+			mockPrimary.EXPECT().AddURLTx(gomock.Any(), shortURL, url).Return(nil)
+			mockSecondary.EXPECT().PutURLs(ctx, gomock.Any()).Return(nil)
+		})
+
+		It("should mirror the ids allocated after the copy to the secondary counter", func() {
+			Expect(repository.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+				primaryCounter.count++
+				return addURL(ctx, tx)
+			})).To(Succeed())
+
+			// after cutover the secondary store allocates ids after the one created in dual-write mode
+			Expect(secondaryCounter.count).To(Equal(int64(6)))
+		})
+	})
+
+	When("transaction fails", func() {
+		BeforeEach(func() {
+			mockPrimary.EXPECT().RunTransaction(ctx, gomock.Any()).DoAndReturn(triggerTransaction)
+			mockPrimary.EXPECT().AddURLTx(gomock.Any(), shortURL, url).Return(errors.New("err"))
+		})
+
+		It("should return an error and not write to the secondary store", func() {
+			Expect(repository.RunTransaction(ctx, addURL)).ToNot(Succeed())
+		})
+	})
+})
+
+// memoryCounter is a counter store whose count is the last allocated id
+type memoryCounter struct {
+	count int64
+}
+
+func (c *memoryCounter) GetCount(ctx context.Context) (int64, error) {
+	return c.count, nil
+}
+
+func (c *memoryCounter) AdvanceTo(ctx context.Context, count int64) error {
+	if c.count < count {
+		c.count = count
+	}
+
+	return nil
+}
+
+func triggerTransaction(ctx context.Context, txFunc func(context.Context, *firestore.Transaction) error) error {
+	return txFunc(ctx, &firestore.Transaction{})
+}
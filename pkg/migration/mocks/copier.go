@@ -0,0 +1,139 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: copier.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+	urls "url-shortener/pkg/repository/firestore/urls"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockSource is a mock of Source interface.
+type MockSource struct {
+	ctrl     *gomock.Controller
+	recorder *MockSourceMockRecorder
+}
+
+// MockSourceMockRecorder is the mock recorder for MockSource.
+type MockSourceMockRecorder struct {
+	mock *MockSource
+}
+
+// NewMockSource creates a new mock instance.
+func NewMockSource(ctrl *gomock.Controller) *MockSource {
+	mock := &MockSource{ctrl: ctrl}
+	mock.recorder = &MockSourceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSource) EXPECT() *MockSourceMockRecorder {
+	return m.recorder
+}
+
+// ForEachAfter mocks base method.
+func (m *MockSource) ForEachAfter(ctx context.Context, afterID string, fn func(urls.Record) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ForEachAfter", ctx, afterID, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ForEachAfter indicates an expected call of ForEachAfter.
+func (mr *MockSourceMockRecorder) ForEachAfter(ctx, afterID, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ForEachAfter", reflect.TypeOf((*MockSource)(nil).ForEachAfter), ctx, afterID, fn)
+}
+
+// MockDestination is a mock of Destination interface.
+type MockDestination struct {
+	ctrl     *gomock.Controller
+	recorder *MockDestinationMockRecorder
+}
+
+// MockDestinationMockRecorder is the mock recorder for MockDestination.
+type MockDestinationMockRecorder struct {
+	mock *MockDestination
+}
+
+// NewMockDestination creates a new mock instance.
+func NewMockDestination(ctrl *gomock.Controller) *MockDestination {
+	mock := &MockDestination{ctrl: ctrl}
+	mock.recorder = &MockDestinationMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDestination) EXPECT() *MockDestinationMockRecorder {
+	return m.recorder
+}
+
+// CreateURLs mocks base method.
+func (m *MockDestination) CreateURLs(ctx context.Context, records []urls.Record) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateURLs", ctx, records)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateURLs indicates an expected call of CreateURLs.
+func (mr *MockDestinationMockRecorder) CreateURLs(ctx, records interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateURLs", reflect.TypeOf((*MockDestination)(nil).CreateURLs), ctx, records)
+}
+
+// MockCounter is a mock of Counter interface.
+type MockCounter struct {
+	ctrl     *gomock.Controller
+	recorder *MockCounterMockRecorder
+}
+
+// MockCounterMockRecorder is the mock recorder for MockCounter.
+type MockCounterMockRecorder struct {
+	mock *MockCounter
+}
+
+// NewMockCounter creates a new mock instance.
+func NewMockCounter(ctrl *gomock.Controller) *MockCounter {
+	mock := &MockCounter{ctrl: ctrl}
+	mock.recorder = &MockCounterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCounter) EXPECT() *MockCounterMockRecorder {
+	return m.recorder
+}
+
+// AdvanceTo mocks base method.
+func (m *MockCounter) AdvanceTo(ctx context.Context, count int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AdvanceTo", ctx, count)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AdvanceTo indicates an expected call of AdvanceTo.
+func (mr *MockCounterMockRecorder) AdvanceTo(ctx, count interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdvanceTo", reflect.TypeOf((*MockCounter)(nil).AdvanceTo), ctx, count)
+}
+
+// GetCount mocks base method.
+func (m *MockCounter) GetCount(ctx context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCount", ctx)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCount indicates an expected call of GetCount.
+func (mr *MockCounterMockRecorder) GetCount(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCount", reflect.TypeOf((*MockCounter)(nil).GetCount), ctx)
+}
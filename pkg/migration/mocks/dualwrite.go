@@ -0,0 +1,424 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: dualwrite.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+	urls "url-shortener/pkg/repository/firestore/urls"
+	unfurl "url-shortener/pkg/unfurl"
+	variants "url-shortener/pkg/variants"
+
+	firestore "cloud.google.com/go/firestore"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockPrimary is a mock of Primary interface.
+type MockPrimary struct {
+	ctrl     *gomock.Controller
+	recorder *MockPrimaryMockRecorder
+}
+
+// MockPrimaryMockRecorder is the mock recorder for MockPrimary.
+type MockPrimaryMockRecorder struct {
+	mock *MockPrimary
+}
+
+// NewMockPrimary creates a new mock instance.
+func NewMockPrimary(ctrl *gomock.Controller) *MockPrimary {
+	mock := &MockPrimary{ctrl: ctrl}
+	mock.recorder = &MockPrimaryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPrimary) EXPECT() *MockPrimaryMockRecorder {
+	return m.recorder
+}
+
+// AddURLTx mocks base method.
+func (m *MockPrimary) AddURLTx(tx *firestore.Transaction, id string, url urls.URL) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddURLTx", tx, id, url)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddURLTx indicates an expected call of AddURLTx.
+func (mr *MockPrimaryMockRecorder) AddURLTx(tx, id, url interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddURLTx", reflect.TypeOf((*MockPrimary)(nil).AddURLTx), tx, id, url)
+}
+
+// ConsumeClick mocks base method.
+func (m *MockPrimary) ConsumeClick(ctx context.Context, shortURL string) (urls.URL, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConsumeClick", ctx, shortURL)
+	ret0, _ := ret[0].(urls.URL)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConsumeClick indicates an expected call of ConsumeClick.
+func (mr *MockPrimaryMockRecorder) ConsumeClick(ctx, shortURL interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumeClick", reflect.TypeOf((*MockPrimary)(nil).ConsumeClick), ctx, shortURL)
+}
+
+// Delete mocks base method.
+func (m *MockPrimary) Delete(ctx context.Context, shortURL string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, shortURL)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockPrimaryMockRecorder) Delete(ctx, shortURL interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockPrimary)(nil).Delete), ctx, shortURL)
+}
+
+// GetByShortURL mocks base method.
+func (m *MockPrimary) GetByShortURL(ctx context.Context, shortURL string) (urls.URL, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByShortURL", ctx, shortURL)
+	ret0, _ := ret[0].(urls.URL)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByShortURL indicates an expected call of GetByShortURL.
+func (mr *MockPrimaryMockRecorder) GetByShortURL(ctx, shortURL interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByShortURL", reflect.TypeOf((*MockPrimary)(nil).GetByShortURL), ctx, shortURL)
+}
+
+// GetDocIDByLongURL mocks base method.
+func (m *MockPrimary) GetDocIDByLongURL(ctx context.Context, domain, longURL string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDocIDByLongURL", ctx, domain, longURL)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDocIDByLongURL indicates an expected call of GetDocIDByLongURL.
+func (mr *MockPrimaryMockRecorder) GetDocIDByLongURL(ctx, domain, longURL interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDocIDByLongURL", reflect.TypeOf((*MockPrimary)(nil).GetDocIDByLongURL), ctx, domain, longURL)
+}
+
+// GetStats mocks base method.
+func (m *MockPrimary) GetStats(ctx context.Context, shortURL string) (urls.URL, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStats", ctx, shortURL)
+	ret0, _ := ret[0].(urls.URL)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStats indicates an expected call of GetStats.
+func (mr *MockPrimaryMockRecorder) GetStats(ctx, shortURL interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStats", reflect.TypeOf((*MockPrimary)(nil).GetStats), ctx, shortURL)
+}
+
+// IncrementVariantClicks mocks base method.
+func (m *MockPrimary) IncrementVariantClicks(ctx context.Context, shortURL, variant string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IncrementVariantClicks", ctx, shortURL, variant)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// IncrementVariantClicks indicates an expected call of IncrementVariantClicks.
+func (mr *MockPrimaryMockRecorder) IncrementVariantClicks(ctx, shortURL, variant interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncrementVariantClicks", reflect.TypeOf((*MockPrimary)(nil).IncrementVariantClicks), ctx, shortURL, variant)
+}
+
+// ListBroken mocks base method.
+func (m *MockPrimary) ListBroken(ctx context.Context) ([]urls.Record, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListBroken", ctx)
+	ret0, _ := ret[0].([]urls.Record)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListBroken indicates an expected call of ListBroken.
+func (mr *MockPrimaryMockRecorder) ListBroken(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListBroken", reflect.TypeOf((*MockPrimary)(nil).ListBroken), ctx)
+}
+
+// ListChanges mocks base method.
+func (m *MockPrimary) ListChanges(ctx context.Context, after urls.Cursor, limit int) ([]urls.Change, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListChanges", ctx, after, limit)
+	ret0, _ := ret[0].([]urls.Change)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListChanges indicates an expected call of ListChanges.
+func (mr *MockPrimaryMockRecorder) ListChanges(ctx, after, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListChanges", reflect.TypeOf((*MockPrimary)(nil).ListChanges), ctx, after, limit)
+}
+
+// ListScheduled mocks base method.
+func (m *MockPrimary) ListScheduled(ctx context.Context, field string, from, to time.Time, after urls.Cursor, limit int) ([]urls.Record, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListScheduled", ctx, field, from, to, after, limit)
+	ret0, _ := ret[0].([]urls.Record)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListScheduled indicates an expected call of ListScheduled.
+func (mr *MockPrimaryMockRecorder) ListScheduled(ctx, field, from, to, after, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListScheduled", reflect.TypeOf((*MockPrimary)(nil).ListScheduled), ctx, field, from, to, after, limit)
+}
+
+// RunTransaction mocks base method.
+func (m *MockPrimary) RunTransaction(ctx context.Context, txFunc func(context.Context, *firestore.Transaction) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RunTransaction", ctx, txFunc)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RunTransaction indicates an expected call of RunTransaction.
+func (mr *MockPrimaryMockRecorder) RunTransaction(ctx, txFunc interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RunTransaction", reflect.TypeOf((*MockPrimary)(nil).RunTransaction), ctx, txFunc)
+}
+
+// UpdateMetadata mocks base method.
+func (m *MockPrimary) UpdateMetadata(ctx context.Context, shortURL string, metadata urls.Metadata) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateMetadata", ctx, shortURL, metadata)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateMetadata indicates an expected call of UpdateMetadata.
+func (mr *MockPrimaryMockRecorder) UpdateMetadata(ctx, shortURL, metadata interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateMetadata", reflect.TypeOf((*MockPrimary)(nil).UpdateMetadata), ctx, shortURL, metadata)
+}
+
+// UpdatePreview mocks base method.
+func (m *MockPrimary) UpdatePreview(ctx context.Context, shortURL string, preview unfurl.Preview) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePreview", ctx, shortURL, preview)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePreview indicates an expected call of UpdatePreview.
+func (mr *MockPrimaryMockRecorder) UpdatePreview(ctx, shortURL, preview interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePreview", reflect.TypeOf((*MockPrimary)(nil).UpdatePreview), ctx, shortURL, preview)
+}
+
+// UpdateVariants mocks base method.
+func (m *MockPrimary) UpdateVariants(ctx context.Context, shortURL string, urlVariants []variants.Variant) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateVariants", ctx, shortURL, urlVariants)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateVariants indicates an expected call of UpdateVariants.
+func (mr *MockPrimaryMockRecorder) UpdateVariants(ctx, shortURL, urlVariants interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateVariants", reflect.TypeOf((*MockPrimary)(nil).UpdateVariants), ctx, shortURL, urlVariants)
+}
+
+// MockSecondary is a mock of Secondary interface.
+type MockSecondary struct {
+	ctrl     *gomock.Controller
+	recorder *MockSecondaryMockRecorder
+}
+
+// MockSecondaryMockRecorder is the mock recorder for MockSecondary.
+type MockSecondaryMockRecorder struct {
+	mock *MockSecondary
+}
+
+// NewMockSecondary creates a new mock instance.
+func NewMockSecondary(ctrl *gomock.Controller) *MockSecondary {
+	mock := &MockSecondary{ctrl: ctrl}
+	mock.recorder = &MockSecondaryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSecondary) EXPECT() *MockSecondaryMockRecorder {
+	return m.recorder
+}
+
+// ConsumeClick mocks base method.
+func (m *MockSecondary) ConsumeClick(ctx context.Context, shortURL string) (urls.URL, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConsumeClick", ctx, shortURL)
+	ret0, _ := ret[0].(urls.URL)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConsumeClick indicates an expected call of ConsumeClick.
+func (mr *MockSecondaryMockRecorder) ConsumeClick(ctx, shortURL interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumeClick", reflect.TypeOf((*MockSecondary)(nil).ConsumeClick), ctx, shortURL)
+}
+
+// Delete mocks base method.
+func (m *MockSecondary) Delete(ctx context.Context, shortURL string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, shortURL)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockSecondaryMockRecorder) Delete(ctx, shortURL interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockSecondary)(nil).Delete), ctx, shortURL)
+}
+
+// GetByShortURL mocks base method.
+func (m *MockSecondary) GetByShortURL(ctx context.Context, shortURL string) (urls.URL, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByShortURL", ctx, shortURL)
+	ret0, _ := ret[0].(urls.URL)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByShortURL indicates an expected call of GetByShortURL.
+func (mr *MockSecondaryMockRecorder) GetByShortURL(ctx, shortURL interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByShortURL", reflect.TypeOf((*MockSecondary)(nil).GetByShortURL), ctx, shortURL)
+}
+
+// GetDocIDByLongURL mocks base method.
+func (m *MockSecondary) GetDocIDByLongURL(ctx context.Context, domain, longURL string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDocIDByLongURL", ctx, domain, longURL)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDocIDByLongURL indicates an expected call of GetDocIDByLongURL.
+func (mr *MockSecondaryMockRecorder) GetDocIDByLongURL(ctx, domain, longURL interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDocIDByLongURL", reflect.TypeOf((*MockSecondary)(nil).GetDocIDByLongURL), ctx, domain, longURL)
+}
+
+// GetStats mocks base method.
+func (m *MockSecondary) GetStats(ctx context.Context, shortURL string) (urls.URL, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStats", ctx, shortURL)
+	ret0, _ := ret[0].(urls.URL)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStats indicates an expected call of GetStats.
+func (mr *MockSecondaryMockRecorder) GetStats(ctx, shortURL interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStats", reflect.TypeOf((*MockSecondary)(nil).GetStats), ctx, shortURL)
+}
+
+// IncrementVariantClicks mocks base method.
+func (m *MockSecondary) IncrementVariantClicks(ctx context.Context, shortURL, variant string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IncrementVariantClicks", ctx, shortURL, variant)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// IncrementVariantClicks indicates an expected call of IncrementVariantClicks.
+func (mr *MockSecondaryMockRecorder) IncrementVariantClicks(ctx, shortURL, variant interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncrementVariantClicks", reflect.TypeOf((*MockSecondary)(nil).IncrementVariantClicks), ctx, shortURL, variant)
+}
+
+// PutURLs mocks base method.
+func (m *MockSecondary) PutURLs(ctx context.Context, records []urls.Record) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PutURLs", ctx, records)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PutURLs indicates an expected call of PutURLs.
+func (mr *MockSecondaryMockRecorder) PutURLs(ctx, records interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PutURLs", reflect.TypeOf((*MockSecondary)(nil).PutURLs), ctx, records)
+}
+
+// SetClicks mocks base method.
+func (m *MockSecondary) SetClicks(ctx context.Context, shortURL string, clicks int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetClicks", ctx, shortURL, clicks)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetClicks indicates an expected call of SetClicks.
+func (mr *MockSecondaryMockRecorder) SetClicks(ctx, shortURL, clicks interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetClicks", reflect.TypeOf((*MockSecondary)(nil).SetClicks), ctx, shortURL, clicks)
+}
+
+// UpdateMetadata mocks base method.
+func (m *MockSecondary) UpdateMetadata(ctx context.Context, shortURL string, metadata urls.Metadata) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateMetadata", ctx, shortURL, metadata)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateMetadata indicates an expected call of UpdateMetadata.
+func (mr *MockSecondaryMockRecorder) UpdateMetadata(ctx, shortURL, metadata interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateMetadata", reflect.TypeOf((*MockSecondary)(nil).UpdateMetadata), ctx, shortURL, metadata)
+}
+
+// UpdatePreview mocks base method.
+func (m *MockSecondary) UpdatePreview(ctx context.Context, shortURL string, preview unfurl.Preview) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePreview", ctx, shortURL, preview)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePreview indicates an expected call of UpdatePreview.
+func (mr *MockSecondaryMockRecorder) UpdatePreview(ctx, shortURL, preview interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePreview", reflect.TypeOf((*MockSecondary)(nil).UpdatePreview), ctx, shortURL, preview)
+}
+
+// UpdateVariants mocks base method.
+func (m *MockSecondary) UpdateVariants(ctx context.Context, shortURL string, urlVariants []variants.Variant) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateVariants", ctx, shortURL, urlVariants)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateVariants indicates an expected call of UpdateVariants.
+func (mr *MockSecondaryMockRecorder) UpdateVariants(ctx, shortURL, urlVariants interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateVariants", reflect.TypeOf((*MockSecondary)(nil).UpdateVariants), ctx, shortURL, urlVariants)
+}
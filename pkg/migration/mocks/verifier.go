@@ -0,0 +1,65 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: verifier.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+	urls "url-shortener/pkg/repository/firestore/urls"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockStore is a mock of Store interface.
+type MockStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockStoreMockRecorder
+}
+
+// MockStoreMockRecorder is the mock recorder for MockStore.
+type MockStoreMockRecorder struct {
+	mock *MockStore
+}
+
+// NewMockStore creates a new mock instance.
+func NewMockStore(ctrl *gomock.Controller) *MockStore {
+	mock := &MockStore{ctrl: ctrl}
+	mock.recorder = &MockStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockStore) EXPECT() *MockStoreMockRecorder {
+	return m.recorder
+}
+
+// ForEachAfter mocks base method.
+func (m *MockStore) ForEachAfter(ctx context.Context, afterID string, fn func(urls.Record) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ForEachAfter", ctx, afterID, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ForEachAfter indicates an expected call of ForEachAfter.
+func (mr *MockStoreMockRecorder) ForEachAfter(ctx, afterID, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ForEachAfter", reflect.TypeOf((*MockStore)(nil).ForEachAfter), ctx, afterID, fn)
+}
+
+// GetByShortURL mocks base method.
+func (m *MockStore) GetByShortURL(ctx context.Context, shortURL string) (urls.URL, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByShortURL", ctx, shortURL)
+	ret0, _ := ret[0].(urls.URL)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByShortURL indicates an expected call of GetByShortURL.
+func (mr *MockStoreMockRecorder) GetByShortURL(ctx, shortURL interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByShortURL", reflect.TypeOf((*MockStore)(nil).GetByShortURL), ctx, shortURL)
+}
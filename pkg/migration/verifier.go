@@ -0,0 +1,173 @@
+package migration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+	"url-shortener/pkg/repository/firestore/urls"
+)
+
+//go:generate mockgen --source=verifier.go --destination mocks/verifier.go --package mocks
+
+type Store interface {
+	ForEachAfter(ctx context.Context, afterID string, fn func(record urls.Record) error) error
+	GetByShortURL(ctx context.Context, shortURL string) (urls.URL, error)
+}
+
+// VerifyReport describes the differences found between two stores
+type VerifyReport struct {
+	SourceURLs      int
+	DestinationURLs int
+	Sampled         int
+	// Mismatches holds the ids of sampled URLs which are missing or different in the destination
+	Mismatches []string
+}
+
+// OK reports whether both stores hold the same number of URLs and all sampled URLs match
+func (r VerifyReport) OK() bool {
+	return r.SourceURLs == r.DestinationURLs && len(r.Mismatches) == 0
+}
+
+// Verifier compares the contents of two stores
+type Verifier struct {
+	source      Store
+	destination Store
+	sampleSize  int
+}
+
+// NewVerifier is a constructor function
+func NewVerifier(source, destination Store, sampleSize int) *Verifier {
+	return &Verifier{
+		source:      source,
+		destination: destination,
+		sampleSize:  sampleSize,
+	}
+}
+
+// Verify counts the URLs in both stores and compares a random sample of source URLs with the destination ones
+func (v *Verifier) Verify(ctx context.Context) (VerifyReport, error) {
+	var (
+		report VerifyReport
+		sample []urls.Record
+	)
+
+	err := v.source.ForEachAfter(ctx, "", func(record urls.Record) error {
+		report.SourceURLs++
+		if len(sample) < v.sampleSize {
+			sample = append(sample, record)
+			return nil
+		}
+
+		// reservoir sampling keeps every URL with the same probability
+		if i := rand.Intn(report.SourceURLs); i < v.sampleSize {
+			sample[i] = record
+		}
+
+		return nil
+	})
+	if err != nil {
+		return report, fmt.Errorf("failed to count source urls: %w", err)
+	}
+
+	err = v.destination.ForEachAfter(ctx, "", func(record urls.Record) error {
+		report.DestinationURLs++
+		return nil
+	})
+	if err != nil {
+		return report, fmt.Errorf("failed to count destination urls: %w", err)
+	}
+
+	for _, record := range sample {
+		report.Sampled++
+		url, err := v.destination.GetByShortURL(ctx, record.ID)
+		if err != nil {
+			var notFoundErr urls.NotFoundError
+			if !errors.As(err, &notFoundErr) {
+				return report, fmt.Errorf("failed to get destination url with id [%s]: %w", record.ID, err)
+			}
+
+			report.Mismatches = append(report.Mismatches, record.ID)
+			continue
+		}
+
+		if !sameURL(url, record.URL) {
+			report.Mismatches = append(report.Mismatches, record.ID)
+		}
+	}
+
+	return report, nil
+}
+
+// sameURL compares the settings of two URLs, counts, previews and health checks change independently in both stores.
+// Times are compared as instants in microseconds and nil slices and maps equal empty ones, as stores may decode them differently.
+func sameURL(a, b urls.URL) bool {
+	if a.LongURL != b.LongURL || a.Domain != b.Domain || a.RedirectType != b.RedirectType ||
+		a.PasswordHash != b.PasswordHash || a.MaxClicks != b.MaxClicks || a.FallbackURL != b.FallbackURL ||
+		a.QueryPassthrough != b.QueryPassthrough || a.Prefix != b.Prefix || a.Exclusive != b.Exclusive {
+		return false
+	}
+
+	if !sameTime(a.NotBefore, b.NotBefore) || !sameTime(a.NotAfter, b.NotAfter) {
+		return false
+	}
+
+	if a.Title != b.Title || a.Description != b.Description || a.Folder != b.Folder || !sameStrings(a.Tags, b.Tags) {
+		return false
+	}
+
+	if len(a.UTM) != len(b.UTM) {
+		return false
+	}
+
+	for key, value := range a.UTM {
+		if b.UTM[key] != value {
+			return false
+		}
+	}
+
+	if len(a.Variants) != len(b.Variants) || len(a.Rules) != len(b.Rules) {
+		return false
+	}
+
+	for i := range a.Variants {
+		if a.Variants[i] != b.Variants[i] {
+			return false
+		}
+	}
+
+	for i, rule := range a.Rules {
+		other := b.Rules[i]
+		if rule.Destination != other.Destination || rule.TimeFrom != other.TimeFrom || rule.TimeTo != other.TimeTo ||
+			rule.TimeZone != other.TimeZone || !sameStrings(rule.Platforms, other.Platforms) ||
+			!sameStrings(rule.Languages, other.Languages) || !sameStrings(rule.Countries, other.Countries) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	// Firestore keeps microseconds
+	return a.Truncate(time.Microsecond).Equal(b.Truncate(time.Microsecond))
+}
+
+func sameStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,111 @@
+package migration_test
+
+import (
+	"context"
+	"time"
+	"url-shortener/pkg/migration"
+	"url-shortener/pkg/migration/mocks"
+	"url-shortener/pkg/repository/firestore/urls"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Verifier", func() {
+	var (
+		mockCtrl        *gomock.Controller
+		mockSource      *mocks.MockStore
+		mockDestination *mocks.MockStore
+		verifier        *migration.Verifier
+		ctx             context.Context
+		record          urls.Record
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockSource = mocks.NewMockStore(mockCtrl)
+		mockDestination = mocks.NewMockStore(mockCtrl)
+		verifier = migration.NewVerifier(mockSource, mockDestination, 10)
+		ctx = context.Background()
+		record = urls.Record{ID: "1", URL: urls.URL{LongURL: "https://first.com"}}
+	})
+
+	iterate := func(records ...urls.Record) func(context.Context, string, func(urls.Record) error) error {
+		return func(ctx context.Context, afterID string, fn func(urls.Record) error) error {
+			for _, record := range records {
+				if err := fn(record); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		}
+	}
+
+	When("stores match", func() {
+		BeforeEach(func() {
+			mockSource.EXPECT().ForEachAfter(ctx, "", gomock.Any()).DoAndReturn(iterate(record))
+			mockDestination.EXPECT().ForEachAfter(ctx, "", gomock.Any()).DoAndReturn(iterate(record))
+			mockDestination.EXPECT().GetByShortURL(ctx, record.ID).Return(record.URL, nil)
+		})
+
+		It("should report no differences", func() {
+			report, err := verifier.Verify(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(report.OK()).To(BeTrue())
+			Expect(report.Sampled).To(Equal(1))
+		})
+	})
+
+	When("a sampled url is decoded differently by the destination", func() {
+		BeforeEach(func() {
+			notAfter := time.Date(2030, 1, 1, 12, 0, 0, 123456789, time.UTC)
+			record.URL.NotAfter = &notAfter
+			record.URL.Tags = []string{}
+			decoded := notAfter.Truncate(time.Microsecond).In(time.FixedZone("CET", 3600))
+			// the destination stores microseconds in another location and drops empty slices
+			decodedURL := urls.URL{LongURL: record.URL.LongURL, NotAfter: &decoded, Clicks: 3}
+
+			mockSource.EXPECT().ForEachAfter(ctx, "", gomock.Any()).DoAndReturn(iterate(record))
+			mockDestination.EXPECT().ForEachAfter(ctx, "", gomock.Any()).DoAndReturn(iterate(record))
+			mockDestination.EXPECT().GetByShortURL(ctx, record.ID).Return(decodedURL, nil)
+		})
+
+		It("should report no differences", func() {
+			report, err := verifier.Verify(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(report.Mismatches).To(BeEmpty())
+		})
+	})
+
+	When("a sampled url differs in the destination", func() {
+		BeforeEach(func() {
+			mockSource.EXPECT().ForEachAfter(ctx, "", gomock.Any()).DoAndReturn(iterate(record))
+			mockDestination.EXPECT().ForEachAfter(ctx, "", gomock.Any()).DoAndReturn(iterate(record))
+			mockDestination.EXPECT().GetByShortURL(ctx, record.ID).Return(urls.URL{LongURL: "https://other.com"}, nil)
+		})
+
+		It("should report the mismatch", func() {
+			report, err := verifier.Verify(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(report.Mismatches).To(Equal([]string{record.ID}))
+		})
+	})
+
+	When("a sampled url is missing in the destination", func() {
+		BeforeEach(func() {
+			mockSource.EXPECT().ForEachAfter(ctx, "", gomock.Any()).DoAndReturn(iterate(record))
+			mockDestination.EXPECT().ForEachAfter(ctx, "", gomock.Any()).DoAndReturn(iterate())
+			mockDestination.EXPECT().GetByShortURL(ctx, record.ID).Return(urls.URL{}, urls.NewNotFoundError())
+		})
+
+		It("should report the mismatch", func() {
+			report, err := verifier.Verify(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(report.OK()).To(BeFalse())
+			Expect(report.Mismatches).To(Equal([]string{record.ID}))
+			Expect(report.DestinationURLs).To(Equal(0))
+		})
+	})
+})
@@ -0,0 +1,219 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// methods are the operations of a path item, in the order they are listed
+var methods = []string{
+	http.MethodGet, http.MethodPut, http.MethodPost, http.MethodDelete,
+	http.MethodOptions, http.MethodHead, http.MethodPatch, http.MethodTrace,
+}
+
+// Spec is the subset of an OpenAPI 3 document used to validate requests and responses
+type Spec struct {
+	OpenAPI    string              `json:"openapi"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+}
+
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas"`
+}
+
+// PathItem holds the operations of a path by lowercase method
+type PathItem map[string]*Operation
+
+type Operation struct {
+	OperationID string              `json:"operationId"`
+	Parameters  []Parameter         `json:"parameters"`
+	RequestBody *RequestBody        `json:"requestBody"`
+	Responses   map[string]Response `json:"responses"`
+}
+
+type Parameter struct {
+	Name string `json:"name"`
+	// In is where the parameter is sent, one of path, query or header
+	In       string  `json:"in"`
+	Required bool    `json:"required"`
+	Schema   *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+type Response struct {
+	Content map[string]MediaType `json:"content"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Schema is the subset of JSON schema keywords supported by the validation
+type Schema struct {
+	Ref        string             `json:"$ref"`
+	Type       string             `json:"type"`
+	Format     string             `json:"format"`
+	Nullable   bool               `json:"nullable"`
+	Enum       []interface{}      `json:"enum"`
+	Properties map[string]*Schema `json:"properties"`
+	Required   []string           `json:"required"`
+	// AdditionalProperties is either a boolean or the schema of the properties which are not listed
+	AdditionalProperties json.RawMessage `json:"additionalProperties"`
+	Items                *Schema         `json:"items"`
+	AllOf                []*Schema       `json:"allOf"`
+	Minimum              *float64        `json:"minimum"`
+	Maximum              *float64        `json:"maximum"`
+	MinLength            *int            `json:"minLength"`
+	MaxLength            *int            `json:"maxLength"`
+	MinItems             *int            `json:"minItems"`
+	MaxItems             *int            `json:"maxItems"`
+}
+
+// Route is an operation of the spec, its path uses the {param} template syntax
+type Route struct {
+	Method string
+	Path   string
+}
+
+// Parse decodes an OpenAPI 3 document and checks that all its schema references resolve
+func Parse(data []byte) (*Spec, error) {
+	var spec Spec
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return nil, fmt.Errorf("failed to decode spec: %w", err)
+	}
+
+	if !strings.HasPrefix(spec.OpenAPI, "3.") {
+		return nil, fmt.Errorf("unsupported openapi version [%s]", spec.OpenAPI)
+	}
+
+	for _, route := range spec.Routes() {
+		operation, _ := spec.Operation(route.Method, route.Path)
+		for _, schema := range operation.schemas() {
+			if err := spec.checkRefs(schema, map[*Schema]bool{}); err != nil {
+				return nil, fmt.Errorf("invalid operation %s %s: %w", route.Method, route.Path, err)
+			}
+		}
+	}
+
+	return &spec, nil
+}
+
+// Routes returns the operations of the spec ordered by path and method
+func (s *Spec) Routes() []Route {
+	var routes []Route
+	for path, item := range s.Paths {
+		for _, method := range methods {
+			if _, ok := item[strings.ToLower(method)]; ok {
+				routes = append(routes, Route{Method: method, Path: path})
+			}
+		}
+	}
+
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Path != routes[j].Path {
+			return routes[i].Path < routes[j].Path
+		}
+
+		return routes[i].Method < routes[j].Method
+	})
+
+	return routes
+}
+
+// Operation returns the operation of a method on a path template
+func (s *Spec) Operation(method, path string) (*Operation, bool) {
+	operation, ok := s.Paths[path][strings.ToLower(method)]
+	return operation, ok && operation != nil
+}
+
+// resolve follows the reference of a schema to the components
+func (s *Spec) resolve(schema *Schema) (*Schema, error) {
+	for schema.Ref != "" {
+		name := strings.TrimPrefix(schema.Ref, "#/components/schemas/")
+		target, ok := s.Components.Schemas[name]
+		if !ok || name == schema.Ref {
+			return nil, fmt.Errorf("unresolved reference [%s]", schema.Ref)
+		}
+
+		schema = target
+	}
+
+	return schema, nil
+}
+
+func (s *Spec) checkRefs(schema *Schema, seen map[*Schema]bool) error {
+	if schema == nil || seen[schema] {
+		return nil
+	}
+
+	seen[schema] = true
+
+	resolved, err := s.resolve(schema)
+	if err != nil {
+		return err
+	}
+
+	children := append([]*Schema{resolved.Items}, resolved.AllOf...)
+	for _, property := range resolved.Properties {
+		children = append(children, property)
+	}
+
+	if additional, _ := additionalSchema(resolved); additional != nil {
+		children = append(children, additional)
+	}
+
+	for _, child := range children {
+		if err := s.checkRefs(child, seen); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (o *Operation) schemas() []*Schema {
+	var schemas []*Schema
+	for _, parameter := range o.Parameters {
+		schemas = append(schemas, parameter.Schema)
+	}
+
+	if o.RequestBody != nil {
+		for _, media := range o.RequestBody.Content {
+			schemas = append(schemas, media.Schema)
+		}
+	}
+
+	for _, response := range o.Responses {
+		for _, media := range response.Content {
+			schemas = append(schemas, media.Schema)
+		}
+	}
+
+	return schemas
+}
+
+// additionalSchema returns the schema of unlisted properties, or nil and whether they are allowed at all
+func additionalSchema(schema *Schema) (*Schema, bool) {
+	raw := strings.TrimSpace(string(schema.AdditionalProperties))
+	switch raw {
+	case "", "true":
+		return nil, true
+	case "false":
+		return nil, false
+	}
+
+	var additional Schema
+	if err := json.Unmarshal(schema.AdditionalProperties, &additional); err != nil {
+		return nil, true
+	}
+
+	return &additional, true
+}
@@ -0,0 +1,177 @@
+package openapi_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"url-shortener/pkg/openapi"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+const document = `{
+	"openapi": "3.0.3",
+	"paths": {
+		"/links": {
+			"post": {
+				"requestBody": {
+					"required": true,
+					"content": {"application/json": {"schema": {"$ref": "#/components/schemas/Link"}}}
+				},
+				"responses": {
+					"201": {"content": {"application/json": {"schema": {"$ref": "#/components/schemas/Link"}}}},
+					"4XX": {"content": {"application/json": {"schema": {"type": "string"}}}}
+				}
+			},
+			"get": {
+				"parameters": [
+					{"name": "limit", "in": "query", "schema": {"type": "integer", "minimum": 1, "maximum": 10}},
+					{"name": "tag", "in": "query", "schema": {"type": "array", "items": {"type": "string"}, "maxItems": 2}}
+				],
+				"responses": {"200": {}}
+			}
+		},
+		"/links/{id}": {
+			"delete": {
+				"parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "string", "minLength": 3}}],
+				"responses": {"204": {}}
+			}
+		},
+		"/text": {
+			"post": {
+				"requestBody": {"required": true, "content": {"text/plain": {"schema": {"type": "string", "minLength": 1}}}},
+				"responses": {"200": {}}
+			}
+		}
+	},
+	"components": {
+		"schemas": {
+			"Link": {
+				"type": "object",
+				"required": ["url"],
+				"additionalProperties": false,
+				"properties": {
+					"url": {"type": "string"},
+					"weight": {"type": "integer"},
+					"kind": {"type": "string", "enum": ["a", "b"]},
+					"expires_at": {"type": "string", "format": "date-time", "nullable": true},
+					"labels": {"type": "object", "additionalProperties": {"type": "string"}}
+				}
+			}
+		}
+	}
+}`
+
+var _ = Describe("OpenAPI", func() {
+	var spec *openapi.Spec
+
+	BeforeEach(func() {
+		var err error
+		spec, err = openapi.Parse([]byte(document))
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	When("parsing a spec", func() {
+		It("should list the routes ordered by path and method", func() {
+			Expect(spec.Routes()).To(Equal([]openapi.Route{
+				{Method: http.MethodGet, Path: "/links"},
+				{Method: http.MethodPost, Path: "/links"},
+				{Method: http.MethodDelete, Path: "/links/{id}"},
+				{Method: http.MethodPost, Path: "/text"},
+			}))
+		})
+
+		It("should fail for unresolved references", func() {
+			_, err := openapi.Parse([]byte(`{"openapi": "3.0.3", "paths": {"/": {"get": {"responses": {
+				"200": {"content": {"application/json": {"schema": {"$ref": "#/components/schemas/Missing"}}}}}}}}}`))
+			Expect(err).To(MatchError(ContainSubstring("unresolved reference [#/components/schemas/Missing]")))
+		})
+
+		It("should fail for other versions", func() {
+			_, err := openapi.Parse([]byte(`{"swagger": "2.0"}`))
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	When("validating request bodies", func() {
+		validate := func(path, contentType, body string) (*http.Request, error) {
+			operation, ok := spec.Operation(http.MethodPost, path)
+			Expect(ok).To(BeTrue())
+			request := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+			request.Header.Set("Content-Type", contentType)
+			return request, spec.ValidateRequest(operation, request, nil)
+		}
+
+		It("should accept valid bodies and keep them readable", func() {
+			body := `{"url":"https://example.com","weight":2,"kind":"a","expires_at":null,"labels":{"team":"x"}}`
+			request, err := validate("/links", "application/json; charset=utf-8", body)
+			Expect(err).ToNot(HaveOccurred())
+
+			read, err := io.ReadAll(request.Body)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(read)).To(Equal(body))
+		})
+
+		DescribeTable("should reject invalid bodies",
+			func(contentType, body, field, reason string) {
+				_, err := validate("/links", contentType, body)
+				Expect(err).To(Equal(openapi.NewValidationError(field, reason)))
+			},
+			Entry("missing", "application/json", ``, "body", "is required"),
+			Entry("malformed", "application/json", `{`, "body", "must be valid JSON"),
+			Entry("unsupported content type", "application/xml", `<a/>`, "body", "content type [application/xml] is not supported"),
+			Entry("missing property", "application/json", `{}`, "body.url", "is required"),
+			Entry("unknown property", "application/json", `{"url":"x","other":1}`, "body.other", "is not allowed"),
+			Entry("wrong type", "application/json", `{"url":1}`, "body.url", "must be a string"),
+			Entry("fraction", "application/json", `{"url":"x","weight":1.5}`, "body.weight", "must be an integer"),
+			Entry("enum", "application/json", `{"url":"x","kind":"c"}`, "body.kind", "must be one of [a b]"),
+			Entry("date-time", "application/json", `{"url":"x","expires_at":"tomorrow"}`, "body.expires_at", "must be an RFC 3339 timestamp"),
+			Entry("additional properties", "application/json", `{"url":"x","labels":{"team":1}}`, "body.labels.team", "must be a string"),
+		)
+
+		It("should validate text bodies as strings", func() {
+			_, err := validate("/text", "text/plain", "https://example.com")
+			Expect(err).ToNot(HaveOccurred())
+		})
+	})
+
+	When("validating parameters", func() {
+		It("should coerce and check query parameters", func() {
+			operation, _ := spec.Operation(http.MethodGet, "/links")
+
+			Expect(spec.ValidateRequest(operation, httptest.NewRequest(http.MethodGet, "/links?limit=5&tag=a&tag=b", nil), nil)).To(Succeed())
+			Expect(spec.ValidateRequest(operation, httptest.NewRequest(http.MethodGet, "/links?limit=x", nil), nil)).
+				To(Equal(openapi.NewValidationError("query.limit", "must be an integer")))
+			Expect(spec.ValidateRequest(operation, httptest.NewRequest(http.MethodGet, "/links?limit=11", nil), nil)).
+				To(Equal(openapi.NewValidationError("query.limit", "must be at most 10")))
+			Expect(spec.ValidateRequest(operation, httptest.NewRequest(http.MethodGet, "/links?tag=a&tag=b&tag=c", nil), nil)).
+				To(Equal(openapi.NewValidationError("query.tag", "must have at most 2 items")))
+		})
+
+		It("should check path parameters", func() {
+			operation, _ := spec.Operation(http.MethodDelete, "/links/{id}")
+			request := httptest.NewRequest(http.MethodDelete, "/links/ab", nil)
+
+			Expect(spec.ValidateRequest(operation, request, map[string]string{"id": "abc"})).To(Succeed())
+			Expect(spec.ValidateRequest(operation, request, map[string]string{"id": "ab"})).
+				To(Equal(openapi.NewValidationError("path.id", "must have at least 3 characters")))
+			Expect(spec.ValidateRequest(operation, request, nil)).
+				To(Equal(openapi.NewValidationError("path.id", "is required")))
+		})
+	})
+
+	When("validating responses", func() {
+		It("should check the body of the documented status", func() {
+			operation, _ := spec.Operation(http.MethodPost, "/links")
+
+			Expect(spec.ValidateResponse(operation, http.StatusCreated, "application/json", []byte(`{"url":"x"}`))).To(Succeed())
+			Expect(spec.ValidateResponse(operation, http.StatusCreated, "application/json", []byte(`{"weight":1}`))).
+				To(Equal(openapi.NewValidationError("response.url", "is required")))
+			Expect(spec.ValidateResponse(operation, http.StatusBadRequest, "application/json", []byte(`"Invalid request body"`))).To(Succeed())
+			Expect(spec.ValidateResponse(operation, http.StatusInternalServerError, "application/json", []byte(`"Error"`))).
+				To(Equal(openapi.NewValidationError("response", "status 500 is not documented")))
+		})
+	})
+})
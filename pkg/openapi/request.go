@@ -0,0 +1,184 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ValidateRequest checks the parameters and body of a request against its operation
+// The body is read and replaced, so handlers can still read it.
+func (s *Spec) ValidateRequest(operation *Operation, request *http.Request, pathParams map[string]string) error {
+	query := request.URL.Query()
+	for _, parameter := range operation.Parameters {
+		var values []string
+		switch parameter.In {
+		case "path":
+			if value, ok := pathParams[parameter.Name]; ok {
+				values = []string{value}
+			}
+		case "query":
+			values = query[parameter.Name]
+		case "header":
+			values = request.Header.Values(parameter.Name)
+		default:
+			continue
+		}
+
+		field := parameter.In + "." + parameter.Name
+		if len(values) == 0 {
+			if parameter.Required {
+				return NewValidationError(field, "is required")
+			}
+
+			continue
+		}
+
+		value, err := s.coerce(parameter.Schema, field, values)
+		if err != nil {
+			return err
+		}
+
+		if err := s.Validate(parameter.Schema, field, value); err != nil {
+			return err
+		}
+	}
+
+	if operation.RequestBody == nil || request.Body == nil {
+		return nil
+	}
+
+	body, err := io.ReadAll(request.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read body: %w", err)
+	}
+
+	request.Body = io.NopCloser(bytes.NewReader(body))
+
+	if len(body) == 0 {
+		if operation.RequestBody.Required {
+			return NewValidationError("body", "is required")
+		}
+
+		return nil
+	}
+
+	return s.validateContent(operation.RequestBody.Content, "body", request.Header.Get("Content-Type"), body)
+}
+
+// ValidateResponse checks the body of a response against the documented response of its status
+func (s *Spec) ValidateResponse(operation *Operation, status int, contentType string, body []byte) error {
+	code := strconv.Itoa(status)
+	response, ok := operation.Responses[code]
+	if !ok {
+		response, ok = operation.Responses[code[:1]+"XX"]
+	}
+
+	if !ok {
+		response, ok = operation.Responses["default"]
+	}
+
+	if !ok {
+		return NewValidationError("response", fmt.Sprintf("status %d is not documented", status))
+	}
+
+	if len(response.Content) == 0 || len(body) == 0 {
+		return nil
+	}
+
+	return s.validateContent(response.Content, "response", contentType, body)
+}
+
+func (s *Spec) validateContent(content map[string]MediaType, field, contentType string, body []byte) error {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil && len(content) == 1 {
+		// a missing content type is taken as the only documented one, as gin does for JSON bodies
+		for documented := range content {
+			mediaType = documented
+		}
+	}
+
+	media, ok := content[mediaType]
+	if !ok {
+		media, ok = content["*/*"]
+	}
+
+	if !ok {
+		return NewValidationError(field, fmt.Sprintf("content type [%s] is not supported", contentType))
+	}
+
+	if media.Schema == nil {
+		return nil
+	}
+
+	if !isJSON(mediaType) {
+		// other bodies are only checked against string schemas, forms and binaries are taken as they are
+		if schema, err := s.resolve(media.Schema); err != nil || schema.Type != "string" {
+			return err
+		}
+
+		return s.Validate(media.Schema, field, string(body))
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		return NewValidationError(field, "must be valid JSON")
+	}
+
+	return s.Validate(media.Schema, field, value)
+}
+
+// coerce converts the string values of a parameter to the type of its schema
+func (s *Spec) coerce(schema *Schema, field string, values []string) (interface{}, error) {
+	if schema == nil {
+		return values[0], nil
+	}
+
+	schema, err := s.resolve(schema)
+	if err != nil {
+		return nil, err
+	}
+
+	if schema.Type == "array" {
+		items := make([]interface{}, 0, len(values))
+		for i, value := range values {
+			item, err := s.coerce(schema.Items, fmt.Sprintf("%s[%d]", field, i), []string{value})
+			if err != nil {
+				return nil, err
+			}
+
+			items = append(items, item)
+		}
+
+		return items, nil
+	}
+
+	value := values[0]
+	switch schema.Type {
+	case "integer", "number":
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, NewValidationError(field, mustBe(schema.Type))
+		}
+
+		return number, nil
+	case "boolean":
+		boolean, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, NewValidationError(field, "must be a boolean")
+		}
+
+		return boolean, nil
+	default:
+		return value, nil
+	}
+}
+
+func isJSON(mediaType string) bool {
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
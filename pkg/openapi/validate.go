@@ -0,0 +1,209 @@
+package openapi
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"time"
+	"unicode/utf8"
+)
+
+// ValidationError is returned for values which do not match their schema
+type ValidationError struct {
+	// Field locates the invalid value, e.g. body.rules[0].destination or query.limit
+	Field  string
+	Reason string
+}
+
+func NewValidationError(field, reason string) ValidationError {
+	return ValidationError{Field: field, Reason: reason}
+}
+
+func (e ValidationError) Error() string {
+	return fmt.Sprintf("%s %s", e.Field, e.Reason)
+}
+
+// Validate checks a decoded JSON value against a schema, field names the value in the returned error
+func (s *Spec) Validate(schema *Schema, field string, value interface{}) error {
+	if schema == nil {
+		return nil
+	}
+
+	schema, err := s.resolve(schema)
+	if err != nil {
+		return err
+	}
+
+	for _, part := range schema.AllOf {
+		if err := s.Validate(part, field, value); err != nil {
+			return err
+		}
+	}
+
+	if value == nil {
+		if schema.Nullable || schema.Type == "" {
+			return nil
+		}
+
+		return NewValidationError(field, "must not be null")
+	}
+
+	if len(schema.Enum) > 0 && !inEnum(schema.Enum, value) {
+		return NewValidationError(field, fmt.Sprintf("must be one of %v", schema.Enum))
+	}
+
+	switch schema.Type {
+	case "":
+		return nil
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return NewValidationError(field, "must be an object")
+		}
+
+		return s.validateObject(schema, field, object)
+	case "array":
+		array, ok := value.([]interface{})
+		if !ok {
+			return NewValidationError(field, "must be an array")
+		}
+
+		return s.validateArray(schema, field, array)
+	case "string":
+		text, ok := value.(string)
+		if !ok {
+			return NewValidationError(field, "must be a string")
+		}
+
+		return validateString(schema, field, text)
+	case "integer", "number":
+		number, ok := value.(float64)
+		if !ok {
+			return NewValidationError(field, mustBe(schema.Type))
+		}
+
+		if schema.Type == "integer" && number != math.Trunc(number) {
+			return NewValidationError(field, mustBe(schema.Type))
+		}
+
+		return validateNumber(schema, field, number)
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return NewValidationError(field, "must be a boolean")
+		}
+
+		return nil
+	default:
+		return fmt.Errorf("unsupported schema type [%s]", schema.Type)
+	}
+}
+
+func (s *Spec) validateObject(schema *Schema, field string, object map[string]interface{}) error {
+	for _, name := range schema.Required {
+		if _, ok := object[name]; !ok {
+			return NewValidationError(join(field, name), "is required")
+		}
+	}
+
+	additional, allowed := additionalSchema(schema)
+	names := make([]string, 0, len(object))
+	for name := range object {
+		names = append(names, name)
+	}
+
+	// sorted so the same invalid value always reports the same field
+	sort.Strings(names)
+
+	for _, name := range names {
+		property, ok := schema.Properties[name]
+		if !ok {
+			if !allowed {
+				return NewValidationError(join(field, name), "is not allowed")
+			}
+
+			property = additional
+		}
+
+		if err := s.Validate(property, join(field, name), object[name]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *Spec) validateArray(schema *Schema, field string, array []interface{}) error {
+	if schema.MinItems != nil && len(array) < *schema.MinItems {
+		return NewValidationError(field, fmt.Sprintf("must have at least %d items", *schema.MinItems))
+	}
+
+	if schema.MaxItems != nil && len(array) > *schema.MaxItems {
+		return NewValidationError(field, fmt.Sprintf("must have at most %d items", *schema.MaxItems))
+	}
+
+	for i, item := range array {
+		if err := s.Validate(schema.Items, fmt.Sprintf("%s[%d]", field, i), item); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func validateString(schema *Schema, field, text string) error {
+	length := utf8.RuneCountInString(text)
+	if schema.MinLength != nil && length < *schema.MinLength {
+		return NewValidationError(field, fmt.Sprintf("must have at least %d characters", *schema.MinLength))
+	}
+
+	if schema.MaxLength != nil && length > *schema.MaxLength {
+		return NewValidationError(field, fmt.Sprintf("must have at most %d characters", *schema.MaxLength))
+	}
+
+	if schema.Format == "date-time" {
+		if _, err := time.Parse(time.RFC3339, text); err != nil {
+			return NewValidationError(field, "must be an RFC 3339 timestamp")
+		}
+	}
+
+	return nil
+}
+
+func validateNumber(schema *Schema, field string, number float64) error {
+	if schema.Minimum != nil && number < *schema.Minimum {
+		return NewValidationError(field, fmt.Sprintf("must be at least %v", *schema.Minimum))
+	}
+
+	if schema.Maximum != nil && number > *schema.Maximum {
+		return NewValidationError(field, fmt.Sprintf("must be at most %v", *schema.Maximum))
+	}
+
+	return nil
+}
+
+func inEnum(enum []interface{}, value interface{}) bool {
+	for _, allowed := range enum {
+		if reflect.DeepEqual(allowed, value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func join(field, name string) string {
+	if field == "" {
+		return name
+	}
+
+	return field + "." + name
+}
+
+func mustBe(schemaType string) string {
+	if schemaType == "integer" {
+		return "must be an integer"
+	}
+
+	return "must be a " + schemaType
+}
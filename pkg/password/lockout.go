@@ -0,0 +1,73 @@
+package password
+
+import (
+	"sync"
+	"time"
+)
+
+// Lockout counts failed password attempts per short URL and locks it once too many failed within a window
+// The state is kept in memory, so every instance of the service locks on its own
+type Lockout struct {
+	maxAttempts int
+	duration    time.Duration
+	mu          sync.Mutex
+	attempts    map[string]*attempts
+}
+
+type attempts struct {
+	failed int
+	since  time.Time
+}
+
+// NewLockout is a constructor function
+// A short URL is locked for duration after maxAttempts failed attempts within the same duration
+func NewLockout(maxAttempts int, duration time.Duration) *Lockout {
+	return &Lockout{
+		maxAttempts: maxAttempts,
+		duration:    duration,
+		attempts:    make(map[string]*attempts),
+	}
+}
+
+// Locked reports whether the short URL is locked
+func (l *Lockout) Locked(shortURL string, now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	a, ok := l.attempts[shortURL]
+	if !ok {
+		return false
+	}
+
+	if now.Sub(a.since) >= l.duration {
+		delete(l.attempts, shortURL)
+		return false
+	}
+
+	return a.failed >= l.maxAttempts
+}
+
+// Fail records a failed attempt
+func (l *Lockout) Fail(shortURL string, now time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	a, ok := l.attempts[shortURL]
+	if !ok || now.Sub(a.since) >= l.duration {
+		a = &attempts{since: now}
+		l.attempts[shortURL] = a
+	}
+
+	a.failed++
+	if a.failed == l.maxAttempts {
+		// the lock lasts for the full duration from the attempt which caused it
+		a.since = now
+	}
+}
+
+// Reset forgets the failed attempts after a successful one
+func (l *Lockout) Reset(shortURL string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.attempts, shortURL)
+}
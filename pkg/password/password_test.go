@@ -0,0 +1,85 @@
+package password_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"url-shortener/pkg/password"
+)
+
+var _ = Describe("Password", func() {
+	When("hashing a password", func() {
+		It("should verify only the same password", func() {
+			hash, err := password.Hash("secret")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(hash).ToNot(Equal("secret"))
+			Expect(password.Verify(hash, "secret")).To(BeTrue())
+			Expect(password.Verify(hash, "other")).To(BeFalse())
+		})
+	})
+
+	When("signing a token", func() {
+		var (
+			signer *password.Signer
+			now    time.Time
+			token  string
+		)
+
+		BeforeEach(func() {
+			signer = password.NewSigner([]byte("key"))
+			now = time.Now()
+			token = signer.Token("abc", now.Add(time.Hour))
+		})
+
+		It("should be valid for the same short url before expiry", func() {
+			Expect(signer.Valid("abc", token, now)).To(BeTrue())
+		})
+
+		It("should be invalid for another short url", func() {
+			Expect(signer.Valid("abd", token, now)).To(BeFalse())
+		})
+
+		It("should be invalid after expiry", func() {
+			Expect(signer.Valid("abc", token, now.Add(2*time.Hour))).To(BeFalse())
+		})
+
+		It("should be invalid when signed with another secret", func() {
+			Expect(password.NewSigner([]byte("other")).Valid("abc", token, now)).To(BeFalse())
+		})
+
+		It("should be invalid when tampered", func() {
+			Expect(signer.Valid("abc", "9999999999"+token[10:], now)).To(BeFalse())
+			Expect(signer.Valid("abc", "invalid", now)).To(BeFalse())
+		})
+	})
+
+	When("too many attempts fail", func() {
+		var (
+			lockout *password.Lockout
+			now     time.Time
+		)
+
+		BeforeEach(func() {
+			lockout = password.NewLockout(2, time.Minute)
+			now = time.Now()
+			lockout.Fail("abc", now)
+			lockout.Fail("abc", now)
+		})
+
+		It("should lock the short url", func() {
+			Expect(lockout.Locked("abc", now)).To(BeTrue())
+			Expect(lockout.Locked("other", now)).To(BeFalse())
+		})
+
+		It("should unlock it after the lockout duration", func() {
+			Expect(lockout.Locked("abc", now.Add(time.Minute))).To(BeFalse())
+		})
+
+		It("should unlock it after a reset", func() {
+			lockout.Reset("abc")
+			Expect(lockout.Locked("abc", now)).To(BeFalse())
+		})
+	})
+})
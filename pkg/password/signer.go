@@ -0,0 +1,54 @@
+package password
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Signer issues and checks tokens proving that the password of a short URL was entered
+type Signer struct {
+	secret []byte
+}
+
+// NewSigner is a constructor function
+func NewSigner(secret []byte) *Signer {
+	return &Signer{
+		secret: secret,
+	}
+}
+
+// Token returns a token for the short URL valid until expires
+func (s *Signer) Token(shortURL string, expires time.Time) string {
+	expiry := strconv.FormatInt(expires.Unix(), 10)
+	return expiry + "." + base64.RawURLEncoding.EncodeToString(s.sign(shortURL, expiry))
+}
+
+// Valid reports whether the token was issued for the short URL and has not expired
+func (s *Signer) Valid(shortURL, token string, now time.Time) bool {
+	expiry, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return false
+	}
+
+	expires, err := strconv.ParseInt(expiry, 10, 64)
+	if err != nil || now.Unix() >= expires {
+		return false
+	}
+
+	decoded, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+
+	return hmac.Equal(decoded, s.sign(shortURL, expiry))
+}
+
+func (s *Signer) sign(shortURL, expiry string) []byte {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(shortURL + "|" + expiry))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,36 @@
+package qrcode_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"url-shortener/pkg/qrcode"
+)
+
+var _ = Describe("Cache", func() {
+	var cache *qrcode.Cache
+
+	BeforeEach(func() {
+		cache = qrcode.NewCache(2)
+	})
+
+	When("adding more images than the capacity", func() {
+		BeforeEach(func() {
+			cache.Add("first", []byte("1"))
+			cache.Add("second", []byte("2"))
+			_, _ = cache.Get("first")
+			cache.Add("third", []byte("3"))
+		})
+
+		It("should evict the least recently used one", func() {
+			_, ok := cache.Get("second")
+			Expect(ok).To(BeFalse())
+			image, ok := cache.Get("first")
+			Expect(ok).To(BeTrue())
+			Expect(image).To(Equal([]byte("1")))
+			image, ok = cache.Get("third")
+			Expect(ok).To(BeTrue())
+			Expect(image).To(Equal([]byte("3")))
+		})
+	})
+})
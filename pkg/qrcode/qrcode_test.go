@@ -0,0 +1,75 @@
+package qrcode_test
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"url-shortener/pkg/qrcode"
+)
+
+var _ = Describe("QRCode", func() {
+	When("parsing an error correction level", func() {
+		It("should accept L, M, Q and H", func() {
+			Expect(qrcode.ParseLevel("l")).To(Equal(qrcode.Low))
+			Expect(qrcode.ParseLevel("M")).To(Equal(qrcode.Medium))
+			Expect(qrcode.ParseLevel("Q")).To(Equal(qrcode.Quartile))
+			Expect(qrcode.ParseLevel("H")).To(Equal(qrcode.High))
+		})
+
+		It("should reject other values", func() {
+			_, err := qrcode.ParseLevel("X")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	When("encoding a short url", func() {
+		var code *qrcode.Code
+		BeforeEach(func() {
+			var err error
+			code, err = qrcode.Encode("https://sho.rt/abc", qrcode.Medium)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("should use the smallest version which fits", func() {
+			Expect(code.Size()).To(Equal(25))
+		})
+
+		It("should draw the finder patterns in three corners", func() {
+			for _, corner := range [][2]int{{0, 0}, {code.Size() - 7, 0}, {0, code.Size() - 7}} {
+				x, y := corner[0], corner[1]
+				Expect(code.Dark(x, y)).To(BeTrue())
+				Expect(code.Dark(x+6, y+6)).To(BeTrue())
+				Expect(code.Dark(x+1, y+1)).To(BeFalse())
+				Expect(code.Dark(x+3, y+3)).To(BeTrue())
+			}
+		})
+
+		It("should draw the timing patterns and the dark module", func() {
+			for i := 8; i < code.Size()-8; i++ {
+				Expect(code.Dark(i, 6)).To(Equal(i%2 == 0))
+				Expect(code.Dark(6, i)).To(Equal(i%2 == 0))
+			}
+
+			Expect(code.Dark(8, code.Size()-8)).To(BeTrue())
+		})
+	})
+
+	When("encoding with a higher error correction level", func() {
+		It("should need a larger version", func() {
+			low, err := qrcode.Encode(strings.Repeat("a", 100), qrcode.Low)
+			Expect(err).ToNot(HaveOccurred())
+			high, err := qrcode.Encode(strings.Repeat("a", 100), qrcode.High)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(high.Size()).To(BeNumerically(">", low.Size()))
+		})
+	})
+
+	When("content does not fit in the largest version", func() {
+		It("should return an error", func() {
+			_, err := qrcode.Encode(strings.Repeat("a", 3000), qrcode.Low)
+			Expect(err).To(MatchError(qrcode.ErrTooLong))
+		})
+	})
+})
@@ -0,0 +1,100 @@
+package qrcode
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"strconv"
+	"strings"
+)
+
+// Options control how a code is rendered
+type Options struct {
+	// Size is the width and height of the image in pixels, rounded down to a whole number of pixels per module
+	Size int
+	// Margin is the width of the quiet zone in modules
+	Margin     int
+	Foreground color.RGBA
+	Background color.RGBA
+}
+
+// ParseColor parses a hex color in RRGGBB form with an optional leading #
+func ParseColor(hex string) (color.RGBA, error) {
+	hex = strings.TrimPrefix(hex, "#")
+	if len(hex) != 6 {
+		return color.RGBA{}, fmt.Errorf("invalid color [%s]", hex)
+	}
+
+	value, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return color.RGBA{}, fmt.Errorf("invalid color [%s]: %w", hex, err)
+	}
+
+	return color.RGBA{R: uint8(value >> 16), G: uint8(value >> 8), B: uint8(value), A: 0xFF}, nil
+}
+
+// PNG renders the code as a PNG image
+func (c *Code) PNG(opts Options) ([]byte, error) {
+	scale := c.scale(opts)
+	side := (c.size + 2*opts.Margin) * scale
+	img := image.NewPaletted(image.Rect(0, 0, side, side), color.Palette{opts.Background, opts.Foreground})
+	for y := 0; y < c.size; y++ {
+		for x := 0; x < c.size; x++ {
+			if !c.modules[y][x] {
+				continue
+			}
+
+			left, top := (x+opts.Margin)*scale, (y+opts.Margin)*scale
+			for dy := 0; dy < scale; dy++ {
+				for dx := 0; dx < scale; dx++ {
+					img.SetColorIndex(left+dx, top+dy, 1)
+				}
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("failed to encode png: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// SVG renders the code as an SVG image, modules are drawn as a single path in module units
+func (c *Code) SVG(opts Options) []byte {
+	side := c.size + 2*opts.Margin
+	pixels := side * c.scale(opts)
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`,
+		pixels, pixels, side, side)
+	fmt.Fprintf(&buf, `<rect width="%d" height="%d" fill="%s"/>`, side, side, hexColor(opts.Background))
+	fmt.Fprintf(&buf, `<path fill="%s" d="`, hexColor(opts.Foreground))
+	for y := 0; y < c.size; y++ {
+		for x := 0; x < c.size; x++ {
+			if c.modules[y][x] {
+				fmt.Fprintf(&buf, "M%d %dh1v1h-1z", x+opts.Margin, y+opts.Margin)
+			}
+		}
+	}
+
+	buf.WriteString(`"/></svg>`)
+	return buf.Bytes()
+}
+
+// scale returns the number of pixels per module, at least one
+func (c *Code) scale(opts Options) int {
+	scale := opts.Size / (c.size + 2*opts.Margin)
+	if scale < 1 {
+		return 1
+	}
+
+	return scale
+}
+
+func hexColor(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
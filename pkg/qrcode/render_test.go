@@ -0,0 +1,69 @@
+package qrcode_test
+
+import (
+	"bytes"
+	"image/color"
+	"image/png"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"url-shortener/pkg/qrcode"
+)
+
+var _ = Describe("Render", func() {
+	var (
+		code *qrcode.Code
+		opts qrcode.Options
+	)
+
+	BeforeEach(func() {
+		var err error
+		code, err = qrcode.Encode("https://sho.rt/abc", qrcode.Medium)
+		Expect(err).ToNot(HaveOccurred())
+		opts = qrcode.Options{
+			Size:       300,
+			Margin:     4,
+			Foreground: color.RGBA{R: 0x11, G: 0x22, B: 0x33, A: 0xFF},
+			Background: color.RGBA{R: 0xFF, G: 0xFF, B: 0xFF, A: 0xFF},
+		}
+	})
+
+	When("parsing a hex color", func() {
+		It("should return the color", func() {
+			Expect(qrcode.ParseColor("#112233")).To(Equal(color.RGBA{R: 0x11, G: 0x22, B: 0x33, A: 0xFF}))
+		})
+
+		It("should reject invalid colors", func() {
+			_, err := qrcode.ParseColor("12345")
+			Expect(err).To(HaveOccurred())
+			_, err = qrcode.ParseColor("zzzzzz")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	When("rendering a png", func() {
+		It("should scale modules to whole pixels and draw the margin in background color", func() {
+			data, err := code.PNG(opts)
+			Expect(err).ToNot(HaveOccurred())
+
+			img, err := png.Decode(bytes.NewReader(data))
+			Expect(err).ToNot(HaveOccurred())
+			// 25 modules with 4 modules margin on both sides, 9 pixels each
+			Expect(img.Bounds().Dx()).To(Equal(297))
+			Expect(color.RGBAModel.Convert(img.At(0, 0))).To(Equal(opts.Background))
+			Expect(color.RGBAModel.Convert(img.At(4*9, 4*9))).To(Equal(opts.Foreground))
+		})
+	})
+
+	When("rendering an svg", func() {
+		It("should use the requested colors and size", func() {
+			svg := string(code.SVG(opts))
+			Expect(svg).To(HavePrefix("<svg"))
+			Expect(svg).To(ContainSubstring(`width="297"`))
+			Expect(svg).To(ContainSubstring(`fill="#112233"`))
+			Expect(svg).To(ContainSubstring(`fill="#ffffff"`))
+			Expect(svg).To(ContainSubstring("M4 4h1v1h-1z"))
+		})
+	})
+})
@@ -0,0 +1,125 @@
+package redirect
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// ErrInvalidSuffix is returned for path suffixes which cannot be forwarded safely
+var ErrInvalidSuffix = errors.New("invalid path suffix")
+
+// PermanentMaxAge is the number of seconds clients may cache a permanent redirect
+const PermanentMaxAge = 86400
+
+// IsValid reports whether the status code is a supported redirect type
+func IsValid(code int) bool {
+	switch code {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsPermanent reports whether the status code is a permanent redirect
+func IsPermanent(code int) bool {
+	return code == http.StatusMovedPermanently || code == http.StatusPermanentRedirect
+}
+
+// Temporary returns the temporary redirect keeping the method semantics of the status code
+func Temporary(code int) int {
+	switch code {
+	case http.StatusMovedPermanently:
+		return http.StatusFound
+	case http.StatusPermanentRedirect:
+		return http.StatusTemporaryRedirect
+	default:
+		return code
+	}
+}
+
+// CacheControl returns the Cache-Control header value matching the redirect type
+// Permanent redirects may be cached, temporary ones must reach the server on every click
+func CacheControl(code int) string {
+	if IsPermanent(code) {
+		return fmt.Sprintf("public, max-age=%d", PermanentMaxAge)
+	}
+
+	return "private, no-cache, no-store, must-revalidate"
+}
+
+// MergeQuery adds the incoming and default query parameters to the destination
+// On key collisions the destination parameters win over the incoming ones, which win over the defaults.
+// The destination is returned verbatim if no parameter is added.
+func MergeQuery(destination string, incoming url.Values, defaults map[string]string) (string, error) {
+	if len(incoming) == 0 && len(defaults) == 0 {
+		return destination, nil
+	}
+
+	target, err := url.Parse(destination)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse destination: %w", err)
+	}
+
+	existing := target.Query()
+	extra := url.Values{}
+	for key, values := range incoming {
+		if _, ok := existing[key]; !ok {
+			extra[key] = values
+		}
+	}
+
+	for key, value := range defaults {
+		_, inDestination := existing[key]
+		_, inIncoming := extra[key]
+		if !inDestination && !inIncoming {
+			extra.Set(key, value)
+		}
+	}
+
+	if len(extra) == 0 {
+		return destination, nil
+	}
+
+	if target.RawQuery != "" {
+		target.RawQuery += "&"
+	}
+
+	target.RawQuery += extra.Encode()
+	return target.String(), nil
+}
+
+// JoinPath appends the path suffix to the path of the destination
+// The suffix is cleaned first, so dot segments cannot climb above the destination path
+// and the scheme, host and query of the destination are never changed by it.
+func JoinPath(destination, suffix string) (string, error) {
+	if strings.Contains(suffix, "\\") || strings.IndexFunc(suffix, isControl) >= 0 {
+		return "", ErrInvalidSuffix
+	}
+
+	cleaned := path.Clean("/" + suffix)
+	if cleaned == "/" {
+		return destination, nil
+	}
+
+	target, err := url.Parse(destination)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse destination: %w", err)
+	}
+
+	if strings.HasSuffix(suffix, "/") {
+		cleaned += "/"
+	}
+
+	target.Path = strings.TrimSuffix(target.Path, "/") + cleaned
+	target.RawPath = ""
+	return target.String(), nil
+}
+
+func isControl(r rune) bool {
+	return r < 0x20 || r == 0x7f
+}
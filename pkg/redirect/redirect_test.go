@@ -0,0 +1,84 @@
+package redirect_test
+
+import (
+	"net/http"
+	"net/url"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"url-shortener/pkg/redirect"
+)
+
+var _ = Describe("Redirect", func() {
+	When("validating a redirect type", func() {
+		It("should accept supported redirect codes", func() {
+			Expect(redirect.IsValid(http.StatusMovedPermanently)).To(BeTrue())
+			Expect(redirect.IsValid(http.StatusFound)).To(BeTrue())
+			Expect(redirect.IsValid(http.StatusTemporaryRedirect)).To(BeTrue())
+			Expect(redirect.IsValid(http.StatusPermanentRedirect)).To(BeTrue())
+		})
+
+		It("should reject unsupported codes", func() {
+			Expect(redirect.IsValid(http.StatusOK)).To(BeFalse())
+			Expect(redirect.IsValid(http.StatusSeeOther)).To(BeFalse())
+		})
+	})
+
+	When("getting cache control for a permanent redirect", func() {
+		It("should allow caching", func() {
+			Expect(redirect.CacheControl(http.StatusPermanentRedirect)).To(ContainSubstring("max-age=86400"))
+		})
+	})
+
+	When("getting cache control for a temporary redirect", func() {
+		It("should forbid caching", func() {
+			Expect(redirect.CacheControl(http.StatusTemporaryRedirect)).To(ContainSubstring("no-store"))
+		})
+	})
+	When("getting the temporary redirect of a status code", func() {
+		It("should downgrade permanent redirects", func() {
+			Expect(redirect.Temporary(http.StatusMovedPermanently)).To(Equal(http.StatusFound))
+			Expect(redirect.Temporary(http.StatusPermanentRedirect)).To(Equal(http.StatusTemporaryRedirect))
+			Expect(redirect.Temporary(http.StatusFound)).To(Equal(http.StatusFound))
+		})
+	})
+	When("merging query parameters", func() {
+		It("should give precedence to the destination, then the incoming and then the default parameters", func() {
+			merged, err := redirect.MergeQuery("https://example.com/page?id=1&utm_source=site#top",
+				url.Values{"id": {"2"}, "ref": {"twitter"}, "utm_medium": {"social"}},
+				map[string]string{"utm_source": "default", "utm_medium": "default", "utm_campaign": "spring"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(merged).To(Equal("https://example.com/page?id=1&utm_source=site&ref=twitter&utm_campaign=spring&utm_medium=social#top"))
+		})
+
+		It("should return the destination verbatim if nothing is added", func() {
+			Expect(redirect.MergeQuery("https://example.com/?b=1&a=2", url.Values{"a": {"3"}}, nil)).To(Equal("https://example.com/?b=1&a=2"))
+			Expect(redirect.MergeQuery("https://example.com/%7Euser", nil, nil)).To(Equal("https://example.com/%7Euser"))
+		})
+	})
+	When("joining a path suffix", func() {
+		It("should append it to the destination path and keep the query", func() {
+			Expect(redirect.JoinPath("https://example.com/docs?v=1", "/guides/setup")).To(Equal("https://example.com/docs/guides/setup?v=1"))
+			Expect(redirect.JoinPath("https://example.com/docs/", "/guides/")).To(Equal("https://example.com/docs/guides/"))
+			Expect(redirect.JoinPath("https://example.com", "/a b")).To(Equal("https://example.com/a%20b"))
+		})
+
+		It("should keep the destination for an empty suffix", func() {
+			Expect(redirect.JoinPath("https://example.com/docs?v=1", "/")).To(Equal("https://example.com/docs?v=1"))
+		})
+
+		It("should not climb above the destination path or change the host", func() {
+			Expect(redirect.JoinPath("https://example.com/docs", "/../../admin")).To(Equal("https://example.com/docs/admin"))
+			Expect(redirect.JoinPath("https://example.com/docs", "//evil.com/x")).To(Equal("https://example.com/docs/evil.com/x"))
+			Expect(redirect.JoinPath("https://example.com", "/@evil.com")).To(Equal("https://example.com/@evil.com"))
+		})
+
+		It("should reject backslashes and control characters", func() {
+			_, err := redirect.JoinPath("https://example.com", "/\\evil.com")
+			Expect(err).To(MatchError(redirect.ErrInvalidSuffix))
+			_, err = redirect.JoinPath("https://example.com", "/a\nb")
+			Expect(err).To(MatchError(redirect.ErrInvalidSuffix))
+		})
+	})
+})
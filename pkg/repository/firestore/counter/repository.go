@@ -15,6 +15,7 @@ import (
 type Repository struct {
 	firestoreClient *firestore.Client
 	ShardsNumber    int
+	collection      string
 }
 
 // NewRepository is a constructor function
@@ -22,6 +23,17 @@ func NewRepository(firestoreClient *firestore.Client, shardsNumber int) *Reposit
 	return &Repository{
 		firestoreClient: firestoreClient,
 		ShardsNumber:    shardsNumber,
+		collection:      "shards",
+	}
+}
+
+// NewTenantRepository is a constructor function of a counter scoped to the tenant,
+// so each tenant allocates ids from its own code space
+func NewTenantRepository(firestoreClient *firestore.Client, tenantID string, shardsNumber int) *Repository {
+	return &Repository{
+		firestoreClient: firestoreClient,
+		ShardsNumber:    shardsNumber,
+		collection:      fmt.Sprintf("tenants/%s/shards", tenantID),
 	}
 }
 
@@ -44,9 +56,15 @@ func (r *Repository) InitCounter(ctx context.Context) error {
 
 // IncrementCounterTx increments a random shard
 func (r *Repository) IncrementCounterTx(tx *firestore.Transaction) error {
+	return r.IncrementCounterByTx(tx, 1)
+}
+
+// IncrementCounterByTx increments a random shard by delta
+// It allows allocating a range of ids with a single write
+func (r *Repository) IncrementCounterByTx(tx *firestore.Transaction, delta int64) error {
 	docID := strconv.Itoa(rand.Intn(r.ShardsNumber))
 	shardRef := r.shardsCollection().Doc(docID)
-	if err := tx.Update(shardRef, []firestore.Update{{Path: "count", Value: firestore.Increment(1)}}); err != nil {
+	if err := tx.Update(shardRef, []firestore.Update{{Path: "count", Value: firestore.Increment(delta)}}); err != nil {
 		return fmt.Errorf("failed to update shard: %w", err)
 	}
 
@@ -78,6 +96,35 @@ func (r *Repository) GetCountTx(tx *firestore.Transaction) (int64, error) {
 	return total, nil
 }
 
+// GetCount get total count across all shards
+func (r *Repository) GetCount(ctx context.Context) (int64, error) {
+	var total int64
+	err := r.firestoreClient.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		var err error
+		total, err = r.GetCountTx(tx)
+		return err
+	})
+
+	return total, err
+}
+
+// AdvanceTo increments the counter up to count if it is lower
+// It guarantees that the next allocated id is greater than count
+func (r *Repository) AdvanceTo(ctx context.Context, count int64) error {
+	return r.firestoreClient.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		total, err := r.GetCountTx(tx)
+		if err != nil {
+			return err
+		}
+
+		if total >= count {
+			return nil
+		}
+
+		return r.IncrementCounterByTx(tx, count-total)
+	})
+}
+
 func (r *Repository) shardsCollection() *firestore.CollectionRef {
-	return r.firestoreClient.Collection("shards")
+	return r.firestoreClient.Collection(r.collection)
 }
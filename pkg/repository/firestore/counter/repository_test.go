@@ -76,6 +76,32 @@ var _ = Describe("Counter Repository", func() {
 		})
 	})
 
+	When("incrementing a shard by delta", func() {
+		const delta = 5
+		BeforeEach(func() {
+			Expect(firestoreFixture.InsertDocument(ctx, shardsCollection, shardID, counter.Shard{0})).To(Succeed())
+		})
+
+		AfterEach(func() {
+			Expect(firestoreFixture.DeleteDocument(ctx, shardsCollection, shardID)).To(Succeed())
+		})
+
+		It("should add delta to the total count", func() {
+			err = firestoreFixture.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+				return repository.IncrementCounterByTx(tx, delta)
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			err = firestoreFixture.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+				total, err := repository.GetCountTx(tx)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(total).To(Equal(int64(delta)))
+				return nil
+			})
+			Expect(err).ToNot(HaveOccurred())
+		})
+	})
+
 	When("it fails to convert document when getting total count", func() {
 		BeforeEach(func() {
 			invalidShard := map[string]interface{}{"count": "invalid"}
@@ -115,4 +141,45 @@ var _ = Describe("Counter Repository", func() {
 			Expect(err).ToNot(HaveOccurred())
 		})
 	})
+	When("advancing the counter", func() {
+		BeforeEach(func() {
+			Expect(firestoreFixture.InsertDocument(ctx, shardsCollection, shardID, counter.Shard{2})).To(Succeed())
+		})
+
+		AfterEach(func() {
+			Expect(firestoreFixture.DeleteDocument(ctx, shardsCollection, shardID)).To(Succeed())
+		})
+
+		It("should increment it up to the given count", func() {
+			Expect(repository.AdvanceTo(ctx, 10)).To(Succeed())
+			Expect(repository.GetCount(ctx)).To(Equal(int64(10)))
+		})
+
+		It("should not decrement it", func() {
+			Expect(repository.AdvanceTo(ctx, 1)).To(Succeed())
+			Expect(repository.GetCount(ctx)).To(Equal(int64(2)))
+		})
+	})
+	When("counting ids of a tenant", func() {
+		const tenantShards = "tenants/acme/shards"
+
+		var tenantRepository *counter.Repository
+
+		BeforeEach(func() {
+			tenantRepository = counter.NewTenantRepository(firestoreClient, "acme", shardNumber)
+			Expect(tenantRepository.InitCounter(ctx)).To(Succeed())
+			Expect(firestoreFixture.InsertDocument(ctx, shardsCollection, shardID, counter.Shard{2})).To(Succeed())
+		})
+
+		AfterEach(func() {
+			Expect(firestoreFixture.DeleteDocument(ctx, shardsCollection, shardID)).To(Succeed())
+			Expect(firestoreFixture.DeleteDocument(ctx, tenantShards, shardID)).To(Succeed())
+		})
+
+		It("should use its own shards", func() {
+			Expect(tenantRepository.AdvanceTo(ctx, 5)).To(Succeed())
+			Expect(tenantRepository.GetCount(ctx)).To(Equal(int64(5)))
+			Expect(repository.GetCount(ctx)).To(Equal(int64(2)))
+		})
+	})
 })
@@ -0,0 +1,21 @@
+package domains
+
+type NotFoundError struct{}
+
+func NewNotFoundError() NotFoundError {
+	return NotFoundError{}
+}
+
+func (e NotFoundError) Error() string {
+	return "failed to get domain, a record was not found"
+}
+
+type AlreadyExistsError struct{}
+
+func NewAlreadyExistsError() AlreadyExistsError {
+	return AlreadyExistsError{}
+}
+
+func (e AlreadyExistsError) Error() string {
+	return "domain is already registered"
+}
@@ -0,0 +1,12 @@
+package domains
+
+// Domain is a branded short domain with its own links and defaults
+type Domain struct {
+	Name string `firestore:"-" json:"name"`
+	// RedirectType overrides the default redirect type for links of the domain which do not set their own
+	RedirectType int `firestore:"redirect_type,omitempty" json:"redirect_type,omitempty"`
+	// NotFoundURL is where visitors of unknown short URLs are sent instead of answering 404
+	NotFoundURL string `firestore:"not_found_url,omitempty" json:"not_found_url,omitempty"`
+	// Tenant owns the domain, its links are stored in the tenant namespace. Empty for domains of the default namespace
+	Tenant string `firestore:"tenant,omitempty" json:"tenant,omitempty"`
+}
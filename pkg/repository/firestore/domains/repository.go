@@ -0,0 +1,81 @@
+package domains
+
+import (
+	"context"
+	"fmt"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type Repository struct {
+	firestoreClient *firestore.Client
+}
+
+// NewRepository is a constructor function
+func NewRepository(firestoreClient *firestore.Client) *Repository {
+	return &Repository{
+		firestoreClient: firestoreClient,
+	}
+}
+
+// AddDomain registers a domain, if it is already registered it returns already exists error
+func (r *Repository) AddDomain(ctx context.Context, domain Domain) error {
+	_, err := r.domainsCollection().Doc(domain.Name).Create(ctx, domain)
+	if err != nil {
+		if status.Code(err) == codes.AlreadyExists {
+			return NewAlreadyExistsError()
+		}
+
+		return fmt.Errorf("failed to create domain: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteDomain unregisters a domain, if it is not registered it returns not found error
+// Links of the domain are kept, they resolve again once the domain is registered again
+func (r *Repository) DeleteDomain(ctx context.Context, name string) error {
+	_, err := r.domainsCollection().Doc(name).Delete(ctx, firestore.Exists)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return NewNotFoundError()
+		}
+
+		return fmt.Errorf("failed to delete domain: %w", err)
+	}
+
+	return nil
+}
+
+// ListDomains returns all registered domains ordered by name
+func (r *Repository) ListDomains(ctx context.Context) ([]Domain, error) {
+	documents := r.domainsCollection().OrderBy(firestore.DocumentID, firestore.Asc).Documents(ctx)
+	defer documents.Stop()
+
+	var domains []Domain
+	for {
+		doc, err := documents.Next()
+		if err == iterator.Done {
+			return domains, nil
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to list domains: %w", err)
+		}
+
+		var domain Domain
+		if err := doc.DataTo(&domain); err != nil {
+			return nil, fmt.Errorf("failed to convert domain [%s]: %w", doc.Ref.ID, err)
+		}
+
+		domain.Name = doc.Ref.ID
+		domains = append(domains, domain)
+	}
+}
+
+func (r *Repository) domainsCollection() *firestore.CollectionRef {
+	return r.firestoreClient.Collection("domains")
+}
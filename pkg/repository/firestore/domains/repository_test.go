@@ -0,0 +1,60 @@
+package domains_test
+
+import (
+	"context"
+
+	"cloud.google.com/go/firestore"
+	. "github.com/onsi/ginkgo/v2"
+
+	"url-shortener/pkg/repository/firestore/domains"
+	"url-shortener/test/fixture"
+
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Domains Repository", func() {
+	const (
+		name              = "go.example.com"
+		domainsCollection = "domains"
+	)
+
+	var (
+		ctx              context.Context
+		firestoreClient  *firestore.Client
+		repository       *domains.Repository
+		firestoreFixture *fixture.FirestoreFixture
+		err              error
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		firestoreClient, err = firestore.NewClient(ctx, firestore.DetectProjectID)
+		Expect(err).NotTo(HaveOccurred())
+		repository = domains.NewRepository(firestoreClient)
+		firestoreFixture = fixture.NewFirestoreFixture(firestoreClient)
+	})
+
+	AfterEach(func() {
+		firestoreClient.Close()
+	})
+
+	When("adding a domain", func() {
+		AfterEach(func() {
+			Expect(firestoreFixture.DeleteDocument(ctx, domainsCollection, name)).To(Succeed())
+		})
+
+		It("should list it and reject adding it again", func() {
+			domain := domains.Domain{Name: name, RedirectType: 301, NotFoundURL: "https://example.com/404", Tenant: "acme"}
+			Expect(repository.AddDomain(ctx, domain)).To(Succeed())
+
+			Expect(repository.ListDomains(ctx)).To(ContainElement(domain))
+			Expect(repository.AddDomain(ctx, domain)).To(BeAssignableToTypeOf(domains.AlreadyExistsError{}))
+		})
+	})
+
+	When("deleting a domain that does not exist", func() {
+		It("should return not found error", func() {
+			Expect(repository.DeleteDomain(ctx, "unknown.example.com")).To(BeAssignableToTypeOf(domains.NotFoundError{}))
+		})
+	})
+})
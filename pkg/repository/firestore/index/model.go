@@ -0,0 +1,10 @@
+package index
+
+import "url-shortener/pkg/search"
+
+// Entry is the index document of a link, with the fields queried by Firestore
+type Entry struct {
+	search.Document
+	Terms   []string `firestore:"terms"`
+	Folders []string `firestore:"folders"`
+}
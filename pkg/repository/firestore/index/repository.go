@@ -0,0 +1,110 @@
+package index
+
+import (
+	"context"
+	"fmt"
+	"url-shortener/pkg/search"
+
+	"cloud.google.com/go/firestore"
+)
+
+// scanPage is the number of index documents read at once by a search
+const scanPage = 500
+
+type Repository struct {
+	firestoreClient *firestore.Client
+	collection      string
+}
+
+// NewRepository is a constructor function
+func NewRepository(firestoreClient *firestore.Client) *Repository {
+	return &Repository{
+		firestoreClient: firestoreClient,
+		collection:      "search",
+	}
+}
+
+// NewTenantRepository is a constructor function of an index scoped to the links of the tenant
+func NewTenantRepository(firestoreClient *firestore.Client, tenantID string) *Repository {
+	return &Repository{
+		firestoreClient: firestoreClient,
+		collection:      fmt.Sprintf("tenants/%s/search", tenantID),
+	}
+}
+
+// Put adds the document or replaces the one with the same id
+func (r *Repository) Put(ctx context.Context, doc search.Document) error {
+	entry := Entry{Document: doc, Terms: search.Terms(doc), Folders: search.Folders(doc.Folder)}
+	if _, err := r.indexCollection().Doc(doc.ID).Set(ctx, entry); err != nil {
+		return fmt.Errorf("failed to index [%s]: %w", doc.ID, err)
+	}
+
+	return nil
+}
+
+// Delete removes the document with the id, it succeeds if there is none
+func (r *Repository) Delete(ctx context.Context, id string) error {
+	if _, err := r.indexCollection().Doc(id).Delete(ctx); err != nil {
+		return fmt.Errorf("failed to remove [%s] from index: %w", id, err)
+	}
+
+	return nil
+}
+
+// Search returns the documents matching the query ordered by id
+// Firestore allows a single array condition per query, so it selects the candidates by the first word,
+// tag or folder of the query and the remaining conditions are checked on pages of candidates ordered by id,
+// until the limit of the query is reached or all candidates are read
+func (r *Repository) Search(ctx context.Context, query search.Query) ([]search.Document, error) {
+	candidates := r.indexCollection().OrderBy(firestore.DocumentID, firestore.Asc)
+	if words := search.Words(query.Text); len(words) > 0 {
+		candidates = candidates.Where("terms", "array-contains", search.Term(words[0]))
+	} else if len(query.Tags) > 0 {
+		candidates = candidates.Where("tags", "array-contains", query.Tags[0])
+	} else if query.Folder != "" {
+		candidates = candidates.Where("folders", "array-contains", query.Folder)
+	}
+
+	var (
+		docs   []search.Document
+		lastID string
+	)
+	for {
+		page := candidates
+		if lastID != "" {
+			page = page.StartAfter(lastID)
+		}
+
+		entries, err := page.Limit(scanPage).Documents(ctx).GetAll()
+		if err != nil {
+			return nil, fmt.Errorf("failed to search: %w", err)
+		}
+
+		for _, doc := range entries {
+			var entry Entry
+			if err := doc.DataTo(&entry); err != nil {
+				return nil, fmt.Errorf("failed to convert index entry [%s]: %w", doc.Ref.ID, err)
+			}
+
+			entry.ID = doc.Ref.ID
+			if !search.Match(entry.Document, query) {
+				continue
+			}
+
+			docs = append(docs, entry.Document)
+			if query.Limit > 0 && len(docs) == query.Limit {
+				return docs, nil
+			}
+		}
+
+		if len(entries) < scanPage {
+			return docs, nil
+		}
+
+		lastID = entries[len(entries)-1].Ref.ID
+	}
+}
+
+func (r *Repository) indexCollection() *firestore.CollectionRef {
+	return r.firestoreClient.Collection(r.collection)
+}
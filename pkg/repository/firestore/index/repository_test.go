@@ -0,0 +1,60 @@
+package index_test
+
+import (
+	"context"
+
+	"cloud.google.com/go/firestore"
+	. "github.com/onsi/ginkgo/v2"
+
+	"url-shortener/pkg/repository/firestore/index"
+	"url-shortener/pkg/search"
+	"url-shortener/test/fixture"
+
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Index Repository", func() {
+	const indexCollection = "search"
+
+	var (
+		ctx              context.Context
+		firestoreClient  *firestore.Client
+		repository       *index.Repository
+		firestoreFixture *fixture.FirestoreFixture
+		err              error
+		launch           = search.Document{ID: "a", LongURL: "https://example.com/spring", Title: "Spring Launch", Tags: []string{"marketing"}, Folder: "campaigns/spring"}
+		guides           = search.Document{ID: "b", LongURL: "https://docs.example.com", Tags: []string{"docs"}}
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		firestoreClient, err = firestore.NewClient(ctx, firestore.DetectProjectID)
+		Expect(err).NotTo(HaveOccurred())
+		repository = index.NewRepository(firestoreClient)
+		firestoreFixture = fixture.NewFirestoreFixture(firestoreClient)
+	})
+
+	AfterEach(func() {
+		firestoreClient.Close()
+	})
+
+	When("searching indexed documents", func() {
+		BeforeEach(func() {
+			Expect(repository.Put(ctx, launch)).To(Succeed())
+			Expect(repository.Put(ctx, guides)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			Expect(firestoreFixture.DeleteDocument(ctx, indexCollection, launch.ID)).To(Succeed())
+			Expect(firestoreFixture.DeleteDocument(ctx, indexCollection, guides.ID)).To(Succeed())
+		})
+
+		It("should find them by words, tags and folders", func() {
+			Expect(repository.Search(ctx, search.Query{Text: "spring laun"})).To(Equal([]search.Document{launch}))
+			Expect(repository.Search(ctx, search.Query{Text: "example"})).To(Equal([]search.Document{launch, guides}))
+			Expect(repository.Search(ctx, search.Query{Tags: []string{"docs"}})).To(Equal([]search.Document{guides}))
+			Expect(repository.Search(ctx, search.Query{Folder: "campaigns"})).To(Equal([]search.Document{launch}))
+			Expect(repository.Search(ctx, search.Query{Text: "example", Limit: 1})).To(Equal([]search.Document{launch}))
+		})
+	})
+})
@@ -0,0 +1,36 @@
+package tenants
+
+import "fmt"
+
+type NotFoundError struct{}
+
+func NewNotFoundError() NotFoundError {
+	return NotFoundError{}
+}
+
+func (e NotFoundError) Error() string {
+	return "failed to get tenant, a record was not found"
+}
+
+type AlreadyExistsError struct{}
+
+func NewAlreadyExistsError() AlreadyExistsError {
+	return AlreadyExistsError{}
+}
+
+func (e AlreadyExistsError) Error() string {
+	return "tenant already exists"
+}
+
+type QuotaExceededError struct {
+	Period string
+	Limit  int64
+}
+
+func NewQuotaExceededError(period string, limit int64) QuotaExceededError {
+	return QuotaExceededError{Period: period, Limit: limit}
+}
+
+func (e QuotaExceededError) Error() string {
+	return fmt.Sprintf("%s quota of %d links is exceeded", e.Period, e.Limit)
+}
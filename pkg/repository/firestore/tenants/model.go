@@ -0,0 +1,31 @@
+package tenants
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
+
+// Tenant is a team with its own namespace of links, code space and quotas
+type Tenant struct {
+	ID   string `firestore:"-" json:"id"`
+	Name string `firestore:"name" json:"name"`
+	// KeyHash is the SHA-256 hash of the API key of the tenant, the key itself is never stored
+	KeyHash   string `firestore:"key_hash" json:"-"`
+	Suspended bool   `firestore:"suspended" json:"suspended"`
+	// DailyQuota and MonthlyQuota limit the links created per UTC day and month, 0 means unlimited
+	DailyQuota   int64     `firestore:"daily_quota,omitempty" json:"daily_quota,omitempty"`
+	MonthlyQuota int64     `firestore:"monthly_quota,omitempty" json:"monthly_quota,omitempty"`
+	CreatedAt    time.Time `firestore:"created_at" json:"created_at"`
+}
+
+// Usage counts the links created by a tenant in a period on one of the shards of the period
+type Usage struct {
+	Count int64 `firestore:"count"`
+}
+
+// HashKey returns the hash of an API key stored as KeyHash
+func HashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
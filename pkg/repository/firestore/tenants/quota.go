@@ -0,0 +1,95 @@
+package tenants
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"time"
+
+	"cloud.google.com/go/firestore"
+)
+
+// usageShards is the number of documents the usage of a period is spread over, like the shards of the counter,
+// so the links created by a tenant do not all write the same document
+const usageShards = 10
+
+// Quota counts the links created by a tenant per UTC day and month and enforces its limits
+type Quota struct {
+	repository *Repository
+	tenant     Tenant
+}
+
+type period struct {
+	name  string
+	id    string
+	limit int64
+}
+
+// ConsumeTx counts n new links of the tenant on a random usage shard of each period in the transaction
+// If they exceed the daily or monthly quota, it returns quota exceeded error and nothing is counted.
+// It reads before it writes, so it has to be called before any other write of the transaction
+func (q *Quota) ConsumeTx(tx *firestore.Transaction, n int64) error {
+	now := time.Now().UTC()
+	periods := []period{
+		{name: "daily", id: now.Format("2006-01-02"), limit: q.tenant.DailyQuota},
+		{name: "monthly", id: now.Format("2006-01"), limit: q.tenant.MonthlyQuota},
+	}
+
+	for _, period := range periods {
+		if period.limit == 0 {
+			continue
+		}
+
+		count, err := q.countTx(tx, period.id)
+		if err != nil {
+			return err
+		}
+
+		if count+n > period.limit {
+			return NewQuotaExceededError(period.name, period.limit)
+		}
+	}
+
+	for _, period := range periods {
+		shard := q.shardsCollection(period.id).Doc(strconv.Itoa(rand.Intn(usageShards)))
+		update := map[string]interface{}{"count": firestore.Increment(n)}
+		if err := tx.Set(shard, update, firestore.MergeAll); err != nil {
+			return fmt.Errorf("failed to update %s usage: %w", period.name, err)
+		}
+	}
+
+	return nil
+}
+
+// countTx returns the usage of the period summed over its shards, shards which were never written count 0
+func (q *Quota) countTx(tx *firestore.Transaction, periodID string) (int64, error) {
+	refs := make([]*firestore.DocumentRef, usageShards)
+	for i := range refs {
+		refs[i] = q.shardsCollection(periodID).Doc(strconv.Itoa(i))
+	}
+
+	snapshots, err := tx.GetAll(refs)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get usage: %w", err)
+	}
+
+	var total int64
+	for _, snapshot := range snapshots {
+		if !snapshot.Exists() {
+			continue
+		}
+
+		var usage Usage
+		if err := snapshot.DataTo(&usage); err != nil {
+			return 0, fmt.Errorf("failed to convert usage: %w", err)
+		}
+
+		total += usage.Count
+	}
+
+	return total, nil
+}
+
+func (q *Quota) shardsCollection(periodID string) *firestore.CollectionRef {
+	return q.repository.usageCollection(q.tenant.ID).Doc(periodID).Collection("shards")
+}
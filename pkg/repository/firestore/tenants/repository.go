@@ -0,0 +1,92 @@
+package tenants
+
+import (
+	"context"
+	"fmt"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type Repository struct {
+	firestoreClient *firestore.Client
+}
+
+// NewRepository is a constructor function
+func NewRepository(firestoreClient *firestore.Client) *Repository {
+	return &Repository{
+		firestoreClient: firestoreClient,
+	}
+}
+
+// AddTenant creates a tenant, if it already exists it returns already exists error
+func (r *Repository) AddTenant(ctx context.Context, tenant Tenant) error {
+	_, err := r.tenantsCollection().Doc(tenant.ID).Create(ctx, tenant)
+	if err != nil {
+		if status.Code(err) == codes.AlreadyExists {
+			return NewAlreadyExistsError()
+		}
+
+		return fmt.Errorf("failed to create tenant: %w", err)
+	}
+
+	return nil
+}
+
+// SetSuspended suspends or resumes a tenant, if it does not exist it returns not found error
+func (r *Repository) SetSuspended(ctx context.Context, id string, suspended bool) error {
+	_, err := r.tenantsCollection().Doc(id).Update(ctx, []firestore.Update{{Path: "suspended", Value: suspended}})
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return NewNotFoundError()
+		}
+
+		return fmt.Errorf("failed to update tenant: %w", err)
+	}
+
+	return nil
+}
+
+// ListTenants returns all tenants ordered by id
+func (r *Repository) ListTenants(ctx context.Context) ([]Tenant, error) {
+	documents := r.tenantsCollection().OrderBy(firestore.DocumentID, firestore.Asc).Documents(ctx)
+	defer documents.Stop()
+
+	var tenants []Tenant
+	for {
+		doc, err := documents.Next()
+		if err == iterator.Done {
+			return tenants, nil
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to list tenants: %w", err)
+		}
+
+		var tenant Tenant
+		if err := doc.DataTo(&tenant); err != nil {
+			return nil, fmt.Errorf("failed to convert tenant [%s]: %w", doc.Ref.ID, err)
+		}
+
+		tenant.ID = doc.Ref.ID
+		tenants = append(tenants, tenant)
+	}
+}
+
+// Quota returns the quota of the tenant
+func (r *Repository) Quota(tenant Tenant) *Quota {
+	return &Quota{
+		repository: r,
+		tenant:     tenant,
+	}
+}
+
+func (r *Repository) tenantsCollection() *firestore.CollectionRef {
+	return r.firestoreClient.Collection("tenants")
+}
+
+func (r *Repository) usageCollection(id string) *firestore.CollectionRef {
+	return r.tenantsCollection().Doc(id).Collection("usage")
+}
@@ -0,0 +1,108 @@
+package tenants_test
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	. "github.com/onsi/ginkgo/v2"
+
+	"url-shortener/pkg/repository/firestore/tenants"
+	"url-shortener/test/fixture"
+
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Tenants Repository", func() {
+	const (
+		id                = "acme"
+		tenantsCollection = "tenants"
+		usageCollection   = "tenants/acme/usage"
+	)
+
+	var (
+		ctx              context.Context
+		firestoreClient  *firestore.Client
+		repository       *tenants.Repository
+		firestoreFixture *fixture.FirestoreFixture
+		err              error
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		firestoreClient, err = firestore.NewClient(ctx, firestore.DetectProjectID)
+		Expect(err).NotTo(HaveOccurred())
+		repository = tenants.NewRepository(firestoreClient)
+		firestoreFixture = fixture.NewFirestoreFixture(firestoreClient)
+	})
+
+	AfterEach(func() {
+		firestoreClient.Close()
+	})
+
+	When("adding a tenant", func() {
+		AfterEach(func() {
+			Expect(firestoreFixture.DeleteDocument(ctx, tenantsCollection, id)).To(Succeed())
+		})
+
+		It("should list it and reject adding it again", func() {
+			tenant := tenants.Tenant{ID: id, Name: "Acme", KeyHash: "hash", DailyQuota: 10, CreatedAt: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)}
+			Expect(repository.AddTenant(ctx, tenant)).To(Succeed())
+
+			Expect(repository.ListTenants(ctx)).To(ContainElement(tenant))
+			Expect(repository.AddTenant(ctx, tenant)).To(BeAssignableToTypeOf(tenants.AlreadyExistsError{}))
+		})
+	})
+
+	When("suspending a tenant", func() {
+		BeforeEach(func() {
+			Expect(firestoreFixture.InsertDocument(ctx, tenantsCollection, id, tenants.Tenant{Name: "Acme"})).To(Succeed())
+		})
+
+		AfterEach(func() {
+			Expect(firestoreFixture.DeleteDocument(ctx, tenantsCollection, id)).To(Succeed())
+		})
+
+		It("should mark it as suspended", func() {
+			Expect(repository.SetSuspended(ctx, id, true)).To(Succeed())
+
+			list, err := repository.ListTenants(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(list).To(HaveLen(1))
+			Expect(list[0].Suspended).To(BeTrue())
+		})
+	})
+
+	When("suspending a tenant that does not exist", func() {
+		It("should return not found error", func() {
+			Expect(repository.SetSuspended(ctx, "unknown", true)).To(BeAssignableToTypeOf(tenants.NotFoundError{}))
+		})
+	})
+
+	When("consuming the quota of a tenant", func() {
+		AfterEach(func() {
+			now := time.Now().UTC()
+			for _, period := range []string{now.Format("2006-01-02"), now.Format("2006-01")} {
+				for shard := 0; shard < 10; shard++ {
+					Expect(firestoreFixture.DeleteDocument(ctx, usageCollection+"/"+period+"/shards", strconv.Itoa(shard))).To(Succeed())
+				}
+			}
+		})
+
+		It("should count links up to the limit", func() {
+			quota := repository.Quota(tenants.Tenant{ID: id, DailyQuota: 3})
+			consume := func(n int64) error {
+				return firestoreFixture.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+					return quota.ConsumeTx(tx, n)
+				})
+			}
+
+			Expect(consume(1)).To(Succeed())
+			Expect(consume(1)).To(Succeed())
+			Expect(consume(2)).To(Equal(tenants.NewQuotaExceededError("daily", 3)))
+			Expect(consume(1)).To(Succeed())
+			Expect(consume(1)).To(Equal(tenants.NewQuotaExceededError("daily", 3)))
+		})
+	})
+})
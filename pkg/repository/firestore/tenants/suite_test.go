@@ -0,0 +1,13 @@
+package tenants_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestTenants(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Tenants Suite")
+}
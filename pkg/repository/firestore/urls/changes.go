@@ -0,0 +1,74 @@
+package urls
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Types of changes of URLs
+const (
+	ChangeCreated = "created"
+	ChangeUpdated = "updated"
+	ChangeDeleted = "deleted"
+	// ChangeClicked is a click counted on a URL with limited clicks
+	ChangeClicked = "clicked"
+)
+
+// Change is an entry of the change log of URLs, written in the transaction changing the URL
+type Change struct {
+	ID   string `firestore:"-"`
+	Type string `firestore:"type"`
+	// Key is the document id of the changed URL
+	Key string `firestore:"key"`
+	// URL is the URL after the change, or the deleted URL of deleted changes
+	URL *URL `firestore:"url,omitempty"`
+	// At is the commit time of the change
+	At time.Time `firestore:"at,serverTimestamp"`
+}
+
+// Cursor returns the position of the change in the log
+func (c Change) Cursor() Cursor {
+	return Cursor{At: c.At, ID: c.ID}
+}
+
+// Cursor is a position in the change log or a schedule, both are ordered by time and id
+// The zero cursor is the start of the list
+type Cursor struct {
+	At time.Time
+	ID string
+}
+
+// IsZero reports whether the cursor is the start of the list
+func (c Cursor) IsZero() bool {
+	return c.ID == ""
+}
+
+// String encodes the cursor for clients, the start of the list is an empty string
+func (c Cursor) String() string {
+	if c.IsZero() {
+		return ""
+	}
+
+	return fmt.Sprintf("%d_%s", c.At.UnixMicro(), c.ID)
+}
+
+// ParseCursor decodes a cursor encoded by String
+func ParseCursor(value string) (Cursor, error) {
+	if value == "" {
+		return Cursor{}, nil
+	}
+
+	micros, id, ok := strings.Cut(value, "_")
+	if !ok || id == "" {
+		return Cursor{}, fmt.Errorf("malformed cursor [%s]", value)
+	}
+
+	at, err := strconv.ParseInt(micros, 10, 64)
+	if err != nil {
+		return Cursor{}, fmt.Errorf("malformed cursor [%s]: %w", value, err)
+	}
+
+	return Cursor{At: time.UnixMicro(at).UTC(), ID: id}, nil
+}
@@ -9,3 +9,13 @@ func NewNotFoundError() NotFoundError {
 func (e NotFoundError) Error() string {
 	return "failed to get document, a record was not found"
 }
+
+type ExhaustedError struct{}
+
+func NewExhaustedError() ExhaustedError {
+	return ExhaustedError{}
+}
+
+func (e ExhaustedError) Error() string {
+	return "url has reached its maximum number of clicks"
+}
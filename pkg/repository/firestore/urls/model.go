@@ -1,5 +1,139 @@
 package urls
 
+import (
+	"strings"
+	"time"
+	"url-shortener/pkg/healthcheck"
+	"url-shortener/pkg/rules"
+	"url-shortener/pkg/search"
+	"url-shortener/pkg/unfurl"
+	"url-shortener/pkg/variants"
+)
+
+// BrokenField is set on URLs whose long URL has been failing for too long
+const BrokenField = "health.broken"
+
+// Fields of the URL schedule, used to list URLs activating or deactivating in a time range
+const (
+	NotBeforeField = "not_before"
+	NotAfterField  = "not_after"
+)
+
+// keySeparator separates the code and the domain in document ids of URLs on branded domains
+const keySeparator = "@"
+
 type URL struct {
-	LongURL string `firestore:"long_url"`
+	LongURL string `firestore:"long_url" json:"long_url"`
+	// Domain is the branded domain of the URL, empty for the default domain
+	Domain       string `firestore:"domain,omitempty" json:"domain,omitempty"`
+	RedirectType int    `firestore:"redirect_type,omitempty" json:"redirect_type,omitempty"`
+	// PasswordHash is the bcrypt hash of the password required before redirecting, empty if not protected
+	PasswordHash string `firestore:"password_hash,omitempty" json:"password_hash,omitempty"`
+	// MaxClicks is the number of redirects after which the URL stops working, zero means unlimited
+	MaxClicks int64 `firestore:"max_clicks,omitempty" json:"max_clicks,omitempty"`
+	// Clicks is the number of redirects so far, it is only counted for URLs with MaxClicks
+	Clicks int64 `firestore:"clicks,omitempty" json:"clicks,omitempty"`
+	// NotBefore is the time the URL starts redirecting, before it the FallbackURL is used if set
+	NotBefore *time.Time `firestore:"not_before,omitempty" json:"not_before,omitempty"`
+	// NotAfter is the time the URL stops redirecting
+	NotAfter    *time.Time `firestore:"not_after,omitempty" json:"not_after,omitempty"`
+	FallbackURL string     `firestore:"fallback_url,omitempty" json:"fallback_url,omitempty"`
+	// Rules route visitors to other destinations, the first matching rule wins and LongURL is the default
+	Rules []rules.Rule `firestore:"rules,omitempty" json:"rules,omitempty"`
+	// Variants split visitors between weighted destinations instead of LongURL
+	Variants []variants.Variant `firestore:"variants,omitempty" json:"variants,omitempty"`
+	// VariantClicks is the number of redirects per variant name, redirects are counted on shards of the URL
+	// which GetStats and ForEach add to it
+	VariantClicks map[string]int64 `firestore:"variant_clicks,omitempty" json:"variant_clicks,omitempty"`
+	// QueryPassthrough adds the query parameters of the visit to the destination
+	QueryPassthrough bool `firestore:"query_passthrough,omitempty" json:"query_passthrough,omitempty"`
+	// UTM holds default utm_* query parameters added to the destination
+	UTM map[string]string `firestore:"utm,omitempty" json:"utm,omitempty"`
+	// Prefix URLs forward the path after the short URL, e.g. /docs/guides to <LongURL>/guides
+	Prefix bool `firestore:"prefix,omitempty" json:"prefix,omitempty"`
+	// Exclusive URLs have their own settings and are never reused for the same long URL
+	Exclusive bool `firestore:"exclusive,omitempty" json:"exclusive,omitempty"`
+	Metadata
+	// Preview is fetched from the destination page after the URL is created and shown to link preview bots
+	Preview *unfurl.Preview `firestore:"preview,omitempty" json:"preview,omitempty"`
+	// Health is the check history of LongURL, recorded by the health monitor
+	Health *healthcheck.Health `firestore:"health,omitempty" json:"health,omitempty"`
+}
+
+// Metadata describes an URL to its owners, it can be changed without changing the short URL
+type Metadata struct {
+	Title       string `firestore:"title,omitempty" json:"title,omitempty"`
+	Description string `firestore:"description,omitempty" json:"description,omitempty"`
+	// Tags are lowercase labels used to filter URLs
+	Tags []string `firestore:"tags,omitempty" json:"tags,omitempty"`
+	// Folder is a slash separated path grouping URLs, e.g. campaigns/spring
+	Folder string `firestore:"folder,omitempty" json:"folder,omitempty"`
+}
+
+// Document returns the searchable description of the URL with the document id
+func (u URL) Document(id string) search.Document {
+	return search.Document{
+		ID:          id,
+		LongURL:     u.LongURL,
+		Title:       u.Title,
+		Description: u.Description,
+		Tags:        u.Tags,
+		Folder:      u.Folder,
+	}
+}
+
+// Exhausted reports whether the URL has reached its maximum number of clicks
+func (u URL) Exhausted() bool {
+	return u.MaxClicks > 0 && u.Clicks >= u.MaxClicks
+}
+
+// NotStarted reports whether the URL is not active yet at the given time
+func (u URL) NotStarted(now time.Time) bool {
+	return u.NotBefore != nil && now.Before(*u.NotBefore)
+}
+
+// Expired reports whether the URL is no longer active at the given time
+func (u URL) Expired(now time.Time) bool {
+	return u.NotAfter != nil && !now.Before(*u.NotAfter)
+}
+
+// Key returns the document id of the code on the domain, codes of the default domain are their own id
+func Key(domain, code string) string {
+	if domain == "" {
+		return code
+	}
+
+	return code + keySeparator + domain
+}
+
+// ValidCode reports whether the code can be looked up with Key, codes never contain the separator of the domain
+// so a code of one domain cannot name the document of a code on another domain
+func ValidCode(code string) bool {
+	return !strings.Contains(code, keySeparator)
+}
+
+// SplitKey returns the domain and the code of a document id
+func SplitKey(key string) (domain, code string) {
+	code, domain, _ = strings.Cut(key, keySeparator)
+	return domain, code
+}
+
+// Record is an URL together with its short URL id
+type Record struct {
+	ID  string
+	URL URL
+}
+
+// ScheduleCursor returns the position of the record in the schedule of the field, NotBeforeField or NotAfterField
+func (r Record) ScheduleCursor(field string) Cursor {
+	at := r.URL.NotBefore
+	if field == NotAfterField {
+		at = r.URL.NotAfter
+	}
+
+	if at == nil {
+		return Cursor{ID: r.ID}
+	}
+
+	return Cursor{At: *at, ID: r.ID}
 }
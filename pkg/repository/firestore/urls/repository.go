@@ -3,6 +3,10 @@ package urls
 import (
 	"context"
 	"fmt"
+	"time"
+	"url-shortener/pkg/healthcheck"
+	"url-shortener/pkg/unfurl"
+	"url-shortener/pkg/variants"
 
 	"cloud.google.com/go/firestore"
 	"google.golang.org/api/iterator"
@@ -10,18 +14,37 @@ import (
 	"google.golang.org/grpc/status"
 )
 
+// maxTransactionWrites is the most documents a Firestore transaction may write
+const maxTransactionWrites = 500
+
+// putBatchSize is the most URLs put in one transaction, each URL writes its document and may delete all its variant click shards
+const putBatchSize = maxTransactionWrites / (variantClickShards + 1)
+
 type Repository struct {
-	firestoreClient *firestore.Client
+	firestoreClient   *firestore.Client
+	collection        string
+	changesCollection string
 }
 
 // NewRepository is a constructor function
 func NewRepository(firestoreClient *firestore.Client) *Repository {
 	return &Repository{
-		firestoreClient: firestoreClient,
+		firestoreClient:   firestoreClient,
+		collection:        "urls",
+		changesCollection: "changes",
+	}
+}
+
+// NewTenantRepository is a constructor function of a repository scoped to the URLs of the tenant
+func NewTenantRepository(firestoreClient *firestore.Client, tenantID string) *Repository {
+	return &Repository{
+		firestoreClient:   firestoreClient,
+		collection:        fmt.Sprintf("tenants/%s/urls", tenantID),
+		changesCollection: fmt.Sprintf("tenants/%s/changes", tenantID),
 	}
 }
 
-// AddURLTx creates URL document in firestore, if
+// AddURLTx creates URL document in firestore together with its created change
 func (r *Repository) AddURLTx(tx *firestore.Transaction, id string, url URL) error {
 	doc := r.urlsCollection().Doc(id)
 	err := tx.Create(doc, url)
@@ -29,7 +52,7 @@ func (r *Repository) AddURLTx(tx *firestore.Transaction, id string, url URL) err
 		return fmt.Errorf("failed to create shortened url: %w", err)
 	}
 
-	return nil
+	return r.addChangeTx(tx, ChangeCreated, id, &url)
 }
 
 // GetByShortURL returns a URL document by short url
@@ -52,24 +75,427 @@ func (r *Repository) GetByShortURL(ctx context.Context, shortURL string) (URL, e
 	return url, nil
 }
 
-// GetDocIDByLongURL returns a URL document id by long url
-// If it does not exist, it returns not found error
-func (r *Repository) GetDocIDByLongURL(ctx context.Context, longURL string) (string, error) {
+// ConsumeClick counts a click of a URL with limited clicks and returns the updated URL
+// The check and the increment run in a transaction, so concurrent clicks never exceed the limit.
+// If the URL has no clicks left, it returns exhausted error
+func (r *Repository) ConsumeClick(ctx context.Context, shortURL string) (URL, error) {
+	var url URL
+	doc := r.urlsCollection().Doc(shortURL)
+	err := r.firestoreClient.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		snapshot, err := tx.Get(doc)
+		if err != nil {
+			if status.Code(err) == codes.NotFound {
+				return NewNotFoundError()
+			}
+
+			return fmt.Errorf("failed to retrieve by short url: %w", err)
+		}
+
+		url = URL{}
+		if err := snapshot.DataTo(&url); err != nil {
+			return fmt.Errorf("failed to convert url: %w", err)
+		}
+
+		if url.Exhausted() {
+			return NewExhaustedError()
+		}
+
+		url.Clicks++
+		if err := tx.Update(doc, []firestore.Update{{Path: "clicks", Value: firestore.Increment(1)}}); err != nil {
+			return fmt.Errorf("failed to count click: %w", err)
+		}
+
+		return r.addChangeTx(tx, ChangeClicked, shortURL, &url)
+	})
+	if err != nil {
+		return URL{}, err
+	}
+
+	return url, nil
+}
+
+// UpdateVariants replaces the variants of a URL, click counts of the variants are kept
+// If the URL does not exist, it returns not found error
+func (r *Repository) UpdateVariants(ctx context.Context, shortURL string, urlVariants []variants.Variant) error {
+	return r.update(ctx, shortURL, func(url *URL) []firestore.Update {
+		url.Variants = urlVariants
+		return []firestore.Update{{Path: "variants", Value: urlVariants}}
+	})
+}
+
+// UpdateMetadata replaces the title, description, tags and folder of a URL
+// If the URL does not exist, it returns not found error
+func (r *Repository) UpdateMetadata(ctx context.Context, shortURL string, metadata Metadata) error {
+	return r.update(ctx, shortURL, func(url *URL) []firestore.Update {
+		url.Metadata = metadata
+		return []firestore.Update{
+			{Path: "title", Value: metadata.Title},
+			{Path: "description", Value: metadata.Description},
+			{Path: "tags", Value: metadata.Tags},
+			{Path: "folder", Value: metadata.Folder},
+		}
+	})
+}
+
+// UpdatePreview stores the preview fetched from the destination page of a URL
+// If the URL does not exist, it returns not found error
+func (r *Repository) UpdatePreview(ctx context.Context, shortURL string, preview unfurl.Preview) error {
+	_, err := r.urlsCollection().Doc(shortURL).Update(ctx, []firestore.Update{
+		{Path: "preview", Value: preview},
+	})
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return NewNotFoundError()
+		}
+
+		return fmt.Errorf("failed to update preview: %w", err)
+	}
+
+	return nil
+}
+
+// SetClicks overwrites the click count of a URL, its other fields and variant clicks are kept
+// If the URL does not exist, it returns not found error
+func (r *Repository) SetClicks(ctx context.Context, shortURL string, clicks int64) error {
+	_, err := r.urlsCollection().Doc(shortURL).Update(ctx, []firestore.Update{
+		{Path: "clicks", Value: clicks},
+	})
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return NewNotFoundError()
+		}
+
+		return fmt.Errorf("failed to set clicks: %w", err)
+	}
+
+	return nil
+}
+
+// Delete deletes a URL together with writing its deleted change, its short URL is not reused
+// If the URL does not exist, it returns not found error
+func (r *Repository) Delete(ctx context.Context, shortURL string) error {
+	doc := r.urlsCollection().Doc(shortURL)
+	return r.firestoreClient.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		url, err := getTx(tx, doc)
+		if err != nil {
+			return err
+		}
+
+		shards, err := r.variantClickShardsTx(tx, shortURL)
+		if err != nil {
+			return err
+		}
+
+		for _, ref := range append(shards, doc) {
+			if err := tx.Delete(ref); err != nil {
+				return fmt.Errorf("failed to delete url: %w", err)
+			}
+		}
+
+		return r.addChangeTx(tx, ChangeDeleted, shortURL, &url)
+	})
+}
+
+// ListChanges returns up to limit changes after the cursor in the order they were committed
+func (r *Repository) ListChanges(ctx context.Context, after Cursor, limit int) ([]Change, error) {
+	query := r.firestoreClient.Collection(r.changesCollection).
+		OrderBy("at", firestore.Asc).
+		OrderBy(firestore.DocumentID, firestore.Asc)
+	if !after.IsZero() {
+		query = query.StartAfter(after.At, after.ID)
+	}
+
+	documents := query.Limit(limit).Documents(ctx)
+	defer documents.Stop()
+
+	var changes []Change
+	for {
+		doc, err := documents.Next()
+		if err == iterator.Done {
+			return changes, nil
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to list changes: %w", err)
+		}
+
+		var change Change
+		if err := doc.DataTo(&change); err != nil {
+			return nil, fmt.Errorf("failed to convert change [%s]: %w", doc.Ref.ID, err)
+		}
+
+		change.ID = doc.Ref.ID
+		changes = append(changes, change)
+	}
+}
+
+// GetDocIDByLongURL returns a URL document id by long url on the domain
+// Exclusive URLs are skipped, if no other exists, it returns not found error
+func (r *Repository) GetDocIDByLongURL(ctx context.Context, domain, longURL string) (string, error) {
 	collection := r.urlsCollection().
 		Where("long_url", "==", longURL).
 		Documents(ctx)
 	defer collection.Stop()
 
-	doc, err := collection.Next()
-	if err != nil {
-		if err == iterator.Done || status.Code(err) == codes.NotFound {
-			return "", NewNotFoundError()
+	for {
+		doc, err := collection.Next()
+		if err != nil {
+			if err == iterator.Done || status.Code(err) == codes.NotFound {
+				return "", NewNotFoundError()
+			}
+
+			return "", fmt.Errorf("failed to retrieve by long url: %w", err)
+		}
+
+		if exclusive, err := doc.DataAt("exclusive"); err == nil && exclusive == true {
+			continue
+		}
+
+		if docDomain, err := doc.DataAt("domain"); (err == nil && docDomain != domain) || (err != nil && domain != "") {
+			continue
+		}
+
+		return doc.Ref.ID, nil
+	}
+}
+
+// ForEach calls fn for every URL document until fn returns an error
+// Variant clicks of URLs with variants are summed over their shards
+func (r *Repository) ForEach(ctx context.Context, fn func(record Record) error) error {
+	return r.ForEachAfter(ctx, "", fn)
+}
+
+// ForEachAfter calls fn for every URL document with id greater than afterID in id order, as ForEach does
+// An empty afterID starts from the first document
+func (r *Repository) ForEachAfter(ctx context.Context, afterID string, fn func(record Record) error) error {
+	query := r.urlsCollection().OrderBy(firestore.DocumentID, firestore.Asc)
+	if afterID != "" {
+		query = query.StartAfter(afterID)
+	}
+
+	documents := query.Documents(ctx)
+	defer documents.Stop()
+
+	for {
+		doc, err := documents.Next()
+		if err == iterator.Done {
+			return nil
+		}
+
+		if err != nil {
+			return fmt.Errorf("failed to return next url: %w", err)
+		}
+
+		var url URL
+		if err := doc.DataTo(&url); err != nil {
+			return fmt.Errorf("failed to convert url with id [%s]: %w", doc.Ref.ID, err)
+		}
+
+		if len(url.Variants) > 0 {
+			if err := r.addVariantClicks(ctx, doc.Ref.ID, &url); err != nil {
+				return err
+			}
+		}
+
+		if err := fn(Record{ID: doc.Ref.ID, URL: url}); err != nil {
+			return err
+		}
+	}
+}
+
+// ForEachLink calls fn for the long URL of every URL which can still be visited
+func (r *Repository) ForEachLink(ctx context.Context, fn func(link healthcheck.Link) error) error {
+	now := time.Now()
+	return r.ForEach(ctx, func(record Record) error {
+		if record.URL.Exhausted() || record.URL.Expired(now) {
+			return nil
+		}
+
+		link := healthcheck.Link{Key: record.ID, URL: record.URL.LongURL}
+		if record.URL.Health != nil {
+			link.CheckedAt = record.URL.Health.CheckedAt
+		}
+
+		return fn(link)
+	})
+}
+
+// RecordCheck adds the check to the health of a URL, the health is read and written in a transaction
+// If the URL does not exist, it returns not found error
+func (r *Repository) RecordCheck(ctx context.Context, shortURL string, check healthcheck.Check, policy healthcheck.Policy) error {
+	doc := r.urlsCollection().Doc(shortURL)
+	return r.firestoreClient.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		snapshot, err := tx.Get(doc)
+		if err != nil {
+			if status.Code(err) == codes.NotFound {
+				return NewNotFoundError()
+			}
+
+			return fmt.Errorf("failed to retrieve by short url: %w", err)
+		}
+
+		var url URL
+		if err := snapshot.DataTo(&url); err != nil {
+			return fmt.Errorf("failed to convert url: %w", err)
+		}
+
+		var health healthcheck.Health
+		if url.Health != nil {
+			health = *url.Health
+		}
+
+		return tx.Update(doc, []firestore.Update{{Path: "health", Value: health.Record(check, policy)}})
+	})
+}
+
+// ListBroken returns URLs whose long URL has been failing for too long
+func (r *Repository) ListBroken(ctx context.Context) ([]Record, error) {
+	documents := r.urlsCollection().Where(BrokenField, "==", true).Documents(ctx)
+	defer documents.Stop()
+
+	var records []Record
+	for {
+		doc, err := documents.Next()
+		if err == iterator.Done {
+			return records, nil
 		}
 
-		return "", fmt.Errorf("failed to retrieve by long url: %w", err)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list broken urls: %w", err)
+		}
+
+		var url URL
+		if err := doc.DataTo(&url); err != nil {
+			return nil, fmt.Errorf("failed to convert url with id [%s]: %w", doc.Ref.ID, err)
+		}
+
+		records = append(records, Record{ID: doc.Ref.ID, URL: url})
 	}
+}
 
-	return doc.Ref.ID, nil
+// ListScheduled returns up to limit URLs with the schedule field, NotBeforeField or NotAfterField, within [from, to)
+// ordered by it and their id, starting after the cursor
+func (r *Repository) ListScheduled(ctx context.Context, field string, from, to time.Time, after Cursor, limit int) ([]Record, error) {
+	query := r.urlsCollection().
+		Where(field, ">=", from).
+		Where(field, "<", to).
+		OrderBy(field, firestore.Asc).
+		OrderBy(firestore.DocumentID, firestore.Asc)
+	if !after.IsZero() {
+		query = query.StartAfter(after.At, after.ID)
+	}
+
+	documents := query.Limit(limit).Documents(ctx)
+	defer documents.Stop()
+
+	var records []Record
+	for {
+		doc, err := documents.Next()
+		if err == iterator.Done {
+			return records, nil
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to list urls by [%s]: %w", field, err)
+		}
+
+		var url URL
+		if err := doc.DataTo(&url); err != nil {
+			return nil, fmt.Errorf("failed to convert url with id [%s]: %w", doc.Ref.ID, err)
+		}
+
+		records = append(records, Record{ID: doc.Ref.ID, URL: url})
+	}
+}
+
+// PutURLs creates or overwrites the given URL documents in transactions of at most putBatchSize URLs
+// The variant clicks of the records replace those counted on shards before
+func (r *Repository) PutURLs(ctx context.Context, records []Record) error {
+	for start := 0; start < len(records); start += putBatchSize {
+		end := start + putBatchSize
+		if end > len(records) {
+			end = len(records)
+		}
+
+		if err := r.putURLs(ctx, records[start:end]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// putURLs overwrites the URL documents and deletes their variant click shards in a single transaction
+func (r *Repository) putURLs(ctx context.Context, records []Record) error {
+	return r.firestoreClient.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		var shards []*firestore.DocumentRef
+		for _, record := range records {
+			refs, err := r.variantClickShardsTx(tx, record.ID)
+			if err != nil {
+				return err
+			}
+
+			shards = append(shards, refs...)
+		}
+
+		for _, ref := range shards {
+			if err := tx.Delete(ref); err != nil {
+				return fmt.Errorf("failed to reset variant clicks: %w", err)
+			}
+		}
+
+		for _, record := range records {
+			if err := tx.Set(r.urlsCollection().Doc(record.ID), record.URL); err != nil {
+				return fmt.Errorf("failed to put url with id [%s]: %w", record.ID, err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// CreateURLs creates the given URL documents which do not exist yet in transactions of at most maxTransactionWrites URLs
+// Existing documents are kept, so URLs written meanwhile are not overwritten by older copies
+func (r *Repository) CreateURLs(ctx context.Context, records []Record) error {
+	for start := 0; start < len(records); start += maxTransactionWrites {
+		end := start + maxTransactionWrites
+		if end > len(records) {
+			end = len(records)
+		}
+
+		if err := r.createURLs(ctx, records[start:end]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// createURLs creates the URL documents which are missing in a single transaction
+func (r *Repository) createURLs(ctx context.Context, records []Record) error {
+	return r.firestoreClient.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		refs := make([]*firestore.DocumentRef, len(records))
+		for i, record := range records {
+			refs[i] = r.urlsCollection().Doc(record.ID)
+		}
+
+		snapshots, err := tx.GetAll(refs)
+		if err != nil {
+			return fmt.Errorf("failed to get urls: %w", err)
+		}
+
+		for i, snapshot := range snapshots {
+			if snapshot.Exists() {
+				continue
+			}
+
+			if err := tx.Create(refs[i], records[i].URL); err != nil {
+				return fmt.Errorf("failed to create url with id [%s]: %w", records[i].ID, err)
+			}
+		}
+
+		return nil
+	})
 }
 
 // RunTransaction the function in a transaction
@@ -77,6 +503,52 @@ func (r *Repository) RunTransaction(ctx context.Context, txFunc func(context.Con
 	return r.firestoreClient.RunTransaction(ctx, txFunc)
 }
 
+// update applies the updates of change to a URL and writes its updated change in a transaction
+func (r *Repository) update(ctx context.Context, shortURL string, change func(url *URL) []firestore.Update) error {
+	doc := r.urlsCollection().Doc(shortURL)
+	return r.firestoreClient.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		url, err := getTx(tx, doc)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Update(doc, change(&url)); err != nil {
+			return fmt.Errorf("failed to update url: %w", err)
+		}
+
+		return r.addChangeTx(tx, ChangeUpdated, shortURL, &url)
+	})
+}
+
+// addChangeTx appends a change of the URL to the change log, it is committed with the change itself
+func (r *Repository) addChangeTx(tx *firestore.Transaction, changeType, id string, url *URL) error {
+	change := Change{Type: changeType, Key: id, URL: url}
+	if err := tx.Create(r.firestoreClient.Collection(r.changesCollection).NewDoc(), change); err != nil {
+		return fmt.Errorf("failed to log change: %w", err)
+	}
+
+	return nil
+}
+
+// getTx reads a URL in the transaction, if it does not exist it returns not found error
+func getTx(tx *firestore.Transaction, doc *firestore.DocumentRef) (URL, error) {
+	snapshot, err := tx.Get(doc)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return URL{}, NewNotFoundError()
+		}
+
+		return URL{}, fmt.Errorf("failed to retrieve by short url: %w", err)
+	}
+
+	var url URL
+	if err := snapshot.DataTo(&url); err != nil {
+		return URL{}, fmt.Errorf("failed to convert url: %w", err)
+	}
+
+	return url, nil
+}
+
 func (r *Repository) urlsCollection() *firestore.CollectionRef {
-	return r.firestoreClient.Collection("urls")
+	return r.firestoreClient.Collection(r.collection)
 }
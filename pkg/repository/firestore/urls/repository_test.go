@@ -2,12 +2,16 @@ package urls_test
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"cloud.google.com/go/firestore"
 	. "github.com/onsi/ginkgo/v2"
 
+	"url-shortener/pkg/healthcheck"
 	"url-shortener/pkg/repository/firestore/urls"
+	"url-shortener/pkg/unfurl"
+	"url-shortener/pkg/variants"
 	"url-shortener/test/fixture"
 
 	. "github.com/onsi/gomega"
@@ -128,14 +132,14 @@ var _ = Describe("URLs Repository", func() {
 		})
 
 		It("should return an error", func() {
-			_, err := repository.GetDocIDByLongURL(ctx, longURL)
+			_, err := repository.GetDocIDByLongURL(ctx, "", longURL)
 			Expect(err).To(HaveOccurred())
 		})
 	})
 
 	When("getting document id by long url that does not exists", func() {
 		It("should return an error", func() {
-			_, err := repository.GetDocIDByLongURL(ctx, "unknown-id")
+			_, err := repository.GetDocIDByLongURL(ctx, "", "unknown-id")
 			Expect(err).To(HaveOccurred())
 			Expect(err).To(BeAssignableToTypeOf(urls.NotFoundError{}))
 		})
@@ -151,9 +155,485 @@ var _ = Describe("URLs Repository", func() {
 		})
 
 		It("should return an document id", func() {
-			docID, err := repository.GetDocIDByLongURL(ctx, longURL)
+			docID, err := repository.GetDocIDByLongURL(ctx, "", longURL)
 			Expect(err).ToNot(HaveOccurred())
 			Expect(docID).To(Equal(id))
 		})
 	})
+	When("putting url documents", func() {
+		var records = []urls.Record{{ID: id, URL: urls.URL{LongURL: longURL}}}
+
+		AfterEach(func() {
+			Expect(firestoreFixture.DeleteDocument(ctx, urlsCollection, id)).To(Succeed())
+		})
+
+		It("should be possible to iterate over them", func() {
+			Expect(repository.PutURLs(ctx, records)).To(Succeed())
+
+			var iterated []urls.Record
+			err = repository.ForEach(ctx, func(record urls.Record) error {
+				iterated = append(iterated, record)
+				return nil
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(iterated).To(Equal(records))
+		})
+	})
+
+	When("putting more url documents than fit in a transaction", func() {
+		var records []urls.Record
+
+		BeforeEach(func() {
+			records = nil
+			for i := 0; i < 100; i++ {
+				records = append(records, urls.Record{ID: fmt.Sprintf("put-%03d", i), URL: urls.URL{LongURL: longURL}})
+			}
+		})
+
+		AfterEach(func() {
+			for _, record := range records {
+				Expect(firestoreFixture.DeleteDocument(ctx, urlsCollection, record.ID)).To(Succeed())
+			}
+		})
+
+		It("should put all of them", func() {
+			Expect(repository.PutURLs(ctx, records)).To(Succeed())
+
+			for _, record := range records {
+				Expect(repository.GetByShortURL(ctx, record.ID)).To(Equal(record.URL))
+			}
+		})
+	})
+
+	When("creating url documents", func() {
+		const otherID = "test-id-2"
+
+		AfterEach(func() {
+			Expect(firestoreFixture.DeleteDocument(ctx, urlsCollection, id)).To(Succeed())
+			Expect(firestoreFixture.DeleteDocument(ctx, urlsCollection, otherID)).To(Succeed())
+		})
+
+		It("should create the missing ones and keep the existing ones", func() {
+			existing := urls.Record{ID: id, URL: urls.URL{LongURL: longURL}}
+			Expect(repository.PutURLs(ctx, []urls.Record{existing})).To(Succeed())
+
+			records := []urls.Record{
+				{ID: id, URL: urls.URL{LongURL: "https://stale.example.com"}},
+				{ID: otherID, URL: urls.URL{LongURL: longURL}},
+			}
+			Expect(repository.CreateURLs(ctx, records)).To(Succeed())
+
+			Expect(repository.GetByShortURL(ctx, id)).To(Equal(existing.URL))
+			Expect(repository.GetByShortURL(ctx, otherID)).To(Equal(records[1].URL))
+		})
+	})
+
+	When("iterating over url documents fails", func() {
+		BeforeEach(func() {
+			firestoreClient.Close()
+		})
+
+		It("should return an error", func() {
+			err = repository.ForEach(ctx, func(record urls.Record) error {
+				return nil
+			})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+	When("iterating over url documents after an id", func() {
+		const otherID = "test-id-2"
+
+		BeforeEach(func() {
+			Expect(firestoreFixture.InsertDocument(ctx, urlsCollection, id, urls.URL{LongURL: longURL})).To(Succeed())
+			Expect(firestoreFixture.InsertDocument(ctx, urlsCollection, otherID, urls.URL{LongURL: longURL})).To(Succeed())
+		})
+
+		AfterEach(func() {
+			Expect(firestoreFixture.DeleteDocument(ctx, urlsCollection, id)).To(Succeed())
+			Expect(firestoreFixture.DeleteDocument(ctx, urlsCollection, otherID)).To(Succeed())
+		})
+
+		It("should skip documents up to and including the id", func() {
+			var ids []string
+			err = repository.ForEachAfter(ctx, id, func(record urls.Record) error {
+				ids = append(ids, record.ID)
+				return nil
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ids).To(Equal([]string{otherID}))
+		})
+	})
+	When("getting document id by long url of an exclusive url", func() {
+		BeforeEach(func() {
+			Expect(firestoreFixture.InsertDocument(ctx, urlsCollection, id, urls.URL{LongURL: longURL, Exclusive: true})).To(Succeed())
+		})
+
+		AfterEach(func() {
+			Expect(firestoreFixture.DeleteDocument(ctx, urlsCollection, id)).To(Succeed())
+		})
+
+		It("should return not found error", func() {
+			_, err := repository.GetDocIDByLongURL(ctx, "", longURL)
+			Expect(err).To(BeAssignableToTypeOf(urls.NotFoundError{}))
+		})
+	})
+	When("consuming a click of an url with clicks left", func() {
+		BeforeEach(func() {
+			Expect(firestoreFixture.InsertDocument(ctx, urlsCollection, id, urls.URL{LongURL: longURL, MaxClicks: 1})).To(Succeed())
+		})
+
+		AfterEach(func() {
+			Expect(firestoreFixture.DeleteDocument(ctx, urlsCollection, id)).To(Succeed())
+		})
+
+		It("should count the click and return exhausted error once no clicks are left", func() {
+			url, err := repository.ConsumeClick(ctx, id)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(url.Clicks).To(Equal(int64(1)))
+
+			_, err = repository.ConsumeClick(ctx, id)
+			Expect(err).To(BeAssignableToTypeOf(urls.ExhaustedError{}))
+		})
+	})
+
+	When("consuming a click of an url that does not exist", func() {
+		It("should return not found error", func() {
+			_, err := repository.ConsumeClick(ctx, "unknown-id")
+			Expect(err).To(BeAssignableToTypeOf(urls.NotFoundError{}))
+		})
+	})
+
+	When("setting the clicks of an url", func() {
+		BeforeEach(func() {
+			Expect(firestoreFixture.InsertDocument(ctx, urlsCollection, id, urls.URL{LongURL: longURL, MaxClicks: 5})).To(Succeed())
+		})
+
+		AfterEach(func() {
+			Expect(firestoreFixture.DeleteDocument(ctx, urlsCollection, id)).To(Succeed())
+		})
+
+		It("should only replace the click count", func() {
+			Expect(repository.SetClicks(ctx, id, 3)).To(Succeed())
+			Expect(repository.GetByShortURL(ctx, id)).To(Equal(urls.URL{LongURL: longURL, MaxClicks: 5, Clicks: 3}))
+		})
+	})
+
+	When("setting the clicks of an url that does not exist", func() {
+		It("should return not found error", func() {
+			Expect(repository.SetClicks(ctx, "unknown-id", 1)).To(BeAssignableToTypeOf(urls.NotFoundError{}))
+		})
+	})
+
+	When("changing urls of a tenant", func() {
+		const changesCollection = "tenants/changes-test/changes"
+
+		var tenantRepository *urls.Repository
+
+		BeforeEach(func() {
+			tenantRepository = urls.NewTenantRepository(firestoreClient, "changes-test")
+		})
+
+		AfterEach(func() {
+			changes, err := tenantRepository.ListChanges(ctx, urls.Cursor{}, 10)
+			Expect(err).NotTo(HaveOccurred())
+			for _, change := range changes {
+				Expect(firestoreFixture.DeleteDocument(ctx, changesCollection, change.ID)).To(Succeed())
+			}
+		})
+
+		It("should log every change in commit order", func() {
+			err = firestoreFixture.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+				return tenantRepository.AddURLTx(tx, id, urls.URL{LongURL: longURL})
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(tenantRepository.UpdateMetadata(ctx, id, urls.Metadata{Title: "title"})).To(Succeed())
+			Expect(tenantRepository.ConsumeClick(ctx, id)).To(HaveField("Clicks", int64(1)))
+			Expect(tenantRepository.Delete(ctx, id)).To(Succeed())
+
+			changes, err := tenantRepository.ListChanges(ctx, urls.Cursor{}, 10)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(changes).To(HaveLen(4))
+			Expect(changes[0].Type).To(Equal(urls.ChangeCreated))
+			Expect(changes[1].Type).To(Equal(urls.ChangeUpdated))
+			Expect(changes[1].URL).To(Equal(&urls.URL{LongURL: longURL, Metadata: urls.Metadata{Title: "title"}}))
+			Expect(changes[2].Type).To(Equal(urls.ChangeClicked))
+			Expect(changes[2].URL.Clicks).To(Equal(int64(1)))
+			Expect(changes[3].Type).To(Equal(urls.ChangeDeleted))
+			Expect(changes[3].URL).To(Equal(changes[2].URL))
+
+			cursor, err := urls.ParseCursor(changes[0].Cursor().String())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(tenantRepository.ListChanges(ctx, cursor, 10)).To(Equal(changes[1:]))
+		})
+	})
+
+	When("parsing a malformed cursor", func() {
+		It("should return an error", func() {
+			_, err := urls.ParseCursor("abc_def")
+			Expect(err).To(HaveOccurred())
+			_, err = urls.ParseCursor("123")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	When("deleting an url", func() {
+		BeforeEach(func() {
+			Expect(firestoreFixture.InsertDocument(ctx, urlsCollection, id, urls.URL{LongURL: longURL})).To(Succeed())
+		})
+
+		It("should not find it anymore", func() {
+			Expect(repository.Delete(ctx, id)).To(Succeed())
+
+			_, err := repository.GetByShortURL(ctx, id)
+			Expect(err).To(BeAssignableToTypeOf(urls.NotFoundError{}))
+			Expect(repository.Delete(ctx, id)).To(BeAssignableToTypeOf(urls.NotFoundError{}))
+		})
+	})
+	When("listing urls scheduled in a time range", func() {
+		const otherID = "test-id-2"
+
+		var launch time.Time
+
+		BeforeEach(func() {
+			launch = time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+			later := launch.Add(48 * time.Hour)
+			Expect(firestoreFixture.InsertDocument(ctx, urlsCollection, id, urls.URL{LongURL: longURL, NotBefore: &launch})).To(Succeed())
+			Expect(firestoreFixture.InsertDocument(ctx, urlsCollection, otherID, urls.URL{LongURL: longURL, NotBefore: &later})).To(Succeed())
+		})
+
+		AfterEach(func() {
+			Expect(firestoreFixture.DeleteDocument(ctx, urlsCollection, id)).To(Succeed())
+			Expect(firestoreFixture.DeleteDocument(ctx, urlsCollection, otherID)).To(Succeed())
+		})
+
+		It("should return only the urls within the range", func() {
+			records, err := repository.ListScheduled(ctx, urls.NotBeforeField, launch, launch.Add(24*time.Hour), urls.Cursor{}, 10)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(records).To(HaveLen(1))
+			Expect(records[0].ID).To(Equal(id))
+			Expect(records[0].URL.NotBefore.Equal(launch)).To(BeTrue())
+		})
+
+		It("should return pages starting after the cursor", func() {
+			records, err := repository.ListScheduled(ctx, urls.NotBeforeField, launch, launch.Add(72*time.Hour), urls.Cursor{}, 1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(records).To(HaveLen(1))
+			Expect(records[0].ID).To(Equal(id))
+
+			records, err = repository.ListScheduled(ctx, urls.NotBeforeField, launch, launch.Add(72*time.Hour), records[0].ScheduleCursor(urls.NotBeforeField), 1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(records).To(HaveLen(1))
+			Expect(records[0].ID).To(Equal(otherID))
+		})
+	})
+	When("updating variants of an url with variant clicks", func() {
+		BeforeEach(func() {
+			Expect(firestoreFixture.InsertDocument(ctx, urlsCollection, id, urls.URL{
+				LongURL:  longURL,
+				Variants: []variants.Variant{{Name: "a", Destination: longURL, Weight: 1}},
+			})).To(Succeed())
+			Expect(repository.IncrementVariantClicks(ctx, id, "a")).To(Succeed())
+		})
+
+		AfterEach(func() {
+			Expect(repository.Delete(ctx, id)).To(Succeed())
+		})
+
+		It("should replace the variants and keep the clicks", func() {
+			updated := []variants.Variant{{Name: "a", Destination: longURL, Weight: 1}, {Name: "b", Destination: longURL, Weight: 3}}
+			Expect(repository.UpdateVariants(ctx, id, updated)).To(Succeed())
+
+			url, err := repository.GetStats(ctx, id)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(url.Variants).To(Equal(updated))
+			Expect(url.VariantClicks).To(Equal(map[string]int64{"a": 1}))
+		})
+	})
+
+	When("counting variant clicks", func() {
+		BeforeEach(func() {
+			Expect(firestoreFixture.InsertDocument(ctx, urlsCollection, id, urls.URL{
+				LongURL:       longURL,
+				Variants:      []variants.Variant{{Name: "a", Destination: longURL, Weight: 1}},
+				VariantClicks: map[string]int64{"a": 5},
+			})).To(Succeed())
+		})
+
+		AfterEach(func() {
+			Expect(repository.Delete(ctx, id)).To(Succeed())
+		})
+
+		It("should count them on shards without writing the url document", func() {
+			for i := 0; i < 3; i++ {
+				Expect(repository.IncrementVariantClicks(ctx, id, "a")).To(Succeed())
+			}
+
+			url, err := repository.GetByShortURL(ctx, id)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(url.VariantClicks).To(Equal(map[string]int64{"a": 5}))
+
+			url, err = repository.GetStats(ctx, id)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(url.VariantClicks).To(Equal(map[string]int64{"a": 8}))
+		})
+
+		It("should replace the counted clicks when the url is put", func() {
+			Expect(repository.IncrementVariantClicks(ctx, id, "a")).To(Succeed())
+			Expect(repository.PutURLs(ctx, []urls.Record{{ID: id, URL: urls.URL{LongURL: longURL, VariantClicks: map[string]int64{"a": 2}}}})).To(Succeed())
+
+			url, err := repository.GetStats(ctx, id)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(url.VariantClicks).To(Equal(map[string]int64{"a": 2}))
+		})
+	})
+
+	When("counting variant clicks of an url that does not exist", func() {
+		It("should return not found error", func() {
+			err := repository.IncrementVariantClicks(ctx, "unknown-id", "a")
+			Expect(err).To(BeAssignableToTypeOf(urls.NotFoundError{}))
+		})
+	})
+
+	When("updating variants of an url that does not exist", func() {
+		It("should return not found error", func() {
+			err := repository.UpdateVariants(ctx, "unknown-id", nil)
+			Expect(err).To(BeAssignableToTypeOf(urls.NotFoundError{}))
+		})
+	})
+	When("getting document id by long url of an url on another domain", func() {
+		BeforeEach(func() {
+			Expect(firestoreFixture.InsertDocument(ctx, urlsCollection, urls.Key("go.example.com", id), urls.URL{LongURL: longURL, Domain: "go.example.com"})).To(Succeed())
+		})
+
+		AfterEach(func() {
+			Expect(firestoreFixture.DeleteDocument(ctx, urlsCollection, urls.Key("go.example.com", id))).To(Succeed())
+		})
+
+		It("should only find it on its domain", func() {
+			_, err := repository.GetDocIDByLongURL(ctx, "", longURL)
+			Expect(err).To(BeAssignableToTypeOf(urls.NotFoundError{}))
+
+			docID, err := repository.GetDocIDByLongURL(ctx, "go.example.com", longURL)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(docID).To(Equal(id + "@go.example.com"))
+		})
+	})
+	When("getting an url document of a tenant", func() {
+		const tenantURLs = "tenants/acme/urls"
+
+		BeforeEach(func() {
+			Expect(firestoreFixture.InsertDocument(ctx, tenantURLs, id, urls.URL{LongURL: longURL})).To(Succeed())
+		})
+
+		AfterEach(func() {
+			Expect(firestoreFixture.DeleteDocument(ctx, tenantURLs, id)).To(Succeed())
+		})
+
+		It("should only find it in the tenant repository", func() {
+			url, err := urls.NewTenantRepository(firestoreClient, "acme").GetByShortURL(ctx, id)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(url.LongURL).To(Equal(longURL))
+
+			_, err = repository.GetByShortURL(ctx, id)
+			Expect(err).To(BeAssignableToTypeOf(urls.NotFoundError{}))
+		})
+	})
+	When("updating metadata of an url", func() {
+		BeforeEach(func() {
+			Expect(firestoreFixture.InsertDocument(ctx, urlsCollection, id, urls.URL{
+				LongURL:  longURL,
+				Metadata: urls.Metadata{Title: "old", Tags: []string{"old"}},
+			})).To(Succeed())
+		})
+
+		AfterEach(func() {
+			Expect(firestoreFixture.DeleteDocument(ctx, urlsCollection, id)).To(Succeed())
+		})
+
+		It("should replace it", func() {
+			metadata := urls.Metadata{Title: "new", Description: "description", Tags: []string{"new"}, Folder: "campaigns"}
+			Expect(repository.UpdateMetadata(ctx, id, metadata)).To(Succeed())
+
+			url, err := repository.GetByShortURL(ctx, id)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(url.Metadata).To(Equal(metadata))
+		})
+	})
+
+	When("updating metadata of an url that does not exist", func() {
+		It("should return not found error", func() {
+			err := repository.UpdateMetadata(ctx, "unknown-id", urls.Metadata{})
+			Expect(err).To(BeAssignableToTypeOf(urls.NotFoundError{}))
+		})
+	})
+
+	When("updating the preview of an url", func() {
+		BeforeEach(func() {
+			Expect(firestoreFixture.InsertDocument(ctx, urlsCollection, id, urls.URL{LongURL: longURL})).To(Succeed())
+		})
+
+		AfterEach(func() {
+			Expect(firestoreFixture.DeleteDocument(ctx, urlsCollection, id)).To(Succeed())
+		})
+
+		It("should store it", func() {
+			preview := unfurl.Preview{Title: "title", Image: "https://example.com/a.png", FetchedAt: time.Now().UTC().Truncate(time.Millisecond)}
+			Expect(repository.UpdatePreview(ctx, id, preview)).To(Succeed())
+
+			url, err := repository.GetByShortURL(ctx, id)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(url.Preview).To(Equal(&preview))
+		})
+	})
+
+	When("updating the preview of an url that does not exist", func() {
+		It("should return not found error", func() {
+			err := repository.UpdatePreview(ctx, "unknown-id", unfurl.Preview{})
+			Expect(err).To(BeAssignableToTypeOf(urls.NotFoundError{}))
+		})
+	})
+
+	When("recording checks of an url", func() {
+		var policy = healthcheck.Policy{History: 2, BrokenAfter: time.Hour}
+
+		BeforeEach(func() {
+			Expect(firestoreFixture.InsertDocument(ctx, urlsCollection, id, urls.URL{LongURL: longURL})).To(Succeed())
+		})
+
+		AfterEach(func() {
+			Expect(firestoreFixture.DeleteDocument(ctx, urlsCollection, id)).To(Succeed())
+		})
+
+		It("should flag it once it has been failing for too long", func() {
+			start := time.Now().UTC().Add(-2 * time.Hour).Truncate(time.Millisecond)
+			Expect(repository.RecordCheck(ctx, id, healthcheck.Check{At: start, Status: 404}, policy)).To(Succeed())
+			Expect(repository.ListBroken(ctx)).To(BeEmpty())
+
+			Expect(repository.RecordCheck(ctx, id, healthcheck.Check{At: start.Add(time.Hour), Error: "timeout"}, policy)).To(Succeed())
+			broken, err := repository.ListBroken(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(broken).To(HaveLen(1))
+			Expect(broken[0].ID).To(Equal(id))
+			Expect(broken[0].URL.Health.FailingSince).To(Equal(&start))
+		})
+
+		It("should list it as checked", func() {
+			at := time.Now().UTC().Truncate(time.Millisecond)
+			Expect(repository.RecordCheck(ctx, id, healthcheck.Check{At: at, Status: 200}, policy)).To(Succeed())
+
+			var links []healthcheck.Link
+			Expect(repository.ForEachLink(ctx, func(link healthcheck.Link) error {
+				links = append(links, link)
+				return nil
+			})).To(Succeed())
+			Expect(links).To(ContainElement(healthcheck.Link{Key: id, URL: longURL, CheckedAt: at}))
+		})
+	})
+
+	When("recording a check of an url that does not exist", func() {
+		It("should return not found error", func() {
+			err := repository.RecordCheck(ctx, "unknown-id", healthcheck.Check{}, healthcheck.Policy{})
+			Expect(err).To(BeAssignableToTypeOf(urls.NotFoundError{}))
+		})
+	})
 })
@@ -0,0 +1,95 @@
+package urls
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strconv"
+
+	"cloud.google.com/go/firestore"
+)
+
+// variantClickShards is the number of documents variant clicks of a URL are spread over,
+// so redirects to a popular split link do not all write the same document
+const variantClickShards = 10
+
+// variantClickShard holds a part of the clicks per variant name of a URL
+type variantClickShard struct {
+	Clicks map[string]int64 `firestore:"clicks"`
+}
+
+// IncrementVariantClicks counts a redirect to the variant of a URL on a random shard
+func (r *Repository) IncrementVariantClicks(ctx context.Context, shortURL, variant string) error {
+	doc := r.urlsCollection().Doc(shortURL)
+	return r.firestoreClient.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		if _, err := getTx(tx, doc); err != nil {
+			return err
+		}
+
+		shard := r.variantClicksCollection(shortURL).Doc(strconv.Itoa(rand.Intn(variantClickShards)))
+		update := map[string]interface{}{"clicks": map[string]interface{}{variant: firestore.Increment(1)}}
+		if err := tx.Set(shard, update, firestore.MergeAll); err != nil {
+			return fmt.Errorf("failed to increment variant clicks: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// GetStats returns a URL with its variant clicks summed over all shards
+func (r *Repository) GetStats(ctx context.Context, shortURL string) (URL, error) {
+	url, err := r.GetByShortURL(ctx, shortURL)
+	if err != nil {
+		return URL{}, err
+	}
+
+	if err := r.addVariantClicks(ctx, shortURL, &url); err != nil {
+		return URL{}, err
+	}
+
+	return url, nil
+}
+
+// addVariantClicks adds the clicks of the shards of a URL to the variant clicks stored on its document
+func (r *Repository) addVariantClicks(ctx context.Context, shortURL string, url *URL) error {
+	shards, err := r.variantClicksCollection(shortURL).Documents(ctx).GetAll()
+	if err != nil {
+		return fmt.Errorf("failed to get variant clicks of [%s]: %w", shortURL, err)
+	}
+
+	for _, doc := range shards {
+		var shard variantClickShard
+		if err := doc.DataTo(&shard); err != nil {
+			return fmt.Errorf("failed to convert variant clicks of [%s]: %w", shortURL, err)
+		}
+
+		for variant, clicks := range shard.Clicks {
+			if url.VariantClicks == nil {
+				url.VariantClicks = make(map[string]int64)
+			}
+
+			url.VariantClicks[variant] += clicks
+		}
+	}
+
+	return nil
+}
+
+// variantClickShardsTx returns the existing shards of a URL, they are read before the transaction writes
+func (r *Repository) variantClickShardsTx(tx *firestore.Transaction, shortURL string) ([]*firestore.DocumentRef, error) {
+	shards, err := tx.Documents(r.variantClicksCollection(shortURL)).GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get variant clicks of [%s]: %w", shortURL, err)
+	}
+
+	refs := make([]*firestore.DocumentRef, len(shards))
+	for i, shard := range shards {
+		refs[i] = shard.Ref
+	}
+
+	return refs, nil
+}
+
+func (r *Repository) variantClicksCollection(shortURL string) *firestore.CollectionRef {
+	return r.urlsCollection().Doc(shortURL).Collection("variant_clicks")
+}
@@ -0,0 +1,11 @@
+package webhooks
+
+type NotFoundError struct{}
+
+func NewNotFoundError() NotFoundError {
+	return NotFoundError{}
+}
+
+func (e NotFoundError) Error() string {
+	return "failed to get subscription, a record was not found"
+}
@@ -0,0 +1,87 @@
+package webhooks
+
+import (
+	"time"
+	"url-shortener/pkg/webhook"
+)
+
+// Statuses of outbox entries
+const (
+	StatusPending   = "pending"
+	StatusDelivered = "delivered"
+	// StatusFailed entries were given up after too many failed attempts
+	StatusFailed = "failed"
+	// StatusCanceled entries were pending when their subscription was deleted
+	StatusCanceled = "canceled"
+)
+
+// maxHistory is the number of attempts kept per outbox entry
+const maxHistory = 10
+
+// Subscription is an URL receiving the events of a namespace
+type Subscription struct {
+	ID  string `firestore:"-" json:"id"`
+	URL string `firestore:"url" json:"url"`
+	// Secret signs the deliveries, it is only returned when the subscription is created
+	Secret string `firestore:"secret" json:"-"`
+	// Events are the event types sent to the URL, all types are sent if empty
+	Events    []string  `firestore:"events,omitempty" json:"events,omitempty"`
+	CreatedAt time.Time `firestore:"created_at" json:"created_at"`
+}
+
+// Wants reports whether events of the type are sent to the subscription
+func (s Subscription) Wants(eventType string) bool {
+	if len(s.Events) == 0 {
+		return true
+	}
+
+	for _, wanted := range s.Events {
+		if wanted == eventType {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Entry is an event waiting in the outbox to be delivered to a subscription, or its delivery log once sent
+type Entry struct {
+	ID string `firestore:"-" json:"id"`
+	// Owner is the tenant of the subscription, empty for the default namespace
+	Owner        string        `firestore:"owner" json:"-"`
+	Subscription string        `firestore:"subscription" json:"subscription"`
+	URL          string        `firestore:"url" json:"url"`
+	Secret       string        `firestore:"secret" json:"-"`
+	Event        webhook.Event `firestore:"event" json:"event"`
+	// Payload is the JSON encoded event, it is signed and sent as is on every attempt
+	Payload  string `firestore:"payload" json:"-"`
+	Status   string `firestore:"status" json:"status"`
+	Attempts int    `firestore:"attempts" json:"attempts"`
+	// NextAttemptAt is when a pending entry is sent, claimed entries are hidden by moving it forward
+	NextAttemptAt time.Time `firestore:"next_attempt_at" json:"next_attempt_at,omitempty"`
+	CreatedAt     time.Time `firestore:"created_at" json:"created_at"`
+	// History holds the latest attempts, oldest first
+	History []webhook.Attempt `firestore:"history,omitempty" json:"history,omitempty"`
+}
+
+// Delivery returns what the dispatcher sends for the entry
+func (e Entry) Delivery() webhook.Delivery {
+	return webhook.Delivery{
+		ID:       e.ID,
+		URL:      e.URL,
+		Secret:   e.Secret,
+		Event:    e.Event.Type,
+		Payload:  []byte(e.Payload),
+		Attempts: e.Attempts,
+	}
+}
+
+// Cursor is the end of the time range whose expired links have been published
+type Cursor struct {
+	Until time.Time `firestore:"until"`
+}
+
+// ChangesPosition is the position in the change log of links up to which events have been published
+type ChangesPosition struct {
+	After string `firestore:"after"`
+}
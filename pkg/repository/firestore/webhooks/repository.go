@@ -0,0 +1,403 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+	"url-shortener/pkg/webhook"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	outboxCollection = "webhook_outbox"
+	expiredCursor    = "expired"
+	changesCursor    = "changes"
+	// maxBatchSize keeps a transaction below the Firestore limit of 500 writes
+	maxBatchSize = 400
+)
+
+// Repository stores the subscriptions of a namespace and the outbox shared by all namespaces
+type Repository struct {
+	firestoreClient *firestore.Client
+	owner           string
+	collection      string
+	stateCollection string
+}
+
+// NewRepository is a constructor function
+func NewRepository(firestoreClient *firestore.Client) *Repository {
+	return &Repository{
+		firestoreClient: firestoreClient,
+		collection:      "webhooks",
+		stateCollection: "webhook_state",
+	}
+}
+
+// NewTenantRepository is a constructor function of a repository scoped to the subscriptions of the tenant
+func NewTenantRepository(firestoreClient *firestore.Client, tenantID string) *Repository {
+	return &Repository{
+		firestoreClient: firestoreClient,
+		owner:           tenantID,
+		collection:      fmt.Sprintf("tenants/%s/webhooks", tenantID),
+		stateCollection: fmt.Sprintf("tenants/%s/webhook_state", tenantID),
+	}
+}
+
+// AddSubscription stores a subscription and returns it with its generated id
+func (r *Repository) AddSubscription(ctx context.Context, subscription Subscription) (Subscription, error) {
+	doc, _, err := r.subscriptionsCollection().Add(ctx, subscription)
+	if err != nil {
+		return Subscription{}, fmt.Errorf("failed to create subscription: %w", err)
+	}
+
+	subscription.ID = doc.ID
+	return subscription, nil
+}
+
+// ListSubscriptions returns the subscriptions of the namespace ordered by creation
+func (r *Repository) ListSubscriptions(ctx context.Context) ([]Subscription, error) {
+	documents := r.subscriptionsCollection().OrderBy("created_at", firestore.Asc).Documents(ctx)
+	defer documents.Stop()
+
+	var subscriptions []Subscription
+	for {
+		doc, err := documents.Next()
+		if err == iterator.Done {
+			return subscriptions, nil
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to list subscriptions: %w", err)
+		}
+
+		var subscription Subscription
+		if err := doc.DataTo(&subscription); err != nil {
+			return nil, fmt.Errorf("failed to convert subscription [%s]: %w", doc.Ref.ID, err)
+		}
+
+		subscription.ID = doc.Ref.ID
+		subscriptions = append(subscriptions, subscription)
+	}
+}
+
+// DeleteSubscription deletes a subscription and cancels its pending deliveries
+// If the subscription does not exist, it returns not found error
+func (r *Repository) DeleteSubscription(ctx context.Context, id string) error {
+	if _, err := r.subscriptionsCollection().Doc(id).Delete(ctx, firestore.Exists); err != nil {
+		if status.Code(err) == codes.NotFound {
+			return NewNotFoundError()
+		}
+
+		return fmt.Errorf("failed to delete subscription: %w", err)
+	}
+
+	documents := r.outboxCollection().
+		Where("subscription", "==", id).
+		Where("status", "==", StatusPending).
+		Documents(ctx)
+	defer documents.Stop()
+
+	for {
+		doc, err := documents.Next()
+		if err == iterator.Done {
+			return nil
+		}
+
+		if err != nil {
+			return fmt.Errorf("failed to list pending deliveries: %w", err)
+		}
+
+		if _, err := doc.Ref.Update(ctx, []firestore.Update{{Path: "status", Value: StatusCanceled}}); err != nil {
+			return fmt.Errorf("failed to cancel delivery [%s]: %w", doc.Ref.ID, err)
+		}
+	}
+}
+
+// ListDeliveries returns the latest limit deliveries of a subscription, newest first
+// If the subscription does not exist in the namespace, it returns not found error
+func (r *Repository) ListDeliveries(ctx context.Context, subscriptionID string, limit int) ([]Entry, error) {
+	if _, err := r.subscriptionsCollection().Doc(subscriptionID).Get(ctx); err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, NewNotFoundError()
+		}
+
+		return nil, fmt.Errorf("failed to retrieve subscription: %w", err)
+	}
+
+	documents := r.outboxCollection().
+		Where("subscription", "==", subscriptionID).
+		OrderBy("created_at", firestore.Desc).
+		Limit(limit).
+		Documents(ctx)
+	defer documents.Stop()
+
+	var entries []Entry
+	for {
+		doc, err := documents.Next()
+		if err == iterator.Done {
+			return entries, nil
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to list deliveries: %w", err)
+		}
+
+		entry, err := toEntry(doc)
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, entry)
+	}
+}
+
+// Publish adds an outbox entry per event and subscription of the namespace wanting it
+// Entries are identified by the event id and the subscription, an event published again is not queued twice
+func (r *Repository) Publish(ctx context.Context, events ...webhook.Event) error {
+	subscriptions, err := r.ListSubscriptions(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	var (
+		refs    []*firestore.DocumentRef
+		entries []Entry
+	)
+
+	for _, event := range events {
+		payload, err := json.Marshal(event)
+		if err != nil {
+			return fmt.Errorf("failed to encode event: %w", err)
+		}
+
+		for _, subscription := range subscriptions {
+			if !subscription.Wants(event.Type) {
+				continue
+			}
+
+			refs = append(refs, r.outboxCollection().Doc(event.ID+"_"+subscription.ID))
+			entries = append(entries, Entry{
+				Owner:         r.owner,
+				Subscription:  subscription.ID,
+				URL:           subscription.URL,
+				Secret:        subscription.Secret,
+				Event:         event,
+				Payload:       string(payload),
+				Status:        StatusPending,
+				NextAttemptAt: now,
+				CreatedAt:     now,
+			})
+		}
+	}
+
+	for start := 0; start < len(entries); start += maxBatchSize {
+		end := start + maxBatchSize
+		if end > len(entries) {
+			end = len(entries)
+		}
+
+		if err := r.addEntries(ctx, refs[start:end], entries[start:end]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Claim returns up to limit pending entries of all namespaces which are due and moves their next attempt
+// to the end of the lease, so other instances do not send them at the same time
+func (r *Repository) Claim(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]webhook.Delivery, error) {
+	var deliveries []webhook.Delivery
+	query := r.outboxCollection().
+		Where("status", "==", StatusPending).
+		Where("next_attempt_at", "<=", now).
+		OrderBy("next_attempt_at", firestore.Asc).
+		Limit(limit)
+	err := r.firestoreClient.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		docs, err := tx.Documents(query).GetAll()
+		if err != nil {
+			return fmt.Errorf("failed to list due deliveries: %w", err)
+		}
+
+		deliveries = make([]webhook.Delivery, 0, len(docs))
+		for _, doc := range docs {
+			entry, err := toEntry(doc)
+			if err != nil {
+				return err
+			}
+
+			deliveries = append(deliveries, entry.Delivery())
+		}
+
+		for _, doc := range docs {
+			if err := tx.Update(doc.Ref, []firestore.Update{{Path: "next_attempt_at", Value: now.Add(lease)}}); err != nil {
+				return fmt.Errorf("failed to claim delivery [%s]: %w", doc.Ref.ID, err)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+// Record adds the attempt to the history of an entry, which is retried at retryAt unless it is zero
+// Entries without retry are delivered or failed depending on the attempt
+func (r *Repository) Record(ctx context.Context, id string, attempt webhook.Attempt, retryAt time.Time) error {
+	doc := r.outboxCollection().Doc(id)
+	return r.firestoreClient.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		snapshot, err := tx.Get(doc)
+		if err != nil {
+			if status.Code(err) == codes.NotFound {
+				return NewNotFoundError()
+			}
+
+			return fmt.Errorf("failed to retrieve delivery: %w", err)
+		}
+
+		entry, err := toEntry(snapshot)
+		if err != nil {
+			return err
+		}
+
+		history := append(entry.History, attempt)
+		if len(history) > maxHistory {
+			history = history[len(history)-maxHistory:]
+		}
+
+		updates := []firestore.Update{
+			{Path: "history", Value: history},
+			{Path: "attempts", Value: entry.Attempts + 1},
+		}
+		// entries of subscriptions deleted while they were sent keep their canceled status
+		if entry.Status == StatusPending {
+			switch {
+			case attempt.OK():
+				updates = append(updates, firestore.Update{Path: "status", Value: StatusDelivered})
+			case retryAt.IsZero():
+				updates = append(updates, firestore.Update{Path: "status", Value: StatusFailed})
+			default:
+				updates = append(updates, firestore.Update{Path: "next_attempt_at", Value: retryAt})
+			}
+		}
+
+		return tx.Update(doc, updates)
+	})
+}
+
+// ExpiredCursor returns the end of the range whose expired links have been published, zero if none was
+func (r *Repository) ExpiredCursor(ctx context.Context) (time.Time, error) {
+	doc, err := r.firestoreClient.Collection(r.stateCollection).Doc(expiredCursor).Get(ctx)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return time.Time{}, nil
+		}
+
+		return time.Time{}, fmt.Errorf("failed to retrieve expired cursor: %w", err)
+	}
+
+	var cursor Cursor
+	if err := doc.DataTo(&cursor); err != nil {
+		return time.Time{}, fmt.Errorf("failed to convert expired cursor: %w", err)
+	}
+
+	return cursor.Until, nil
+}
+
+// SetExpiredCursor stores the end of the range whose expired links have been published
+func (r *Repository) SetExpiredCursor(ctx context.Context, until time.Time) error {
+	if _, err := r.firestoreClient.Collection(r.stateCollection).Doc(expiredCursor).Set(ctx, Cursor{Until: until}); err != nil {
+		return fmt.Errorf("failed to store expired cursor: %w", err)
+	}
+
+	return nil
+}
+
+// ChangesCursor returns the position in the change log of links up to which events have been published,
+// ok is false if the position was never stored
+func (r *Repository) ChangesCursor(ctx context.Context) (cursor string, ok bool, err error) {
+	doc, err := r.firestoreClient.Collection(r.stateCollection).Doc(changesCursor).Get(ctx)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return "", false, nil
+		}
+
+		return "", false, fmt.Errorf("failed to retrieve changes cursor: %w", err)
+	}
+
+	var position ChangesPosition
+	if err := doc.DataTo(&position); err != nil {
+		return "", false, fmt.Errorf("failed to convert changes cursor: %w", err)
+	}
+
+	return position.After, true, nil
+}
+
+// SetChangesCursor stores the position in the change log of links up to which events have been published,
+// an empty cursor is the start of the change log
+func (r *Repository) SetChangesCursor(ctx context.Context, cursor string) error {
+	if _, err := r.firestoreClient.Collection(r.stateCollection).Doc(changesCursor).Set(ctx, ChangesPosition{After: cursor}); err != nil {
+		return fmt.Errorf("failed to store changes cursor: %w", err)
+	}
+
+	return nil
+}
+
+// InitChangesCursor stores the start of the change log as changes cursor, unless a cursor is already stored
+func (r *Repository) InitChangesCursor(ctx context.Context) error {
+	_, err := r.firestoreClient.Collection(r.stateCollection).Doc(changesCursor).Create(ctx, ChangesPosition{})
+	if err != nil && status.Code(err) != codes.AlreadyExists {
+		return fmt.Errorf("failed to initialize changes cursor: %w", err)
+	}
+
+	return nil
+}
+
+// addEntries creates the entries which do not exist yet
+func (r *Repository) addEntries(ctx context.Context, refs []*firestore.DocumentRef, entries []Entry) error {
+	return r.firestoreClient.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		existing, err := tx.GetAll(refs)
+		if err != nil {
+			return fmt.Errorf("failed to get deliveries: %w", err)
+		}
+
+		for i, entry := range entries {
+			if existing[i].Exists() {
+				continue
+			}
+
+			if err := tx.Create(refs[i], entry); err != nil {
+				return fmt.Errorf("failed to add delivery: %w", err)
+			}
+		}
+
+		return nil
+	})
+}
+
+func toEntry(doc *firestore.DocumentSnapshot) (Entry, error) {
+	var entry Entry
+	if err := doc.DataTo(&entry); err != nil {
+		return Entry{}, fmt.Errorf("failed to convert delivery [%s]: %w", doc.Ref.ID, err)
+	}
+
+	entry.ID = doc.Ref.ID
+	return entry, nil
+}
+
+func (r *Repository) subscriptionsCollection() *firestore.CollectionRef {
+	return r.firestoreClient.Collection(r.collection)
+}
+
+func (r *Repository) outboxCollection() *firestore.CollectionRef {
+	return r.firestoreClient.Collection(outboxCollection)
+}
@@ -0,0 +1,139 @@
+package webhooks_test
+
+import (
+	"context"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	. "github.com/onsi/ginkgo/v2"
+
+	"url-shortener/pkg/repository/firestore/webhooks"
+	"url-shortener/pkg/webhook"
+	"url-shortener/test/fixture"
+
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Webhooks Repository", func() {
+	const (
+		tenantID         = "webhooks-test"
+		outboxCollection = "webhook_outbox"
+	)
+
+	var (
+		ctx              context.Context
+		firestoreClient  *firestore.Client
+		repository       *webhooks.Repository
+		firestoreFixture *fixture.FirestoreFixture
+		err              error
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		firestoreClient, err = firestore.NewClient(ctx, firestore.DetectProjectID)
+		Expect(err).NotTo(HaveOccurred())
+		repository = webhooks.NewTenantRepository(firestoreClient, tenantID)
+		firestoreFixture = fixture.NewFirestoreFixture(firestoreClient)
+	})
+
+	AfterEach(func() {
+		firestoreClient.Close()
+	})
+
+	When("publishing events to a subscription", func() {
+		var subscription webhooks.Subscription
+
+		BeforeEach(func() {
+			subscription, err = repository.AddSubscription(ctx, webhooks.Subscription{
+				URL:       "https://hooks.example.com",
+				Secret:    "secret",
+				Events:    []string{webhook.LinkCreated},
+				CreatedAt: time.Now().UTC(),
+			})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			entries, err := repository.ListDeliveries(ctx, subscription.ID, 10)
+			Expect(err).NotTo(HaveOccurred())
+			for _, entry := range entries {
+				Expect(firestoreFixture.DeleteDocument(ctx, outboxCollection, entry.ID)).To(Succeed())
+			}
+
+			Expect(repository.DeleteSubscription(ctx, subscription.ID)).To(Succeed())
+		})
+
+		It("should queue the wanted events until they are delivered", func() {
+			Expect(repository.Publish(ctx,
+				webhook.Event{ID: "created", Type: webhook.LinkCreated, ShortURL: "b", LongURL: "https://example.com"},
+				webhook.Event{ID: "deleted", Type: webhook.LinkDeleted, ShortURL: "c"},
+			)).To(Succeed())
+
+			deliveries, err := repository.Claim(ctx, time.Now(), time.Minute, 10)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deliveries).To(HaveLen(1))
+			Expect(deliveries[0].Event).To(Equal(webhook.LinkCreated))
+			Expect(deliveries[0].Secret).To(Equal("secret"))
+			Expect(repository.Claim(ctx, time.Now(), time.Minute, 10)).To(BeEmpty())
+
+			failed := webhook.Attempt{At: time.Now().UTC(), Status: 500}
+			Expect(repository.Record(ctx, deliveries[0].ID, failed, time.Now().Add(-time.Second))).To(Succeed())
+			retried, err := repository.Claim(ctx, time.Now(), time.Minute, 10)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(retried).To(HaveLen(1))
+			Expect(retried[0].Attempts).To(Equal(1))
+
+			delivered := webhook.Attempt{At: time.Now().UTC(), Status: 204}
+			Expect(repository.Record(ctx, deliveries[0].ID, delivered, time.Time{})).To(Succeed())
+			entries, err := repository.ListDeliveries(ctx, subscription.ID, 10)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(entries).To(HaveLen(1))
+			Expect(entries[0].Status).To(Equal(webhooks.StatusDelivered))
+			Expect(entries[0].History).To(HaveLen(2))
+		})
+
+		It("should queue an event published again only once", func() {
+			event := webhook.Event{ID: "again", Type: webhook.LinkCreated, ShortURL: "b"}
+			Expect(repository.Publish(ctx, event)).To(Succeed())
+			Expect(repository.Publish(ctx, event)).To(Succeed())
+
+			Expect(repository.ListDeliveries(ctx, subscription.ID, 10)).To(HaveLen(1))
+		})
+	})
+
+	When("storing the changes cursor", func() {
+		AfterEach(func() {
+			Expect(firestoreFixture.DeleteDocument(ctx, "tenants/"+tenantID+"/webhook_state", "changes")).To(Succeed())
+		})
+
+		It("should return it", func() {
+			_, ok, err := repository.ChangesCursor(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeFalse())
+
+			Expect(repository.SetChangesCursor(ctx, "")).To(Succeed())
+			cursor, ok, err := repository.ChangesCursor(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeTrue())
+			Expect(cursor).To(BeEmpty())
+		})
+
+		It("should keep it when initializing the cursor again", func() {
+			Expect(repository.InitChangesCursor(ctx)).To(Succeed())
+			Expect(repository.SetChangesCursor(ctx, "42")).To(Succeed())
+			Expect(repository.InitChangesCursor(ctx)).To(Succeed())
+
+			cursor, ok, err := repository.ChangesCursor(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeTrue())
+			Expect(cursor).To(Equal("42"))
+		})
+	})
+
+	When("listing deliveries of a subscription of another namespace", func() {
+		It("should return not found error", func() {
+			_, err := repository.ListDeliveries(ctx, "unknown", 10)
+			Expect(err).To(BeAssignableToTypeOf(webhooks.NotFoundError{}))
+		})
+	})
+})
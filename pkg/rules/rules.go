@@ -0,0 +1,246 @@
+package rules
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Platforms matched against the user agent
+const (
+	PlatformIOS     = "ios"
+	PlatformAndroid = "android"
+	PlatformWindows = "windows"
+	PlatformMacOS   = "macos"
+	PlatformLinux   = "linux"
+	PlatformOther   = "other"
+)
+
+// Rule sends visitors matching all of its conditions to its destination
+// Empty conditions match every visitor, a list condition matches if any of its values does.
+type Rule struct {
+	Platforms []string `firestore:"platforms,omitempty" json:"platforms,omitempty"`
+	// Languages are matched against the most preferred Accept-Language, "en" matches "en-US" too
+	Languages []string `firestore:"languages,omitempty" json:"languages,omitempty"`
+	// Countries are ISO 3166 country codes of the visitor IP
+	Countries []string `firestore:"countries,omitempty" json:"countries,omitempty"`
+	// TimeFrom and TimeTo are "HH:MM" in TimeZone, UTC if empty; a window may wrap around midnight
+	TimeFrom    string `firestore:"time_from,omitempty" json:"time_from,omitempty"`
+	TimeTo      string `firestore:"time_to,omitempty" json:"time_to,omitempty"`
+	TimeZone    string `firestore:"time_zone,omitempty" json:"time_zone,omitempty"`
+	Destination string `firestore:"destination" json:"destination"`
+}
+
+// Visitor holds the request attributes rules are matched against
+type Visitor struct {
+	Platform string
+	Language string
+	Country  string
+	Time     time.Time
+}
+
+// Match returns the destination of the first rule matching the visitor
+func Match(rules []Rule, visitor Visitor) (string, bool) {
+	for _, rule := range rules {
+		if rule.matches(visitor) {
+			return rule.Destination, true
+		}
+	}
+
+	return "", false
+}
+
+// Validate checks that the rule has a destination, at least one condition and valid values
+func (r Rule) Validate() error {
+	if r.Destination == "" {
+		return errors.New("rule has no destination")
+	}
+
+	if len(r.Platforms) == 0 && len(r.Languages) == 0 && len(r.Countries) == 0 && r.TimeFrom == "" && r.TimeTo == "" {
+		return errors.New("rule has no condition")
+	}
+
+	for _, platform := range r.Platforms {
+		switch platform {
+		case PlatformIOS, PlatformAndroid, PlatformWindows, PlatformMacOS, PlatformLinux, PlatformOther:
+		default:
+			return fmt.Errorf("unsupported platform [%s]", platform)
+		}
+	}
+
+	if (r.TimeFrom == "") != (r.TimeTo == "") {
+		return errors.New("rule time window needs both time from and time to")
+	}
+
+	if r.TimeFrom != "" {
+		if _, err := parseClock(r.TimeFrom); err != nil {
+			return err
+		}
+
+		if _, err := parseClock(r.TimeTo); err != nil {
+			return err
+		}
+	}
+
+	if _, err := loadLocation(r.TimeZone); err != nil {
+		return fmt.Errorf("unsupported time zone [%s]", r.TimeZone)
+	}
+
+	return nil
+}
+
+// locations caches time zones by name, rules load them when they are saved so redirects do not read the zone database
+var locations sync.Map
+
+func loadLocation(name string) (*time.Location, error) {
+	if location, ok := locations.Load(name); ok {
+		return location.(*time.Location), nil
+	}
+
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, err
+	}
+
+	locations.Store(name, location)
+	return location, nil
+}
+
+func (r Rule) matches(visitor Visitor) bool {
+	if len(r.Platforms) > 0 && !contains(r.Platforms, visitor.Platform) {
+		return false
+	}
+
+	if len(r.Countries) > 0 && !contains(r.Countries, visitor.Country) {
+		return false
+	}
+
+	if len(r.Languages) > 0 && !matchesLanguage(r.Languages, visitor.Language) {
+		return false
+	}
+
+	if r.TimeFrom != "" && !r.withinTime(visitor.Time) {
+		return false
+	}
+
+	return true
+}
+
+func (r Rule) withinTime(now time.Time) bool {
+	from, err := parseClock(r.TimeFrom)
+	if err != nil {
+		return false
+	}
+
+	to, err := parseClock(r.TimeTo)
+	if err != nil {
+		return false
+	}
+
+	location, err := loadLocation(r.TimeZone)
+	if err != nil {
+		return false
+	}
+
+	now = now.In(location)
+	minute := now.Hour()*60 + now.Minute()
+	if from <= to {
+		return minute >= from && minute < to
+	}
+
+	return minute >= from || minute < to
+}
+
+// Platform detects the platform of the user agent
+func Platform(userAgent string) string {
+	switch {
+	case strings.Contains(userAgent, "iPhone"), strings.Contains(userAgent, "iPad"), strings.Contains(userAgent, "iPod"):
+		return PlatformIOS
+	case strings.Contains(userAgent, "Android"):
+		return PlatformAndroid
+	case strings.Contains(userAgent, "Windows"):
+		return PlatformWindows
+	case strings.Contains(userAgent, "Macintosh"), strings.Contains(userAgent, "Mac OS X"):
+		return PlatformMacOS
+	case strings.Contains(userAgent, "Linux"):
+		return PlatformLinux
+	default:
+		return PlatformOther
+	}
+}
+
+// Language returns the most preferred language of the Accept-Language header, empty if there is none
+func Language(acceptLanguage string) string {
+	type preference struct {
+		tag     string
+		quality float64
+	}
+
+	var preferences []preference
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		tag = strings.TrimSpace(tag)
+		if tag == "" || tag == "*" {
+			continue
+		}
+
+		quality := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+
+			quality = parsed
+		}
+
+		if quality > 0 {
+			preferences = append(preferences, preference{tag: tag, quality: quality})
+		}
+	}
+
+	if len(preferences) == 0 {
+		return ""
+	}
+
+	sort.SliceStable(preferences, func(i, j int) bool {
+		return preferences[i].quality > preferences[j].quality
+	})
+
+	return preferences[0].tag
+}
+
+func matchesLanguage(languages []string, language string) bool {
+	for _, candidate := range languages {
+		if strings.EqualFold(candidate, language) ||
+			(len(language) > len(candidate) && strings.EqualFold(candidate, language[:len(candidate)]) && language[len(candidate)] == '-') {
+			return true
+		}
+	}
+
+	return false
+}
+
+func contains(values []string, value string) bool {
+	for _, candidate := range values {
+		if strings.EqualFold(candidate, value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parseClock returns the minutes since midnight of a "HH:MM" time
+func parseClock(clock string) (int, error) {
+	parsed, err := time.Parse("15:04", clock)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time [%s], expected HH:MM", clock)
+	}
+
+	return parsed.Hour()*60 + parsed.Minute(), nil
+}
@@ -0,0 +1,101 @@
+package rules_test
+
+import (
+	"time"
+	"url-shortener/pkg/rules"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Rules", func() {
+	const (
+		appStore = "https://apps.apple.com/app"
+		play     = "https://play.google.com/app"
+		german   = "https://example.de"
+		night    = "https://example.com/night"
+	)
+
+	var (
+		linkRules []rules.Rule
+		noon      time.Time
+	)
+
+	BeforeEach(func() {
+		linkRules = []rules.Rule{
+			{Platforms: []string{rules.PlatformIOS}, Destination: appStore},
+			{Platforms: []string{rules.PlatformAndroid}, Destination: play},
+			{Countries: []string{"DE", "AT"}, Languages: []string{"de"}, Destination: german},
+			{TimeFrom: "22:00", TimeTo: "06:00", Destination: night},
+		}
+		noon = time.Date(2030, 1, 1, 12, 0, 0, 0, time.UTC)
+	})
+
+	match := func(visitor rules.Visitor) string {
+		destination, _ := rules.Match(linkRules, visitor)
+		return destination
+	}
+
+	When("the visitor matches a rule", func() {
+		It("should return the destination of the first matching rule", func() {
+			Expect(match(rules.Visitor{Platform: rules.PlatformIOS, Country: "DE", Language: "de", Time: noon})).To(Equal(appStore))
+			Expect(match(rules.Visitor{Platform: rules.PlatformAndroid, Time: noon})).To(Equal(play))
+			Expect(match(rules.Visitor{Platform: rules.PlatformWindows, Country: "at", Language: "de-AT", Time: noon})).To(Equal(german))
+		})
+	})
+
+	When("the visitor matches only some conditions of a rule", func() {
+		It("should not match it", func() {
+			_, ok := rules.Match(linkRules, rules.Visitor{Platform: rules.PlatformWindows, Country: "DE", Language: "en", Time: noon})
+			Expect(ok).To(BeFalse())
+		})
+	})
+
+	When("the time window wraps around midnight", func() {
+		It("should match before and after midnight only", func() {
+			for _, hour := range []int{23, 5} {
+				Expect(match(rules.Visitor{Time: time.Date(2030, 1, 1, hour, 0, 0, 0, time.UTC)})).To(Equal(night))
+			}
+
+			_, ok := rules.Match(linkRules, rules.Visitor{Time: time.Date(2030, 1, 1, 6, 0, 0, 0, time.UTC)})
+			Expect(ok).To(BeFalse())
+		})
+	})
+
+	When("validating rules", func() {
+		It("should reject incomplete or invalid rules", func() {
+			Expect(rules.Rule{Platforms: []string{rules.PlatformIOS}}.Validate()).ToNot(Succeed())
+			Expect(rules.Rule{Destination: appStore}.Validate()).ToNot(Succeed())
+			Expect(rules.Rule{Platforms: []string{"symbian"}, Destination: appStore}.Validate()).ToNot(Succeed())
+			Expect(rules.Rule{TimeFrom: "22:00", Destination: night}.Validate()).ToNot(Succeed())
+			Expect(rules.Rule{TimeFrom: "25:00", TimeTo: "06:00", Destination: night}.Validate()).ToNot(Succeed())
+			Expect(rules.Rule{TimeFrom: "22:00", TimeTo: "06:00", TimeZone: "Mars/Base", Destination: night}.Validate()).ToNot(Succeed())
+		})
+
+		It("should accept valid rules", func() {
+			for _, rule := range linkRules {
+				Expect(rule.Validate()).To(Succeed())
+			}
+		})
+	})
+
+	When("detecting the platform", func() {
+		It("should recognize common user agents", func() {
+			Expect(rules.Platform("Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X)")).To(Equal(rules.PlatformIOS))
+			Expect(rules.Platform("Mozilla/5.0 (Linux; Android 14; Pixel 8)")).To(Equal(rules.PlatformAndroid))
+			Expect(rules.Platform("Mozilla/5.0 (Windows NT 10.0; Win64; x64)")).To(Equal(rules.PlatformWindows))
+			Expect(rules.Platform("Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7)")).To(Equal(rules.PlatformMacOS))
+			Expect(rules.Platform("Mozilla/5.0 (X11; Linux x86_64)")).To(Equal(rules.PlatformLinux))
+			Expect(rules.Platform("curl/8.0")).To(Equal(rules.PlatformOther))
+		})
+	})
+
+	When("parsing the Accept-Language header", func() {
+		It("should return the most preferred language", func() {
+			Expect(rules.Language("en;q=0.5, de-CH, fr;q=0.8")).To(Equal("de-CH"))
+			Expect(rules.Language("fr;q=0.8, en;q=0.9")).To(Equal("en"))
+			Expect(rules.Language("*, de;q=0")).To(BeEmpty())
+			Expect(rules.Language("")).To(BeEmpty())
+		})
+	})
+})
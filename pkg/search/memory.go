@@ -0,0 +1,92 @@
+package search
+
+import (
+	"context"
+	"sync"
+)
+
+// MemoryIndex is an in-process index of documents
+// It is not shared between instances and has to be filled again after a restart
+type MemoryIndex struct {
+	mu       sync.RWMutex
+	docs     map[string]Document
+	postings map[string]map[string]struct{}
+}
+
+// NewMemoryIndex is a constructor function
+func NewMemoryIndex() *MemoryIndex {
+	return &MemoryIndex{
+		docs:     map[string]Document{},
+		postings: map[string]map[string]struct{}{},
+	}
+}
+
+// Put adds the document or replaces the one with the same id
+func (i *MemoryIndex) Put(_ context.Context, doc Document) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if old, ok := i.docs[doc.ID]; ok {
+		for _, term := range Terms(old) {
+			delete(i.postings[term], doc.ID)
+		}
+	}
+
+	i.docs[doc.ID] = doc
+	for _, term := range Terms(doc) {
+		if i.postings[term] == nil {
+			i.postings[term] = map[string]struct{}{}
+		}
+
+		i.postings[term][doc.ID] = struct{}{}
+	}
+
+	return nil
+}
+
+// Delete removes the document with the id, it succeeds if there is none
+func (i *MemoryIndex) Delete(_ context.Context, id string) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if old, ok := i.docs[id]; ok {
+		for _, term := range Terms(old) {
+			delete(i.postings[term], id)
+		}
+	}
+
+	delete(i.docs, id)
+	return nil
+}
+
+// Search returns the documents matching the query ordered by id
+func (i *MemoryIndex) Search(_ context.Context, query Query) ([]Document, error) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	var docs []Document
+	collect := func(doc Document) {
+		if Match(doc, query) {
+			docs = append(docs, doc)
+		}
+	}
+
+	if words := Words(query.Text); len(words) > 0 {
+		for id := range i.postings[Term(words[0])] {
+			collect(i.docs[id])
+		}
+	} else {
+		for _, doc := range i.docs {
+			collect(doc)
+		}
+	}
+
+	return Sort(docs, query.Limit), nil
+}
+
+// Len returns the number of indexed documents
+func (i *MemoryIndex) Len() int {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return len(i.docs)
+}
@@ -0,0 +1,191 @@
+package search
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+const (
+	// maxPrefix is the longest indexed prefix of a word, longer query words are matched by their prefix first
+	maxPrefix = 12
+	maxTags   = 20
+)
+
+var (
+	tagPattern    = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,31}$`)
+	folderPattern = regexp.MustCompile(`^[a-z0-9_-]+(/[a-z0-9_-]+)*$`)
+)
+
+// Document is the searchable description of a link
+type Document struct {
+	// ID is the document id of the link
+	ID          string   `firestore:"-"`
+	LongURL     string   `firestore:"long_url"`
+	Title       string   `firestore:"title,omitempty"`
+	Description string   `firestore:"description,omitempty"`
+	Tags        []string `firestore:"tags"`
+	Folder      string   `firestore:"folder,omitempty"`
+}
+
+// Query selects documents matching all of its words, tags and its folder, including its subfolders
+type Query struct {
+	Text   string
+	Tags   []string
+	Folder string
+	// Limit is the maximum number of returned documents, 0 means no limit
+	Limit int
+}
+
+// Words splits text into lowercase words of letters and digits
+func Words(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// Terms returns the index terms of the document, the prefixes of all its words
+// A query word matches the document if its first maxPrefix characters are one of the terms
+func Terms(doc Document) []string {
+	seen := map[string]struct{}{}
+	var terms []string
+	for _, word := range documentWords(doc) {
+		runes := []rune(word)
+		for i := 1; i <= len(runes) && i <= maxPrefix; i++ {
+			term := string(runes[:i])
+			if _, ok := seen[term]; ok {
+				continue
+			}
+
+			seen[term] = struct{}{}
+			terms = append(terms, term)
+		}
+	}
+
+	sort.Strings(terms)
+	return terms
+}
+
+// Term returns the index term looked up for a query word
+func Term(word string) string {
+	if runes := []rune(word); len(runes) > maxPrefix {
+		return string(runes[:maxPrefix])
+	}
+
+	return word
+}
+
+// Folders returns the folder with all its parent folders
+func Folders(folder string) []string {
+	if folder == "" {
+		return nil
+	}
+
+	parts := strings.Split(folder, "/")
+	folders := make([]string, len(parts))
+	for i := range parts {
+		folders[i] = strings.Join(parts[:i+1], "/")
+	}
+
+	return folders
+}
+
+// Match reports whether the document matches the query
+func Match(doc Document, query Query) bool {
+	words := documentWords(doc)
+	for _, queryWord := range Words(query.Text) {
+		if !hasPrefix(words, queryWord) {
+			return false
+		}
+	}
+
+	for _, tag := range query.Tags {
+		if !contains(doc.Tags, tag) {
+			return false
+		}
+	}
+
+	return query.Folder == "" || contains(Folders(doc.Folder), query.Folder)
+}
+
+// Sort orders documents by id and keeps at most limit of them, all if limit is 0
+func Sort(docs []Document, limit int) []Document {
+	sort.Slice(docs, func(i, j int) bool {
+		return docs[i].ID < docs[j].ID
+	})
+
+	if limit > 0 && len(docs) > limit {
+		docs = docs[:limit]
+	}
+
+	return docs
+}
+
+// NormalizeTags lowercases, deduplicates and validates tags
+func NormalizeTags(tags []string) ([]string, error) {
+	if len(tags) > maxTags {
+		return nil, fmt.Errorf("at most %d tags are allowed", maxTags)
+	}
+
+	var normalized []string
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if !tagPattern.MatchString(tag) {
+			return nil, fmt.Errorf("invalid tag [%s]", tag)
+		}
+
+		if !contains(normalized, tag) {
+			normalized = append(normalized, tag)
+		}
+	}
+
+	return normalized, nil
+}
+
+// NormalizeFolder lowercases and validates a folder path like marketing/2024
+func NormalizeFolder(folder string) (string, error) {
+	folder = strings.Trim(strings.ToLower(strings.TrimSpace(folder)), "/")
+	if folder == "" {
+		return "", nil
+	}
+
+	if len(folder) > 128 || !folderPattern.MatchString(folder) {
+		return "", errors.New("folder must be a path of letters, digits, dashes and underscores")
+	}
+
+	return folder, nil
+}
+
+func documentWords(doc Document) []string {
+	words := Words(doc.LongURL)
+	words = append(words, Words(doc.Title)...)
+	words = append(words, Words(doc.Description)...)
+	for _, tag := range doc.Tags {
+		words = append(words, Words(tag)...)
+	}
+
+	return words
+}
+
+func hasPrefix(words []string, prefix string) bool {
+	for _, word := range words {
+		if strings.HasPrefix(word, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
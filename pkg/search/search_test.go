@@ -0,0 +1,116 @@
+package search_test
+
+import (
+	"context"
+	"url-shortener/pkg/search"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Search", func() {
+	var (
+		launch = search.Document{
+			ID:      "a",
+			LongURL: "https://example.com/spring-launch",
+			Title:   "Spring Launch",
+			Tags:    []string{"marketing", "2024"},
+			Folder:  "campaigns/spring",
+		}
+		docs = search.Document{
+			ID:          "b",
+			LongURL:     "https://docs.example.com/guides",
+			Description: "Developer guides",
+			Tags:        []string{"docs"},
+		}
+	)
+
+	When("matching documents", func() {
+		It("should match word prefixes of the long url, title, description and tags", func() {
+			Expect(search.Match(launch, search.Query{Text: "spring"})).To(BeTrue())
+			Expect(search.Match(launch, search.Query{Text: "laun MARK"})).To(BeTrue())
+			Expect(search.Match(docs, search.Query{Text: "developer"})).To(BeTrue())
+			Expect(search.Match(launch, search.Query{Text: "spring guides"})).To(BeFalse())
+		})
+
+		It("should match all tags and the folder with its subfolders", func() {
+			Expect(search.Match(launch, search.Query{Tags: []string{"marketing", "2024"}})).To(BeTrue())
+			Expect(search.Match(launch, search.Query{Tags: []string{"marketing", "docs"}})).To(BeFalse())
+			Expect(search.Match(launch, search.Query{Folder: "campaigns"})).To(BeTrue())
+			Expect(search.Match(launch, search.Query{Folder: "campaigns/spring"})).To(BeTrue())
+			Expect(search.Match(launch, search.Query{Folder: "camp"})).To(BeFalse())
+		})
+	})
+
+	When("computing index terms", func() {
+		It("should contain the prefixes of every word up to the maximum length", func() {
+			terms := search.Terms(search.Document{LongURL: "https://example.com/internationalization"})
+			Expect(terms).To(ContainElements("h", "https", "ex", "example", "internationa"))
+			Expect(terms).ToNot(ContainElement("internationalization"))
+			Expect(search.Term("internationalization")).To(Equal("internationa"))
+		})
+	})
+
+	When("normalizing tags and folders", func() {
+		It("should lowercase and deduplicate valid values", func() {
+			Expect(search.NormalizeTags([]string{" Marketing", "marketing", "q1"})).To(Equal([]string{"marketing", "q1"}))
+			Expect(search.NormalizeFolder("/Campaigns/Spring/")).To(Equal("campaigns/spring"))
+			Expect(search.Folders("campaigns/spring")).To(Equal([]string{"campaigns", "campaigns/spring"}))
+		})
+
+		It("should reject invalid values", func() {
+			_, err := search.NormalizeTags([]string{"two words"})
+			Expect(err).To(HaveOccurred())
+
+			_, err = search.NormalizeFolder("campaigns//spring")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	When("searching the memory index", func() {
+		var index *search.MemoryIndex
+
+		BeforeEach(func() {
+			index = search.NewMemoryIndex()
+			ctx := context.Background()
+			Expect(index.Put(ctx, docs)).To(Succeed())
+			Expect(index.Put(ctx, launch)).To(Succeed())
+		})
+
+		It("should return matching documents ordered by id", func() {
+			found, err := index.Search(context.Background(), search.Query{Text: "example"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(found).To(Equal([]search.Document{launch, docs}))
+
+			found, err = index.Search(context.Background(), search.Query{Tags: []string{"docs"}})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(found).To(Equal([]search.Document{docs}))
+		})
+
+		It("should limit the results", func() {
+			found, err := index.Search(context.Background(), search.Query{Limit: 1})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(found).To(Equal([]search.Document{launch}))
+		})
+
+		It("should replace documents with the same id", func() {
+			retagged := launch
+			retagged.Tags = []string{"sales"}
+			Expect(index.Put(context.Background(), retagged)).To(Succeed())
+
+			found, err := index.Search(context.Background(), search.Query{Text: "marketing"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(found).To(BeEmpty())
+			Expect(index.Len()).To(Equal(2))
+		})
+
+		It("should not find deleted documents", func() {
+			Expect(index.Delete(context.Background(), launch.ID)).To(Succeed())
+
+			found, err := index.Search(context.Background(), search.Query{Text: "example"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(found).To(Equal([]search.Document{docs}))
+			Expect(index.Len()).To(Equal(1))
+		})
+	})
+})
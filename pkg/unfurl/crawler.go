@@ -0,0 +1,35 @@
+package unfurl
+
+import "strings"
+
+// crawlers are user agent fragments of link preview bots, search engine crawlers are redirected like visitors
+var crawlers = []string{
+	"slackbot",
+	"slack-imgproxy",
+	"twitterbot",
+	"facebookexternalhit",
+	"facebookcatalog",
+	"linkedinbot",
+	"discordbot",
+	"telegrambot",
+	"whatsapp",
+	"skypeuripreview",
+	"mattermost",
+	"embedly",
+	"pinterestbot",
+	"redditbot",
+	"vkshare",
+	"iframely",
+}
+
+// IsCrawler reports whether the user agent belongs to a bot fetching link previews
+func IsCrawler(userAgent string) bool {
+	userAgent = strings.ToLower(userAgent)
+	for _, crawler := range crawlers {
+		if strings.Contains(userAgent, crawler) {
+			return true
+		}
+	}
+
+	return false
+}
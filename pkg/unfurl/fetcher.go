@@ -0,0 +1,61 @@
+package unfurl
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	netURL "net/url"
+	"time"
+)
+
+const userAgent = "url-shortener-unfurl/1.0"
+
+// Fetcher reads the previews of destination pages
+type Fetcher struct {
+	client   *http.Client
+	maxBytes int64
+}
+
+// NewFetcher is a constructor function, at most maxBytes of a page are read
+// The client must not reach internal addresses, see httpclient.New
+func NewFetcher(client *http.Client, maxBytes int64) *Fetcher {
+	return &Fetcher{client: client, maxBytes: maxBytes}
+}
+
+// Fetch downloads the page and returns its preview
+// Pages which are not HTML or not successful return an error
+func (f *Fetcher) Fetch(ctx context.Context, url string) (Preview, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return Preview{}, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	request.Header.Set("User-Agent", userAgent)
+	request.Header.Set("Accept", "text/html,application/xhtml+xml")
+	response, err := f.client.Do(request)
+	if err != nil {
+		return Preview{}, fmt.Errorf("failed to get page: %w", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return Preview{}, fmt.Errorf("unexpected status [%d]", response.StatusCode)
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(response.Header.Get("Content-Type"))
+	if mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		return Preview{}, fmt.Errorf("unexpected content type [%s]", mediaType)
+	}
+
+	preview := Parse(io.LimitReader(response.Body, f.maxBytes), response.Request.URL)
+	preview.FetchedAt = time.Now().UTC()
+	return preview, nil
+}
+
+// IsWebURL reports whether the URL can be fetched
+func IsWebURL(url string) bool {
+	parsed, err := netURL.Parse(url)
+	return err == nil && (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != ""
+}
@@ -0,0 +1,92 @@
+package unfurl_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"time"
+	"url-shortener/pkg/httpclient"
+	"url-shortener/pkg/unfurl"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Fetcher", func() {
+	var (
+		ctx     context.Context
+		server  *httptest.Server
+		handler http.HandlerFunc
+		fetcher *unfurl.Fetcher
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			handler(w, r)
+		}))
+		fetcher = unfurl.NewFetcher(server.Client(), 1<<20)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	When("the page is HTML", func() {
+		BeforeEach(func() {
+			handler = func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/html; charset=utf-8")
+				w.Write([]byte(`<head><title>Hello</title><meta property="og:image" content="/a.png"></head>`))
+			}
+		})
+
+		It("should return its preview", func() {
+			preview, err := fetcher.Fetch(ctx, server.URL+"/page")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(preview.Title).To(Equal("Hello"))
+			Expect(preview.Image).To(Equal(server.URL + "/a.png"))
+			Expect(preview.FetchedAt).To(BeTemporally("~", time.Now(), time.Minute))
+		})
+	})
+
+	When("the page is not HTML", func() {
+		BeforeEach(func() {
+			handler = func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/pdf")
+			}
+		})
+
+		It("should return an error", func() {
+			_, err := fetcher.Fetch(ctx, server.URL)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	When("the page fails", func() {
+		BeforeEach(func() {
+			handler = func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNotFound)
+			}
+		})
+
+		It("should return an error", func() {
+			_, err := fetcher.Fetch(ctx, server.URL)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	When("the destination is a private address", func() {
+		BeforeEach(func() {
+			handler = func(w http.ResponseWriter, r *http.Request) {
+				Fail("request reached the server")
+			}
+			fetcher = unfurl.NewFetcher(httpclient.New(httpclient.Config{Timeout: time.Second}), 1<<20)
+		})
+
+		It("should refuse to connect", func() {
+			_, err := fetcher.Fetch(ctx, server.URL)
+			Expect(errors.Is(err, httpclient.ErrForbiddenAddress)).To(BeTrue())
+		})
+	})
+})
@@ -0,0 +1,76 @@
+package unfurl
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Save stores the fetched preview of a link
+type Save func(ctx context.Context, preview Preview) error
+
+type job struct {
+	url  string
+	save Save
+}
+
+// Queue fetches previews in the background, so creating a link never waits for its destination
+type Queue struct {
+	fetcher *Fetcher
+	jobs    chan job
+}
+
+// NewQueue is a constructor function, at most size previews wait to be fetched
+func NewQueue(fetcher *Fetcher, size int) *Queue {
+	return &Queue{fetcher: fetcher, jobs: make(chan job, size)}
+}
+
+// Enqueue schedules fetching the preview of the URL, the preview is passed to save unless it is empty
+// The preview is dropped and false returned if the queue is full
+func (q *Queue) Enqueue(url string, save Save) bool {
+	if !IsWebURL(url) {
+		return false
+	}
+
+	select {
+	case q.jobs <- job{url: url, save: save}:
+		return true
+	default:
+		logrus.Warnf("unfurl queue is full, dropping preview of [%s]", url)
+		return false
+	}
+}
+
+// Run fetches queued previews with the given number of workers until ctx is done
+func (q *Queue) Run(ctx context.Context, workers int) {
+	for i := 0; i < workers; i++ {
+		go q.work(ctx)
+	}
+}
+
+func (q *Queue) work(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case job := <-q.jobs:
+			q.fetch(ctx, job)
+		}
+	}
+}
+
+func (q *Queue) fetch(ctx context.Context, job job) {
+	preview, err := q.fetcher.Fetch(ctx, job.url)
+	if err != nil {
+		logrus.Debugf("failed to fetch preview of [%s]: %v", job.url, err)
+		return
+	}
+
+	if preview.Empty() {
+		return
+	}
+
+	if err := job.save(ctx, preview); err != nil {
+		logrus.Warnf("failed to save preview of [%s]: %v", job.url, err)
+	}
+}
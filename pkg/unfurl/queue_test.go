@@ -0,0 +1,72 @@
+package unfurl_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"url-shortener/pkg/unfurl"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Queue", func() {
+	var (
+		ctx        context.Context
+		cancelFunc context.CancelFunc
+		server     *httptest.Server
+		queue      *unfurl.Queue
+		saved      chan unfurl.Preview
+		save       unfurl.Save
+	)
+
+	BeforeEach(func() {
+		ctx, cancelFunc = context.WithCancel(context.Background())
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/html")
+			if r.URL.Path == "/titled" {
+				w.Write([]byte(`<title>Titled</title>`))
+			}
+		}))
+		queue = unfurl.NewQueue(unfurl.NewFetcher(server.Client(), 1<<20), 1)
+		saved = make(chan unfurl.Preview, 1)
+		save = func(ctx context.Context, preview unfurl.Preview) error {
+			saved <- preview
+			return nil
+		}
+	})
+
+	AfterEach(func() {
+		cancelFunc()
+		server.Close()
+	})
+
+	When("a preview is fetched", func() {
+		It("should save it", func() {
+			Expect(queue.Enqueue(server.URL+"/titled", save)).To(BeTrue())
+			queue.Run(ctx, 1)
+			Eventually(saved).Should(Receive(HaveField("Title", "Titled")))
+		})
+	})
+
+	When("the page has no preview", func() {
+		It("should not save it", func() {
+			Expect(queue.Enqueue(server.URL+"/empty", save)).To(BeTrue())
+			queue.Run(ctx, 1)
+			Consistently(saved).ShouldNot(Receive())
+		})
+	})
+
+	When("the queue is full", func() {
+		It("should drop the preview", func() {
+			Expect(queue.Enqueue(server.URL+"/titled", save)).To(BeTrue())
+			Expect(queue.Enqueue(server.URL+"/titled", save)).To(BeFalse())
+		})
+	})
+
+	When("the URL is not a web URL", func() {
+		It("should not enqueue it", func() {
+			Expect(queue.Enqueue("mailto:someone@example.com", save)).To(BeFalse())
+		})
+	})
+})
@@ -0,0 +1,13 @@
+package unfurl_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestUnfurl(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Unfurl Suite")
+}
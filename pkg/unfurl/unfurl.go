@@ -0,0 +1,140 @@
+package unfurl
+
+import (
+	"io"
+	netURL "net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+const (
+	maxTitleLength       = 200
+	maxDescriptionLength = 1000
+	maxImageLength       = 2048
+)
+
+// Preview describes the destination page of a link, it is shown by chat apps and social networks
+type Preview struct {
+	Title       string    `firestore:"title,omitempty" json:"title,omitempty"`
+	Description string    `firestore:"description,omitempty" json:"description,omitempty"`
+	Image       string    `firestore:"image,omitempty" json:"image,omitempty"`
+	FetchedAt   time.Time `firestore:"fetched_at" json:"fetched_at"`
+}
+
+// Empty reports whether the page described nothing worth showing
+func (p Preview) Empty() bool {
+	return p.Title == "" && p.Description == "" && p.Image == ""
+}
+
+// Parse reads the title, description and image of an HTML page, OpenGraph and Twitter tags are preferred
+// over the title element and the description meta tag. Relative image URLs are resolved against base
+// Parsing stops at the end of the head, the body is never read
+func Parse(r io.Reader, base *netURL.URL) Preview {
+	meta := map[string]string{}
+	var title strings.Builder
+	inTitle := false
+
+	tokenizer := html.NewTokenizer(r)
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			return preview(meta, title.String(), base)
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, hasAttr := tokenizer.TagName()
+			switch atom.Lookup(name) {
+			case atom.Title:
+				inTitle = true
+			case atom.Meta:
+				if hasAttr {
+					key, content := metaAttributes(tokenizer)
+					if _, ok := meta[key]; !ok && key != "" {
+						meta[key] = content
+					}
+				}
+			case atom.Body:
+				return preview(meta, title.String(), base)
+			}
+		case html.EndTagToken:
+			name, _ := tokenizer.TagName()
+			switch atom.Lookup(name) {
+			case atom.Title:
+				inTitle = false
+			case atom.Head:
+				return preview(meta, title.String(), base)
+			}
+		case html.TextToken:
+			if inTitle {
+				title.Write(tokenizer.Text())
+			}
+		}
+	}
+}
+
+// metaAttributes returns the lowercase property or name of a meta tag and its content
+func metaAttributes(tokenizer *html.Tokenizer) (key, content string) {
+	for {
+		name, value, more := tokenizer.TagAttr()
+		switch string(name) {
+		case "property", "name":
+			if key == "" {
+				key = strings.ToLower(strings.TrimSpace(string(value)))
+			}
+		case "content":
+			content = string(value)
+		}
+
+		if !more {
+			return key, content
+		}
+	}
+}
+
+func preview(meta map[string]string, title string, base *netURL.URL) Preview {
+	return Preview{
+		Title:       clean(first(meta["og:title"], meta["twitter:title"], title), maxTitleLength),
+		Description: clean(first(meta["og:description"], meta["twitter:description"], meta["description"]), maxDescriptionLength),
+		Image:       image(first(meta["og:image:secure_url"], meta["og:image"], meta["twitter:image"]), base),
+	}
+}
+
+func first(values ...string) string {
+	for _, value := range values {
+		if strings.TrimSpace(value) != "" {
+			return value
+		}
+	}
+
+	return ""
+}
+
+// clean collapses whitespace and cuts the text to at most limit characters
+func clean(text string, limit int) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if utf8.RuneCountInString(text) <= limit {
+		return text
+	}
+
+	return string([]rune(text)[:limit])
+}
+
+// image returns the absolute http(s) URL of the image, or empty string if it is not one
+func image(value string, base *netURL.URL) string {
+	ref, err := netURL.Parse(strings.TrimSpace(value))
+	if err != nil || value == "" {
+		return ""
+	}
+
+	if base != nil {
+		ref = base.ResolveReference(ref)
+	}
+
+	if (ref.Scheme != "http" && ref.Scheme != "https") || ref.Host == "" || len(ref.String()) > maxImageLength {
+		return ""
+	}
+
+	return ref.String()
+}
@@ -0,0 +1,79 @@
+package unfurl_test
+
+import (
+	netURL "net/url"
+	"strings"
+	"url-shortener/pkg/unfurl"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Unfurl", func() {
+	var base *netURL.URL
+
+	BeforeEach(func() {
+		var err error
+		base, err = netURL.Parse("https://example.com/blog/post")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	When("parsing a page with OpenGraph tags", func() {
+		const page = `<!DOCTYPE html><html><head>
+<title>Fallback title</title>
+<meta name="description" content="Fallback description">
+<meta property="og:title" content="  Spring   sale ">
+<meta property="og:description" content="Everything must go">
+<meta property="og:image" content="/images/sale.png">
+</head><body><meta property="og:title" content="Ignored"></body></html>`
+
+		It("should prefer them", func() {
+			preview := unfurl.Parse(strings.NewReader(page), base)
+			Expect(preview.Title).To(Equal("Spring sale"))
+			Expect(preview.Description).To(Equal("Everything must go"))
+			Expect(preview.Image).To(Equal("https://example.com/images/sale.png"))
+		})
+	})
+
+	When("parsing a page without OpenGraph tags", func() {
+		const page = `<html><head><title>Plain &amp; simple</title><meta name="Description" content="A page"></head></html>`
+
+		It("should use the title and description", func() {
+			preview := unfurl.Parse(strings.NewReader(page), base)
+			Expect(preview).To(Equal(unfurl.Preview{Title: "Plain & simple", Description: "A page"}))
+		})
+	})
+
+	When("the image is not a web URL", func() {
+		const page = `<head><meta property="og:image" content="javascript:alert(1)"></head>`
+
+		It("should drop it", func() {
+			Expect(unfurl.Parse(strings.NewReader(page), base).Image).To(BeEmpty())
+		})
+	})
+
+	When("the title is too long", func() {
+		It("should cut it", func() {
+			page := "<title>" + strings.Repeat("ä", 300) + "</title>"
+			Expect([]rune(unfurl.Parse(strings.NewReader(page), base).Title)).To(HaveLen(200))
+		})
+	})
+
+	When("the page has no metadata", func() {
+		It("should return an empty preview", func() {
+			Expect(unfurl.Parse(strings.NewReader("<p>hello</p>"), base).Empty()).To(BeTrue())
+		})
+	})
+
+	When("detecting crawlers", func() {
+		It("should recognize link preview bots", func() {
+			Expect(unfurl.IsCrawler("Slackbot-LinkExpanding 1.0 (+https://api.slack.com/robots)")).To(BeTrue())
+			Expect(unfurl.IsCrawler("Twitterbot/1.0")).To(BeTrue())
+			Expect(unfurl.IsCrawler("facebookexternalhit/1.1")).To(BeTrue())
+		})
+
+		It("should not recognize browsers", func() {
+			Expect(unfurl.IsCrawler("Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 Safari/605.1.15")).To(BeFalse())
+		})
+	})
+})
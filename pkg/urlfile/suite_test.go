@@ -0,0 +1,13 @@
+package urlfile_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestURLFile(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "URL File Suite")
+}
@@ -0,0 +1,74 @@
+package urlfile
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type jsonRecord struct {
+	LongURL string `json:"long_url"`
+}
+
+// Read reads long URLs from a file, the format is chosen by its extension
+// CSV files use the first column and may start with a long_url header, JSONL files contain a long_url field per line
+func Read(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return readCSV(file)
+	case ".jsonl", ".ndjson":
+		return readJSONL(file)
+	default:
+		return nil, fmt.Errorf("unsupported file format [%s]", filepath.Ext(path))
+	}
+}
+
+func readCSV(reader io.Reader) ([]string, error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.FieldsPerRecord = -1
+	records, err := csvReader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read csv: %w", err)
+	}
+
+	var longURLs []string
+	for i, record := range records {
+		longURL := strings.TrimSpace(record[0])
+		if longURL == "" || (i == 0 && longURL == "long_url") {
+			continue
+		}
+
+		longURLs = append(longURLs, longURL)
+	}
+
+	return longURLs, nil
+}
+
+func readJSONL(reader io.Reader) ([]string, error) {
+	var longURLs []string
+	decoder := json.NewDecoder(reader)
+	for {
+		var record jsonRecord
+		if err := decoder.Decode(&record); err != nil {
+			if err == io.EOF {
+				return longURLs, nil
+			}
+
+			return nil, fmt.Errorf("failed to read jsonl: %w", err)
+		}
+
+		if record.LongURL != "" {
+			longURLs = append(longURLs, record.LongURL)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package urlfile_test
+
+import (
+	"os"
+	"path/filepath"
+	"url-shortener/pkg/urlfile"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Read", func() {
+	const (
+		firstURL  = "https://first.com"
+		secondURL = "https://second.com"
+	)
+
+	var dir string
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+	})
+
+	writeFile := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		Expect(os.WriteFile(path, []byte(content), 0o644)).To(Succeed())
+		return path
+	}
+
+	When("reading a csv file", func() {
+		It("should skip the header and return the first column", func() {
+			path := writeFile("urls.csv", "long_url,comment\nhttps://first.com,a\n\nhttps://second.com,b\n")
+			longURLs, err := urlfile.Read(path)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(longURLs).To(Equal([]string{firstURL, secondURL}))
+		})
+	})
+
+	When("reading a jsonl file", func() {
+		It("should return the long url of each line", func() {
+			path := writeFile("urls.jsonl", `{"long_url": "https://first.com"}`+"\n"+`{"long_url": "https://second.com"}`+"\n")
+			longURLs, err := urlfile.Read(path)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(longURLs).To(Equal([]string{firstURL, secondURL}))
+		})
+	})
+
+	When("reading a file with unsupported format", func() {
+		It("should return an error", func() {
+			path := writeFile("urls.txt", firstURL)
+			_, err := urlfile.Read(path)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,13 @@
+package variants_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestVariants(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Variants Suite")
+}
@@ -0,0 +1,91 @@
+package variants
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+)
+
+// Variant is one of the weighted destinations of an URL
+type Variant struct {
+	Name        string `firestore:"name" json:"name"`
+	Destination string `firestore:"destination" json:"destination"`
+	// Weight is relative to the weights of the other variants, zero stops assigning the variant
+	Weight int `firestore:"weight" json:"weight"`
+}
+
+// Validate checks that the variants have unique names, destinations and at least one positive weight
+func Validate(variants []Variant) error {
+	total := 0
+	names := make(map[string]struct{}, len(variants))
+	for _, variant := range variants {
+		if variant.Name == "" {
+			return errors.New("variant has no name")
+		}
+
+		if _, ok := names[variant.Name]; ok {
+			return fmt.Errorf("variant [%s] is duplicated", variant.Name)
+		}
+
+		names[variant.Name] = struct{}{}
+
+		if variant.Destination == "" {
+			return fmt.Errorf("variant [%s] has no destination", variant.Name)
+		}
+
+		if variant.Weight < 0 {
+			return fmt.Errorf("variant [%s] has negative weight", variant.Name)
+		}
+
+		total += variant.Weight
+	}
+
+	if total == 0 {
+		return errors.New("variants need a positive weight")
+	}
+
+	return nil
+}
+
+// Find returns the variant with the name if it can still be assigned
+func Find(variants []Variant, name string) (Variant, bool) {
+	for _, variant := range variants {
+		if variant.Name == name && variant.Weight > 0 {
+			return variant, true
+		}
+	}
+
+	return Variant{}, false
+}
+
+// Pick chooses a variant at random proportionally to the weights
+// It returns false if no variant has a positive weight
+func Pick(variants []Variant, random *rand.Rand) (Variant, bool) {
+	total := 0
+	for _, variant := range variants {
+		total += variant.Weight
+	}
+
+	if total <= 0 {
+		return Variant{}, false
+	}
+
+	n := rand.Intn(total)
+	if random != nil {
+		n = random.Intn(total)
+	}
+
+	for _, variant := range variants {
+		if variant.Weight <= 0 {
+			continue
+		}
+
+		if n < variant.Weight {
+			return variant, true
+		}
+
+		n -= variant.Weight
+	}
+
+	return Variant{}, false
+}
@@ -0,0 +1,70 @@
+package variants_test
+
+import (
+	"math/rand"
+	"url-shortener/pkg/variants"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Variants", func() {
+	var abTest []variants.Variant
+
+	BeforeEach(func() {
+		abTest = []variants.Variant{
+			{Name: "a", Destination: "https://example.com/a", Weight: 3},
+			{Name: "b", Destination: "https://example.com/b", Weight: 1},
+			{Name: "c", Destination: "https://example.com/c", Weight: 0},
+		}
+	})
+
+	When("validating variants", func() {
+		It("should accept valid variants", func() {
+			Expect(variants.Validate(abTest)).To(Succeed())
+		})
+
+		It("should reject invalid variants", func() {
+			Expect(variants.Validate(nil)).ToNot(Succeed())
+			Expect(variants.Validate([]variants.Variant{{Destination: "https://example.com", Weight: 1}})).ToNot(Succeed())
+			Expect(variants.Validate([]variants.Variant{{Name: "a", Weight: 1}})).ToNot(Succeed())
+			Expect(variants.Validate([]variants.Variant{{Name: "a", Destination: "https://example.com", Weight: -1}})).ToNot(Succeed())
+			Expect(variants.Validate([]variants.Variant{{Name: "a", Destination: "https://example.com"}})).ToNot(Succeed())
+			Expect(variants.Validate(append(abTest, abTest[0]))).ToNot(Succeed())
+		})
+	})
+
+	When("picking variants", func() {
+		It("should follow the weights and never pick a variant without weight", func() {
+			random := rand.New(rand.NewSource(1))
+			picks := map[string]int{}
+			for i := 0; i < 4000; i++ {
+				variant, ok := variants.Pick(abTest, random)
+				Expect(ok).To(BeTrue())
+				picks[variant.Name]++
+			}
+
+			Expect(picks["a"]).To(BeNumerically("~", 3000, 150))
+			Expect(picks["b"]).To(BeNumerically("~", 1000, 150))
+			Expect(picks).ToNot(HaveKey("c"))
+		})
+
+		It("should pick nothing if no variant has weight", func() {
+			_, ok := variants.Pick([]variants.Variant{{Name: "a"}}, nil)
+			Expect(ok).To(BeFalse())
+		})
+	})
+
+	When("finding a variant by name", func() {
+		It("should only return variants which can be assigned", func() {
+			variant, ok := variants.Find(abTest, "b")
+			Expect(ok).To(BeTrue())
+			Expect(variant.Destination).To(Equal("https://example.com/b"))
+
+			_, ok = variants.Find(abTest, "c")
+			Expect(ok).To(BeFalse())
+			_, ok = variants.Find(abTest, "unknown")
+			Expect(ok).To(BeFalse())
+		})
+	})
+})
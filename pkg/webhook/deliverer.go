@@ -0,0 +1,75 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const userAgent = "url-shortener-webhook/1.0"
+
+// Delivery is an event to send to the URL of a subscription
+type Delivery struct {
+	ID      string
+	URL     string
+	Secret  string
+	Event   string
+	Payload []byte
+	// Attempts is the number of failed attempts so far
+	Attempts int
+}
+
+// Attempt is the outcome of sending a delivery once
+type Attempt struct {
+	At time.Time `firestore:"at" json:"at"`
+	// Status is the HTTP status of the response, 0 if there was none
+	Status   int    `firestore:"status,omitempty" json:"status,omitempty"`
+	Error    string `firestore:"error,omitempty" json:"error,omitempty"`
+	Duration int64  `firestore:"duration_ms" json:"duration_ms"`
+}
+
+// OK reports whether the receiver accepted the delivery
+func (a Attempt) OK() bool {
+	return a.Error == "" && a.Status >= 200 && a.Status < 300
+}
+
+// Deliverer posts signed deliveries
+type Deliverer struct {
+	client *http.Client
+}
+
+// NewDeliverer is a constructor function, the client must not reach internal addresses, see httpclient.New
+func NewDeliverer(client *http.Client) *Deliverer {
+	return &Deliverer{client: client}
+}
+
+// Deliver posts the payload of the delivery with its signature and returns the attempt
+func (d *Deliverer) Deliver(ctx context.Context, delivery Delivery) (attempt Attempt) {
+	attempt.At = time.Now().UTC()
+	defer func() {
+		attempt.Duration = time.Since(attempt.At).Milliseconds()
+	}()
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		attempt.Error = fmt.Sprintf("failed to create request: %v", err)
+		return attempt
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("User-Agent", userAgent)
+	request.Header.Set(EventHeader, delivery.Event)
+	request.Header.Set(DeliveryHeader, delivery.ID)
+	request.Header.Set(SignatureHeader, Sign(delivery.Secret, attempt.At, delivery.Payload))
+	response, err := d.client.Do(request)
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt
+	}
+
+	response.Body.Close()
+	attempt.Status = response.StatusCode
+	return attempt
+}
@@ -0,0 +1,64 @@
+package webhook_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"time"
+	"url-shortener/pkg/webhook"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Deliverer", func() {
+	var (
+		ctx       context.Context
+		deliverer *webhook.Deliverer
+		delivery  webhook.Delivery
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		deliverer = webhook.NewDeliverer(http.DefaultClient)
+		delivery = webhook.Delivery{ID: "d1", Secret: "secret", Event: webhook.LinkCreated, Payload: []byte(`{"short_url":"b"}`)}
+	})
+
+	It("should post the signed payload", func() {
+		var received *http.Request
+		var body []byte
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			received = r
+			body, _ = io.ReadAll(r.Body)
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer server.Close()
+		delivery.URL = server.URL
+
+		attempt := deliverer.Deliver(ctx, delivery)
+
+		Expect(attempt.OK()).To(BeTrue())
+		Expect(attempt.Status).To(Equal(http.StatusNoContent))
+		Expect(body).To(Equal(delivery.Payload))
+		Expect(received.Header.Get(webhook.EventHeader)).To(Equal(webhook.LinkCreated))
+		Expect(received.Header.Get(webhook.DeliveryHeader)).To(Equal("d1"))
+		Expect(webhook.Verify("secret", received.Header.Get(webhook.SignatureHeader), body, time.Now(), time.Minute)).To(Succeed())
+	})
+
+	It("should fail on error statuses and unreachable receivers", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadGateway)
+		}))
+		delivery.URL = server.URL
+
+		attempt := deliverer.Deliver(ctx, delivery)
+		Expect(attempt.OK()).To(BeFalse())
+		Expect(attempt.Status).To(Equal(http.StatusBadGateway))
+
+		server.Close()
+		attempt = deliverer.Deliver(ctx, delivery)
+		Expect(attempt.OK()).To(BeFalse())
+		Expect(attempt.Error).NotTo(BeEmpty())
+	})
+})
@@ -0,0 +1,106 @@
+package webhook
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Outbox keeps deliveries in the store until they are sent, so they survive restarts
+type Outbox interface {
+	// Claim returns up to limit due deliveries and hides them from other claims for the lease
+	Claim(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]Delivery, error)
+	// Record stores the attempt, the delivery is retried at retryAt unless it is zero
+	Record(ctx context.Context, id string, attempt Attempt, retryAt time.Time) error
+}
+
+// Config holds the dispatcher settings
+type Config struct {
+	// Interval is how often the outbox is polled when it is empty
+	Interval time.Duration
+	// Lease is how long a claimed delivery is hidden, it must be longer than a delivery takes
+	Lease     time.Duration
+	BatchSize int
+	Workers   int
+	// MaxAttempts failed attempts give a delivery up
+	MaxAttempts int
+	// BaseBackoff is the delay after the first failed attempt, it doubles per attempt up to MaxBackoff
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+}
+
+// Dispatcher sends the deliveries of the outbox and retries failed ones with exponential backoff
+type Dispatcher struct {
+	outbox    Outbox
+	deliverer *Deliverer
+	config    Config
+}
+
+// NewDispatcher is a constructor function
+func NewDispatcher(outbox Outbox, deliverer *Deliverer, config Config) *Dispatcher {
+	return &Dispatcher{outbox: outbox, deliverer: deliverer, config: config}
+}
+
+// Run sends due deliveries until ctx is done
+func (d *Dispatcher) Run(ctx context.Context) {
+	for {
+		sent, err := d.DispatchDue(ctx)
+		if err != nil {
+			logrus.Errorf("failed to dispatch webhooks: %v", err)
+		}
+
+		if sent == d.config.BatchSize {
+			continue
+		}
+
+		timer := time.NewTimer(d.config.Interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// DispatchDue claims a batch of due deliveries, sends them and returns their number
+func (d *Dispatcher) DispatchDue(ctx context.Context) (int, error) {
+	deliveries, err := d.outbox.Claim(ctx, time.Now(), d.config.Lease, d.config.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	jobs := make(chan Delivery)
+	var wg sync.WaitGroup
+	for i := 0; i < d.config.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for delivery := range jobs {
+				d.dispatch(ctx, delivery)
+			}
+		}()
+	}
+
+	for _, delivery := range deliveries {
+		jobs <- delivery
+	}
+
+	close(jobs)
+	wg.Wait()
+	return len(deliveries), nil
+}
+
+func (d *Dispatcher) dispatch(ctx context.Context, delivery Delivery) {
+	attempt := d.deliverer.Deliver(ctx, delivery)
+	var retryAt time.Time
+	if !attempt.OK() && delivery.Attempts+1 < d.config.MaxAttempts {
+		retryAt = attempt.At.Add(Backoff(delivery.Attempts+1, d.config.BaseBackoff, d.config.MaxBackoff))
+	}
+
+	if err := d.outbox.Record(ctx, delivery.ID, attempt, retryAt); err != nil {
+		logrus.Errorf("failed to record webhook delivery [%s]: %v", delivery.ID, err)
+	}
+}
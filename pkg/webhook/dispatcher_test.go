@@ -0,0 +1,89 @@
+package webhook_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+	"url-shortener/pkg/webhook"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type recorded struct {
+	attempt webhook.Attempt
+	retryAt time.Time
+}
+
+type fakeOutbox struct {
+	mu         sync.Mutex
+	deliveries []webhook.Delivery
+	records    map[string]recorded
+}
+
+func (o *fakeOutbox) Claim(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]webhook.Delivery, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	claimed := o.deliveries
+	o.deliveries = nil
+	return claimed, nil
+}
+
+func (o *fakeOutbox) Record(ctx context.Context, id string, attempt webhook.Attempt, retryAt time.Time) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.records[id] = recorded{attempt: attempt, retryAt: retryAt}
+	return nil
+}
+
+var _ = Describe("Dispatcher", func() {
+	var (
+		ctx        context.Context
+		outbox     *fakeOutbox
+		server     *httptest.Server
+		dispatcher *webhook.Dispatcher
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		outbox = &fakeOutbox{records: map[string]recorded{}}
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/down" {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}
+		}))
+		dispatcher = webhook.NewDispatcher(outbox, webhook.NewDeliverer(http.DefaultClient), webhook.Config{
+			Interval:    time.Second,
+			Lease:       time.Minute,
+			BatchSize:   10,
+			Workers:     2,
+			MaxAttempts: 3,
+			BaseBackoff: time.Minute,
+			MaxBackoff:  time.Hour,
+		})
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should finish delivered ones and schedule retries of failed ones with backoff", func() {
+		outbox.deliveries = []webhook.Delivery{
+			{ID: "ok", URL: server.URL + "/up"},
+			{ID: "retry", URL: server.URL + "/down", Attempts: 1},
+			{ID: "give-up", URL: server.URL + "/down", Attempts: 2},
+		}
+
+		Expect(dispatcher.DispatchDue(ctx)).To(Equal(3))
+
+		Expect(outbox.records["ok"].attempt.OK()).To(BeTrue())
+		Expect(outbox.records["ok"].retryAt.IsZero()).To(BeTrue())
+		retry := outbox.records["retry"]
+		Expect(retry.attempt.Status).To(Equal(http.StatusServiceUnavailable))
+		Expect(retry.retryAt.Sub(retry.attempt.At)).To(BeNumerically("~", 2*time.Minute, 12*time.Second))
+		Expect(outbox.records["give-up"].attempt.OK()).To(BeFalse())
+		Expect(outbox.records["give-up"].retryAt.IsZero()).To(BeTrue())
+	})
+})
@@ -0,0 +1,119 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Event types of links
+const (
+	LinkCreated = "link.created"
+	LinkUpdated = "link.updated"
+	LinkDeleted = "link.deleted"
+	LinkExpired = "link.expired"
+	// LinkClicks is sent when the counted clicks of a link reach a threshold
+	LinkClicks = "link.clicks"
+	// LinkExhausted is sent when a link reaches its maximum number of clicks
+	LinkExhausted = "link.exhausted"
+)
+
+// Types are all event types a subscription can select
+var Types = []string{LinkCreated, LinkUpdated, LinkDeleted, LinkExpired, LinkClicks, LinkExhausted}
+
+// Headers of deliveries
+const (
+	SignatureHeader = "X-Webhook-Signature"
+	EventHeader     = "X-Webhook-Event"
+	DeliveryHeader  = "X-Webhook-Delivery"
+)
+
+// Event is something that happened to a link
+type Event struct {
+	// ID identifies the event, an event published again keeps its id so receivers can drop repeated deliveries
+	ID   string    `firestore:"id" json:"id"`
+	Type string    `firestore:"type" json:"type"`
+	At   time.Time `firestore:"at" json:"at"`
+	// ShortURL and Domain identify the link
+	ShortURL string `firestore:"short_url" json:"short_url"`
+	Domain   string `firestore:"domain,omitempty" json:"domain,omitempty"`
+	LongURL  string `firestore:"long_url,omitempty" json:"long_url,omitempty"`
+	// Clicks is the number of counted clicks of click events
+	Clicks int64 `firestore:"clicks,omitempty" json:"clicks,omitempty"`
+}
+
+// IsType reports whether the event type exists
+func IsType(eventType string) bool {
+	for _, known := range Types {
+		if known == eventType {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Sign returns the signature header of a payload sent at the given time
+// The signature is the hex HMAC-SHA256 of "<unix seconds>.<payload>" with the subscription secret,
+// receivers should recompute it and reject old timestamps to prevent replays
+func Sign(secret string, at time.Time, payload []byte) string {
+	timestamp := strconv.FormatInt(at.Unix(), 10)
+	return fmt.Sprintf("t=%s,v1=%s", timestamp, digest(secret, timestamp, payload))
+}
+
+// Verify checks a signature header against the payload, signatures older than tolerance are rejected
+func Verify(secret, header string, payload []byte, now time.Time, tolerance time.Duration) error {
+	var timestamp, signature string
+	for _, part := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(part, "=")
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			signature = value
+		}
+	}
+
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || signature == "" {
+		return fmt.Errorf("malformed signature header")
+	}
+
+	if now.Sub(time.Unix(seconds, 0)) > tolerance {
+		return fmt.Errorf("signature is too old")
+	}
+
+	if !hmac.Equal([]byte(signature), []byte(digest(secret, timestamp, payload))) {
+		return fmt.Errorf("signature does not match")
+	}
+
+	return nil
+}
+
+func digest(secret, timestamp string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Backoff returns the delay before retrying a delivery after the given number of failed attempts,
+// it doubles per attempt from base up to max with up to 10% random jitter
+func Backoff(attempts int, base, max time.Duration) time.Duration {
+	delay := base
+	for i := 1; i < attempts && delay < max; i++ {
+		delay *= 2
+	}
+
+	if delay > max {
+		delay = max
+	}
+
+	return delay + time.Duration(rand.Int63n(int64(delay)/10+1))
+}
@@ -0,0 +1,53 @@
+package webhook_test
+
+import (
+	"time"
+	"url-shortener/pkg/webhook"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Webhook", func() {
+	var (
+		payload = []byte(`{"type":"link.created"}`)
+		at      = time.Date(2023, 3, 1, 12, 0, 0, 0, time.UTC)
+	)
+
+	Describe("Sign and Verify", func() {
+		It("should accept a fresh signature of the payload", func() {
+			header := webhook.Sign("secret", at, payload)
+
+			Expect(header).To(HavePrefix("t=1677672000,v1="))
+			Expect(webhook.Verify("secret", header, payload, at.Add(time.Minute), 5*time.Minute)).To(Succeed())
+		})
+
+		It("should reject another secret, another payload and old signatures", func() {
+			header := webhook.Sign("secret", at, payload)
+
+			Expect(webhook.Verify("other", header, payload, at, 5*time.Minute)).NotTo(Succeed())
+			Expect(webhook.Verify("secret", header, []byte(`{}`), at, 5*time.Minute)).NotTo(Succeed())
+			Expect(webhook.Verify("secret", header, payload, at.Add(10*time.Minute), 5*time.Minute)).NotTo(Succeed())
+		})
+
+		It("should reject malformed headers", func() {
+			Expect(webhook.Verify("secret", "v1=abc", payload, at, time.Minute)).NotTo(Succeed())
+			Expect(webhook.Verify("secret", "t=1677672000", payload, at, time.Minute)).NotTo(Succeed())
+		})
+	})
+
+	Describe("Backoff", func() {
+		It("should double the delay per attempt up to the maximum", func() {
+			Expect(webhook.Backoff(1, time.Second, time.Minute)).To(BeNumerically("~", time.Second, 100*time.Millisecond))
+			Expect(webhook.Backoff(3, time.Second, time.Minute)).To(BeNumerically("~", 4*time.Second, 400*time.Millisecond))
+			Expect(webhook.Backoff(20, time.Second, time.Minute)).To(BeNumerically("~", time.Minute, 6*time.Second))
+		})
+	})
+
+	Describe("IsType", func() {
+		It("should only know the link event types", func() {
+			Expect(webhook.IsType(webhook.LinkExpired)).To(BeTrue())
+			Expect(webhook.IsType("link.renamed")).To(BeFalse())
+		})
+	})
+})